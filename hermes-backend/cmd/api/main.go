@@ -4,18 +4,24 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/amaydixit11/hermes/hermes-backend/internal/api"
 	"github.com/amaydixit11/hermes/hermes-backend/internal/config"
 	"github.com/amaydixit11/hermes/hermes-backend/internal/database"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/gateway"
 	repoPostgres "github.com/amaydixit11/hermes/hermes-backend/internal/repository/postgres"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/security"
 	"github.com/amaydixit11/hermes/hermes-backend/internal/service"
 	"github.com/amaydixit11/hermes/hermes-backend/internal/worker"
 	"github.com/amaydixit11/hermes/hermes-backend/pkg/logger"
@@ -28,6 +34,7 @@ func main() {
 	migrateFlag := flag.Bool("migrate", false, "Run database migrations")
 	migrationsPath := flag.String("migrations", "./migrations", "Path to migrations directory")
 	rollbackFlag := flag.Bool("rollback", false, "Rollback the last migration")
+	reencryptFlag := flag.Bool("reencrypt", false, "Re-encrypt secrets stored in the database with the current encryption key")
 	flag.Parse()
 
 	// Load configuration
@@ -78,33 +85,301 @@ func main() {
 	}
 	defer sqlDB.Close()
 
+	// Initialize encryption of secrets stored in the database
+	secretBox, err := newSecretBox(cfg)
+	if err != nil {
+		log.Fatal("Failed to initialize encryption", "error", err)
+	}
+	encryptionService := service.NewEncryptionService(repoPostgres.NewEncryptionRepository(db), secretBox, cfg.Encryption.SecretMetadataKeys, log)
+
+	// Re-encrypt stored secrets after a key rotation if requested
+	if *reencryptFlag {
+		counts, err := encryptionService.Reencrypt(context.Background())
+		if err != nil {
+			log.Fatal("Re-encryption failed", "error", err, "counts", counts)
+		}
+		log.Info("Re-encryption completed successfully", "counts", counts)
+		return
+	}
+
 	// Initialize repositories and services
 	serviceRepo := repoPostgres.NewServiceRepository(db)
 	healthRepo := repoPostgres.NewHealthRepositoryGorm(db)
-	serviceService := service.NewServiceService(serviceRepo, log)
-	healthService := service.NewHealthService(healthRepo, serviceRepo, log)
+	teamRepo := repoPostgres.NewTeamRepository(db)
+	routeRepo := repoPostgres.NewRouteRepository(db)
+	userRepo := repoPostgres.NewUserRepository(db)
+	apiSpecRepo := repoPostgres.NewAPISpecRepository(db)
+	policy := security.NewPolicy()
+	auditService := service.NewAuditService(repoPostgres.NewAuditRepository(db), log)
+	namespaceService := service.NewNamespaceService(repoPostgres.NewNamespaceRepository(db), serviceRepo, routeRepo, userRepo, teamRepo, policy, auditService, log)
+	serviceService := service.NewServiceService(serviceRepo, teamRepo, apiSpecRepo, namespaceService, encryptionService, auditService, log)
+	instanceService := service.NewInstanceService(repoPostgres.NewServiceInstanceRepository(db), serviceRepo, healthRepo, encryptionService, auditService, log)
+	healthService := service.NewHealthService(healthRepo, serviceRepo, instanceService, encryptionService, auditService, log)
 	healthCheckManager := worker.NewHealthCheckManager(healthRepo, healthService, log)
 	go healthCheckManager.Start()
 
-	// Set up HTTP router
-	router := api.SetupRouter(cfg, log, serviceService, healthService)
+	// Expire services that stop sending heartbeats
+	heartbeatPolicy := service.HeartbeatPolicy{
+		DefaultTTL:      cfg.Heartbeat.DefaultTTL,
+		UnknownAfter:    cfg.Heartbeat.UnknownAfter,
+		UnhealthyAfter:  cfg.Heartbeat.UnhealthyAfter,
+		DeregisterAfter: cfg.Heartbeat.DeregisterAfter,
+	}
+	switch cfg.Heartbeat.Deregistration {
+	case "", "soft_delete":
+	case "delete":
+		heartbeatPolicy.Delete = true
+	default:
+		log.Fatal("Invalid heartbeat deregistration, must be soft_delete or delete", "deregistration", cfg.Heartbeat.Deregistration)
+	}
+	heartbeatService := service.NewHeartbeatService(serviceRepo, healthRepo, instanceService, heartbeatPolicy, auditService, log)
+	heartbeatInterval := time.Duration(cfg.Heartbeat.Interval) * time.Second
+	if heartbeatInterval <= 0 {
+		heartbeatInterval = 10 * time.Second
+	}
+	heartbeatMonitor := worker.NewHeartbeatMonitor(heartbeatService, heartbeatInterval, log)
+	go heartbeatMonitor.Start()
 
-	// Start HTTP server with proper timeouts
-	srv := &http.Server{
-		Addr:         "localhost:" + strconv.Itoa(cfg.Server.Port),
-		Handler:      router,
-		ReadTimeout:  time.Duration(cfg.Server.TimeoutRead) * time.Second,
-		WriteTimeout: time.Duration(cfg.Server.TimeoutWrite) * time.Second,
-		IdleTimeout:  time.Duration(cfg.Server.TimeoutIdle) * time.Second,
+	// Initialize the certificate authority used for mTLS to upstreams
+	var ca *security.CertificateAuthority
+	if cfg.MTLS.Enabled {
+		ca, err = security.NewCertificateAuthority(security.CAConfig{
+			TrustDomain: cfg.MTLS.TrustDomain,
+			CertFile:    cfg.MTLS.CACertFile,
+			KeyFile:     cfg.MTLS.CAKeyFile,
+			CertTTL:     time.Duration(cfg.MTLS.CertTTL) * time.Minute,
+		})
+		if err != nil {
+			log.Fatal("Failed to initialize certificate authority", "error", err)
+		}
+		log.Info("Certificate authority initialized", "trust_domain", cfg.MTLS.TrustDomain)
+	}
+	certificateService := service.NewCertificateService(ca, serviceRepo, log)
+
+	// Initialize the gateway proxy and keep its routes in sync with the database
+	proxy := gateway.NewProxy(log)
+	trustedProxies, err := security.ParseCIDRs(cfg.Gateway.TrustedProxies)
+	if err != nil {
+		log.Fatal("Invalid gateway trusted proxies", "error", err)
 	}
+	proxy.SetTrustedProxies(trustedProxies)
+	if ca != nil {
+		identity := security.NewCertificateRotator(func(ctx context.Context) (*security.WorkloadCertificate, error) {
+			return ca.Issue(ca.GatewaySpiffeID(), "")
+		})
+		proxy.SetTransport(gateway.NewMTLSTransport(identity))
+	}
+	keyAuth := gateway.NewKeyAuth(log)
+	signatureAuth := gateway.NewSignatureAuth(log)
+	jwtIssuers := make([]gateway.JWTIssuer, len(cfg.Gateway.JWTIssuers))
+	for i, issuer := range cfg.Gateway.JWTIssuers {
+		jwtIssuers[i] = gateway.JWTIssuer{
+			Name:            issuer.Name,
+			Issuer:          issuer.Issuer,
+			JWKSURL:         issuer.JWKSURL,
+			JWKSFile:        issuer.JWKSFile,
+			RefreshInterval: time.Duration(issuer.RefreshInterval) * time.Second,
+		}
+	}
+	jwtValidator, err := gateway.NewJWTValidator(jwtIssuers, log)
+	if err != nil {
+		log.Fatal("Failed to initialize JWT issuers", "error", err)
+	}
+	ipAccess := gateway.NewIPAccessControl(log)
+	cors := gateway.NewCORSHandler(log)
+	requestValidator := gateway.NewRequestValidator(log)
+	rateLimiter := gateway.NewRateLimiter(log)
+	adaptiveLimiters := gateway.NewAdaptiveLimiters(log)
+	bulkheads := gateway.NewBulkheads(log)
+	loadShedder := gateway.NewLoadShedder(gateway.LoadShedderConfig{
+		MaxInFlight:    cfg.Gateway.LoadShedding.MaxInFlight,
+		MaxQueueDepth:  cfg.Gateway.LoadShedding.MaxQueueDepth,
+		MaxCPUPercent:  cfg.Gateway.LoadShedding.MaxCPUPercent,
+		SheddableRatio: cfg.Gateway.LoadShedding.SheddableRatio,
+	}, bulkheads.QueueDepth, log)
+	go loadShedder.Start()
+	proxy.Use(
+		ipAccess.Middleware(),
+		cors.Middleware(),
+		keyAuth.Middleware(),
+		signatureAuth.Middleware(),
+		loadShedder.Middleware(), // After authentication, consumers can carry a criticality class
+		jwtValidator.Middleware(),
+		rateLimiter.Middleware(),
+		requestValidator.Middleware(),
+		adaptiveLimiters.Middleware(),
+		bulkheads.Middleware(),
+		gateway.FaultInjection(log),
+	)
+	proxy.RegisterStats("adaptive_limiters", adaptiveLimiters)
+	proxy.RegisterStats("bulkheads", bulkheads)
+	proxy.RegisterStats("load_shedder", loadShedder)
+	proxy.RegisterStats("consumers", keyAuth)
+	proxy.RegisterStats("signatures", signatureAuth)
+	proxy.RegisterStats("ip_access", ipAccess)
+	proxy.RegisterStats("cors", cors)
+	proxy.RegisterStats("request_validation", requestValidator)
+	routeService := service.NewRouteService(routeRepo, serviceRepo, apiSpecRepo, namespaceService, proxy, jwtValidator, requestValidator, ipAccess, cors, auditService, log)
+	apiSpecService := service.NewAPISpecService(apiSpecRepo, routeService, auditService, log)
+	syncInterval := time.Duration(cfg.Gateway.SyncInterval) * time.Second
+	if syncInterval <= 0 {
+		syncInterval = 10 * time.Second
+	}
+	routeSyncer := worker.NewRouteSyncer(routeService, syncInterval, log)
+	go routeSyncer.Start()
+
+	consumerRepo := repoPostgres.NewConsumerRepository(db)
+	consumerService := service.NewConsumerService(consumerRepo, routeRepo, keyAuth, signatureAuth, secretBox, log)
+	signatureAuth.SetNonceStore(consumerService)
+	consumerSyncer := worker.NewConsumerSyncer(consumerService, syncInterval, log)
+	go consumerSyncer.Start()
 
-	// Start server in goroutine
-	go func() {
-		log.Info("Starting server", "address", cfg.Server.Port)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatal("Failed to start server", "error", err)
+	// Initialize the TLS certificate store, kept in sync with the database
+	certificateStore := security.NewCertificateStore()
+	tlsCertificateRepo := repoPostgres.NewTLSCertificateRepository(db)
+	tlsCertificateService := service.NewTLSCertificateService(tlsCertificateRepo, secretBox, certificateStore, cfg.TLS.DefaultCertificate, log)
+	var tlsMonitor *worker.TLSCertificateMonitor
+	if cfg.TLS.Enabled {
+		reloadInterval := time.Duration(cfg.TLS.ReloadInterval) * time.Second
+		if reloadInterval <= 0 {
+			reloadInterval = time.Minute
+		}
+		warnBefore := time.Duration(cfg.TLS.ExpiryWarningDays) * 24 * time.Hour
+		if warnBefore <= 0 {
+			warnBefore = 14 * 24 * time.Hour
 		}
-	}()
+		if err := tlsCertificateService.Reload(context.Background()); err != nil {
+			log.Error("Failed to load TLS certificates", "error", err)
+		}
+		tlsMonitor = worker.NewTLSCertificateMonitor(tlsCertificateService, reloadInterval, warnBefore, log)
+		go tlsMonitor.Start()
+	}
+
+	// Initialize token signing and revocation, loading or generating the signing key
+	tokenConfig := service.TokenConfig{
+		Algorithm:       cfg.JWT.Algorithm,
+		AccessTokenTTL:  time.Duration(cfg.JWT.AccessTokenTTL) * time.Minute,
+		RefreshTokenTTL: time.Duration(cfg.JWT.RefreshTokenTTL) * time.Hour,
+		KeyRotation:     time.Duration(cfg.JWT.KeyRotationDays) * 24 * time.Hour,
+	}
+	if tokenConfig.Algorithm == "" {
+		tokenConfig.Algorithm = security.AlgorithmEdDSA
+	}
+	if tokenConfig.AccessTokenTTL <= 0 {
+		tokenConfig.AccessTokenTTL = 15 * time.Minute
+	}
+	if tokenConfig.RefreshTokenTTL <= 0 {
+		tokenConfig.RefreshTokenTTL = 30 * 24 * time.Hour
+	}
+	if tokenConfig.KeyRotation <= 0 {
+		tokenConfig.KeyRotation = 30 * 24 * time.Hour
+	}
+	issuer := cfg.JWT.Issuer
+	if issuer == "" {
+		issuer = "hermes"
+	}
+	keySet := security.NewKeySet()
+	revocations := security.NewRevocationList()
+	tokenIssuer := security.NewTokenIssuer(keySet, revocations, issuer, tokenConfig.AccessTokenTTL)
+	tokenRepo := repoPostgres.NewTokenRepository(db)
+	tokenService := service.NewTokenService(tokenRepo, userRepo, secretBox, keySet, revocations, tokenIssuer, tokenConfig, log)
+	if err := tokenService.Sync(context.Background()); err != nil {
+		log.Fatal("Failed to load token signing keys", "error", err)
+	}
+	tokenSyncInterval := time.Duration(cfg.JWT.SyncInterval) * time.Second
+	if tokenSyncInterval <= 0 {
+		tokenSyncInterval = 30 * time.Second
+	}
+	tokenKeeper := worker.NewTokenKeeper(tokenService, tokenSyncInterval, log)
+	go tokenKeeper.Start()
+
+	// Initialize multi-factor authentication and lockout after failed logins
+	mfaIssuer := cfg.Auth.MFA.Issuer
+	if mfaIssuer == "" {
+		mfaIssuer = "Hermes"
+	}
+	lockout := service.LockoutPolicy{
+		MaxAttempts: cfg.Auth.Lockout.MaxAttempts,
+		Duration:    time.Duration(cfg.Auth.Lockout.Duration) * time.Minute,
+	}
+	if lockout.Duration <= 0 {
+		lockout.Duration = 15 * time.Minute
+	}
+	mfaService := service.NewMFAService(userRepo, tokenRepo, secretBox, mfaIssuer, lockout, auditService, log)
+
+	// Initialize user accounts, creating the first admin if configured
+	userService := service.NewUserService(userRepo, tokenService, mfaService, lockout, auditService, log)
+	if admin := cfg.Auth.BootstrapAdmin; admin.Password != "" {
+		err := userService.BootstrapAdmin(context.Background(), models.UserRegistration{
+			Username: admin.Username,
+			Email:    admin.Email,
+			Password: admin.Password,
+		})
+		if err != nil {
+			log.Fatal("Failed to bootstrap admin user", "error", err)
+		}
+	}
+
+	// Initialize role-based access control with the custom roles from the database
+	roleRepo := repoPostgres.NewRoleRepository(db)
+	roleService := service.NewRoleService(roleRepo, userRepo, tokenService, policy, auditService, log)
+	if err := roleService.Reload(context.Background()); err != nil {
+		log.Fatal("Failed to load roles", "error", err)
+	}
+	roleSyncInterval := time.Duration(cfg.Auth.SyncInterval) * time.Second
+	if roleSyncInterval <= 0 {
+		roleSyncInterval = 30 * time.Second
+	}
+	roleSyncer := worker.NewRoleSyncer(roleService, roleSyncInterval, log)
+	go roleSyncer.Start()
+
+	teamService := service.NewTeamService(teamRepo, userRepo, serviceRepo, log)
+
+	// Initialize single sign-on when an OIDC provider is configured
+	var oidcService *service.OIDCService
+	if oidc := cfg.Auth.OIDC; oidc.Enabled {
+		provider, err := security.NewOIDCProvider(security.OIDCConfig{
+			IssuerURL:    oidc.IssuerURL,
+			ClientID:     oidc.ClientID,
+			ClientSecret: oidc.ClientSecret,
+			RedirectURL:  oidc.RedirectURL,
+			Scopes:       oidc.Scopes,
+			GroupsClaim:  oidc.GroupsClaim,
+		})
+		if err != nil {
+			log.Fatal("Failed to configure OIDC provider", "error", err)
+		}
+		mappings := make([]service.OIDCRoleMapping, len(oidc.RoleMappings))
+		for i, m := range oidc.RoleMappings {
+			mappings[i] = service.OIDCRoleMapping{Group: m.Group, Role: models.Role(strings.ToUpper(m.Role))}
+		}
+		oidcService = service.NewOIDCService(provider, userRepo, tokenService, mfaService, policy, secretBox, mappings, models.Role(strings.ToUpper(oidc.DefaultRole)), auditService, log)
+		log.Info("Single sign-on enabled", "issuer", oidc.IssuerURL)
+	}
+
+	// Initialize service accounts that report health for their own service
+	serviceAccountRepo := repoPostgres.NewServiceAccountRepository(db)
+	serviceAccountService := service.NewServiceAccountService(serviceAccountRepo, serviceRepo, tokenIssuer, auditService, log)
+
+	// Set up HTTP router
+	router := api.SetupRouter(cfg, log, serviceService, healthService, routeService, certificateService, tlsCertificateService, userService, tokenService, tokenIssuer, roleService, policy, teamService, oidcService, serviceAccountService, consumerService, auditService, namespaceService, apiSpecService, mfaService, heartbeatService, instanceService)
+
+	// Start the API and gateway servers, over HTTPS as well when TLS is enabled
+	gatewayHandler := proxy.Handler()
+	srv := newServer(cfg, cfg.Server.Port, router)
+	gatewaySrv := newServer(cfg, cfg.Gateway.Port, gatewayHandler)
+	go serve("server", srv, log)
+	go serve("gateway", gatewaySrv, log)
+	servers := []*http.Server{srv, gatewaySrv}
+	if cfg.TLS.Enabled {
+		tlsSrv := newServer(cfg, cfg.TLS.ServerPort, router)
+		tlsSrv.TLSConfig = certificateStore.TLSConfig()
+		gatewayTLSSrv := newServer(cfg, cfg.TLS.GatewayPort, gatewayHandler)
+		gatewayTLSSrv.TLSConfig = certificateStore.TLSConfig()
+		go serve("HTTPS server", tlsSrv, log)
+		go serve("HTTPS gateway", gatewayTLSSrv, log)
+		servers = append(servers, tlsSrv, gatewayTLSSrv)
+	}
 
 	// Wait for interrupt signal
 	quit := make(chan os.Signal, 1)
@@ -115,10 +390,69 @@ func main() {
 	// Shutdown server with timeout
 	ctxShutdown, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Server.TimeoutShutdown)*time.Second)
 	defer cancel()
-	if err := srv.Shutdown(ctxShutdown); err != nil {
-		log.Fatal("Server forced to shutdown", "error", err)
+	for _, srv := range servers {
+		if err := srv.Shutdown(ctxShutdown); err != nil {
+			log.Fatal("Server forced to shutdown", "address", srv.Addr, "error", err)
+		}
 	}
 	healthCheckManager.Stop()
+	heartbeatMonitor.Stop()
+	routeSyncer.Stop()
+	consumerSyncer.Stop()
+	loadShedder.Stop()
+	tokenKeeper.Stop()
+	roleSyncer.Stop()
+	if tlsMonitor != nil {
+		tlsMonitor.Stop()
+	}
 	log.Info("Server exited gracefully")
 	defer log.Sync()
 }
+
+// newSecretBox creates the secret box encrypting secrets stored in the
+// database, with keys from the configured provider
+func newSecretBox(cfg *config.Config) (*security.SecretBox, error) {
+	var keys *security.Keyring
+	var err error
+	switch cfg.Encryption.Provider {
+	case "env":
+		keys, err = security.ParseKeyring(cfg.Encryption.Keys)
+	case "file":
+		keys, err = security.LoadKeyFile(cfg.Encryption.KeyFile)
+	case "":
+		keys, err = security.SingleKey(cfg.EncryptionKey)
+	default:
+		err = fmt.Errorf("unknown encryption key provider: %s", cfg.Encryption.Provider)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Values sealed before keys were identified are opened with the encryption key
+	return security.NewSecretBox(keys, cfg.EncryptionKey)
+}
+
+// newServer creates an HTTP server with the configured address and timeouts
+func newServer(cfg *config.Config, port int, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:         net.JoinHostPort(cfg.Server.Address, strconv.Itoa(port)),
+		Handler:      handler,
+		ReadTimeout:  time.Duration(cfg.Server.TimeoutRead) * time.Second,
+		WriteTimeout: time.Duration(cfg.Server.TimeoutWrite) * time.Second,
+		IdleTimeout:  time.Duration(cfg.Server.TimeoutIdle) * time.Second,
+	}
+}
+
+// serve runs a server until it is shut down, using TLS when it has a TLS config
+func serve(name string, srv *http.Server, log *logger.Logger) {
+	log.Info("Starting "+name, "address", srv.Addr)
+
+	var err error
+	if srv.TLSConfig != nil {
+		err = srv.ListenAndServeTLS("", "")
+	} else {
+		err = srv.ListenAndServe()
+	}
+	if err != nil && err != http.ErrServerClosed {
+		log.Fatal("Failed to start "+name, "error", err)
+	}
+}
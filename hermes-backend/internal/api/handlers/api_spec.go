@@ -0,0 +1,133 @@
+// internal/api/handlers/api_spec.go
+package handlers
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/service"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// maxSpecDocumentSize caps the size of an uploaded API specification
+const maxSpecDocumentSize = 10 << 20
+
+// APISpecHandler handles HTTP requests for the API specifications gateway
+// requests are validated against
+type APISpecHandler struct {
+	service *service.APISpecService
+}
+
+// NewAPISpecHandler creates a new APISpecHandler
+func NewAPISpecHandler(service *service.APISpecService) *APISpecHandler {
+	return &APISpecHandler{
+		service: service,
+	}
+}
+
+// CreateSpec handles uploads of an OpenAPI document in JSON or YAML. The
+// name and description are passed as query parameters, the document as the body.
+func (h *APISpecHandler) CreateSpec(c *gin.Context) {
+	document, ok := readSpecDocument(c)
+	if !ok {
+		return
+	}
+
+	spec, err := h.service.CreateSpec(c.Request.Context(), c.Query("name"), c.Query("description"), document)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, spec)
+}
+
+// ListSpecs handles requests to list API specifications
+func (h *APISpecHandler) ListSpecs(c *gin.Context) {
+	specs, err := h.service.ListSpecs(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list API specifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"specs": specs,
+		"total": len(specs),
+	})
+}
+
+// GetSpec handles requests to retrieve an API specification
+func (h *APISpecHandler) GetSpec(c *gin.Context) {
+	spec, err := h.service.GetSpec(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to retrieve API specification")
+		return
+	}
+
+	c.JSON(http.StatusOK, spec)
+}
+
+// GetDocument handles requests to download the document of an API specification
+func (h *APISpecHandler) GetDocument(c *gin.Context) {
+	document, err := h.service.GetDocument(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to retrieve API specification")
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json", document)
+}
+
+// UpdateSpec handles requests to replace the document or description of an
+// API specification
+func (h *APISpecHandler) UpdateSpec(c *gin.Context) {
+	document, ok := readSpecDocument(c)
+	if !ok {
+		return
+	}
+	var description *string
+	if value, ok := c.GetQuery("description"); ok {
+		description = &value
+	}
+
+	spec, err := h.service.UpdateSpec(c.Request.Context(), c.Param("id"), description, document)
+	if err != nil {
+		h.handleError(c, err, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, spec)
+}
+
+// DeleteSpec handles requests to delete an API specification
+func (h *APISpecHandler) DeleteSpec(c *gin.Context) {
+	if err := h.service.DeleteSpec(c.Request.Context(), c.Param("id")); err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to delete API specification")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API specification deleted successfully"})
+}
+
+// handleError maps service errors to HTTP responses
+func (h *APISpecHandler) handleError(c *gin.Context, err error, status int, message string) {
+	switch {
+	case errors.Is(err, service.ErrAPISpecNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "API specification not found"})
+	case errors.Is(err, service.ErrAPISpecInUse):
+		c.JSON(http.StatusConflict, gin.H{"error": "API specification is used by routes or service versions"})
+	default:
+		c.JSON(status, gin.H{"error": message})
+	}
+}
+
+// readSpecDocument reads an uploaded document, responding with an error if it cannot
+func readSpecDocument(c *gin.Context) ([]byte, bool) {
+	document, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxSpecDocumentSize))
+	if err != nil {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "API specification is too large"})
+		return nil, false
+	}
+	return document, true
+}
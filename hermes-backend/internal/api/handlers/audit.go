@@ -0,0 +1,62 @@
+// internal/api/handlers/audit.go
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// AuditHandler handles HTTP requests for the audit log
+type AuditHandler struct {
+	service *service.AuditService
+}
+
+// NewAuditHandler creates a new AuditHandler
+func NewAuditHandler(service *service.AuditService) *AuditHandler {
+	return &AuditHandler{
+		service: service,
+	}
+}
+
+// ListEntries handles requests to search the audit log
+func (h *AuditHandler) ListEntries(c *gin.Context) {
+	var params models.AuditQueryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid query parameters: " + err.Error()})
+		return
+	}
+
+	entries, total, err := h.service.ListEntries(c.Request.Context(), params)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list audit entries"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"entries": entries,
+		"total":   total,
+	})
+}
+
+// ExportEntries handles requests to download the matching audit entries as CSV
+func (h *AuditHandler) ExportEntries(c *gin.Context) {
+	var params models.AuditQueryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid query parameters: " + err.Error()})
+		return
+	}
+
+	filename := "audit-" + time.Now().UTC().Format("20060102-150405") + ".csv"
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+
+	// Headers are already sent, so a failure can only cut the file short
+	if err := h.service.ExportCSV(c.Request.Context(), params, c.Writer); err != nil {
+		_ = c.Error(err)
+	}
+}
@@ -0,0 +1,336 @@
+// internal/api/handlers/auth.go
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/security"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/service"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// AuthHandler handles HTTP requests for user accounts and authentication
+type AuthHandler struct {
+	service *service.UserService
+	tokens  *service.TokenService
+	mfa     *service.MFAService
+}
+
+// NewAuthHandler creates a new AuthHandler
+func NewAuthHandler(service *service.UserService, tokens *service.TokenService, mfa *service.MFAService) *AuthHandler {
+	return &AuthHandler{
+		service: service,
+		tokens:  tokens,
+		mfa:     mfa,
+	}
+}
+
+// Register handles user registration requests
+func (h *AuthHandler) Register(c *gin.Context) {
+	var req models.UserRegistration
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.service.Register(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, user)
+}
+
+// Login handles login requests and returns an access and refresh token, or
+// an MFA challenge for users with multi-factor authentication
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req models.UserLogin
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	token, challenge, err := h.service.Login(c.Request.Context(), req, sessionMetadata(c))
+	if err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to log in")
+		return
+	}
+	if challenge != nil {
+		c.JSON(http.StatusOK, challenge)
+		return
+	}
+
+	c.JSON(http.StatusOK, token)
+}
+
+// MFALogin handles requests completing a login with a TOTP or recovery code
+func (h *AuthHandler) MFALogin(c *gin.Context) {
+	var req models.MFALoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Code == "" && req.RecoveryCode == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "code or recovery_code is required"})
+		return
+	}
+
+	token, err := h.service.CompleteMFALogin(c.Request.Context(), req, sessionMetadata(c))
+	if err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to log in")
+		return
+	}
+
+	c.JSON(http.StatusOK, token)
+}
+
+// EnrollTOTP handles requests to start enrolling an authenticator app
+func (h *AuthHandler) EnrollTOTP(c *gin.Context) {
+	var req models.TOTPEnrollmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	enrollment, err := h.mfa.EnrollTOTP(c.Request.Context(), c.GetString("userID"), req.Password)
+	if err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to enroll authenticator")
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// ConfirmTOTP handles requests enabling MFA with a code from the enrolled
+// authenticator and returns the recovery codes
+func (h *AuthHandler) ConfirmTOTP(c *gin.Context) {
+	var req models.TOTPConfirmation
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	codes, err := h.mfa.ConfirmTOTP(c.Request.Context(), c.GetString("userID"), req.Code)
+	if err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to enable MFA")
+		return
+	}
+
+	c.JSON(http.StatusOK, codes)
+}
+
+// DisableTOTP handles requests to turn MFA off with a TOTP or recovery code
+func (h *AuthHandler) DisableTOTP(c *gin.Context) {
+	var req models.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.mfa.DisableTOTP(c.Request.Context(), c.GetString("userID"), req); err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to disable MFA")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "MFA disabled successfully"})
+}
+
+// RegenerateRecoveryCodes handles requests to replace the recovery codes
+func (h *AuthHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req models.TOTPConfirmation
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	codes, err := h.mfa.RegenerateRecoveryCodes(c.Request.Context(), c.GetString("userID"), req.Code)
+	if err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to regenerate recovery codes")
+		return
+	}
+
+	c.JSON(http.StatusOK, codes)
+}
+
+// ListSessions handles requests for the authenticated user's sessions
+func (h *AuthHandler) ListSessions(c *gin.Context) {
+	claims := c.MustGet("claims").(*security.Claims)
+	sessions, err := h.tokens.ListSessions(c.Request.Context(), claims.Subject, claims.SessionID)
+	if err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to list sessions")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sessions": sessions})
+}
+
+// RevokeSession handles requests to end one of the authenticated user's sessions
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	if err := h.tokens.RevokeSession(c.Request.Context(), c.GetString("userID"), c.Param("id"), "revoked by user"); err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to revoke session")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked successfully"})
+}
+
+// ForceLogout handles requests to end every session of a user
+func (h *AuthHandler) ForceLogout(c *gin.Context) {
+	if err := h.service.ForceLogout(c.Request.Context(), c.Param("id")); err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to log out user")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User logged out successfully"})
+}
+
+// UnlockUser handles requests to lift a user's lockout after failed logins
+func (h *AuthHandler) UnlockUser(c *gin.Context) {
+	if err := h.service.UnlockUser(c.Request.Context(), c.Param("id")); err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to unlock user")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User unlocked successfully"})
+}
+
+// ResetMFA handles requests to turn MFA off for a user who lost their authenticator
+func (h *AuthHandler) ResetMFA(c *gin.Context) {
+	if err := h.mfa.ResetMFA(c.Request.Context(), c.Param("id")); err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to reset MFA")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "MFA reset successfully"})
+}
+
+// Me handles requests for the authenticated user's account
+func (h *AuthHandler) Me(c *gin.Context) {
+	user, err := h.service.GetUser(c.Request.Context(), c.GetString("userID"))
+	if err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to retrieve user")
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// ChangePassword handles requests to change the authenticated user's password
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	var req models.UserPasswordChange
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	claims := c.MustGet("claims").(*security.Claims)
+	if err := h.service.ChangePassword(c.Request.Context(), claims.Subject, claims.SessionID, req); err != nil {
+		h.handleError(c, err, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password changed successfully"})
+}
+
+// Refresh handles requests to exchange a refresh token for new tokens
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req models.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	token, err := h.tokens.Refresh(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to refresh token")
+		return
+	}
+
+	c.JSON(http.StatusOK, token)
+}
+
+// Logout handles requests to revoke the caller's access token and session
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req models.LogoutRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	claims := c.MustGet("claims").(*security.Claims)
+	if err := h.tokens.Logout(c.Request.Context(), claims, req.RefreshToken); err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to log out")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+// RevokeToken handles requests to revoke a leaked token or a user's sessions
+func (h *AuthHandler) RevokeToken(c *gin.Context) {
+	var req models.TokenRevocationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.tokens.Revoke(c.Request.Context(), req); err != nil {
+		h.handleError(c, err, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Token revoked successfully"})
+}
+
+// RotateKeys handles requests to start signing tokens with a new key
+func (h *AuthHandler) RotateKeys(c *gin.Context) {
+	if err := h.tokens.RotateKeys(c.Request.Context()); err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to rotate signing keys")
+		return
+	}
+
+	c.JSON(http.StatusOK, h.tokens.JWKS())
+}
+
+// JWKS handles requests for the public keys that verify access tokens
+func (h *AuthHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.tokens.JWKS())
+}
+
+// handleError maps service errors to HTTP responses
+func (h *AuthHandler) handleError(c *gin.Context, err error, status int, message string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidCredentials):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid username or password"})
+	case errors.Is(err, service.ErrInvalidRefreshToken):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+	case errors.Is(err, service.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	case errors.Is(err, service.ErrAccountLocked):
+		c.JSON(http.StatusLocked, gin.H{"error": "Account is temporarily locked after too many failed logins"})
+	case errors.Is(err, service.ErrInvalidMFACode):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication code"})
+	case errors.Is(err, service.ErrInvalidMFAToken):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired MFA token, log in again"})
+	case errors.Is(err, service.ErrMFAAlreadyEnabled), errors.Is(err, service.ErrMFANotEnabled), errors.Is(err, service.ErrMFANotEnrolled):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrSessionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+	default:
+		c.JSON(status, gin.H{"error": message})
+	}
+}
+
+// sessionMetadata describes the client a session is started from
+func sessionMetadata(c *gin.Context) models.SessionMetadata {
+	return models.SessionMetadata{
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
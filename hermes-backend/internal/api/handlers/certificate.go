@@ -0,0 +1,64 @@
+// internal/api/handlers/certificate.go
+package handlers
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/service"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// CertificateHandler handles HTTP requests for workload certificates
+type CertificateHandler struct {
+	service *service.CertificateService
+}
+
+// NewCertificateHandler creates a new CertificateHandler
+func NewCertificateHandler(service *service.CertificateService) *CertificateHandler {
+	return &CertificateHandler{
+		service: service,
+	}
+}
+
+// IssueCertificate handles requests from services for their workload certificate
+func (h *CertificateHandler) IssueCertificate(c *gin.Context) {
+	var req models.CertificateRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cert, err := h.service.IssueServiceCertificate(c.Request.Context(), c.Param("id"), req.CSR)
+	if err != nil {
+		h.handleError(c, err, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, cert)
+}
+
+// GetTrustBundle handles requests for the PEM encoded trust bundle
+func (h *CertificateHandler) GetTrustBundle(c *gin.Context) {
+	bundle, err := h.service.TrustBundle()
+	if err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to retrieve trust bundle")
+		return
+	}
+
+	c.Data(http.StatusOK, "application/x-pem-file", []byte(bundle))
+}
+
+// handleError maps service errors to HTTP responses
+func (h *CertificateHandler) handleError(c *gin.Context, err error, status int, message string) {
+	switch {
+	case errors.Is(err, service.ErrServiceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Service not found"})
+	case errors.Is(err, service.ErrCANotEnabled):
+		c.JSON(http.StatusNotFound, gin.H{"error": "mTLS is not enabled"})
+	default:
+		c.JSON(status, gin.H{"error": message})
+	}
+}
@@ -0,0 +1,192 @@
+// internal/api/handlers/consumer.go
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/service"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// ConsumerHandler handles HTTP requests for gateway consumers and their API keys
+type ConsumerHandler struct {
+	service *service.ConsumerService
+}
+
+// NewConsumerHandler creates a new ConsumerHandler
+func NewConsumerHandler(service *service.ConsumerService) *ConsumerHandler {
+	return &ConsumerHandler{
+		service: service,
+	}
+}
+
+// CreateConsumer handles requests to create a consumer
+func (h *ConsumerHandler) CreateConsumer(c *gin.Context) {
+	var req models.ConsumerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	consumer, err := h.service.CreateConsumer(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, consumer)
+}
+
+// ListConsumers handles requests to list consumers
+func (h *ConsumerHandler) ListConsumers(c *gin.Context) {
+	consumers, err := h.service.ListConsumers(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list consumers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"consumers": consumers,
+		"total":     len(consumers),
+	})
+}
+
+// GetConsumer handles requests to retrieve a consumer
+func (h *ConsumerHandler) GetConsumer(c *gin.Context) {
+	consumer, err := h.service.GetConsumer(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to retrieve consumer")
+		return
+	}
+
+	c.JSON(http.StatusOK, consumer)
+}
+
+// UpdateConsumer handles requests to update or deactivate a consumer
+func (h *ConsumerHandler) UpdateConsumer(c *gin.Context) {
+	var req models.ConsumerUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	consumer, err := h.service.UpdateConsumer(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		h.handleError(c, err, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, consumer)
+}
+
+// DeleteConsumer handles requests to delete a consumer
+func (h *ConsumerHandler) DeleteConsumer(c *gin.Context) {
+	if err := h.service.DeleteConsumer(c.Request.Context(), c.Param("id")); err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to delete consumer")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Consumer deleted successfully"})
+}
+
+// CreateKey handles requests to create an API key for a consumer
+func (h *ConsumerHandler) CreateKey(c *gin.Context) {
+	var req models.ConsumerKeyRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	key, err := h.service.CreateKey(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to create API key")
+		return
+	}
+
+	c.JSON(http.StatusCreated, key)
+}
+
+// ListKeys handles requests to list the API keys of a consumer
+func (h *ConsumerHandler) ListKeys(c *gin.Context) {
+	keys, err := h.service.ListKeys(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to list API keys")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"keys":  keys,
+		"total": len(keys),
+	})
+}
+
+// RevokeKey handles requests to revoke an API key
+func (h *ConsumerHandler) RevokeKey(c *gin.Context) {
+	if err := h.service.RevokeKey(c.Request.Context(), c.Param("id"), c.Param("key_id")); err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to revoke API key")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked successfully"})
+}
+
+// CreateSecret handles requests to create a signing secret for a consumer
+func (h *ConsumerHandler) CreateSecret(c *gin.Context) {
+	var req models.ConsumerSecretRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	secret, err := h.service.CreateSecret(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to create signing secret")
+		return
+	}
+
+	c.JSON(http.StatusCreated, secret)
+}
+
+// ListSecrets handles requests to list the signing secrets of a consumer
+func (h *ConsumerHandler) ListSecrets(c *gin.Context) {
+	secrets, err := h.service.ListSecrets(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to list signing secrets")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"secrets": secrets,
+		"total":   len(secrets),
+	})
+}
+
+// RevokeSecret handles requests to revoke a signing secret
+func (h *ConsumerHandler) RevokeSecret(c *gin.Context) {
+	if err := h.service.RevokeSecret(c.Request.Context(), c.Param("id"), c.Param("secret_id")); err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to revoke signing secret")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Signing secret revoked successfully"})
+}
+
+// handleError maps service errors to HTTP responses
+func (h *ConsumerHandler) handleError(c *gin.Context, err error, status int, message string) {
+	switch {
+	case errors.Is(err, service.ErrConsumerNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Consumer not found"})
+	case errors.Is(err, service.ErrConsumerKeyNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+	case errors.Is(err, service.ErrConsumerSecretNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Signing secret not found"})
+	default:
+		c.JSON(status, gin.H{"error": message})
+	}
+}
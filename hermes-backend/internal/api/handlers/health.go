@@ -7,16 +7,19 @@ import (
 
 	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
 	"github.com/amaydixit11/hermes/hermes-backend/internal/service"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/errors"
 	"github.com/gin-gonic/gin"
 )
 
 type HealthHandler struct {
-	healthService *service.HealthService
+	healthService    *service.HealthService
+	heartbeatService *service.HeartbeatService
 }
 
-func NewHealthHandler(healthService *service.HealthService) *HealthHandler {
+func NewHealthHandler(healthService *service.HealthService, heartbeatService *service.HeartbeatService) *HealthHandler {
 	return &HealthHandler{
-		healthService: healthService,
+		healthService:    healthService,
+		heartbeatService: heartbeatService,
 	}
 }
 func (h *HealthHandler) CreateHealthCheck(c *gin.Context) {
@@ -32,7 +35,7 @@ func (h *HealthHandler) CreateHealthCheck(c *gin.Context) {
 		return
 	}
 
-	check, err := h.healthService.CreateHealthCheck(c, serviceID, req)
+	check, err := h.healthService.CreateHealthCheck(c.Request.Context(), serviceID, req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create health check: " + err.Error()})
 		return
@@ -48,7 +51,7 @@ func (h *HealthHandler) GetHealthChecks(c *gin.Context) {
 		return
 	}
 
-	checks, err := h.healthService.GetHealthChecks(c, serviceID)
+	checks, err := h.healthService.GetHealthChecks(c.Request.Context(), serviceID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get health checks: " + err.Error()})
 		return
@@ -70,7 +73,7 @@ func (h *HealthHandler) GetHealthCheck(c *gin.Context) {
 		return
 	}
 
-	check, err := h.healthService.GetHealthCheck(c, uint(id))
+	check, err := h.healthService.GetHealthCheck(c.Request.Context(), uint(id))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "health check not found"})
 		return
@@ -98,7 +101,7 @@ func (h *HealthHandler) UpdateHealthCheck(c *gin.Context) {
 		return
 	}
 
-	check, err := h.healthService.UpdateHealthCheck(c, uint(id), req)
+	check, err := h.healthService.UpdateHealthCheck(c.Request.Context(), uint(id), req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update health check: " + err.Error()})
 		return
@@ -120,7 +123,7 @@ func (h *HealthHandler) DeleteHealthCheck(c *gin.Context) {
 		return
 	}
 
-	err = h.healthService.DeleteHealthCheck(c, uint(id))
+	err = h.healthService.DeleteHealthCheck(c.Request.Context(), uint(id))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete health check: " + err.Error()})
 		return
@@ -142,7 +145,7 @@ func (h *HealthHandler) ReportServiceHealth(c *gin.Context) {
 		return
 	}
 
-	err := h.healthService.ReportServiceHealth(c, serviceID, req)
+	err := h.healthService.ReportServiceHealth(c.Request.Context(), serviceID, req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to report service health: " + err.Error()})
 		return
@@ -151,6 +154,37 @@ func (h *HealthHandler) ReportServiceHealth(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "health status updated successfully"})
 }
 
+// Heartbeat handles heartbeats sent by a service to show it is alive. The
+// body is optional and defaults to a healthy status.
+func (h *HealthHandler) Heartbeat(c *gin.Context) {
+	var req models.ServiceHeartbeat
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+			return
+		}
+	}
+
+	svc, err := h.heartbeatService.Heartbeat(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrServiceNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "service not found"})
+		case errors.Is(err, service.ErrInvalidHeartbeatStatus), errors.Is(err, service.ErrInvalidHeartbeatTTL):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to record heartbeat"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":        svc.Status,
+		"last_seen":     svc.LastSeen,
+		"heartbeat_ttl": svc.HeartbeatTTL,
+	})
+}
+
 func (h *HealthHandler) GetHealthHistory(c *gin.Context) {
 	serviceID := c.Param("id")
 	if serviceID == "" {
@@ -164,7 +198,7 @@ func (h *HealthHandler) GetHealthHistory(c *gin.Context) {
 		return
 	}
 
-	history, total, err := h.healthService.GetHealthHistory(c, serviceID, params)
+	history, total, err := h.healthService.GetHealthHistory(c.Request.Context(), serviceID, params)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get health history: " + err.Error()})
 		return
@@ -183,7 +217,7 @@ func (h *HealthHandler) GetCustomMetrics(c *gin.Context) {
 		return
 	}
 
-	metrics, err := h.healthService.GetCustomMetrics(c, serviceID)
+	metrics, err := h.healthService.GetCustomMetrics(c.Request.Context(), serviceID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get custom metrics: " + err.Error()})
 		return
@@ -205,7 +239,7 @@ func (h *HealthHandler) CreateOrUpdateCustomMetric(c *gin.Context) {
 		return
 	}
 
-	err := h.healthService.CreateOrUpdateMetric(c, serviceID, req)
+	err := h.healthService.CreateOrUpdateMetric(c.Request.Context(), serviceID, req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create/update metric: " + err.Error()})
 		return
@@ -227,7 +261,7 @@ func (h *HealthHandler) CreateHealthThreshold(c *gin.Context) {
 		return
 	}
 
-	threshold, err := h.healthService.CreateHealthThreshold(c, serviceID, req)
+	threshold, err := h.healthService.CreateHealthThreshold(c.Request.Context(), serviceID, req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create health threshold: " + err.Error()})
 		return
@@ -243,7 +277,7 @@ func (h *HealthHandler) GetHealthThresholds(c *gin.Context) {
 		return
 	}
 
-	thresholds, err := h.healthService.GetHealthThresholds(c, serviceID)
+	thresholds, err := h.healthService.GetHealthThresholds(c.Request.Context(), serviceID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get health thresholds: " + err.Error()})
 		return
@@ -271,7 +305,7 @@ func (h *HealthHandler) UpdateHealthThreshold(c *gin.Context) {
 		return
 	}
 
-	threshold, err := h.healthService.UpdateHealthThreshold(c, uint(id), req)
+	threshold, err := h.healthService.UpdateHealthThreshold(c.Request.Context(), uint(id), req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update health threshold: " + err.Error()})
 		return
@@ -293,7 +327,7 @@ func (h *HealthHandler) DeleteHealthThreshold(c *gin.Context) {
 		return
 	}
 
-	err = h.healthService.DeleteHealthThreshold(c, uint(id))
+	err = h.healthService.DeleteHealthThreshold(c.Request.Context(), uint(id))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete health threshold: " + err.Error()})
 		return
@@ -0,0 +1,149 @@
+// internal/api/handlers/namespace.go
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/service"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// NamespaceHandler handles HTTP requests for namespaces and their role bindings
+type NamespaceHandler struct {
+	service *service.NamespaceService
+}
+
+// NewNamespaceHandler creates a new NamespaceHandler
+func NewNamespaceHandler(service *service.NamespaceService) *NamespaceHandler {
+	return &NamespaceHandler{
+		service: service,
+	}
+}
+
+// CreateNamespace handles requests to create a namespace
+func (h *NamespaceHandler) CreateNamespace(c *gin.Context) {
+	var req models.NamespaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	namespace, err := h.service.CreateNamespace(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, namespace)
+}
+
+// ListNamespaces handles requests to list namespaces
+func (h *NamespaceHandler) ListNamespaces(c *gin.Context) {
+	namespaces, err := h.service.ListNamespaces(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list namespaces"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"namespaces": namespaces,
+		"total":      len(namespaces),
+	})
+}
+
+// GetNamespace handles requests to get a namespace by name
+func (h *NamespaceHandler) GetNamespace(c *gin.Context) {
+	namespace, err := h.service.GetNamespace(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to retrieve namespace")
+		return
+	}
+
+	c.JSON(http.StatusOK, namespace)
+}
+
+// UpdateNamespace handles requests to update a namespace
+func (h *NamespaceHandler) UpdateNamespace(c *gin.Context) {
+	var req models.NamespaceUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	namespace, err := h.service.UpdateNamespace(c.Request.Context(), c.Param("name"), req)
+	if err != nil {
+		h.handleError(c, err, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, namespace)
+}
+
+// DeleteNamespace handles requests to delete a namespace
+func (h *NamespaceHandler) DeleteNamespace(c *gin.Context) {
+	if err := h.service.DeleteNamespace(c.Request.Context(), c.Param("name")); err != nil {
+		h.handleError(c, err, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Namespace deleted successfully"})
+}
+
+// ListBindings handles requests to list the role bindings of a namespace
+func (h *NamespaceHandler) ListBindings(c *gin.Context) {
+	bindings, err := h.service.ListBindings(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to list namespace bindings")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"bindings": bindings,
+		"total":    len(bindings),
+	})
+}
+
+// CreateBinding handles requests to grant a role within a namespace
+func (h *NamespaceHandler) CreateBinding(c *gin.Context) {
+	var req models.NamespaceBindingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	binding, err := h.service.CreateBinding(c.Request.Context(), c.Param("name"), req)
+	if err != nil {
+		h.handleError(c, err, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, binding)
+}
+
+// DeleteBinding handles requests to remove a role binding from a namespace
+func (h *NamespaceHandler) DeleteBinding(c *gin.Context) {
+	if err := h.service.DeleteBinding(c.Request.Context(), c.Param("name"), c.Param("binding_id")); err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to delete namespace binding")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Namespace binding deleted successfully"})
+}
+
+// handleError maps service errors to HTTP responses
+func (h *NamespaceHandler) handleError(c *gin.Context, err error, status int, message string) {
+	switch {
+	case errors.Is(err, service.ErrNamespaceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Namespace not found"})
+	case errors.Is(err, service.ErrNamespaceBindingNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Namespace binding not found"})
+	case errors.Is(err, service.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	case errors.Is(err, service.ErrTeamNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team not found"})
+	default:
+		c.JSON(status, gin.H{"error": message})
+	}
+}
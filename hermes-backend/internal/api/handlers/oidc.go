@@ -0,0 +1,101 @@
+// internal/api/handlers/oidc.go
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/service"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// oidcSessionCookie holds the sealed login session between login and callback
+const oidcSessionCookie = "hermes_oidc"
+
+// oidcCookiePath limits the session cookie to the single sign-on routes
+const oidcCookiePath = "/api/v1/auth/oidc"
+
+// OIDCHandler handles HTTP requests for single sign-on
+type OIDCHandler struct {
+	service      *service.OIDCService
+	secureCookie bool
+}
+
+// NewOIDCHandler creates a new OIDCHandler. secureCookie restricts the
+// session cookie to HTTPS and should be set whenever TLS is enabled.
+func NewOIDCHandler(service *service.OIDCService, secureCookie bool) *OIDCHandler {
+	return &OIDCHandler{
+		service:      service,
+		secureCookie: secureCookie,
+	}
+}
+
+// Login handles requests to start a single sign-on login and redirects to the provider
+func (h *OIDCHandler) Login(c *gin.Context) {
+	login, err := h.service.StartLogin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to start single sign-on"})
+		return
+	}
+
+	// Lax so the cookie is sent on the top-level redirect back from the provider
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcSessionCookie, login.Session, 600, oidcCookiePath, "", h.secureCookie, true)
+	c.Redirect(http.StatusFound, login.AuthURL)
+}
+
+// Callback handles the provider's redirect and returns an access and refresh
+// token, or an MFA challenge for users with multi-factor authentication
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	if errCode := c.Query("error"); errCode != "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":             "Single sign-on was rejected by the identity provider",
+			"provider_error":    errCode,
+			"error_description": c.Query("error_description"),
+		})
+		return
+	}
+
+	session, err := c.Cookie(oidcSessionCookie)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Single sign-on session not found"})
+		return
+	}
+	// The session is single use
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcSessionCookie, "", -1, oidcCookiePath, "", h.secureCookie, true)
+
+	code := c.Query("code")
+	if code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Authorization code is required"})
+		return
+	}
+
+	token, challenge, err := h.service.CompleteLogin(c.Request.Context(), session, c.Query("state"), code, sessionMetadata(c))
+	if err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to complete single sign-on")
+		return
+	}
+	if challenge != nil {
+		c.JSON(http.StatusOK, challenge)
+		return
+	}
+
+	c.JSON(http.StatusOK, token)
+}
+
+// handleError maps service errors to HTTP responses
+func (h *OIDCHandler) handleError(c *gin.Context, err error, status int, message string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidSSOLogin):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid single sign-on login"})
+	case errors.Is(err, service.ErrInvalidCredentials):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Account is deactivated"})
+	case errors.Is(err, service.ErrAccountLocked):
+		c.JSON(http.StatusLocked, gin.H{"error": "Account is temporarily locked after too many failed logins"})
+	case errors.Is(err, service.ErrSSOAccountConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(status, gin.H{"error": message})
+	}
+}
@@ -0,0 +1,127 @@
+// internal/api/handlers/role.go
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/security"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/service"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// RoleHandler handles HTTP requests for roles and user role assignments
+type RoleHandler struct {
+	service *service.RoleService
+}
+
+// NewRoleHandler creates a new RoleHandler
+func NewRoleHandler(service *service.RoleService) *RoleHandler {
+	return &RoleHandler{
+		service: service,
+	}
+}
+
+// ListRoles handles requests to list roles
+func (h *RoleHandler) ListRoles(c *gin.Context) {
+	roles, err := h.service.ListRoles(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list roles"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"roles": roles,
+		"total": len(roles),
+	})
+}
+
+// ListPermissions handles requests to list the permissions roles can grant
+func (h *RoleHandler) ListPermissions(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"permissions": security.Permissions()})
+}
+
+// CreateRole handles requests to create a custom role
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	var req models.RoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	role, err := h.service.CreateRole(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, role)
+}
+
+// GetRole handles requests to get a role by name
+func (h *RoleHandler) GetRole(c *gin.Context) {
+	role, err := h.service.GetRole(c.Request.Context(), models.Role(c.Param("name")))
+	if err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to retrieve role")
+		return
+	}
+
+	c.JSON(http.StatusOK, role)
+}
+
+// UpdateRole handles requests to update a custom role
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	var req models.RoleUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	role, err := h.service.UpdateRole(c.Request.Context(), models.Role(c.Param("name")), req)
+	if err != nil {
+		h.handleError(c, err, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, role)
+}
+
+// DeleteRole handles requests to delete a custom role
+func (h *RoleHandler) DeleteRole(c *gin.Context) {
+	if err := h.service.DeleteRole(c.Request.Context(), models.Role(c.Param("name"))); err != nil {
+		h.handleError(c, err, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role deleted successfully"})
+}
+
+// SetUserRole handles requests to change a user's role
+func (h *RoleHandler) SetUserRole(c *gin.Context) {
+	var req models.UserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.service.SetUserRole(c.Request.Context(), c.Param("id"), req.Role)
+	if err != nil {
+		h.handleError(c, err, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// handleError maps service errors to HTTP responses
+func (h *RoleHandler) handleError(c *gin.Context, err error, status int, message string) {
+	switch {
+	case errors.Is(err, service.ErrRoleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Role not found"})
+	case errors.Is(err, service.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	default:
+		c.JSON(status, gin.H{"error": message})
+	}
+}
@@ -0,0 +1,140 @@
+// internal/api/handlers/route.go
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/service"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// RouteHandler handles HTTP requests for gateway routes
+type RouteHandler struct {
+	service *service.RouteService
+}
+
+// NewRouteHandler creates a new RouteHandler
+func NewRouteHandler(service *service.RouteService) *RouteHandler {
+	return &RouteHandler{
+		service: service,
+	}
+}
+
+// CreateRoute handles requests to create a gateway route
+func (h *RouteHandler) CreateRoute(c *gin.Context) {
+	var req models.RouteCreationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	route, err := h.service.CreateRoute(c.Request.Context(), req)
+	if err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to create route")
+		return
+	}
+
+	c.JSON(http.StatusCreated, route)
+}
+
+// ListRoutes handles requests to list gateway routes
+func (h *RouteHandler) ListRoutes(c *gin.Context) {
+	routes, err := h.service.ListRoutes(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list routes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"routes": routes,
+		"total":  len(routes),
+	})
+}
+
+// GetRoute handles requests to get a route by ID
+func (h *RouteHandler) GetRoute(c *gin.Context) {
+	route, err := h.service.GetRoute(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to retrieve route")
+		return
+	}
+
+	c.JSON(http.StatusOK, route)
+}
+
+// UpdateRoute handles requests to update a route
+func (h *RouteHandler) UpdateRoute(c *gin.Context) {
+	var req models.RouteUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	route, err := h.service.UpdateRoute(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to update route")
+		return
+	}
+
+	c.JSON(http.StatusOK, route)
+}
+
+// DeleteRoute handles requests to delete a route
+func (h *RouteHandler) DeleteRoute(c *gin.Context) {
+	if err := h.service.DeleteRoute(c.Request.Context(), c.Param("id")); err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to delete route")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Route deleted successfully"})
+}
+
+// SetRouteFault handles requests to enable fault injection on a route
+func (h *RouteHandler) SetRouteFault(c *gin.Context) {
+	var req models.FaultInjectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	route, err := h.service.SetRouteFault(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to set route fault")
+		return
+	}
+
+	c.JSON(http.StatusOK, route)
+}
+
+// ClearRouteFault handles requests to disable fault injection on a route
+func (h *RouteHandler) ClearRouteFault(c *gin.Context) {
+	if err := h.service.ClearRouteFault(c.Request.Context(), c.Param("id")); err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to clear route fault")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Fault injection disabled"})
+}
+
+// GetGatewayMetrics handles requests for the gateway's runtime statistics
+func (h *RouteHandler) GetGatewayMetrics(c *gin.Context) {
+	c.JSON(http.StatusOK, h.service.GatewayStats())
+}
+
+// handleError maps service errors to HTTP responses
+func (h *RouteHandler) handleError(c *gin.Context, err error, status int, message string) {
+	switch {
+	case errors.Is(err, service.ErrRouteNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Route not found"})
+	case errors.Is(err, service.ErrInvalidRoute):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrNamespaceNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Namespace not found"})
+	case errors.Is(err, service.ErrNamespaceForbidden), errors.Is(err, service.ErrNamespaceQuotaExceeded):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(status, gin.H{"error": message})
+	}
+}
@@ -30,9 +30,24 @@ func (h *ServiceHandler) RegisterService(c *gin.Context) {
 		return
 	}
 	// TODO: Add validation for registration fields
+	if !canAssignTeam(c, registration.OwnerTeamID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only members of the owner team may assign it"})
+		return
+	}
 
-	service, err := h.service.RegisterService(c.Request.Context(), registration)
+	svc, err := h.service.RegisterService(c.Request.Context(), registration)
 	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNamespaceNotFound):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Namespace not found"})
+			return
+		case errors.Is(err, service.ErrInvalidHeartbeatTTL):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		case errors.Is(err, service.ErrNamespaceForbidden), errors.Is(err, service.ErrNamespaceQuotaExceeded):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		if errors.Is(err, errors.New("service with this name already exists")) {
 			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 			return
@@ -41,7 +56,7 @@ func (h *ServiceHandler) RegisterService(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, service)
+	c.JSON(http.StatusCreated, svc)
 }
 
 func (h *ServiceHandler) BulkRegisterService(c *gin.Context) {
@@ -89,6 +104,14 @@ func (h *ServiceHandler) BulkRegisterService(c *gin.Context) {
 			registration.Tags = append(registration.Tags, tag)
 		}
 
+		if !canAssignTeam(c, registration.OwnerTeamID) {
+			failed = append(failed, FailureResult{
+				Name:  registration.Name,
+				Error: "only members of the owner team may assign it",
+			})
+			continue
+		}
+
 		// Register service
 		service, err := h.service.RegisterService(c.Request.Context(), registration)
 		if err != nil {
@@ -134,12 +157,12 @@ func (h *ServiceHandler) GetServiceByID(c *gin.Context) {
 // GetServiceByName handles requests to get a service by its name
 func (h *ServiceHandler) GetServiceByName(c *gin.Context) {
 	name := c.Param("name")
-	service, err := h.service.GetServiceByName(c.Request.Context(), name)
+	service, err := h.service.GetServiceByName(c.Request.Context(), c.Query("namespace"), name)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve service"})
 		return
 	}
-	if service == nil {
+	if service == nil || !canView(c, service) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Service not found"})
 		return
 	}
@@ -154,6 +177,9 @@ func (h *ServiceHandler) ListServices(c *gin.Context) {
 		return
 	}
 
+	params.ViewerTeams = c.GetStringSlice("teamIDs")
+	params.AllVisible = c.GetBool("servicesAdmin")
+
 	services, total, err := h.service.ListServices(c.Request.Context(), params)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list services"})
@@ -174,17 +200,26 @@ func (h *ServiceHandler) UpdateService(c *gin.Context) {
 		return
 	}
 
+	if updateRequest.OwnerTeamID != nil && !canAssignTeam(c, *updateRequest.OwnerTeamID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only members of the owner team may assign it"})
+		return
+	}
+
 	id := c.Param("id")
-	service, err := h.service.UpdateService(c.Request.Context(), id, updateRequest)
+	svc, err := h.service.UpdateService(c.Request.Context(), id, updateRequest)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidHeartbeatTTL) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update service"})
 		return
 	}
-	if service == nil {
+	if svc == nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Service not found"})
 		return
 	}
-	c.JSON(http.StatusOK, service)
+	c.JSON(http.StatusOK, svc)
 }
 
 // DeleteService handles requests to delete a service by ID
@@ -215,14 +250,20 @@ func (h *ServiceHandler) UpdateServiceStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Service status updated successfully"})
 }
 
-// UpdateServiceLastSeen handles requests to update the LastSeen time of a service
-func (h *ServiceHandler) UpdateServiceLastSeen(c *gin.Context) {
-	id := c.Param("id")
+// canView reports whether the caller may see a service
+func canView(c *gin.Context, service *models.Service) bool {
+	return c.GetBool("servicesAdmin") || service.VisibleTo(c.GetStringSlice("teamIDs"))
+}
 
-	err := h.service.UpdateServiceLastSeen(c.Request.Context(), id)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update service last seen"})
-		return
+// canAssignTeam reports whether the caller may make a team the owner of a service
+func canAssignTeam(c *gin.Context, teamID string) bool {
+	if teamID == "" || c.GetBool("servicesAdmin") {
+		return true
+	}
+	for _, id := range c.GetStringSlice("teamIDs") {
+		if id == teamID {
+			return true
+		}
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "Service last seen updated successfully"})
+	return false
 }
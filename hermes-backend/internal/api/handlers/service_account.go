@@ -0,0 +1,106 @@
+// internal/api/handlers/service_account.go
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/service"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// ServiceAccountHandler handles HTTP requests for service accounts and their tokens
+type ServiceAccountHandler struct {
+	service *service.ServiceAccountService
+}
+
+// NewServiceAccountHandler creates a new ServiceAccountHandler
+func NewServiceAccountHandler(service *service.ServiceAccountService) *ServiceAccountHandler {
+	return &ServiceAccountHandler{
+		service: service,
+	}
+}
+
+// CreateAccount handles requests to create a service account for a service
+func (h *ServiceAccountHandler) CreateAccount(c *gin.Context) {
+	var req models.ServiceAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	creds, err := h.service.CreateAccount(c.Request.Context(), c.Param("id"), req, c.GetString("userID"))
+	if err != nil {
+		h.handleError(c, err, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, creds)
+}
+
+// ListAccounts handles requests to list the service accounts of a service
+func (h *ServiceAccountHandler) ListAccounts(c *gin.Context) {
+	accounts, err := h.service.ListAccounts(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to list service accounts")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"accounts": accounts,
+		"total":    len(accounts),
+	})
+}
+
+// RotateSecret handles requests to replace the secret of a service account
+func (h *ServiceAccountHandler) RotateSecret(c *gin.Context) {
+	creds, err := h.service.RotateSecret(c.Request.Context(), c.Param("id"), c.Param("account_id"))
+	if err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to rotate service account secret")
+		return
+	}
+
+	c.JSON(http.StatusOK, creds)
+}
+
+// DeleteAccount handles requests to delete a service account
+func (h *ServiceAccountHandler) DeleteAccount(c *gin.Context) {
+	if err := h.service.DeleteAccount(c.Request.Context(), c.Param("id"), c.Param("account_id")); err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to delete service account")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Service account deleted successfully"})
+}
+
+// MintToken handles client credentials requests for a service account token
+func (h *ServiceAccountHandler) MintToken(c *gin.Context) {
+	var req models.ServiceTokenRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	token, err := h.service.MintToken(c.Request.Context(), req)
+	if err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to issue service account token")
+		return
+	}
+
+	c.JSON(http.StatusOK, token)
+}
+
+// handleError maps service errors to HTTP responses
+func (h *ServiceAccountHandler) handleError(c *gin.Context, err error, status int, message string) {
+	switch {
+	case errors.Is(err, service.ErrServiceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Service not found"})
+	case errors.Is(err, service.ErrServiceAccountNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Service account not found"})
+	case errors.Is(err, service.ErrInvalidClientCredentials):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid client credentials"})
+	default:
+		c.JSON(status, gin.H{"error": message})
+	}
+}
@@ -12,13 +12,15 @@ import (
 
 // ServiceDiscoveryHandler handles service discovery HTTP requests
 type ServiceDiscoveryHandler struct {
-	service *service.ServiceService
+	service   *service.ServiceService
+	instances *service.InstanceService
 }
 
 // NewServiceDiscoveryHandler creates a new ServiceDiscoveryHandler
-func NewServiceDiscoveryHandler(service *service.ServiceService) *ServiceDiscoveryHandler {
+func NewServiceDiscoveryHandler(service *service.ServiceService, instances *service.InstanceService) *ServiceDiscoveryHandler {
 	return &ServiceDiscoveryHandler{
-		service: service,
+		service:   service,
+		instances: instances,
 	}
 }
 
@@ -40,11 +42,20 @@ func (h *ServiceDiscoveryHandler) AdvancedSearch(c *gin.Context) {
 		params.LastSeenSince = lastSeen
 	}
 
+	params.ViewerTeams = c.GetStringSlice("teamIDs")
+	params.AllVisible = c.GetBool("servicesAdmin")
+
 	services, total, err := h.service.AdvancedDiscovery(c.Request.Context(), params)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search services"})
 		return
 	}
+	if params.IncludeInstances {
+		if err := h.instances.AttachHealthy(c.Request.Context(), services); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list service instances"})
+			return
+		}
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"services": services,
@@ -0,0 +1,122 @@
+// internal/api/handlers/service_instance.go
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/service"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// ServiceInstanceHandler handles HTTP requests for the instances of services
+type ServiceInstanceHandler struct {
+	instances  *service.InstanceService
+	heartbeats *service.HeartbeatService
+}
+
+// NewServiceInstanceHandler creates a new ServiceInstanceHandler
+func NewServiceInstanceHandler(instances *service.InstanceService, heartbeats *service.HeartbeatService) *ServiceInstanceHandler {
+	return &ServiceInstanceHandler{
+		instances:  instances,
+		heartbeats: heartbeats,
+	}
+}
+
+// RegisterInstance handles requests to register an instance of a service
+func (h *ServiceInstanceHandler) RegisterInstance(c *gin.Context) {
+	var reg models.ServiceInstanceRegistration
+	if err := c.ShouldBindJSON(&reg); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	instance, err := h.instances.RegisterInstance(c.Request.Context(), c.Param("id"), reg)
+	if err != nil {
+		h.handleError(c, err, "Failed to register instance")
+		return
+	}
+
+	c.JSON(http.StatusCreated, instance)
+}
+
+// ListInstances handles requests to list the instances of a service
+func (h *ServiceInstanceHandler) ListInstances(c *gin.Context) {
+	var params models.InstanceQueryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	instances, err := h.instances.ListInstances(c.Request.Context(), c.Param("id"), params)
+	if err != nil {
+		h.handleError(c, err, "Failed to list instances")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"instances": instances,
+		"total":     len(instances),
+	})
+}
+
+// GetInstance handles requests to get an instance of a service
+func (h *ServiceInstanceHandler) GetInstance(c *gin.Context) {
+	instance, err := h.instances.GetInstance(c.Request.Context(), c.Param("id"), c.Param("instance_id"))
+	if err != nil {
+		h.handleError(c, err, "Failed to retrieve instance")
+		return
+	}
+
+	c.JSON(http.StatusOK, instance)
+}
+
+// DeregisterInstance handles requests to deregister an instance of a service
+func (h *ServiceInstanceHandler) DeregisterInstance(c *gin.Context) {
+	if err := h.instances.DeregisterInstance(c.Request.Context(), c.Param("id"), c.Param("instance_id")); err != nil {
+		h.handleError(c, err, "Failed to deregister instance")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Instance deregistered successfully"})
+}
+
+// Heartbeat handles heartbeats sent by an instance to show it is alive. The
+// body is optional and defaults to a healthy status.
+func (h *ServiceInstanceHandler) Heartbeat(c *gin.Context) {
+	var req models.ServiceHeartbeat
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	instance, err := h.heartbeats.InstanceHeartbeat(c.Request.Context(), c.Param("id"), c.Param("instance_id"), req)
+	if err != nil {
+		h.handleError(c, err, "Failed to record heartbeat")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":        instance.Status,
+		"last_seen":     instance.LastSeen,
+		"heartbeat_ttl": instance.HeartbeatTTL,
+	})
+}
+
+// handleError maps service errors to HTTP responses
+func (h *ServiceInstanceHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrServiceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Service not found"})
+	case errors.Is(err, service.ErrInstanceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Instance not found"})
+	case errors.Is(err, service.ErrInvalidInstanceWeight), errors.Is(err, service.ErrInvalidHeartbeatTTL),
+		errors.Is(err, service.ErrInvalidHeartbeatStatus):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
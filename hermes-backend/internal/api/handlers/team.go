@@ -0,0 +1,145 @@
+// internal/api/handlers/team.go
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/service"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// TeamHandler handles HTTP requests for teams and their members
+type TeamHandler struct {
+	service *service.TeamService
+}
+
+// NewTeamHandler creates a new TeamHandler
+func NewTeamHandler(service *service.TeamService) *TeamHandler {
+	return &TeamHandler{
+		service: service,
+	}
+}
+
+// CreateTeam handles requests to create a team
+func (h *TeamHandler) CreateTeam(c *gin.Context) {
+	var req models.TeamRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	team, err := h.service.CreateTeam(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, team)
+}
+
+// ListTeams handles requests to list teams
+func (h *TeamHandler) ListTeams(c *gin.Context) {
+	teams, err := h.service.ListTeams(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list teams"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"teams": teams,
+		"total": len(teams),
+	})
+}
+
+// GetTeam handles requests to get a team by ID
+func (h *TeamHandler) GetTeam(c *gin.Context) {
+	team, err := h.service.GetTeam(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to retrieve team")
+		return
+	}
+
+	c.JSON(http.StatusOK, team)
+}
+
+// UpdateTeam handles requests to update a team
+func (h *TeamHandler) UpdateTeam(c *gin.Context) {
+	var req models.TeamUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	team, err := h.service.UpdateTeam(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		h.handleError(c, err, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, team)
+}
+
+// DeleteTeam handles requests to delete a team
+func (h *TeamHandler) DeleteTeam(c *gin.Context) {
+	if err := h.service.DeleteTeam(c.Request.Context(), c.Param("id")); err != nil {
+		h.handleError(c, err, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Team deleted successfully"})
+}
+
+// ListMembers handles requests to list the members of a team
+func (h *TeamHandler) ListMembers(c *gin.Context) {
+	members, err := h.service.ListMembers(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to list team members")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"members": members,
+		"total":   len(members),
+	})
+}
+
+// AddMember handles requests to add a user to a team
+func (h *TeamHandler) AddMember(c *gin.Context) {
+	var req models.TeamMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	member, err := h.service.AddMember(c.Request.Context(), c.Param("id"), req.UserID)
+	if err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to add team member")
+		return
+	}
+
+	c.JSON(http.StatusCreated, member)
+}
+
+// RemoveMember handles requests to remove a user from a team
+func (h *TeamHandler) RemoveMember(c *gin.Context) {
+	if err := h.service.RemoveMember(c.Request.Context(), c.Param("id"), c.Param("user_id")); err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to remove team member")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Team member removed successfully"})
+}
+
+// handleError maps service errors to HTTP responses
+func (h *TeamHandler) handleError(c *gin.Context, err error, status int, message string) {
+	switch {
+	case errors.Is(err, service.ErrTeamNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team not found"})
+	case errors.Is(err, service.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	default:
+		c.JSON(status, gin.H{"error": message})
+	}
+}
@@ -0,0 +1,101 @@
+// internal/api/handlers/tls_certificate.go
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/service"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// TLSCertificateHandler handles HTTP requests for TLS certificates
+type TLSCertificateHandler struct {
+	service *service.TLSCertificateService
+}
+
+// NewTLSCertificateHandler creates a new TLSCertificateHandler
+func NewTLSCertificateHandler(service *service.TLSCertificateService) *TLSCertificateHandler {
+	return &TLSCertificateHandler{
+		service: service,
+	}
+}
+
+// CreateCertificate handles requests to upload a TLS certificate
+func (h *TLSCertificateHandler) CreateCertificate(c *gin.Context) {
+	var req models.TLSCertificateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cert, err := h.service.CreateCertificate(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, cert)
+}
+
+// ListCertificates handles requests to list TLS certificates
+func (h *TLSCertificateHandler) ListCertificates(c *gin.Context) {
+	certs, err := h.service.ListCertificates(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list certificates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"certificates": certs,
+		"total":        len(certs),
+	})
+}
+
+// GetCertificate handles requests to get a TLS certificate by ID
+func (h *TLSCertificateHandler) GetCertificate(c *gin.Context) {
+	cert, err := h.service.GetCertificate(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to retrieve certificate")
+		return
+	}
+
+	c.JSON(http.StatusOK, cert)
+}
+
+// UpdateCertificate handles requests to replace a TLS certificate
+func (h *TLSCertificateHandler) UpdateCertificate(c *gin.Context) {
+	var req models.TLSCertificateUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cert, err := h.service.UpdateCertificate(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		h.handleError(c, err, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, cert)
+}
+
+// DeleteCertificate handles requests to delete a TLS certificate
+func (h *TLSCertificateHandler) DeleteCertificate(c *gin.Context) {
+	if err := h.service.DeleteCertificate(c.Request.Context(), c.Param("id")); err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, "Failed to delete certificate")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Certificate deleted successfully"})
+}
+
+// handleError maps service errors to HTTP responses
+func (h *TLSCertificateHandler) handleError(c *gin.Context, err error, status int, message string) {
+	if errors.Is(err, service.ErrTLSCertificateNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Certificate not found"})
+		return
+	}
+	c.JSON(status, gin.H{"error": message})
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/security"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// AuditSource records the client address of every request, so changes made
+// without signing in, such as registrations, are still attributed to one
+func AuditSource() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		setAuditActor(c, models.AuditActor{SourceIP: c.ClientIP()})
+		c.Next()
+	}
+}
+
+// auditCaller records the authenticated caller of a request as the actor of
+// the changes it makes
+func auditCaller(c *gin.Context, claims *security.Claims) {
+	actor := models.AuditActor{
+		ID:       claims.Subject,
+		Name:     claims.Username,
+		Role:     claims.Role,
+		SourceIP: c.ClientIP(),
+	}
+	// Service accounts have no name of their own, only the service they act for
+	if claims.IsServiceAccount() {
+		actor.Name = claims.ServiceID
+	}
+	setAuditActor(c, actor)
+}
+
+func setAuditActor(c *gin.Context, actor models.AuditActor) {
+	c.Request = c.Request.WithContext(service.WithAuditActor(c.Request.Context(), actor))
+}
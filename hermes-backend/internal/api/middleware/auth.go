@@ -1,15 +1,19 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
-	"github.com/amaydixit11/hermes/hermes-backend/internal/config"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/security"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/service"
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 )
 
-func Auth(cfg *config.Config) gin.HandlerFunc {
+// Auth verifies the bearer access token of a request and sets the caller's
+// identity in the context
+func Auth(tokens *security.TokenIssuer) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get the Authorization header
 		authHeader := c.GetHeader("Authorization")
@@ -25,29 +29,84 @@ func Auth(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
-		tokenStr := parts[1]
+		// Verify the signature, lifetime and revocation of the token
+		claims, err := tokens.Verify(parts[1])
+		if errors.Is(err, security.ErrTokenRevoked) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token has been revoked"})
+			return
+		}
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+			return
+		}
+
+		c.Set("claims", claims)
+		c.Set("userID", claims.Subject)
+		c.Set("username", claims.Username)
+		c.Set("email", claims.Email)
+		c.Set("role", claims.Role)
+		if claims.IsServiceAccount() {
+			c.Set("serviceID", claims.ServiceID)
+			c.Set("scopes", claims.Scopes())
+		}
+		auditCaller(c, claims)
 
-		// Parse and validate the JWT token
-		token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-			// Validate the algorithm
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
+		c.Next()
+	}
+}
+
+// RequireMFA rejects users whose session was started without a second
+// factor. Service account tokens are not affected. It must run after Auth.
+func RequireMFA() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, _ := c.Get("claims")
+		if claims, ok := claims.(*security.Claims); ok && (claims.IsServiceAccount() || claims.MFA) {
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Multi-factor authentication required, enable it and log in again"})
+	}
+}
+
+// Require rejects requests from callers whose role does not grant permission.
+// Service accounts have no role and need a scope granting permission on the
+// service in the :id parameter instead. With namespaces set, callers whose
+// role lacks permission may still act within the namespaces where a binding
+// grants it, and the request is restricted to those. It must run after Auth.
+func Require(policy *security.Policy, namespaces *service.NamespaceService, permission security.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if serviceID := c.GetString("serviceID"); serviceID != "" {
+			if !security.ScopeGrants(c.GetStringSlice("scopes"), permission) {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Missing scope: " + string(permission) + ":self"})
+				return
+			}
+			if c.Param("id") != serviceID {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Token is bound to another service"})
+				return
 			}
-			return []byte(cfg.JWT.Secret), nil
-		})
+			c.Next()
+			return
+		}
 
-		if err != nil || !token.Valid {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		if policy.Allows(models.Role(c.GetString("role")), permission) {
+			c.Next()
 			return
 		}
 
-		// Optionally: extract claims and set them in context
-		if claims, ok := token.Claims.(jwt.MapClaims); ok {
-			c.Set("userID", claims["sub"])
-			c.Set("email", claims["email"])
-			c.Set("role", claims["role"])
+		var permitted []string
+		if namespaces != nil {
+			var err error
+			permitted, err = namespaces.PermittedNamespaces(c.Request.Context(), c.GetString("userID"), permission)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve namespace bindings"})
+				return
+			}
+		}
+		if len(permitted) == 0 {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Missing permission: " + string(permission)})
+			return
 		}
 
+		c.Request = c.Request.WithContext(service.WithNamespaceScope(c.Request.Context(), permitted))
 		c.Next()
 	}
 }
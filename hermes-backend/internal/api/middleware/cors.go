@@ -1,18 +1,16 @@
 package middleware
 
 import (
+	"github.com/amaydixit11/hermes/hermes-backend/internal/security"
 	"github.com/gin-gonic/gin"
 )
 
-func CORS() gin.HandlerFunc {
+// CORS answers cross-origin requests from browsers according to a policy,
+// ending preflight requests without running the rest of the chain
+func CORS(policy *security.CORS) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*") // Consider restricting in prod
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
+		if policy.Handle(c.Writer, c.Request) {
+			c.Abort()
 			return
 		}
 
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/security"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// IPAccess rejects requests from client addresses the rules do not admit.
+// The client address is resolved by gin through the trusted proxies of the
+// router. scope names the rules in the log of denied requests.
+func IPAccess(rules *security.IPRules, scope string, log *logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if allowed, rule := rules.Check(net.ParseIP(c.ClientIP())); !allowed {
+			log.Warn("API request denied by access rules",
+				"scope", scope,
+				"method", c.Request.Method,
+				"path", c.Request.URL.Path,
+				"clientIP", c.ClientIP(),
+				"rule", rule,
+			)
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		}
+	}
+}
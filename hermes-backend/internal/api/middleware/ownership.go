@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/security"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// TeamScope resolves the teams of the caller for ownership and visibility
+// checks on services. It must run after Auth.
+func TeamScope(policy *security.Policy, teams *service.TeamService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Service accounts belong to no team, Require limits them to their own service
+		if c.GetString("serviceID") != "" {
+			c.Next()
+			return
+		}
+
+		teamIDs, err := teams.TeamIDsForUser(c.Request.Context(), c.GetString("userID"))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve teams"})
+			return
+		}
+
+		c.Set("teamIDs", teamIDs)
+		c.Set("servicesAdmin", policy.Allows(models.Role(c.GetString("role")), security.PermServicesAdmin))
+		c.Next()
+	}
+}
+
+// ServiceOwner guards a route on the service in the :id parameter. Private
+// services are hidden from callers outside the owner team, and with write set
+// only members of the owner team may change an owned service. Callers with
+// services:admin pass both checks, as do service accounts on their own
+// service. Services outside the namespaces a request is restricted to are
+// hidden from everyone. It must run after TeamScope and Require.
+func ServiceOwner(services *service.ServiceService, write bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("serviceID") != "" && c.GetString("serviceID") == c.Param("id") {
+			c.Next()
+			return
+		}
+
+		svc, err := services.GetServiceByID(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve service"})
+			return
+		}
+		if svc == nil {
+			// Let the handler report the missing service
+			c.Next()
+			return
+		}
+		if !service.NamespaceAllowed(c.Request.Context(), svc.Namespace) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Service not found"})
+			return
+		}
+		if c.GetBool("servicesAdmin") {
+			c.Next()
+			return
+		}
+
+		teamIDs := c.GetStringSlice("teamIDs")
+		if !svc.VisibleTo(teamIDs) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Service not found"})
+			return
+		}
+		if write && svc.OwnerTeamID != nil && !svc.OwnedBy(teamIDs) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Service is owned by another team"})
+			return
+		}
+
+		c.Next()
+	}
+}
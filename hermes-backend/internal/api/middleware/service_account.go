@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/security"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// ServiceAccount rejects service account tokens whose account was deleted or
+// whose secret was rotated after they were minted. Tokens of users pass
+// through. It must run after Auth.
+func ServiceAccount(accounts *service.ServiceAccountService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := c.MustGet("claims").(*security.Claims)
+		if !ok || !claims.IsServiceAccount() {
+			c.Next()
+			return
+		}
+
+		err := accounts.Authorize(c.Request.Context(), claims)
+		if errors.Is(err, service.ErrServiceAccountRevoked) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token has been revoked"})
+			return
+		}
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify service account"})
+			return
+		}
+
+		c.Next()
+	}
+}
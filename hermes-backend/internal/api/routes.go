@@ -6,13 +6,15 @@ import (
 	"github.com/amaydixit11/hermes/hermes-backend/internal/api/handlers"
 	"github.com/amaydixit11/hermes/hermes-backend/internal/api/middleware"
 	"github.com/amaydixit11/hermes/hermes-backend/internal/config"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/security"
 	"github.com/amaydixit11/hermes/hermes-backend/internal/service"
 	"github.com/amaydixit11/hermes/hermes-backend/pkg/logger"
 	"github.com/gin-gonic/gin"
 )
 
 // SetupRouter configures the HTTP routes for the API
-func SetupRouter(cfg *config.Config, log *logger.Logger, serviceService *service.ServiceService, healthService *service.HealthService) *gin.Engine {
+func SetupRouter(cfg *config.Config, log *logger.Logger, serviceService *service.ServiceService, healthService *service.HealthService, routeService *service.RouteService, certificateService *service.CertificateService, tlsCertificateService *service.TLSCertificateService, userService *service.UserService, tokenService *service.TokenService, tokenIssuer *security.TokenIssuer, roleService *service.RoleService, policy *security.Policy, teamService *service.TeamService, oidcService *service.OIDCService, serviceAccountService *service.ServiceAccountService, consumerService *service.ConsumerService, auditService *service.AuditService, namespaceService *service.NamespaceService, apiSpecService *service.APISpecService, mfaService *service.MFAService, heartbeatService *service.HeartbeatService, instanceService *service.InstanceService) *gin.Engine {
 	// Set Gin mode based on environment
 	if cfg.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
@@ -21,10 +23,35 @@ func SetupRouter(cfg *config.Config, log *logger.Logger, serviceService *service
 	// Create router
 	router := gin.New()
 
+	// Client addresses come from X-Forwarded-For only when sent by a trusted proxy
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatal("Invalid trusted proxies", "error", err)
+	}
+	apiAccess, err := security.ParseIPRules(cfg.Server.Access.Allow, cfg.Server.Access.Deny)
+	if err != nil {
+		log.Fatal("Invalid API access rules", "error", err)
+	}
+	adminAccess, err := security.ParseIPRules(cfg.Server.AdminAccess.Allow, cfg.Server.AdminAccess.Deny)
+	if err != nil {
+		log.Fatal("Invalid admin access rules", "error", err)
+	}
+	cors, err := security.NewCORS(models.CORSPolicy{
+		AllowedOrigins:   cfg.Server.CORS.AllowedOrigins,
+		AllowedMethods:   cfg.Server.CORS.AllowedMethods,
+		AllowedHeaders:   cfg.Server.CORS.AllowedHeaders,
+		ExposedHeaders:   cfg.Server.CORS.ExposedHeaders,
+		MaxAge:           cfg.Server.CORS.MaxAge,
+		AllowCredentials: cfg.Server.CORS.AllowCredentials,
+	})
+	if err != nil {
+		log.Fatal("Invalid CORS policy", "error", err)
+	}
+
 	// Add middleware
 	router.Use(middleware.RequestLogger(log))
 	router.Use(middleware.Recovery(log))
-	router.Use(middleware.CORS())
+	router.Use(middleware.AuditSource())
+	router.Use(middleware.CORS(cors))
 
 	// Add health check endpoint
 	router.GET("/health", func(c *gin.Context) {
@@ -33,8 +60,48 @@ func SetupRouter(cfg *config.Config, log *logger.Logger, serviceService *service
 		})
 	})
 
+	// Routes are authorized by the permissions of the caller's role. Routes
+	// requiring an admin permission are only served to the admin networks
+	// and, if configured, to sessions that verified a second factor.
+	requireAuth := middleware.Auth(tokenIssuer)
+	var adminChecks []gin.HandlerFunc
+	if !adminAccess.Empty() {
+		adminChecks = append(adminChecks, middleware.IPAccess(adminAccess, "admin", log))
+	}
+	if cfg.Auth.MFA.RequireForAdmin {
+		adminChecks = append(adminChecks, middleware.RequireMFA())
+	}
+	guard := func(permission security.Permission, require gin.HandlerFunc) gin.HandlerFunc {
+		if !security.IsAdminPermission(permission) || len(adminChecks) == 0 {
+			return require
+		}
+		return func(c *gin.Context) {
+			for _, check := range adminChecks {
+				if check(c); c.IsAborted() {
+					return
+				}
+			}
+			require(c)
+		}
+	}
+	allow := func(permission security.Permission) gin.HandlerFunc {
+		return guard(permission, middleware.Require(policy, nil, permission))
+	}
+	// Namespace bindings also count on routes that act within a namespace
+	inNamespace := func(permission security.Permission) gin.HandlerFunc {
+		return guard(permission, middleware.Require(policy, namespaceService, permission))
+	}
+
+	// Public keys for verifying access tokens without contacting Hermes
+	authHandler := handlers.NewAuthHandler(userService, tokenService, mfaService)
+	serviceAccountHandler := handlers.NewServiceAccountHandler(serviceAccountService)
+	router.GET("/.well-known/jwks.json", authHandler.JWKS)
+
 	// API v1 group
 	v1 := router.Group("/api/v1")
+	if !apiAccess.Empty() {
+		v1.Use(middleware.IPAccess(apiAccess, "api", log))
+	}
 	{
 		// Public routes
 		v1.GET("/status", func(c *gin.Context) {
@@ -45,80 +112,241 @@ func SetupRouter(cfg *config.Config, log *logger.Logger, serviceService *service
 		})
 
 		// Authentication routes
-		// auth := v1.Group("/auth")
-		// {
-		// These handlers will be implemented later
-		// auth.POST("/login", handlers.Login)
-		// auth.POST("/register", handlers.Register)
-		// }
+		auth := v1.Group("/auth")
+		{
+			auth.POST("/login", authHandler.Login)
+			auth.POST("/refresh", authHandler.Refresh)
+			if cfg.Auth.AllowRegistration {
+				auth.POST("/register", authHandler.Register)
+			}
+			auth.POST("/service-token", serviceAccountHandler.MintToken)
+			auth.GET("/jwks", authHandler.JWKS)
+			auth.GET("/me", requireAuth, authHandler.Me)
+			auth.PUT("/password", requireAuth, authHandler.ChangePassword)
+			auth.POST("/logout", requireAuth, authHandler.Logout)
+			auth.GET("/sessions", requireAuth, authHandler.ListSessions)
+			auth.DELETE("/sessions/:id", requireAuth, authHandler.RevokeSession)
+
+			// Multi-factor authentication with a TOTP authenticator
+			auth.POST("/mfa/login", authHandler.MFALogin)
+			auth.POST("/mfa/enroll", requireAuth, authHandler.EnrollTOTP)
+			auth.POST("/mfa/confirm", requireAuth, authHandler.ConfirmTOTP)
+			auth.POST("/mfa/disable", requireAuth, authHandler.DisableTOTP)
+			auth.POST("/mfa/recovery-codes", requireAuth, authHandler.RegenerateRecoveryCodes)
+			auth.POST("/revoke", requireAuth, allow(security.PermTokensAdmin), authHandler.RevokeToken)
+			auth.POST("/keys/rotate", requireAuth, allow(security.PermTokensAdmin), authHandler.RotateKeys)
+
+			// Single sign-on, when an OIDC provider is configured
+			if oidcService != nil {
+				oidcHandler := handlers.NewOIDCHandler(oidcService, cfg.TLS.Enabled)
+				auth.GET("/oidc/login", oidcHandler.Login)
+				auth.GET("/oidc/callback", oidcHandler.Callback)
+			}
+		}
 
 		// Protected routes
 		protected := v1.Group("/")
-		// TODO: Add Auth
-		// protected.Use(middleware.Auth(cfg))
+		protected.Use(requireAuth, middleware.ServiceAccount(serviceAccountService))
 		{
 			// Service routes
 			services := protected.Group("/services")
+			services.Use(middleware.TeamScope(policy, teamService))
 			{
+				// Owned services may only be changed by their team, private ones only seen by it
+				owner := middleware.ServiceOwner(serviceService, true)
+				visible := middleware.ServiceOwner(serviceService, false)
+
 				serviceHandler := handlers.NewServiceHandler(serviceService)
-				services.POST("/", serviceHandler.RegisterService)
-				services.GET("/", serviceHandler.ListServices)
-				services.GET("/:id", serviceHandler.GetServiceByID)
-				services.PUT("/:id", serviceHandler.UpdateService)
-				services.DELETE("/:id", serviceHandler.DeleteService)
-				services.POST("/bulk", serviceHandler.BulkRegisterService)
+				services.POST("/", inNamespace(security.PermServicesWrite), serviceHandler.RegisterService)
+				services.GET("/", inNamespace(security.PermServicesRead), serviceHandler.ListServices)
+				services.GET("/:id", inNamespace(security.PermServicesRead), visible, serviceHandler.GetServiceByID)
+				services.GET("/by-name/:name", inNamespace(security.PermServicesRead), serviceHandler.GetServiceByName)
+				services.PUT("/:id", inNamespace(security.PermServicesWrite), owner, serviceHandler.UpdateService)
+				services.DELETE("/:id", inNamespace(security.PermServicesDelete), owner, serviceHandler.DeleteService)
+				services.POST("/bulk", inNamespace(security.PermServicesWrite), serviceHandler.BulkRegisterService)
 				// Add this to your existing routes setup
 
 				// Service Discovery routes
-				discoveryHandler := handlers.NewServiceDiscoveryHandler(serviceService)
-				services.GET("/discovery", discoveryHandler.AdvancedSearch)
+				discoveryHandler := handlers.NewServiceDiscoveryHandler(serviceService, instanceService)
+				services.GET("/discovery", inNamespace(security.PermServicesRead), discoveryHandler.AdvancedSearch)
+
+				// Service instance routes, registered by the replicas themselves through a service account
+				instanceHandler := handlers.NewServiceInstanceHandler(instanceService, heartbeatService)
+				services.POST("/:id/instances", inNamespace(security.PermInstancesWrite), owner, instanceHandler.RegisterInstance)
+				services.GET("/:id/instances", inNamespace(security.PermServicesRead), visible, instanceHandler.ListInstances)
+				services.GET("/:id/instances/:instance_id", inNamespace(security.PermServicesRead), visible, instanceHandler.GetInstance)
+				services.DELETE("/:id/instances/:instance_id", inNamespace(security.PermInstancesWrite), owner, instanceHandler.DeregisterInstance)
+				services.POST("/:id/instances/:instance_id/heartbeat", inNamespace(security.PermHealthReport), owner, instanceHandler.Heartbeat)
 
 				// Service Version routes
 				versionHandler := handlers.NewServiceVersionHandler(serviceService)
-				services.POST("/:id/versions", versionHandler.AddServiceVersion)
-				services.GET("/:id/versions", versionHandler.GetServiceVersions)
-				services.PUT("/:id/versions/:version/activate", versionHandler.ActivateServiceVersion)
+				services.POST("/:id/versions", inNamespace(security.PermServicesWrite), owner, versionHandler.AddServiceVersion)
+				services.GET("/:id/versions", inNamespace(security.PermServicesRead), visible, versionHandler.GetServiceVersions)
+				services.PUT("/:id/versions/:version/activate", inNamespace(security.PermServicesWrite), owner, versionHandler.ActivateServiceVersion)
 
 				// Service Dependency routes
 				dependencyHandler := handlers.NewServiceDependencyHandler(serviceService)
-				services.POST("/:id/dependencies", dependencyHandler.AddServiceDependency)
-				services.GET("/:id/dependencies", dependencyHandler.GetServiceDependencies)
-				services.GET("/:id/dependents", dependencyHandler.GetServiceDependents)
-				services.DELETE("/:id/dependencies/:dependency_id", dependencyHandler.RemoveServiceDependency) // api/router.go (add to your existing routes)
+				services.POST("/:id/dependencies", inNamespace(security.PermServicesWrite), owner, dependencyHandler.AddServiceDependency)
+				services.GET("/:id/dependencies", inNamespace(security.PermServicesRead), visible, dependencyHandler.GetServiceDependencies)
+				services.GET("/:id/dependents", inNamespace(security.PermServicesRead), visible, dependencyHandler.GetServiceDependents)
+				services.DELETE("/:id/dependencies/:dependency_id", inNamespace(security.PermServicesWrite), owner, dependencyHandler.RemoveServiceDependency) // api/router.go (add to your existing routes)
 
 				// Create health handler
-				healthHandler := handlers.NewHealthHandler(healthService)
+				healthHandler := handlers.NewHealthHandler(healthService, heartbeatService)
 
-				// Health check routes
-				services.POST("/:id/health", healthHandler.ReportServiceHealth)
-				services.GET("/:id/health/history", healthHandler.GetHealthHistory)
+				// Health check routes, reported by the service itself through a service account
+				services.POST("/:id/health", inNamespace(security.PermHealthReport), owner, healthHandler.ReportServiceHealth)
+				services.POST("/:id/heartbeat", inNamespace(security.PermHealthReport), owner, healthHandler.Heartbeat)
+				services.GET("/:id/health/history", inNamespace(security.PermHealthRead), visible, healthHandler.GetHealthHistory)
 
 				// Health checks configuration routes
-				services.POST("/:id/health-checks", healthHandler.CreateHealthCheck)
-				services.GET("/:id/health-checks", healthHandler.GetHealthChecks)
-				services.GET("/:id/health-checks/:check_id", healthHandler.GetHealthCheck)
-				services.PUT("/:id/health-checks/:check_id", healthHandler.UpdateHealthCheck)
-				services.DELETE("/:id/health-checks/:check_id", healthHandler.DeleteHealthCheck)
+				services.POST("/:id/health-checks", inNamespace(security.PermHealthWrite), owner, healthHandler.CreateHealthCheck)
+				services.GET("/:id/health-checks", inNamespace(security.PermHealthRead), visible, healthHandler.GetHealthChecks)
+				services.GET("/:id/health-checks/:check_id", inNamespace(security.PermHealthRead), visible, healthHandler.GetHealthCheck)
+				services.PUT("/:id/health-checks/:check_id", inNamespace(security.PermHealthWrite), owner, healthHandler.UpdateHealthCheck)
+				services.DELETE("/:id/health-checks/:check_id", inNamespace(security.PermHealthWrite), owner, healthHandler.DeleteHealthCheck)
 
 				// Custom metrics routes
-				services.GET("/:id/metrics", healthHandler.GetCustomMetrics)
-				services.POST("/:id/metrics", healthHandler.CreateOrUpdateCustomMetric)
+				services.GET("/:id/metrics", inNamespace(security.PermHealthRead), visible, healthHandler.GetCustomMetrics)
+				services.POST("/:id/metrics", inNamespace(security.PermMetricsWrite), owner, healthHandler.CreateOrUpdateCustomMetric)
 
 				// Health thresholds routes
-				services.POST("/:id/thresholds", healthHandler.CreateHealthThreshold)
-				services.GET("/:id/thresholds", healthHandler.GetHealthThresholds)
-				services.PUT("/:id/thresholds/:threshold_id", healthHandler.UpdateHealthThreshold)
-				services.DELETE("/:id/thresholds/:threshold_id", healthHandler.DeleteHealthThreshold)
+				services.POST("/:id/thresholds", inNamespace(security.PermHealthWrite), owner, healthHandler.CreateHealthThreshold)
+				services.GET("/:id/thresholds", inNamespace(security.PermHealthRead), visible, healthHandler.GetHealthThresholds)
+				services.PUT("/:id/thresholds/:threshold_id", inNamespace(security.PermHealthWrite), owner, healthHandler.UpdateHealthThreshold)
+				services.DELETE("/:id/thresholds/:threshold_id", inNamespace(security.PermHealthWrite), owner, healthHandler.DeleteHealthThreshold)
+
+				// Workload certificate routes
+				certificateHandler := handlers.NewCertificateHandler(certificateService)
+				services.POST("/:id/certificate", inNamespace(security.PermCertificatesIssue), owner, certificateHandler.IssueCertificate)
+
+				// Service account routes
+				services.POST("/:id/accounts", inNamespace(security.PermServiceAccountsAdmin), owner, serviceAccountHandler.CreateAccount)
+				services.GET("/:id/accounts", inNamespace(security.PermServicesRead), owner, serviceAccountHandler.ListAccounts)
+				services.POST("/:id/accounts/:account_id/rotate", inNamespace(security.PermServiceAccountsAdmin), owner, serviceAccountHandler.RotateSecret)
+				services.DELETE("/:id/accounts/:account_id", inNamespace(security.PermServiceAccountsAdmin), owner, serviceAccountHandler.DeleteAccount)
 
 			}
 
-			// // Gateway routes
-			// gateway := protected.Group("/gateway")
-			// {
-			// These handlers will be implemented later
-			// gateway.GET("/routes", handlers.ListRoutes)
-			// gateway.POST("/routes", handlers.CreateRoute)
-			// }
+			// Gateway routes
+			gateway := protected.Group("/gateway")
+			{
+				routeHandler := handlers.NewRouteHandler(routeService)
+				gateway.GET("/routes", inNamespace(security.PermGatewayRead), routeHandler.ListRoutes)
+				gateway.POST("/routes", inNamespace(security.PermGatewayAdmin), routeHandler.CreateRoute)
+				gateway.GET("/routes/:id", inNamespace(security.PermGatewayRead), routeHandler.GetRoute)
+				gateway.PUT("/routes/:id", inNamespace(security.PermGatewayAdmin), routeHandler.UpdateRoute)
+				gateway.DELETE("/routes/:id", inNamespace(security.PermGatewayAdmin), routeHandler.DeleteRoute)
+
+				// Fault injection routes
+				gateway.PUT("/routes/:id/fault", inNamespace(security.PermGatewayAdmin), routeHandler.SetRouteFault)
+				gateway.DELETE("/routes/:id/fault", inNamespace(security.PermGatewayAdmin), routeHandler.ClearRouteFault)
+
+				// Gateway runtime metrics
+				gateway.GET("/metrics", allow(security.PermGatewayRead), routeHandler.GetGatewayMetrics)
+
+				// Consumer and API key routes
+				consumerHandler := handlers.NewConsumerHandler(consumerService)
+				gateway.GET("/consumers", allow(security.PermGatewayRead), consumerHandler.ListConsumers)
+				gateway.POST("/consumers", allow(security.PermGatewayAdmin), consumerHandler.CreateConsumer)
+				gateway.GET("/consumers/:id", allow(security.PermGatewayRead), consumerHandler.GetConsumer)
+				gateway.PUT("/consumers/:id", allow(security.PermGatewayAdmin), consumerHandler.UpdateConsumer)
+				gateway.DELETE("/consumers/:id", allow(security.PermGatewayAdmin), consumerHandler.DeleteConsumer)
+				gateway.GET("/consumers/:id/keys", allow(security.PermGatewayRead), consumerHandler.ListKeys)
+				gateway.POST("/consumers/:id/keys", allow(security.PermGatewayAdmin), consumerHandler.CreateKey)
+				gateway.DELETE("/consumers/:id/keys/:key_id", allow(security.PermGatewayAdmin), consumerHandler.RevokeKey)
+				gateway.GET("/consumers/:id/secrets", allow(security.PermGatewayRead), consumerHandler.ListSecrets)
+				gateway.POST("/consumers/:id/secrets", allow(security.PermGatewayAdmin), consumerHandler.CreateSecret)
+				gateway.DELETE("/consumers/:id/secrets/:secret_id", allow(security.PermGatewayAdmin), consumerHandler.RevokeSecret)
+
+				// API specification routes for request validation
+				apiSpecHandler := handlers.NewAPISpecHandler(apiSpecService)
+				gateway.GET("/specs", allow(security.PermGatewayRead), apiSpecHandler.ListSpecs)
+				gateway.POST("/specs", allow(security.PermGatewayAdmin), apiSpecHandler.CreateSpec)
+				gateway.GET("/specs/:id", allow(security.PermGatewayRead), apiSpecHandler.GetSpec)
+				gateway.GET("/specs/:id/document", allow(security.PermGatewayRead), apiSpecHandler.GetDocument)
+				gateway.PUT("/specs/:id", allow(security.PermGatewayAdmin), apiSpecHandler.UpdateSpec)
+				gateway.DELETE("/specs/:id", allow(security.PermGatewayAdmin), apiSpecHandler.DeleteSpec)
+			}
+
+			// Mesh routes
+			mesh := protected.Group("/mesh")
+			{
+				certificateHandler := handlers.NewCertificateHandler(certificateService)
+				mesh.GET("/trust-bundle", allow(security.PermMeshRead), certificateHandler.GetTrustBundle)
+			}
+
+			// TLS certificate routes
+			tlsRoutes := protected.Group("/tls")
+			tlsRoutes.Use(allow(security.PermTLSAdmin))
+			{
+				tlsCertificateHandler := handlers.NewTLSCertificateHandler(tlsCertificateService)
+				tlsRoutes.GET("/certificates", tlsCertificateHandler.ListCertificates)
+				tlsRoutes.POST("/certificates", tlsCertificateHandler.CreateCertificate)
+				tlsRoutes.GET("/certificates/:id", tlsCertificateHandler.GetCertificate)
+				tlsRoutes.PUT("/certificates/:id", tlsCertificateHandler.UpdateCertificate)
+				tlsRoutes.DELETE("/certificates/:id", tlsCertificateHandler.DeleteCertificate)
+			}
+
+			// Team routes
+			teams := protected.Group("/teams")
+			{
+				teamHandler := handlers.NewTeamHandler(teamService)
+				teams.GET("", allow(security.PermTeamsRead), teamHandler.ListTeams)
+				teams.POST("", allow(security.PermTeamsAdmin), teamHandler.CreateTeam)
+				teams.GET("/:id", allow(security.PermTeamsRead), teamHandler.GetTeam)
+				teams.PUT("/:id", allow(security.PermTeamsAdmin), teamHandler.UpdateTeam)
+				teams.DELETE("/:id", allow(security.PermTeamsAdmin), teamHandler.DeleteTeam)
+				teams.GET("/:id/members", allow(security.PermTeamsRead), teamHandler.ListMembers)
+				teams.POST("/:id/members", allow(security.PermTeamsAdmin), teamHandler.AddMember)
+				teams.DELETE("/:id/members/:user_id", allow(security.PermTeamsAdmin), teamHandler.RemoveMember)
+			}
+
+			// Role routes
+			roleHandler := handlers.NewRoleHandler(roleService)
+			roles := protected.Group("/roles")
+			roles.Use(allow(security.PermRolesAdmin))
+			{
+				roles.GET("", roleHandler.ListRoles)
+				roles.POST("", roleHandler.CreateRole)
+				roles.GET("/permissions", roleHandler.ListPermissions)
+				roles.GET("/:name", roleHandler.GetRole)
+				roles.PUT("/:name", roleHandler.UpdateRole)
+				roles.DELETE("/:name", roleHandler.DeleteRole)
+			}
+
+			// User administration routes
+			users := protected.Group("/users")
+			users.Use(allow(security.PermUsersAdmin))
+			{
+				users.PUT("/:id/role", roleHandler.SetUserRole)
+				users.POST("/:id/logout", authHandler.ForceLogout)
+				users.POST("/:id/unlock", authHandler.UnlockUser)
+				users.DELETE("/:id/mfa", authHandler.ResetMFA)
+			}
+
+			// Namespace routes
+			namespaces := protected.Group("/namespaces")
+			{
+				namespaceHandler := handlers.NewNamespaceHandler(namespaceService)
+				namespaces.GET("", inNamespace(security.PermServicesRead), namespaceHandler.ListNamespaces)
+				namespaces.POST("", allow(security.PermNamespacesAdmin), namespaceHandler.CreateNamespace)
+				namespaces.GET("/:name", inNamespace(security.PermServicesRead), namespaceHandler.GetNamespace)
+				namespaces.PUT("/:name", allow(security.PermNamespacesAdmin), namespaceHandler.UpdateNamespace)
+				namespaces.DELETE("/:name", allow(security.PermNamespacesAdmin), namespaceHandler.DeleteNamespace)
+				namespaces.GET("/:name/bindings", allow(security.PermNamespacesAdmin), namespaceHandler.ListBindings)
+				namespaces.POST("/:name/bindings", allow(security.PermNamespacesAdmin), namespaceHandler.CreateBinding)
+				namespaces.DELETE("/:name/bindings/:binding_id", allow(security.PermNamespacesAdmin), namespaceHandler.DeleteBinding)
+			}
+
+			// Audit log routes
+			auditHandler := handlers.NewAuditHandler(auditService)
+			audit := protected.Group("/audit")
+			audit.Use(allow(security.PermAuditRead))
+			{
+				audit.GET("", auditHandler.ListEntries)
+				audit.GET("/export", auditHandler.ExportEntries)
+			}
 
 			// // Metrics routes
 			// metrics := protected.Group("/metrics")
@@ -20,8 +20,63 @@ type Config struct {
 		TimeoutWrite    int    `mapstructure:"timeout_write"`
 		TimeoutIdle     int    `mapstructure:"timeout_idle"`
 		TimeoutShutdown int    `mapstructure:"timeout_shutdown"`
+
+		// Proxies whose X-Forwarded-For header is believed for the client address
+		TrustedProxies []string `mapstructure:"trusted_proxies"`
+
+		// Client networks that may call the API, and its admin routes in particular
+		Access      IPAccess `mapstructure:"access"`
+		AdminAccess IPAccess `mapstructure:"admin_access"`
+
+		// Browser origins that may call the API, such as the dashboard
+		CORS CORS `mapstructure:"cors"`
 	} `mapstructure:"server"`
 
+	// Gateway configuration
+	Gateway struct {
+		Port         int `mapstructure:"port"`
+		SyncInterval int `mapstructure:"sync_interval"` // in seconds
+
+		// Proxies whose X-Forwarded-For header is believed for the client address
+		TrustedProxies []string `mapstructure:"trusted_proxies"`
+
+		// Load shedding thresholds, zero disables a signal
+		LoadShedding struct {
+			MaxInFlight    int     `mapstructure:"max_in_flight"`
+			MaxQueueDepth  int     `mapstructure:"max_queue_depth"`
+			MaxCPUPercent  float64 `mapstructure:"max_cpu_percent"`
+			SheddableRatio float64 `mapstructure:"sheddable_ratio"`
+		} `mapstructure:"load_shedding"`
+
+		// Issuers whose bearer tokens routes can require
+		JWTIssuers []struct {
+			Name            string `mapstructure:"name"`
+			Issuer          string `mapstructure:"issuer"`
+			JWKSURL         string `mapstructure:"jwks_url"`
+			JWKSFile        string `mapstructure:"jwks_file"`        // used instead of jwks_url
+			RefreshInterval int    `mapstructure:"refresh_interval"` // in seconds
+		} `mapstructure:"jwt_issuers"`
+	} `mapstructure:"gateway"`
+
+	// TLS termination for the gateway and the API
+	TLS struct {
+		Enabled            bool   `mapstructure:"enabled"`
+		ServerPort         int    `mapstructure:"server_port"`
+		GatewayPort        int    `mapstructure:"gateway_port"`
+		DefaultCertificate string `mapstructure:"default_certificate"` // served to clients without SNI
+		ReloadInterval     int    `mapstructure:"reload_interval"`     // in seconds
+		ExpiryWarningDays  int    `mapstructure:"expiry_warning_days"`
+	} `mapstructure:"tls"`
+
+	// Mutual TLS between the gateway, services and upstreams
+	MTLS struct {
+		Enabled     bool   `mapstructure:"enabled"`
+		TrustDomain string `mapstructure:"trust_domain"`
+		CACertFile  string `mapstructure:"ca_cert_file"`
+		CAKeyFile   string `mapstructure:"ca_key_file"`
+		CertTTL     int    `mapstructure:"cert_ttl"` // in minutes
+	} `mapstructure:"mtls"`
+
 	// Database configuration
 	Database struct {
 		Host     string `mapstructure:"host"`
@@ -34,15 +89,90 @@ type Config struct {
 
 	// JWT configuration
 	JWT struct {
-		Secret     string `mapstructure:"secret"`
-		ExpiryHour int    `mapstructure:"expiry_hour"`
+		Issuer          string `mapstructure:"issuer"`
+		Algorithm       string `mapstructure:"algorithm"`         // EdDSA or RS256
+		AccessTokenTTL  int    `mapstructure:"access_token_ttl"`  // in minutes
+		RefreshTokenTTL int    `mapstructure:"refresh_token_ttl"` // in hours
+		KeyRotationDays int    `mapstructure:"key_rotation_days"`
+		SyncInterval    int    `mapstructure:"sync_interval"` // in seconds
 	} `mapstructure:"jwt"`
 
+	// Auth configuration
+	Auth struct {
+		SyncInterval int `mapstructure:"sync_interval"` // in seconds between custom role reloads
+
+		// Self-service registration through /auth/register, off unless
+		// enabled. Registered users are GUESTs until an admin promotes them.
+		AllowRegistration bool `mapstructure:"allow_registration"`
+
+		// Administrator created on startup while no users exist
+		BootstrapAdmin struct {
+			Username string `mapstructure:"username"`
+			Email    string `mapstructure:"email"`
+			Password string `mapstructure:"password"`
+		} `mapstructure:"bootstrap_admin"`
+
+		// Multi-factor authentication with TOTP authenticators
+		MFA struct {
+			Issuer          string `mapstructure:"issuer"`            // Name authenticator apps show for Hermes accounts
+			RequireForAdmin bool   `mapstructure:"require_for_admin"` // Admin permissions need a session that verified a second factor
+		} `mapstructure:"mfa"`
+
+		// Lockout after repeated failed logins
+		Lockout struct {
+			MaxAttempts int `mapstructure:"max_attempts"` // zero disables lockout
+			Duration    int `mapstructure:"duration"`     // in minutes
+		} `mapstructure:"lockout"`
+
+		// Single sign-on through an OpenID Connect provider
+		OIDC struct {
+			Enabled      bool     `mapstructure:"enabled"`
+			IssuerURL    string   `mapstructure:"issuer_url"`
+			ClientID     string   `mapstructure:"client_id"`
+			ClientSecret string   `mapstructure:"client_secret"`
+			RedirectURL  string   `mapstructure:"redirect_url"` // must point at /api/v1/auth/oidc/callback
+			Scopes       []string `mapstructure:"scopes"`
+			GroupsClaim  string   `mapstructure:"groups_claim"`
+			DefaultRole  string   `mapstructure:"default_role"` // role of users matching no mapping
+
+			// Provider groups granting a role, the first match wins
+			RoleMappings []struct {
+				Group string `mapstructure:"group"`
+				Role  string `mapstructure:"role"`
+			} `mapstructure:"role_mappings"`
+		} `mapstructure:"oidc"`
+	} `mapstructure:"auth"`
+
 	// HealthCheck configuration
 	HealthCheck struct {
 		Interval int `mapstructure:"interval"` // in seconds
 	} `mapstructure:"health_check"`
 
+	// Heartbeats services send to show they are alive. Steps are counted in
+	// TTLs missed since the last heartbeat, a zero step is skipped.
+	Heartbeat struct {
+		Interval        int    `mapstructure:"interval"`         // in seconds between checks for missed heartbeats
+		DefaultTTL      int    `mapstructure:"default_ttl"`      // in seconds, for services sending heartbeats without a TTL
+		UnknownAfter    int    `mapstructure:"unknown_after"`    // missed TTLs before a service is marked UNKNOWN
+		UnhealthyAfter  int    `mapstructure:"unhealthy_after"`  // missed TTLs before a service is marked UNHEALTHY
+		DeregisterAfter int    `mapstructure:"deregister_after"` // missed TTLs before a service is deregistered
+		Deregistration  string `mapstructure:"deregistration"`   // soft_delete keeps the service until its next heartbeat, delete removes it
+	} `mapstructure:"heartbeat"`
+
+	// Key encrypting secrets stored in the database when no keyring is
+	// configured. Values sealed before envelope encryption are opened with it.
+	EncryptionKey string `mapstructure:"encryption_key"`
+
+	// Envelope encryption of secrets stored in the database
+	Encryption struct {
+		Provider string   `mapstructure:"provider"` // env or file, the encryption key alone if empty
+		Keys     []string `mapstructure:"keys"`     // env provider: "<id>:<key>" entries, the first seals new values
+		KeyFile  string   `mapstructure:"key_file"` // file provider: one "<id>:<key>" entry per line, the first seals new values
+
+		// Service metadata keys containing one of these are encrypted
+		SecretMetadataKeys []string `mapstructure:"secret_metadata_keys"`
+	} `mapstructure:"encryption"`
+
 	// Log level (debug, info, warn, error)
 	LogLevel string `mapstructure:"log_level"`
 
@@ -50,6 +180,25 @@ type Config struct {
 	Environment string `mapstructure:"environment"`
 }
 
+// IPAccess lists client networks, as CIDRs or single addresses. Deny
+// entries win over allow entries, and with allow entries only clients in
+// one of them are let in.
+type IPAccess struct {
+	Allow []string `mapstructure:"allow"`
+	Deny  []string `mapstructure:"deny"`
+}
+
+// CORS is a policy for cross-origin requests from browsers. Origins are exact,
+// "*" or a wildcard subdomain such as https://*.example.com.
+type CORS struct {
+	AllowedOrigins   []string `mapstructure:"allowed_origins"`
+	AllowedMethods   []string `mapstructure:"allowed_methods"`
+	AllowedHeaders   []string `mapstructure:"allowed_headers"`
+	ExposedHeaders   []string `mapstructure:"exposed_headers"`
+	MaxAge           int      `mapstructure:"max_age"` // in seconds
+	AllowCredentials bool     `mapstructure:"allow_credentials"`
+}
+
 // Load reads configuration from file or environment variables
 func Load() (*Config, error) {
 	// Set default config name and path
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// APISpec is an uploaded OpenAPI 3 document that routes validate requests against
+type APISpec struct {
+	ID          string          `json:"id" gorm:"primaryKey"`
+	Name        string          `json:"name" gorm:"uniqueIndex;not null"`
+	Description string          `json:"description"`
+	Title       string          `json:"title"`               // From the info object of the document
+	Version     string          `json:"version"`             // From the info object of the document
+	Document    json.RawMessage `json:"-" gorm:"type:jsonb"` // Stored as JSON, whatever format was uploaded
+	CreatedAt   time.Time       `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time       `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// ValidationMode decides what happens to requests that break their specification
+type ValidationMode string
+
+// ValidationMode constants
+const (
+	ValidationModeEnforce ValidationMode = "enforce" // Reject them with a 400
+	ValidationModeReport  ValidationMode = "report"  // Log them and proxy them anyway
+)
+
+// RequestValidation makes the gateway validate a route's requests against an
+// API specification before proxying them
+type RequestValidation struct {
+	SpecID string         `json:"spec_id,omitempty"` // The specification of the service's active version if empty
+	Mode   ValidationMode `json:"mode"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditAction is the kind of change recorded in the audit log
+type AuditAction string
+
+// Audit log actions
+const (
+	AuditActionCreate AuditAction = "create"
+	AuditActionUpdate AuditAction = "update"
+	AuditActionDelete AuditAction = "delete"
+)
+
+// AuditActor is whoever made a change through the management API
+type AuditActor struct {
+	ID       string // User or service account ID, empty for anonymous callers
+	Name     string
+	Role     string
+	SourceIP string
+}
+
+// AuditEntry records one change made through the management API. Entries are
+// never updated or deleted.
+type AuditEntry struct {
+	ID           uint            `json:"id" gorm:"primaryKey"`
+	Timestamp    time.Time       `json:"timestamp" gorm:"index;not null"`
+	ActorID      string          `json:"actor_id" gorm:"index"`
+	ActorName    string          `json:"actor_name"`
+	ActorRole    string          `json:"actor_role"`
+	SourceIP     string          `json:"source_ip"`
+	Action       AuditAction     `json:"action" gorm:"not null"`
+	ResourceType string          `json:"resource_type" gorm:"not null"`
+	ResourceID   string          `json:"resource_id"`
+	Before       json.RawMessage `json:"before" gorm:"serializer:json"`
+	After        json.RawMessage `json:"after" gorm:"serializer:json"`
+}
+
+// AuditQueryParams represents query parameters for searching the audit log
+type AuditQueryParams struct {
+	ActorID      string      `form:"actor_id"`
+	Action       AuditAction `form:"action"`
+	ResourceType string      `form:"resource_type"`
+	ResourceID   string      `form:"resource_id"`
+	StartTime    time.Time   `form:"start_time"`
+	EndTime      time.Time   `form:"end_time"`
+	Limit        int         `form:"limit,default=100"`
+	Offset       int         `form:"offset,default=0"`
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Consumer is a client of the gateway, such as a partner integration, that
+// authenticates with API keys
+type Consumer struct {
+	ID            string         `json:"id" gorm:"primaryKey"`
+	Name          string         `json:"name" gorm:"uniqueIndex;not null"` // Passed upstream to identify the caller
+	Description   string         `json:"description"`
+	Active        bool           `json:"active" gorm:"not null;default:true"` // Inactive consumers are rejected on every route
+	AllowedRoutes pq.StringArray `json:"allowed_routes" gorm:"type:text[]"`   // Route IDs the consumer may call, every route if empty
+	RateLimit     *RateLimit     `json:"rate_limit" gorm:"serializer:json"`   // Replaces the route's rate limit for this consumer
+	Criticality   Criticality    `json:"criticality,omitempty"`               // Replaces the route's criticality class, unless a header rule matches
+	CreatedAt     time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// ConsumerKey is an API key of a consumer. Only a hash of the key is stored.
+type ConsumerKey struct {
+	ID         string     `json:"id" gorm:"primaryKey"`
+	ConsumerID string     `json:"consumer_id" gorm:"not null;index"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`                        // Start of the key, to tell keys apart
+	KeyHash    string     `json:"-" gorm:"uniqueIndex;not null"` // Never expose the key hash
+	ExpiresAt  *time.Time `json:"expires_at"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// Expired reports whether the key can no longer be used
+func (k *ConsumerKey) Expired(now time.Time) bool {
+	return k.ExpiresAt != nil && now.After(*k.ExpiresAt)
+}
+
+// ConsumerSecret is a shared secret a consumer signs requests with. Unlike
+// API keys the secret is needed to verify signatures, so it is stored encrypted.
+type ConsumerSecret struct {
+	ID              string     `json:"id" gorm:"primaryKey"` // Sent with signatures to name the secret used
+	ConsumerID      string     `json:"consumer_id" gorm:"not null;index"`
+	Name            string     `json:"name"`
+	EncryptedSecret []byte     `json:"-" gorm:"not null"` // Sealed with the encryption key
+	ExpiresAt       *time.Time `json:"expires_at"`
+	CreatedAt       time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// Expired reports whether the secret can no longer be used
+func (s *ConsumerSecret) Expired(now time.Time) bool {
+	return s.ExpiresAt != nil && now.After(*s.ExpiresAt)
+}
+
+// ConsumerRequest represents a request to create a consumer
+type ConsumerRequest struct {
+	Name          string      `json:"name" binding:"required,max=100"`
+	Description   string      `json:"description"`
+	AllowedRoutes []string    `json:"allowed_routes"`
+	RateLimit     *RateLimit  `json:"rate_limit"`
+	Criticality   Criticality `json:"criticality"`
+}
+
+// ConsumerUpdateRequest represents a request to update a consumer
+type ConsumerUpdateRequest struct {
+	Description   *string      `json:"description"`
+	Active        *bool        `json:"active"`
+	AllowedRoutes []string     `json:"allowed_routes"`
+	RateLimit     *RateLimit   `json:"rate_limit"`
+	Criticality   *Criticality `json:"criticality"` // Empty to use the route's class again
+}
+
+// ConsumerKeyRequest represents a request to create an API key for a consumer
+type ConsumerKeyRequest struct {
+	Name          string `json:"name"`
+	ExpiresInDays int    `json:"expires_in_days" binding:"min=0"` // Never expires if zero
+}
+
+// ConsumerKeyResponse is returned when an API key is created. The key
+// cannot be retrieved again.
+type ConsumerKeyResponse struct {
+	Key    *ConsumerKey `json:"key"`
+	APIKey string       `json:"api_key"`
+}
+
+// ConsumerSecretRequest represents a request to create a signing secret for a consumer
+type ConsumerSecretRequest struct {
+	Name          string `json:"name"`
+	ExpiresInDays int    `json:"expires_in_days" binding:"min=0"` // Never expires if zero
+}
+
+// ConsumerSecretResponse is returned when a signing secret is created. The
+// secret cannot be retrieved again.
+type ConsumerSecretResponse struct {
+	Secret        *ConsumerSecret `json:"secret"`
+	SigningSecret string          `json:"signing_secret"`
+}
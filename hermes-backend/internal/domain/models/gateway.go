@@ -2,21 +2,35 @@ package models
 
 import (
 	"time"
+
+	"github.com/lib/pq"
 )
 
 // Route represents an API gateway route configuration
 type Route struct {
-	ID             string            `json:"id" db:"id"`
-	Path           string            `json:"path" db:"path"`
-	Description    string            `json:"description" db:"description"`
-	ServiceID      string            `json:"service_id" db:"service_id"`
-	LoadBalancerID string            `json:"load_balancer_id" db:"load_balancer_id"`
-	Targets        []string          `json:"targets" db:"targets"`
-	Active         bool              `json:"active" db:"active"`
-	Headers        map[string]string `json:"headers" db:"headers"`
-	RateLimit      *RateLimit        `json:"rate_limit" db:"rate_limit"`
-	CreatedAt      time.Time         `json:"created_at" db:"created_at"`
-	UpdatedAt      time.Time         `json:"updated_at" db:"updated_at"`
+	ID             string             `json:"id" gorm:"primaryKey"`
+	Namespace      string             `json:"namespace" gorm:"index;not null;default:'default'"` // Namespace of the service, paths are unique across namespaces
+	Path           string             `json:"path" gorm:"uniqueIndex;not null"`
+	Description    string             `json:"description"`
+	ServiceID      string             `json:"service_id" gorm:"index;not null"`
+	LoadBalancerID string             `json:"load_balancer_id"`
+	Targets        pq.StringArray     `json:"targets" gorm:"type:text[]"`
+	Active         bool               `json:"active" gorm:"not null;default:true"`
+	Headers        map[string]string  `json:"headers" gorm:"serializer:json"`
+	RateLimit      *RateLimit         `json:"rate_limit" gorm:"serializer:json"`
+	Fault          *FaultInjection    `json:"fault,omitempty" gorm:"serializer:json"`
+	RequireAPIKey  bool               `json:"require_api_key" gorm:"not null;default:false"` // Only consumers with a valid API key may call the route
+	JWT            *JWTRequirement    `json:"jwt,omitempty" gorm:"serializer:json"`
+	HMAC           *HMACRequirement   `json:"hmac,omitempty" gorm:"serializer:json"`
+	IPAccess       *IPAccessRules     `json:"ip_access,omitempty" gorm:"serializer:json"`
+	Validation     *RequestValidation `json:"validation,omitempty" gorm:"serializer:json"`
+	CORS           *CORSPolicy        `json:"cors,omitempty" gorm:"serializer:json"`
+
+	Criticality      Criticality       `json:"criticality" gorm:"not null;default:'DEFAULT'"`
+	CriticalityRules []CriticalityRule `json:"criticality_rules,omitempty" gorm:"serializer:json"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 }
 
 // RateLimit defines rate limiting configuration for a route
@@ -26,27 +40,175 @@ type RateLimit struct {
 	PerIP  bool          `json:"per_ip"` // Whether to apply per IP address
 }
 
+// JWTRequirement makes a route require a valid bearer token from one of the
+// issuers configured for the gateway
+type JWTRequirement struct {
+	Issuers        []string          `json:"issuers,omitempty"`         // Names of the accepted issuers, every configured issuer if empty
+	Audiences      []string          `json:"audiences,omitempty"`       // The token must be issued for one of them
+	RequiredClaims map[string]string `json:"required_claims,omitempty"` // Claim values the token must carry, any value if empty
+	Scopes         []string          `json:"scopes,omitempty"`          // Scopes the token must all grant
+	ForwardClaims  map[string]string `json:"forward_claims,omitempty"`  // Upstream header name to the claim passed in it
+}
+
+// SignatureFormat is how a signed request carries its signature
+type SignatureFormat string
+
+// SignatureFormat constants
+const (
+	// X-Hermes-Key-Id, X-Hermes-Timestamp, X-Hermes-Nonce and X-Hermes-Signature
+	// headers, with a hex encoded signature
+	SignatureFormatHermes SignatureFormat = "hermes"
+	// Authorization: HMAC-SHA256 key_id="...", timestamp="...", nonce="...",
+	// signature="..." with a base64 encoded signature
+	SignatureFormatAuthorization SignatureFormat = "authorization"
+	// X-Signature: k=...,t=...,n=...,v1=... with a hex encoded signature
+	SignatureFormatCompact SignatureFormat = "compact"
+)
+
+// Valid reports whether f is a known signature format
+func (f SignatureFormat) Valid() bool {
+	switch f {
+	case SignatureFormatHermes, SignatureFormatAuthorization, SignatureFormatCompact:
+		return true
+	}
+	return false
+}
+
+// HMACRequirement makes a route accept only requests signed with the shared
+// secret of a consumer. Signatures cover the method, path and query,
+// timestamp, nonce and a hash of the body.
+type HMACRequirement struct {
+	Format  SignatureFormat `json:"format"`             // hermes if empty
+	MaxSkew int             `json:"max_skew,omitempty"` // Seconds a signature's timestamp may be off, 300 if zero
+}
+
+// IPAccessRules restricts a route to client addresses. Deny rules win over
+// allow rules, and with allow rules only matching clients may call the route.
+type IPAccessRules struct {
+	Allow []string `json:"allow,omitempty"` // CIDRs or single addresses
+	Deny  []string `json:"deny,omitempty"`
+}
+
+// CORSPolicy decides which browser origins may call a route and what they
+// may send and read
+type CORSPolicy struct {
+	AllowedOrigins   []string `json:"allowed_origins"`           // Exact origins, "*" or a wildcard subdomain such as https://*.example.com
+	AllowedMethods   []string `json:"allowed_methods,omitempty"` // GET, HEAD and POST if empty
+	AllowedHeaders   []string `json:"allowed_headers,omitempty"` // "*" allows every request header
+	ExposedHeaders   []string `json:"exposed_headers,omitempty"` // Response headers scripts may read
+	MaxAge           int      `json:"max_age,omitempty"`         // Seconds browsers may cache a preflight response
+	AllowCredentials bool     `json:"allow_credentials"`         // Let browsers send cookies and authorization headers
+}
+
+// Criticality classifies requests for load shedding
+type Criticality string
+
+// Criticality constants, from last to first to be shed
+const (
+	CriticalityCritical  Criticality = "CRITICAL"
+	CriticalityDefault   Criticality = "DEFAULT"
+	CriticalitySheddable Criticality = "SHEDDABLE"
+)
+
+// Valid reports whether c is a known criticality class
+func (c Criticality) Valid() bool {
+	return c == CriticalityCritical || c == CriticalityDefault || c == CriticalitySheddable
+}
+
+// CriticalityRule assigns a criticality to requests carrying a header
+type CriticalityRule struct {
+	Header      string      `json:"header"`
+	Value       string      `json:"value"` // Any value if empty
+	Criticality Criticality `json:"criticality"`
+}
+
+// FaultInjection describes faults the gateway injects into a route's traffic
+type FaultInjection struct {
+	Delay       *FaultDelay `json:"delay,omitempty"`
+	Abort       *FaultAbort `json:"abort,omitempty"`
+	Reset       *FaultReset `json:"reset,omitempty"`
+	MatchHeader string      `json:"match_header,omitempty"` // Only inject into requests carrying this header
+	MatchValue  string      `json:"match_value,omitempty"`  // Required header value, any value if empty
+	ExpiresAt   time.Time   `json:"expires_at"`
+}
+
+// FaultDelay delays requests before they are proxied
+type FaultDelay struct {
+	FixedMs    int     `json:"fixed_ms"`   // Delay in milliseconds
+	MaxMs      int     `json:"max_ms"`     // If greater than FixedMs, delay is random between FixedMs and MaxMs
+	Percentage float64 `json:"percentage"` // Share of requests affected, 0-100
+}
+
+// FaultAbort answers requests with a status code instead of proxying them
+type FaultAbort struct {
+	StatusCode int     `json:"status_code"`
+	Percentage float64 `json:"percentage"` // Share of requests affected, 0-100
+}
+
+// FaultReset resets the client connection instead of proxying requests
+type FaultReset struct {
+	Percentage float64 `json:"percentage"` // Share of requests affected, 0-100
+}
+
+// Expired reports whether the fault injection is no longer in effect
+func (f *FaultInjection) Expired(now time.Time) bool {
+	return !f.ExpiresAt.IsZero() && now.After(f.ExpiresAt)
+}
+
 // RouteCreationRequest represents a request to create a new route
 type RouteCreationRequest struct {
-	Path           string            `json:"path" binding:"required"`
-	Description    string            `json:"description"`
-	ServiceID      string            `json:"service_id" binding:"required"`
-	LoadBalancerID string            `json:"load_balancer_id"`
-	Targets        []string          `json:"targets" binding:"required"`
-	Headers        map[string]string `json:"headers"`
-	RateLimit      *RateLimit        `json:"rate_limit"`
+	Path           string             `json:"path" binding:"required"`
+	Description    string             `json:"description"`
+	ServiceID      string             `json:"service_id" binding:"required"`
+	LoadBalancerID string             `json:"load_balancer_id"`
+	Targets        []string           `json:"targets" binding:"required"`
+	Headers        map[string]string  `json:"headers"`
+	RateLimit      *RateLimit         `json:"rate_limit"`
+	RequireAPIKey  bool               `json:"require_api_key"`
+	JWT            *JWTRequirement    `json:"jwt"`
+	HMAC           *HMACRequirement   `json:"hmac"`
+	IPAccess       *IPAccessRules     `json:"ip_access"`
+	Validation     *RequestValidation `json:"validation"`
+	CORS           *CORSPolicy        `json:"cors"`
+
+	Criticality      Criticality       `json:"criticality"`
+	CriticalityRules []CriticalityRule `json:"criticality_rules"`
 }
 
 // RouteUpdateRequest represents a request to update an existing route
 type RouteUpdateRequest struct {
-	Path           *string           `json:"path"`
-	Description    *string           `json:"description"`
-	ServiceID      *string           `json:"service_id"`
-	LoadBalancerID *string           `json:"load_balancer_id"`
-	Targets        []string          `json:"targets"`
-	Active         *bool             `json:"active"`
-	Headers        map[string]string `json:"headers"`
-	RateLimit      *RateLimit        `json:"rate_limit"`
+	Path             *string            `json:"path"`
+	Description      *string            `json:"description"`
+	ServiceID        *string            `json:"service_id"`
+	LoadBalancerID   *string            `json:"load_balancer_id"`
+	Targets          []string           `json:"targets"`
+	Active           *bool              `json:"active"`
+	Headers          map[string]string  `json:"headers"`
+	RateLimit        *RateLimit         `json:"rate_limit"`
+	RequireAPIKey    *bool              `json:"require_api_key"`
+	JWT              *JWTRequirement    `json:"jwt"`
+	RemoveJWT        bool               `json:"remove_jwt"` // Stop requiring a bearer token
+	HMAC             *HMACRequirement   `json:"hmac"`
+	RemoveHMAC       bool               `json:"remove_hmac"` // Stop requiring signed requests
+	IPAccess         *IPAccessRules     `json:"ip_access"`
+	RemoveIPAccess   bool               `json:"remove_ip_access"` // Let every client address call the route
+	Validation       *RequestValidation `json:"validation"`
+	RemoveValidation bool               `json:"remove_validation"` // Stop validating requests
+	CORS             *CORSPolicy        `json:"cors"`
+	RemoveCORS       bool               `json:"remove_cors"` // Stop answering cross-origin requests at the gateway
+
+	Criticality      *Criticality      `json:"criticality"`
+	CriticalityRules []CriticalityRule `json:"criticality_rules"`
+}
+
+// FaultInjectionRequest represents a request to enable fault injection on a route
+type FaultInjectionRequest struct {
+	Delay           *FaultDelay `json:"delay"`
+	Abort           *FaultAbort `json:"abort"`
+	Reset           *FaultReset `json:"reset"`
+	MatchHeader     string      `json:"match_header"`
+	MatchValue      string      `json:"match_value"`
+	DurationSeconds int         `json:"duration_seconds" binding:"required,min=1"`
 }
 
 // LoadBalancer represents a load balancer configuration
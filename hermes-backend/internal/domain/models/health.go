@@ -39,6 +39,7 @@ type HealthHistory struct {
 	ID             uint          `json:"id" gorm:"primaryKey"`
 	ServiceID      string        `json:"service_id" gorm:"index;not null"`
 	CheckID        uint          `json:"check_id" gorm:"index"`
+	InstanceID     string        `json:"instance_id,omitempty" gorm:"index"`
 	Status         ServiceStatus `json:"status" gorm:"not null"`
 	Message        string        `json:"message"`
 	ResponseTimeMs int           `json:"response_time_ms"`
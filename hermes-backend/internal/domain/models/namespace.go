@@ -0,0 +1,78 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// DefaultNamespace holds services and routes created without a namespace
+const DefaultNamespace = "default"
+
+// Namespace partitions the registry, for example by environment or business
+// unit. Service names are unique within a namespace.
+type Namespace struct {
+	Name        string          `json:"name" gorm:"primaryKey"`
+	Description string          `json:"description"`
+	Quota       *NamespaceQuota `json:"quota,omitempty" gorm:"serializer:json"`
+
+	// Namespaces whose services the services of this namespace may depend on
+	AllowedDependencies pq.StringArray `json:"allowed_dependencies" gorm:"type:text[]"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// AllowsDependencyOn reports whether services of the namespace may depend on
+// services of namespace
+func (n *Namespace) AllowsDependencyOn(namespace string) bool {
+	if namespace == n.Name {
+		return true
+	}
+	for _, allowed := range n.AllowedDependencies {
+		if allowed == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// NamespaceQuota limits what a namespace may hold, zero means unlimited
+type NamespaceQuota struct {
+	MaxServices int `json:"max_services"`
+	MaxRoutes   int `json:"max_routes"`
+}
+
+// NamespaceBinding grants a user, or every member of a team, a role within
+// one namespace on top of their own role
+type NamespaceBinding struct {
+	ID        string    `json:"id" gorm:"primaryKey"`
+	Namespace string    `json:"namespace" gorm:"index;not null"`
+	UserID    *string   `json:"user_id,omitempty" gorm:"index"`
+	TeamID    *string   `json:"team_id,omitempty" gorm:"index"`
+	Role      Role      `json:"role" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// NamespaceRequest represents the data needed to create a namespace
+type NamespaceRequest struct {
+	Name                string          `json:"name" binding:"required,max=63"`
+	Description         string          `json:"description"`
+	Quota               *NamespaceQuota `json:"quota"`
+	AllowedDependencies []string        `json:"allowed_dependencies"`
+}
+
+// NamespaceUpdateRequest represents the data that can be updated for a namespace
+type NamespaceUpdateRequest struct {
+	Description         *string         `json:"description"`
+	Quota               *NamespaceQuota `json:"quota"` // An empty quota removes the limits
+	AllowedDependencies []string        `json:"allowed_dependencies"`
+}
+
+// NamespaceBindingRequest represents a request to grant a role within a
+// namespace to either a user or a team
+type NamespaceBindingRequest struct {
+	UserID string `json:"user_id"`
+	TeamID string `json:"team_id"`
+	Role   Role   `json:"role" binding:"required"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// RoleDefinition is a custom role defined by an administrator
+type RoleDefinition struct {
+	Name        Role           `json:"name" gorm:"primaryKey"`
+	Description string         `json:"description"`
+	Permissions pq.StringArray `json:"permissions" gorm:"type:text[]"`
+	Builtin     bool           `json:"builtin" gorm:"-"`
+	CreatedAt   time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// RoleRequest represents a request to create a custom role
+type RoleRequest struct {
+	Name        Role     `json:"name" binding:"required,max=50"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions" binding:"required,min=1"`
+}
+
+// RoleUpdateRequest represents a request to update a custom role
+type RoleUpdateRequest struct {
+	Description *string  `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// UserRoleRequest represents a request to change a user's role
+type UserRoleRequest struct {
+	Role Role `json:"role" binding:"required"`
+}
@@ -9,7 +9,8 @@ import (
 // Service represents a microservice in the system
 type Service struct {
 	ID           string            `json:"id" gorm:"primaryKey"`
-	Name         string            `json:"name" gorm:"uniqueIndex;not null"`
+	Namespace    string            `json:"namespace" gorm:"uniqueIndex:idx_services_namespace_name;not null;default:'default'"`
+	Name         string            `json:"name" gorm:"uniqueIndex:idx_services_namespace_name;not null"`
 	Description  string            `json:"description"`
 	Status       ServiceStatus     `json:"status" gorm:"not null;default:'UNKNOWN'"`
 	Type         string            `json:"type"`
@@ -19,7 +20,62 @@ type Service struct {
 	CreatedAt    time.Time         `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt    time.Time         `json:"updated_at" gorm:"autoUpdateTime"`
 	LastSeen     time.Time         `json:"last_seen"`
+	HeartbeatTTL int               `json:"heartbeat_ttl,omitempty"` // in seconds, zero when no heartbeats are expected
 	RegisteredBy string            `json:"registered_by,omitempty"`
+	OwnerTeamID  *string           `json:"owner_team_id,omitempty" gorm:"index"`
+	Visibility   Visibility        `json:"visibility" gorm:"not null;default:'PUBLIC'"`
+	Bulkhead     *BulkheadConfig   `json:"bulkhead,omitempty" gorm:"serializer:json"`
+
+	AdaptiveConcurrency *AdaptiveConcurrencyConfig `json:"adaptive_concurrency,omitempty" gorm:"serializer:json"`
+
+	// Set when the service was deregistered after missing its heartbeats, a
+	// heartbeat registers it again
+	DeregisteredAt *time.Time `json:"deregistered_at,omitempty" gorm:"index"`
+
+	// Healthy instances, filled in by discovery when requested
+	Instances []*ServiceInstance `json:"instances,omitempty" gorm:"-"`
+}
+
+// Visibility controls who can see a service
+type Visibility string
+
+// Visibility constants
+const (
+	VisibilityPublic  Visibility = "PUBLIC"  // Visible to everyone who may read services
+	VisibilityPrivate Visibility = "PRIVATE" // Visible only to members of the owner team
+)
+
+// OwnedBy reports whether the service is owned by one of the given teams
+func (s *Service) OwnedBy(teamIDs []string) bool {
+	if s.OwnerTeamID == nil {
+		return false
+	}
+	for _, id := range teamIDs {
+		if id == *s.OwnerTeamID {
+			return true
+		}
+	}
+	return false
+}
+
+// VisibleTo reports whether members of the given teams can see the service
+func (s *Service) VisibleTo(teamIDs []string) bool {
+	return s.Visibility != VisibilityPrivate || s.OwnedBy(teamIDs)
+}
+
+// BulkheadConfig limits the concurrent gateway requests to a service
+type BulkheadConfig struct {
+	MaxConcurrent  int `json:"max_concurrent"`   // Maximum in-flight requests
+	MaxQueue       int `json:"max_queue"`        // Requests allowed to wait for a free slot
+	QueueTimeoutMs int `json:"queue_timeout_ms"` // Maximum wait in the queue, defaults to 1000
+}
+
+// AdaptiveConcurrencyConfig lets the gateway learn a service's concurrency limit from its latency
+type AdaptiveConcurrencyConfig struct {
+	InitialLimit int     `json:"initial_limit"` // Starting limit, defaults to 20
+	MinLimit     int     `json:"min_limit"`     // Lower bound, defaults to 1
+	MaxLimit     int     `json:"max_limit"`     // Upper bound, defaults to 1000
+	Tolerance    float64 `json:"tolerance"`     // Accepted latency increase before shrinking, defaults to 1.5
 }
 
 // ServiceStatus represents the health status of a service
@@ -36,12 +92,19 @@ const (
 // ServiceRegistration represents the data needed to register a new service
 type ServiceRegistration struct {
 	Name         string            `json:"name" binding:"required"`
+	Namespace    string            `json:"namespace"` // Defaults to the default namespace
 	Description  string            `json:"description"`
 	Type         string            `json:"type"`
 	Endpoint     string            `json:"endpoint" binding:"required"`
 	Metadata     map[string]string `json:"metadata"`
 	Tags         []string          `json:"tags"`
 	RegisteredBy string            `json:"registered_by"`
+	OwnerTeamID  string            `json:"owner_team_id"`
+	Visibility   Visibility        `json:"visibility"`
+	Bulkhead     *BulkheadConfig   `json:"bulkhead"`
+	HeartbeatTTL int               `json:"heartbeat_ttl"` // in seconds, zero when no heartbeats are expected
+
+	AdaptiveConcurrency *AdaptiveConcurrencyConfig `json:"adaptive_concurrency"`
 }
 
 type BulkServiceRegistration struct {
@@ -59,23 +122,52 @@ type ServiceHealthUpdate struct {
 
 // ServiceUpdateRequest represents the data that can be updated for a service
 type ServiceUpdateRequest struct {
-	Name        *string           `json:"name"`
-	Description *string           `json:"description"`
-	Status      *ServiceStatus    `json:"status"`
-	Type        *string           `json:"type"`
-	Endpoint    *string           `json:"endpoint"`
-	Metadata    map[string]string `json:"metadata"`
-	Tags        []string          `json:"tags"`
+	Name         *string           `json:"name"`
+	Description  *string           `json:"description"`
+	Status       *ServiceStatus    `json:"status"`
+	Type         *string           `json:"type"`
+	Endpoint     *string           `json:"endpoint"`
+	Metadata     map[string]string `json:"metadata"`
+	Tags         []string          `json:"tags"`
+	OwnerTeamID  *string           `json:"owner_team_id"` // An empty ID removes the owner
+	Visibility   *Visibility       `json:"visibility"`
+	Bulkhead     *BulkheadConfig   `json:"bulkhead"`
+	HeartbeatTTL *int              `json:"heartbeat_ttl"` // Zero stops expecting heartbeats
+
+	AdaptiveConcurrency *AdaptiveConcurrencyConfig `json:"adaptive_concurrency"`
+}
+
+// ServiceHeartbeat tells Hermes a service is alive. Services that stop
+// sending heartbeats within their TTL are marked UNKNOWN, then UNHEALTHY,
+// and finally deregistered.
+type ServiceHeartbeat struct {
+	Status  ServiceStatus `json:"status"` // Defaults to HEALTHY
+	Message string        `json:"message"`
+	TTL     int           `json:"ttl"` // in seconds, keeps the current TTL if zero
 }
 
 // ServiceQueryParams represents query parameters for listing services
 type ServiceQueryParams struct {
-	Status string   `form:"status"`
-	Type   string   `form:"type"`
-	Tags   []string `form:"tags"`
-	Search string   `form:"search"`
-	Limit  int      `form:"limit,default=20"`
-	Offset int      `form:"offset,default=0"`
+	Status    string   `form:"status"`
+	Type      string   `form:"type"`
+	Tags      []string `form:"tags"`
+	Search    string   `form:"search"`
+	Owner     string   `form:"owner_team_id"`
+	Namespace string   `form:"namespace"`
+	Limit     int      `form:"limit,default=20"`
+	Offset    int      `form:"offset,default=0"`
+
+	// Deregistered services are left out unless requested
+	IncludeDeregistered bool `form:"include_deregistered"`
+
+	// Set by the API from the caller's identity: unless AllVisible is set, only
+	// public services and services owned by ViewerTeams are listed
+	ViewerTeams []string `form:"-" json:"-"`
+	AllVisible  bool     `form:"-" json:"-"`
+
+	// Set from the caller's namespace bindings: when not nil, only services
+	// in these namespaces are listed
+	Namespaces []string `form:"-" json:"-"`
 }
 
 // ServiceVersion tracks different versions of a service
@@ -86,6 +178,7 @@ type ServiceVersion struct {
 	IsActive    bool      `json:"is_active" gorm:"default:false"`
 	Endpoint    string    `json:"endpoint" gorm:"not null"`
 	Description string    `json:"description"`
+	SpecID      *string   `json:"spec_id,omitempty" gorm:"index"` // API specification the version implements
 	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 }
@@ -96,6 +189,7 @@ type ServiceVersionRequest struct {
 	IsActive    bool   `json:"is_active"`
 	Endpoint    string `json:"endpoint" binding:"required"`
 	Description string `json:"description"`
+	SpecID      string `json:"spec_id"`
 }
 
 // ServiceDependency tracks dependencies between services
@@ -132,4 +226,12 @@ type AdvancedDiscoveryParams struct {
 	DependencyOf string `form:"dependency_of"`
 
 	LastSeenSince time.Time `form:"last_seen_since"`
+
+	IncludeInstances bool `form:"include_instances"` // Add the healthy instances of each service
+}
+
+// CertificateRequest represents a request for a workload certificate.
+// Without a CSR the key pair is generated by Hermes.
+type CertificateRequest struct {
+	CSR string `json:"csr"` // PEM encoded certificate signing request
 }
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ServiceAccount is a machine credential bound to a single service. Its ID
+// is the client ID used to mint tokens.
+type ServiceAccount struct {
+	ID               string         `json:"id" gorm:"primaryKey"`
+	ServiceID        string         `json:"service_id" gorm:"not null;index"`
+	Name             string         `json:"name" gorm:"not null"`
+	Description      string         `json:"description"`
+	Scopes           pq.StringArray `json:"scopes" gorm:"type:text[]"`
+	SecretHash       string         `json:"-" gorm:"not null"` // Never expose the secret hash
+	SecretRotatedAt  time.Time      `json:"secret_rotated_at" gorm:"not null"`
+	SecretGeneration int            `json:"secret_generation" gorm:"not null;default:0"` // Counts rotations, tokens of earlier generations are rejected
+	LastUsedAt       *time.Time     `json:"last_used_at"`
+	CreatedBy        string         `json:"created_by"`
+	CreatedAt        time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt        time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// ServiceAccountRequest represents a request to create a service account
+type ServiceAccountRequest struct {
+	Name        string   `json:"name" binding:"required,max=100"`
+	Description string   `json:"description"`
+	Scopes      []string `json:"scopes"`
+}
+
+// ServiceAccountCredentials is returned when a service account is created or
+// its secret is rotated. The secret cannot be retrieved again.
+type ServiceAccountCredentials struct {
+	Account      *ServiceAccount `json:"account"`
+	ClientID     string          `json:"client_id"`
+	ClientSecret string          `json:"client_secret"`
+}
+
+// ServiceTokenRequest represents a client credentials request for a service account token
+type ServiceTokenRequest struct {
+	ClientID     string `json:"client_id" form:"client_id" binding:"required"`
+	ClientSecret string `json:"client_secret" form:"client_secret" binding:"required"`
+}
+
+// ServiceTokenResponse represents a minted service account token
+type ServiceTokenResponse struct {
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	ServiceID   string    `json:"service_id"`
+	Scope       string    `json:"scope"`
+}
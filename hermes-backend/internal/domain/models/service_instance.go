@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+)
+
+// ServiceInstance is one replica of a service, reachable at its own
+// endpoint. The status of a service with instances is aggregated from theirs.
+type ServiceInstance struct {
+	ID           string            `json:"id" gorm:"primaryKey"`
+	ServiceID    string            `json:"service_id" gorm:"not null;index"`
+	Endpoint     string            `json:"endpoint" gorm:"not null"`
+	Zone         string            `json:"zone,omitempty" gorm:"index"`
+	Weight       int               `json:"weight" gorm:"not null;default:1"` // Relative share of the traffic
+	Metadata     map[string]string `json:"metadata,omitempty" gorm:"serializer:json"`
+	Status       ServiceStatus     `json:"status" gorm:"not null;default:'UNKNOWN'"`
+	Failures     int               `json:"failures"`                // Consecutive failed active health checks
+	HeartbeatTTL int               `json:"heartbeat_ttl,omitempty"` // in seconds, zero when no heartbeats are expected
+	LastSeen     time.Time         `json:"last_seen"`
+	CreatedAt    time.Time         `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time         `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// Routable reports whether the instance should receive traffic
+func (i *ServiceInstance) Routable() bool {
+	return i.Status == ServiceStatusHealthy || i.Status == ServiceStatusWarning
+}
+
+// ServiceInstanceRegistration represents the data needed to register an instance of a service
+type ServiceInstanceRegistration struct {
+	Endpoint     string            `json:"endpoint" binding:"required"`
+	Zone         string            `json:"zone"`
+	Weight       int               `json:"weight"` // Defaults to 1
+	Metadata     map[string]string `json:"metadata"`
+	HeartbeatTTL int               `json:"heartbeat_ttl"` // in seconds, zero when no heartbeats are expected
+}
+
+// InstanceQueryParams represents query parameters for listing the instances of a service
+type InstanceQueryParams struct {
+	Status  string `form:"status"`
+	Zone    string `form:"zone"`
+	Healthy bool   `form:"healthy"` // Only instances that should receive traffic
+}
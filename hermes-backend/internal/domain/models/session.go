@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+)
+
+// Ways a session can be started
+const (
+	SessionMethodPassword = "password"
+	SessionMethodSSO      = "sso"
+)
+
+// Session is a login of a user. Its refresh tokens form one family and its
+// access tokens carry its ID, so revoking a session ends it at once.
+type Session struct {
+	ID         string     `json:"id" gorm:"primaryKey"`
+	UserID     string     `json:"user_id" gorm:"index;not null"`
+	Method     string     `json:"method" gorm:"not null"`
+	MFA        bool       `json:"mfa" gorm:"column:mfa;not null;default:false"` // Whether a second factor was verified
+	IPAddress  string     `json:"ip_address"`
+	UserAgent  string     `json:"user_agent"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at"` // Moves with each refresh
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	Current    bool       `json:"current" gorm:"-"` // Whether the caller's token belongs to the session
+}
+
+// SessionMetadata describes how and from where a session is started
+type SessionMetadata struct {
+	Method    string
+	MFA       bool
+	IPAddress string
+	UserAgent string
+}
+
+// MFAChallenge is a login waiting for its second factor. The token handed to
+// the client is only stored hashed.
+type MFAChallenge struct {
+	ID        string    `gorm:"primaryKey"`
+	UserID    string    `gorm:"index;not null"`
+	TokenHash string    `gorm:"uniqueIndex;not null"`
+	Method    string    `gorm:"not null"`
+	Attempts  int       `gorm:"not null;default:0"`
+	ExpiresAt time.Time `gorm:"not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+)
+
+// Team is a group of users that owns services
+type Team struct {
+	ID          string    `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" gorm:"uniqueIndex;not null"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TeamMember is the membership of a user in a team
+type TeamMember struct {
+	TeamID    string    `json:"team_id" gorm:"primaryKey"`
+	UserID    string    `json:"user_id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TeamRequest represents the data needed to create a team
+type TeamRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description"`
+}
+
+// TeamUpdateRequest represents the data that can be updated for a team
+type TeamUpdateRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+}
+
+// TeamMemberRequest represents a request to add a user to a team
+type TeamMemberRequest struct {
+	UserID string `json:"user_id" binding:"required"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// TLSCertificate is a certificate the gateway and API present to clients,
+// chosen by the SNI host name of the connection
+type TLSCertificate struct {
+	ID           string         `json:"id" gorm:"primaryKey"`
+	Name         string         `json:"name" gorm:"uniqueIndex;not null"`
+	Hostnames    pq.StringArray `json:"hostnames" gorm:"type:text[]"`
+	Certificate  string         `json:"certificate" gorm:"not null"` // PEM chain, leaf first
+	EncryptedKey []byte         `json:"-" gorm:"not null"`           // Private key sealed with the TLS encryption key
+	Issuer       string         `json:"issuer"`
+	NotBefore    time.Time      `json:"not_before"`
+	NotAfter     time.Time      `json:"not_after"`
+	CreatedAt    time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TLSCertificateRequest represents the data needed to upload a certificate.
+// Host names default to the DNS names of the certificate.
+type TLSCertificateRequest struct {
+	Name        string   `json:"name" binding:"required"`
+	Certificate string   `json:"certificate" binding:"required"`
+	PrivateKey  string   `json:"private_key" binding:"required"`
+	Hostnames   []string `json:"hostnames"`
+}
+
+// TLSCertificateUpdateRequest represents the data for replacing a certificate, e.g. on renewal
+type TLSCertificateUpdateRequest struct {
+	Certificate *string  `json:"certificate"`
+	PrivateKey  *string  `json:"private_key"`
+	Hostnames   []string `json:"hostnames"`
+}
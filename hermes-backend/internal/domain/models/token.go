@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+)
+
+// SigningKey is a key used to sign access tokens. The private key is
+// encrypted with the configured encryption key.
+type SigningKey struct {
+	ID           string    `json:"id" gorm:"primaryKey"` // Published as the JWT kid
+	Algorithm    string    `json:"algorithm" gorm:"not null"`
+	EncryptedKey []byte    `json:"-" gorm:"not null"`
+	RetiresAt    time.Time `json:"retires_at"` // No tokens are signed after this time
+	ExpiresAt    time.Time `json:"expires_at"` // Removed from the JWKS after this time
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// RefreshToken is a long-lived token exchanged for new access tokens.
+// Each refresh rotates the token; tokens descending from the same login share
+// a family so that reuse of a rotated token revokes the whole family.
+type RefreshToken struct {
+	ID         string     `json:"id" gorm:"primaryKey"`
+	UserID     string     `json:"user_id" gorm:"index;not null"`
+	FamilyID   string     `json:"family_id" gorm:"index;not null"`
+	TokenHash  string     `json:"-" gorm:"uniqueIndex;not null"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	ReplacedBy string     `json:"replaced_by"` // ID of the token it was rotated to
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// RevokedToken is an access token that must no longer be accepted
+type RevokedToken struct {
+	ID        string    `json:"id" gorm:"primaryKey"` // The token's jti
+	UserID    string    `json:"user_id"`
+	Reason    string    `json:"reason"`
+	ExpiresAt time.Time `json:"expires_at"`
+	RevokedAt time.Time `json:"revoked_at" gorm:"autoCreateTime"`
+}
+
+// RefreshTokenRequest represents a request to exchange a refresh token
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// LogoutRequest represents a request to end the current session
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// TokenRevocationRequest represents a request to revoke tokens, either one
+// access token by its jti or all refresh tokens of a user
+type TokenRevocationRequest struct {
+	TokenID   string    `json:"token_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+	UserID    string    `json:"user_id"`
+	Reason    string    `json:"reason"`
+}
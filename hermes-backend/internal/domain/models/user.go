@@ -2,6 +2,8 @@ package models
 
 import (
 	"time"
+
+	"github.com/lib/pq"
 )
 
 // User represents a user in the system
@@ -9,7 +11,8 @@ type User struct {
 	ID           string     `json:"id" gorm:"primaryKey"`
 	Username     string     `json:"username" gorm:"uniqueIndex;not null"`
 	Email        string     `json:"email" gorm:"uniqueIndex;not null"`
-	PasswordHash string     `json:"-" gorm:"not null"` // Never expose password hash
+	PasswordHash string     `json:"-" gorm:"not null"`    // Never expose password hash, empty for single sign-on users
+	ExternalID   *string    `json:"-" gorm:"uniqueIndex"` // Identity at the single sign-on provider
 	FirstName    string     `json:"first_name"`
 	LastName     string     `json:"last_name"`
 	Role         Role       `json:"role" gorm:"not null;default:'USER'"`
@@ -17,6 +20,16 @@ type User struct {
 	LastLogin    *time.Time `json:"last_login"`
 	CreatedAt    time.Time  `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt    time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// Multi-factor authentication with a TOTP authenticator
+	MFAEnabled    bool           `json:"mfa_enabled" gorm:"column:mfa_enabled;not null;default:false"`
+	MFASecret     string         `json:"-" gorm:"column:mfa_secret"`             // Encrypted TOTP secret, pending until MFA is enabled
+	MFALastStep   int64          `json:"-" gorm:"column:mfa_last_step;not null"` // Last TOTP time step used, codes are single use
+	RecoveryCodes pq.StringArray `json:"-" gorm:"type:text[]"`                   // Hashes of the unused recovery codes
+
+	// Lockout after repeated failed logins
+	FailedLogins int        `json:"-" gorm:"not null;default:0"`
+	LockedUntil  *time.Time `json:"locked_until,omitempty"`
 }
 
 // Role represents user roles for RBAC
@@ -33,7 +46,7 @@ const (
 type UserRegistration struct {
 	Username  string `json:"username" binding:"required,min=3,max=50"`
 	Email     string `json:"email" binding:"required,email"`
-	Password  string `json:"password" binding:"required,min=8"`
+	Password  string `json:"password" binding:"required,min=8,max=72"`
 	FirstName string `json:"first_name"`
 	LastName  string `json:"last_name"`
 }
@@ -44,6 +57,51 @@ type UserLogin struct {
 	Password string `json:"password" binding:"required"`
 }
 
+// MFALoginRequest completes a login with a second factor, either a TOTP code
+// or a recovery code
+type MFALoginRequest struct {
+	MFAToken     string `json:"mfa_token" binding:"required"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+// MFACodeRequest proves possession of the second factor, with a TOTP code or
+// a recovery code
+type MFACodeRequest struct {
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+// MFAChallengeResponse is returned instead of tokens when a login needs a
+// second factor
+type MFAChallengeResponse struct {
+	MFARequired bool      `json:"mfa_required"`
+	MFAToken    string    `json:"mfa_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// TOTPEnrollmentRequest starts TOTP enrollment, confirming the password of
+// users who have one
+type TOTPEnrollmentRequest struct {
+	Password string `json:"password"`
+}
+
+// TOTPConfirmation enables MFA with a code from the enrolled authenticator
+type TOTPConfirmation struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// TOTPEnrollment is a pending TOTP secret for the user's authenticator app
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URL    string `json:"otpauth_url"`
+}
+
+// RecoveryCodesResponse holds newly generated recovery codes, shown only once
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
 // UserUpdateRequest represents the data that can be updated for a user
 type UserUpdateRequest struct {
 	Email     *string `json:"email"`
@@ -56,13 +114,15 @@ type UserUpdateRequest struct {
 // UserPasswordChange represents the data needed to change password
 type UserPasswordChange struct {
 	CurrentPassword string `json:"current_password" binding:"required"`
-	NewPassword     string `json:"new_password" binding:"required,min=8"`
+	NewPassword     string `json:"new_password" binding:"required,min=8,max=72"`
 }
 
 // TokenResponse represents the auth token response
 type TokenResponse struct {
-	AccessToken string    `json:"access_token"`
-	TokenType   string    `json:"token_type"`
-	ExpiresAt   time.Time `json:"expires_at"`
-	User        User      `json:"user"`
+	AccessToken      string    `json:"access_token"`
+	TokenType        string    `json:"token_type"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+	User             User      `json:"user"`
 }
@@ -0,0 +1,19 @@
+// internal/domain/repository/api_spec.go
+package repository
+
+import (
+	"context"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+)
+
+type APISpecRepository interface {
+	Create(ctx context.Context, spec *models.APISpec) error
+	GetByID(ctx context.Context, id string) (*models.APISpec, error)
+	GetByName(ctx context.Context, name string) (*models.APISpec, error)
+	GetByIDs(ctx context.Context, ids []string) ([]*models.APISpec, error)
+	List(ctx context.Context) ([]*models.APISpec, error)
+	Update(ctx context.Context, spec *models.APISpec) error
+	Delete(ctx context.Context, id string) error
+	InUse(ctx context.Context, id string) (bool, error)
+}
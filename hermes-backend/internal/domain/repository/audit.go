@@ -0,0 +1,14 @@
+// internal/domain/repository/audit.go
+package repository
+
+import (
+	"context"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+)
+
+type AuditRepository interface {
+	Create(ctx context.Context, entry *models.AuditEntry) error
+	List(ctx context.Context, params models.AuditQueryParams) ([]*models.AuditEntry, int64, error)
+	ForEach(ctx context.Context, params models.AuditQueryParams, fn func(*models.AuditEntry) error) error
+}
@@ -0,0 +1,35 @@
+// internal/domain/repository/consumer.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+)
+
+type ConsumerRepository interface {
+	Create(ctx context.Context, consumer *models.Consumer) error
+	GetByID(ctx context.Context, id string) (*models.Consumer, error)
+	GetByName(ctx context.Context, name string) (*models.Consumer, error)
+	List(ctx context.Context) ([]*models.Consumer, error)
+	ListActive(ctx context.Context) ([]*models.Consumer, error)
+	Update(ctx context.Context, consumer *models.Consumer) error
+	Delete(ctx context.Context, id string) error
+
+	// API key management
+	CreateKey(ctx context.Context, key *models.ConsumerKey) error
+	GetKey(ctx context.Context, id string) (*models.ConsumerKey, error)
+	ListKeys(ctx context.Context, consumerIDs ...string) ([]*models.ConsumerKey, error)
+	DeleteKey(ctx context.Context, id string) error
+
+	// Signing secret management
+	CreateSecret(ctx context.Context, secret *models.ConsumerSecret) error
+	GetSecret(ctx context.Context, id string) (*models.ConsumerSecret, error)
+	ListSecrets(ctx context.Context, consumerIDs ...string) ([]*models.ConsumerSecret, error)
+	DeleteSecret(ctx context.Context, id string) error
+
+	// Nonces of signed requests, shared by every Hermes instance
+	UseNonce(ctx context.Context, nonce string, expiresAt time.Time) (bool, error)
+	DeleteExpiredNonces(ctx context.Context) error
+}
@@ -0,0 +1,14 @@
+// internal/domain/repository/encryption.go
+package repository
+
+import "context"
+
+type EncryptionRepository interface {
+	// RewriteSealed passes every value of a binary column to rewrite and
+	// stores the values it replaces, returning how many were replaced
+	RewriteSealed(ctx context.Context, table, column string, rewrite func(sealed []byte) ([]byte, bool, error)) (int, error)
+	// RewriteSealedString does the same for text columns
+	RewriteSealedString(ctx context.Context, table, column string, rewrite func(sealed string) (string, bool, error)) (int, error)
+	// RewriteSealedMap does the same for JSON object columns with string values
+	RewriteSealedMap(ctx context.Context, table, column string, rewrite func(values map[string]string) (bool, error)) (int, error)
+}
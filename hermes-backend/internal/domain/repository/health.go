@@ -27,6 +27,7 @@ type HealthRepository interface {
 	// Health thresholds
 	CreateHealthThreshold(ctx context.Context, threshold *models.HealthThreshold) error
 	GetHealthThresholds(ctx context.Context, serviceID string) ([]*models.HealthThreshold, error)
+	GetHealthThreshold(ctx context.Context, id uint) (*models.HealthThreshold, error)
 	UpdateHealthThreshold(ctx context.Context, threshold *models.HealthThreshold) error
 	DeleteHealthThreshold(ctx context.Context, id uint) error
 
@@ -0,0 +1,23 @@
+// internal/domain/repository/namespace.go
+package repository
+
+import (
+	"context"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+)
+
+type NamespaceRepository interface {
+	Create(ctx context.Context, namespace *models.Namespace) error
+	GetByName(ctx context.Context, name string) (*models.Namespace, error)
+	List(ctx context.Context) ([]*models.Namespace, error)
+	Update(ctx context.Context, namespace *models.Namespace) error
+	Delete(ctx context.Context, name string) error
+
+	// Role bindings
+	CreateBinding(ctx context.Context, binding *models.NamespaceBinding) error
+	GetBinding(ctx context.Context, id string) (*models.NamespaceBinding, error)
+	ListBindings(ctx context.Context, namespace string) ([]*models.NamespaceBinding, error)
+	ListBindingsForUser(ctx context.Context, userID string) ([]*models.NamespaceBinding, error)
+	DeleteBinding(ctx context.Context, id string) error
+}
@@ -0,0 +1,16 @@
+// internal/domain/repository/role.go
+package repository
+
+import (
+	"context"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+)
+
+type RoleRepository interface {
+	Create(ctx context.Context, role *models.RoleDefinition) error
+	GetByName(ctx context.Context, name models.Role) (*models.RoleDefinition, error)
+	List(ctx context.Context) ([]*models.RoleDefinition, error)
+	Update(ctx context.Context, role *models.RoleDefinition) error
+	Delete(ctx context.Context, name models.Role) error
+}
@@ -0,0 +1,19 @@
+// internal/domain/repository/route.go
+package repository
+
+import (
+	"context"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+)
+
+type RouteRepository interface {
+	Create(ctx context.Context, route *models.Route) error
+	GetByID(ctx context.Context, id string) (*models.Route, error)
+	GetByPath(ctx context.Context, path string) (*models.Route, error)
+	List(ctx context.Context, namespaces ...string) ([]*models.Route, error)
+	ListActive(ctx context.Context) ([]*models.Route, error)
+	Update(ctx context.Context, route *models.Route) error
+	Delete(ctx context.Context, id string) error
+	CountByNamespace(ctx context.Context, namespace string) (int64, error)
+}
@@ -3,6 +3,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
 )
@@ -10,12 +11,16 @@ import (
 type ServiceRepository interface {
 	Create(ctx context.Context, service *models.Service) error
 	GetByID(ctx context.Context, id string) (*models.Service, error)
-	GetByName(ctx context.Context, name string) (*models.Service, error)
+	GetByName(ctx context.Context, namespace, name string) (*models.Service, error)
+	GetByIDs(ctx context.Context, ids []string) ([]*models.Service, error)
 	List(ctx context.Context, params models.ServiceQueryParams) ([]*models.Service, int64, error)
 	Update(ctx context.Context, service *models.Service) error
 	Delete(ctx context.Context, id string) error
 	UpdateStatus(ctx context.Context, id string, status models.ServiceStatus) error
-	UpdateLastSeen(ctx context.Context, id string) error
+	RecordHeartbeat(ctx context.Context, id string, status models.ServiceStatus, ttl int) error
+	ListMissedHeartbeats(ctx context.Context, now time.Time) ([]*models.Service, error)
+	ExpireHeartbeat(ctx context.Context, id string, lastSeen time.Time, status models.ServiceStatus, deregister bool) (bool, error)
+	CountByNamespace(ctx context.Context, namespace string) (int64, error)
 
 	AdvancedSearch(ctx context.Context, params models.AdvancedDiscoveryParams) ([]*models.Service, int64, error)
 
@@ -0,0 +1,19 @@
+// internal/domain/repository/service_account.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+)
+
+type ServiceAccountRepository interface {
+	Create(ctx context.Context, account *models.ServiceAccount) error
+	GetByID(ctx context.Context, id string) (*models.ServiceAccount, error)
+	GetByName(ctx context.Context, serviceID, name string) (*models.ServiceAccount, error)
+	ListByService(ctx context.Context, serviceID string) ([]*models.ServiceAccount, error)
+	Update(ctx context.Context, account *models.ServiceAccount) error
+	Delete(ctx context.Context, id string) error
+	UpdateLastUsed(ctx context.Context, id string, at time.Time) error
+}
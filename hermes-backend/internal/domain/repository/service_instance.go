@@ -0,0 +1,30 @@
+// internal/domain/repository/service_instance.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+)
+
+type ServiceInstanceRepository interface {
+	Create(ctx context.Context, instance *models.ServiceInstance) error
+	GetByID(ctx context.Context, id string) (*models.ServiceInstance, error)
+	GetByEndpoint(ctx context.Context, serviceID, endpoint string) (*models.ServiceInstance, error)
+	ListByService(ctx context.Context, serviceID string, params models.InstanceQueryParams) ([]*models.ServiceInstance, error)
+	ListByServices(ctx context.Context, serviceIDs []string) ([]*models.ServiceInstance, error)
+	Update(ctx context.Context, instance *models.ServiceInstance) error
+	Delete(ctx context.Context, id string) error
+	UpdateStatus(ctx context.Context, id string, status models.ServiceStatus) error
+
+	// Active health checks
+	IncrementFailures(ctx context.Context, id string) (int, error)
+	ResetFailures(ctx context.Context, id string) error
+
+	// Heartbeats
+	RecordHeartbeat(ctx context.Context, id string, status models.ServiceStatus, ttl int) error
+	ListMissedHeartbeats(ctx context.Context, now time.Time) ([]*models.ServiceInstance, error)
+	ExpireHeartbeat(ctx context.Context, id string, lastSeen time.Time, status models.ServiceStatus) (bool, error)
+	DeleteExpired(ctx context.Context, id string, lastSeen time.Time) (bool, error)
+}
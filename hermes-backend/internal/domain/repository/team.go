@@ -0,0 +1,23 @@
+// internal/domain/repository/team.go
+package repository
+
+import (
+	"context"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+)
+
+type TeamRepository interface {
+	Create(ctx context.Context, team *models.Team) error
+	GetByID(ctx context.Context, id string) (*models.Team, error)
+	GetByName(ctx context.Context, name string) (*models.Team, error)
+	List(ctx context.Context) ([]*models.Team, error)
+	Update(ctx context.Context, team *models.Team) error
+	Delete(ctx context.Context, id string) error
+
+	// Membership management
+	AddMember(ctx context.Context, member *models.TeamMember) error
+	RemoveMember(ctx context.Context, teamID, userID string) error
+	ListMembers(ctx context.Context, teamID string) ([]*models.TeamMember, error)
+	ListTeamIDsForUser(ctx context.Context, userID string) ([]string, error)
+}
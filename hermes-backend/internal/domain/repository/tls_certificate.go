@@ -0,0 +1,17 @@
+// internal/domain/repository/tls_certificate.go
+package repository
+
+import (
+	"context"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+)
+
+type TLSCertificateRepository interface {
+	Create(ctx context.Context, cert *models.TLSCertificate) error
+	GetByID(ctx context.Context, id string) (*models.TLSCertificate, error)
+	GetByName(ctx context.Context, name string) (*models.TLSCertificate, error)
+	List(ctx context.Context) ([]*models.TLSCertificate, error)
+	Update(ctx context.Context, cert *models.TLSCertificate) error
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,45 @@
+// internal/domain/repository/token.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+)
+
+type TokenRepository interface {
+	// Signing keys
+	CreateSigningKey(ctx context.Context, key *models.SigningKey) error
+	ListSigningKeys(ctx context.Context) ([]*models.SigningKey, error)
+	RetireSigningKeys(ctx context.Context, at, expiresAt time.Time) error
+	DeleteExpiredSigningKeys(ctx context.Context) error
+
+	// Refresh tokens
+	CreateRefreshToken(ctx context.Context, token *models.RefreshToken) error
+	GetRefreshTokenByHash(ctx context.Context, hash string) (*models.RefreshToken, error)
+	RotateRefreshToken(ctx context.Context, id, replacedBy string) (bool, error)
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string) error
+	DeleteExpiredRefreshTokens(ctx context.Context) error
+
+	// Sessions
+	CreateSession(ctx context.Context, session *models.Session) error
+	GetSession(ctx context.Context, id string) (*models.Session, error)
+	ListUserSessions(ctx context.Context, userID string) ([]*models.Session, error)
+	TouchSession(ctx context.Context, id string, lastSeen, expiresAt time.Time) error
+	RevokeSession(ctx context.Context, id string) error
+	RevokeUserSessions(ctx context.Context, userID, keepID string) ([]string, error)
+	DeleteExpiredSessions(ctx context.Context) error
+
+	// Logins waiting for a second factor
+	CreateMFAChallenge(ctx context.Context, challenge *models.MFAChallenge) error
+	GetMFAChallengeByHash(ctx context.Context, hash string) (*models.MFAChallenge, error)
+	RecordMFAChallengeAttempt(ctx context.Context, id string) (int, error)
+	DeleteMFAChallenge(ctx context.Context, id string) (bool, error)
+	DeleteExpiredMFAChallenges(ctx context.Context) error
+
+	// Revoked access tokens and sessions
+	CreateRevokedToken(ctx context.Context, token *models.RevokedToken) error
+	ListRevokedTokens(ctx context.Context) ([]*models.RevokedToken, error)
+	DeleteExpiredRevokedTokens(ctx context.Context) error
+}
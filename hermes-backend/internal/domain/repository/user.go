@@ -0,0 +1,30 @@
+// internal/domain/repository/user.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+)
+
+type UserRepository interface {
+	Create(ctx context.Context, user *models.User) error
+	GetByID(ctx context.Context, id string) (*models.User, error)
+	GetByUsername(ctx context.Context, username string) (*models.User, error)
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	GetByExternalID(ctx context.Context, externalID string) (*models.User, error)
+	Count(ctx context.Context) (int64, error)
+	CountByRole(ctx context.Context, role models.Role) (int64, error)
+	Update(ctx context.Context, user *models.User) error
+	RecordLogin(ctx context.Context, id string, at time.Time) error
+
+	// Lockout
+	RecordFailedLogin(ctx context.Context, id string) (int, error)
+	Lock(ctx context.Context, id string, until time.Time) error
+	Unlock(ctx context.Context, id string) error
+
+	// Multi-factor authentication
+	UseTOTPStep(ctx context.Context, id string, step int64) (bool, error)
+	UseRecoveryCode(ctx context.Context, id, hash string) (bool, error)
+}
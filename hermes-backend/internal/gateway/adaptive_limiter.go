@@ -0,0 +1,253 @@
+package gateway
+
+import (
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/logger"
+)
+
+// ShedReasonAdaptiveLimit is reported when the adaptive limiter sheds a request
+const ShedReasonAdaptiveLimit = "adaptive-concurrency-limit"
+
+// Defaults for AdaptiveConcurrencyConfig fields left at zero
+const (
+	defaultInitialLimit = 20
+	defaultMinLimit     = 1
+	defaultMaxLimit     = 1000
+	defaultTolerance    = 1.5
+)
+
+const (
+	// Number of samples averaged into one short-term latency measurement
+	adaptiveWindowSamples = 10
+	// Longest time samples are collected before the limit is updated anyway
+	adaptiveWindowDuration = time.Second
+	// Weight of each window in the long-term latency average (roughly 60 windows)
+	adaptiveLongRTTAlpha = 2.0 / 61.0
+	// Weight of each new limit estimate
+	adaptiveSmoothing = 0.2
+)
+
+// AdaptiveLimiter learns a concurrency limit from observed latency using a
+// gradient algorithm: while short-term latency stays within Tolerance of the
+// long-term average the limit grows, and it shrinks proportionally as latency rises.
+type AdaptiveLimiter struct {
+	config models.AdaptiveConcurrencyConfig
+	mu     sync.Mutex
+
+	limit    float64
+	inFlight int
+	longRTT  float64
+
+	windowStart time.Time
+	windowSum   time.Duration
+	windowCount int
+	windowPeak  int
+
+	accepted uint64
+	shed     uint64
+}
+
+// AdaptiveLimiterStats reports the current state of an adaptive limiter
+type AdaptiveLimiterStats struct {
+	Limit     int     `json:"limit"`
+	InFlight  int     `json:"in_flight"`
+	LongRTTMs float64 `json:"long_rtt_ms"`
+	Accepted  uint64  `json:"accepted"`
+	Shed      uint64  `json:"shed"`
+}
+
+// NewAdaptiveLimiter creates an adaptive limiter, filling in defaults for unset fields
+func NewAdaptiveLimiter(config models.AdaptiveConcurrencyConfig) *AdaptiveLimiter {
+	if config.MinLimit <= 0 {
+		config.MinLimit = defaultMinLimit
+	}
+	if config.MaxLimit <= 0 {
+		config.MaxLimit = defaultMaxLimit
+	}
+	if config.InitialLimit <= 0 {
+		config.InitialLimit = defaultInitialLimit
+	}
+	if config.Tolerance <= 0 {
+		config.Tolerance = defaultTolerance
+	}
+
+	limiter := &AdaptiveLimiter{config: config}
+	limiter.limit = limiter.clamp(float64(config.InitialLimit))
+	return limiter
+}
+
+// Acquire takes a slot if the current limit allows it. The returned function
+// must be called with the request latency once it completes, with ok set to
+// false when the latency should not be learned from (for example on errors).
+func (l *AdaptiveLimiter) Acquire() (func(rtt time.Duration, ok bool), bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.inFlight >= int(l.limit) {
+		l.shed++
+		return nil, false
+	}
+
+	l.inFlight++
+	l.accepted++
+	inFlight := l.inFlight
+
+	return func(rtt time.Duration, ok bool) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		l.inFlight--
+		if ok {
+			l.sample(rtt, inFlight)
+		}
+	}, true
+}
+
+// sample records a latency measurement and updates the limit once a window is complete
+func (l *AdaptiveLimiter) sample(rtt time.Duration, inFlight int) {
+	now := time.Now()
+	if l.windowCount == 0 {
+		l.windowStart = now
+	}
+	l.windowSum += rtt
+	l.windowCount++
+	if inFlight > l.windowPeak {
+		l.windowPeak = inFlight
+	}
+
+	if l.windowCount < adaptiveWindowSamples && now.Sub(l.windowStart) < adaptiveWindowDuration {
+		return
+	}
+
+	shortRTT := float64(l.windowSum) / float64(l.windowCount)
+	peak := l.windowPeak
+	l.windowSum, l.windowCount, l.windowPeak = 0, 0, 0
+
+	if l.longRTT == 0 {
+		l.longRTT = shortRTT
+	} else {
+		l.longRTT = l.longRTT*(1-adaptiveLongRTTAlpha) + shortRTT*adaptiveLongRTTAlpha
+	}
+
+	// Let the long-term average recover quickly after a latency spike has passed
+	if l.longRTT/shortRTT > 2 {
+		l.longRTT *= 0.95
+	}
+
+	// Don't grow the limit while the service isn't using half of it
+	if float64(peak) < l.limit/2 {
+		return
+	}
+
+	gradient := math.Max(0.5, math.Min(1.0, l.config.Tolerance*l.longRTT/shortRTT))
+	newLimit := l.limit*gradient + math.Sqrt(l.limit)
+	l.limit = l.clamp(l.limit*(1-adaptiveSmoothing) + newLimit*adaptiveSmoothing)
+}
+
+func (l *AdaptiveLimiter) clamp(limit float64) float64 {
+	return math.Max(float64(l.config.MinLimit), math.Min(float64(l.config.MaxLimit), limit))
+}
+
+// Stats returns the limiter's current state
+func (l *AdaptiveLimiter) Stats() AdaptiveLimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return AdaptiveLimiterStats{
+		Limit:     int(l.limit),
+		InFlight:  l.inFlight,
+		LongRTTMs: l.longRTT / float64(time.Millisecond),
+		Accepted:  l.accepted,
+		Shed:      l.shed,
+	}
+}
+
+// AdaptiveLimiters keeps one adaptive limiter per upstream service
+type AdaptiveLimiters struct {
+	limiters map[string]*AdaptiveLimiter
+	configs  map[string]models.AdaptiveConcurrencyConfig
+	mu       sync.Mutex
+	log      *logger.Logger
+}
+
+// NewAdaptiveLimiters creates an empty adaptive limiter registry
+func NewAdaptiveLimiters(log *logger.Logger) *AdaptiveLimiters {
+	return &AdaptiveLimiters{
+		limiters: make(map[string]*AdaptiveLimiter),
+		configs:  make(map[string]models.AdaptiveConcurrencyConfig),
+		log:      log,
+	}
+}
+
+// get returns the limiter of a service, recreating it when its configuration changed
+func (a *AdaptiveLimiters) get(service *models.Service) *AdaptiveLimiter {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if service.AdaptiveConcurrency == nil {
+		delete(a.limiters, service.ID)
+		delete(a.configs, service.ID)
+		return nil
+	}
+
+	limiter, ok := a.limiters[service.ID]
+	if !ok || a.configs[service.ID] != *service.AdaptiveConcurrency {
+		limiter = NewAdaptiveLimiter(*service.AdaptiveConcurrency)
+		a.limiters[service.ID] = limiter
+		a.configs[service.ID] = *service.AdaptiveConcurrency
+	}
+	return limiter
+}
+
+// Middleware returns a middleware enforcing the learned limit of the matched route's service
+func (a *AdaptiveLimiters) Middleware() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			service := ServiceFromContext(r.Context())
+			if service == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			limiter := a.get(service)
+			if limiter == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			done, ok := limiter.Acquire()
+			if !ok {
+				a.log.Warn("Request shed by adaptive limiter", "serviceID", service.ID, "path", r.URL.Path)
+				w.Header().Set(ShedReasonHeader, ShedReasonAdaptiveLimit)
+				writeError(w, http.StatusServiceUnavailable, "Service is at capacity")
+				return
+			}
+
+			start := time.Now()
+			recorder := newStatusRecorder(w)
+			defer func() {
+				// Errors return fast and would make the service look faster than it is
+				done(time.Since(start), recorder.status < http.StatusInternalServerError)
+			}()
+
+			next.ServeHTTP(recorder, r)
+		})
+	}
+}
+
+// Stats returns the state of every adaptive limiter keyed by service ID
+func (a *AdaptiveLimiters) Stats() interface{} {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	stats := make(map[string]AdaptiveLimiterStats, len(a.limiters))
+	for serviceID, limiter := range a.limiters {
+		stats[serviceID] = limiter.Stats()
+	}
+	return stats
+}
@@ -0,0 +1,217 @@
+package gateway
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/logger"
+)
+
+// APIKeyHeader carries the API key of a consumer
+const APIKeyHeader = "X-API-Key"
+
+// ConsumerHeader tells upstreams which consumer sent a request. Values sent
+// by clients are removed so it cannot be spoofed.
+const ConsumerHeader = "X-Hermes-Consumer"
+
+// HashAPIKey returns the value stored for an API key
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// consumerKey is an API key with the consumer it authenticates
+type consumerKey struct {
+	consumer  *models.Consumer
+	allowed   map[string]bool // Route IDs, every route if empty
+	expiresAt *time.Time
+}
+
+// ConsumerStats counts the requests of one consumer
+type ConsumerStats struct {
+	Accepted uint64 `json:"accepted"`
+	Rejected uint64 `json:"rejected"`
+}
+
+// KeyAuthStats reports who is calling the gateway
+type KeyAuthStats struct {
+	Consumers    map[string]ConsumerStats `json:"consumers"`
+	Unauthorized uint64                   `json:"unauthorized"` // Missing, unknown or expired keys
+	ActiveKeys   int                      `json:"active_keys"`
+}
+
+// KeyAuth identifies consumers by their API key and enforces the routes that
+// require one. It keeps an in-memory copy of the active consumers and keys.
+type KeyAuth struct {
+	keys map[string]*consumerKey // By key hash
+	mu   sync.RWMutex
+
+	stats        map[string]*ConsumerStats // By consumer name
+	unauthorized uint64
+	statsMu      sync.Mutex
+
+	log *logger.Logger
+}
+
+// NewKeyAuth creates a new key authenticator without consumers
+func NewKeyAuth(log *logger.Logger) *KeyAuth {
+	return &KeyAuth{
+		keys:  make(map[string]*consumerKey),
+		stats: make(map[string]*ConsumerStats),
+		log:   log,
+	}
+}
+
+// Update replaces the consumers and keys. Keys of consumers not in consumers,
+// such as inactive ones, are dropped.
+func (a *KeyAuth) Update(consumers []*models.Consumer, keys []*models.ConsumerKey) {
+	byID := make(map[string]*models.Consumer, len(consumers))
+	allowed := make(map[string]map[string]bool, len(consumers))
+	for _, consumer := range consumers {
+		byID[consumer.ID] = consumer
+		routes := make(map[string]bool, len(consumer.AllowedRoutes))
+		for _, id := range consumer.AllowedRoutes {
+			routes[id] = true
+		}
+		allowed[consumer.ID] = routes
+	}
+
+	byHash := make(map[string]*consumerKey, len(keys))
+	for _, key := range keys {
+		consumer, ok := byID[key.ConsumerID]
+		if !ok {
+			continue
+		}
+		byHash[key.KeyHash] = &consumerKey{
+			consumer:  consumer,
+			allowed:   allowed[consumer.ID],
+			expiresAt: key.ExpiresAt,
+		}
+	}
+
+	a.mu.Lock()
+	a.keys = byHash
+	a.mu.Unlock()
+
+	a.log.Debug("Updated gateway consumers", "consumers", len(consumers), "keys", len(byHash))
+}
+
+// authenticate returns the consumer owning key, or nil if the key is unknown or expired
+func (a *KeyAuth) authenticate(key string, now time.Time) *consumerKey {
+	if key == "" {
+		return nil
+	}
+
+	a.mu.RLock()
+	entry := a.keys[HashAPIKey(key)]
+	a.mu.RUnlock()
+
+	if entry == nil || (entry.expiresAt != nil && now.After(*entry.expiresAt)) {
+		return nil
+	}
+	return entry
+}
+
+// Middleware returns a middleware that identifies the consumer of each
+// request and rejects requests to routes requiring a key without a valid one
+func (a *KeyAuth) Middleware() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := RouteFromContext(r.Context())
+
+			key := r.Header.Get(APIKeyHeader)
+			// Never pass keys or a client supplied identity upstream
+			r.Header.Del(APIKeyHeader)
+			r.Header.Del(ConsumerHeader)
+
+			entry := a.authenticate(key, time.Now())
+			if entry == nil {
+				if route != nil && route.RequireAPIKey {
+					a.recordUnauthorized()
+					message := "API key required"
+					if key != "" {
+						message = "Invalid API key"
+					}
+					a.log.Debug("Request without valid API key", "route", route.ID, "path", r.URL.Path)
+					w.Header().Set("WWW-Authenticate", `APIKey header="`+APIKeyHeader+`"`)
+					writeError(w, http.StatusUnauthorized, message)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			consumer := entry.consumer
+			if route != nil && len(entry.allowed) > 0 && !entry.allowed[route.ID] {
+				a.record(consumer.Name, false)
+				a.log.Debug("Consumer not allowed on route", "consumer", consumer.Name, "route", route.ID)
+				writeError(w, http.StatusForbidden, "Consumer is not allowed on this route")
+				return
+			}
+
+			a.record(consumer.Name, true)
+			r.Header.Set(ConsumerHeader, consumer.Name)
+			next.ServeHTTP(w, r.WithContext(WithConsumer(r.Context(), consumer)))
+		})
+	}
+}
+
+// Stats returns the request counts of each consumer
+func (a *KeyAuth) Stats() interface{} {
+	a.mu.RLock()
+	activeKeys := len(a.keys)
+	a.mu.RUnlock()
+
+	a.statsMu.Lock()
+	defer a.statsMu.Unlock()
+
+	consumers := make(map[string]ConsumerStats, len(a.stats))
+	for name, stats := range a.stats {
+		consumers[name] = *stats
+	}
+	return KeyAuthStats{
+		Consumers:    consumers,
+		Unauthorized: a.unauthorized,
+		ActiveKeys:   activeKeys,
+	}
+}
+
+func (a *KeyAuth) record(consumer string, accepted bool) {
+	a.statsMu.Lock()
+	defer a.statsMu.Unlock()
+
+	stats, ok := a.stats[consumer]
+	if !ok {
+		stats = &ConsumerStats{}
+		a.stats[consumer] = stats
+	}
+	if accepted {
+		stats.Accepted++
+	} else {
+		stats.Rejected++
+	}
+}
+
+func (a *KeyAuth) recordUnauthorized() {
+	a.statsMu.Lock()
+	a.unauthorized++
+	a.statsMu.Unlock()
+}
+
+type consumerContextKey struct{}
+
+// WithConsumer returns a context carrying the consumer that sent a request
+func WithConsumer(ctx context.Context, consumer *models.Consumer) context.Context {
+	return context.WithValue(ctx, consumerContextKey{}, consumer)
+}
+
+// ConsumerFromContext returns the consumer that sent a request, or nil if it is anonymous
+func ConsumerFromContext(ctx context.Context) *models.Consumer {
+	consumer, _ := ctx.Value(consumerContextKey{}).(*models.Consumer)
+	return consumer
+}
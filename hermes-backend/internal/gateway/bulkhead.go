@@ -0,0 +1,191 @@
+package gateway
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/logger"
+)
+
+// ShedReasonHeader tells clients why the gateway refused a request
+const ShedReasonHeader = "X-Hermes-Shed-Reason"
+
+// Reasons reported in ShedReasonHeader when a bulkhead sheds a request
+const (
+	ShedReasonQueueFull    = "bulkhead-queue-full"
+	ShedReasonQueueTimeout = "bulkhead-queue-timeout"
+)
+
+// defaultQueueTimeout is used when a bulkhead has a queue but no timeout
+const defaultQueueTimeout = time.Second
+
+// Bulkhead limits the number of concurrent requests to one upstream service
+type Bulkhead struct {
+	config models.BulkheadConfig
+	slots  chan struct{}
+
+	queued   int64
+	accepted uint64
+	shed     uint64
+}
+
+// BulkheadStats reports the current state of a bulkhead
+type BulkheadStats struct {
+	MaxConcurrent int    `json:"max_concurrent"`
+	MaxQueue      int    `json:"max_queue"`
+	InFlight      int    `json:"in_flight"`
+	QueueDepth    int64  `json:"queue_depth"`
+	Accepted      uint64 `json:"accepted"`
+	Shed          uint64 `json:"shed"`
+}
+
+// NewBulkhead creates a bulkhead with the given limits
+func NewBulkhead(config models.BulkheadConfig) *Bulkhead {
+	return &Bulkhead{
+		config: config,
+		slots:  make(chan struct{}, config.MaxConcurrent),
+	}
+}
+
+// Acquire waits for a free slot. On success it returns a release function,
+// otherwise it returns the reason the request was shed.
+func (b *Bulkhead) Acquire(r *http.Request) (func(), string) {
+	release := func() { <-b.slots }
+
+	// Fast path: a slot is free
+	select {
+	case b.slots <- struct{}{}:
+		atomic.AddUint64(&b.accepted, 1)
+		return release, ""
+	default:
+	}
+
+	if atomic.AddInt64(&b.queued, 1) > int64(b.config.MaxQueue) {
+		atomic.AddInt64(&b.queued, -1)
+		atomic.AddUint64(&b.shed, 1)
+		return nil, ShedReasonQueueFull
+	}
+	defer atomic.AddInt64(&b.queued, -1)
+
+	timeout := time.Duration(b.config.QueueTimeoutMs) * time.Millisecond
+	if timeout <= 0 {
+		timeout = defaultQueueTimeout
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case b.slots <- struct{}{}:
+		atomic.AddUint64(&b.accepted, 1)
+		return release, ""
+	case <-timer.C:
+		atomic.AddUint64(&b.shed, 1)
+		return nil, ShedReasonQueueTimeout
+	case <-r.Context().Done():
+		atomic.AddUint64(&b.shed, 1)
+		return nil, ShedReasonQueueTimeout
+	}
+}
+
+// Stats returns the bulkhead's current counters
+func (b *Bulkhead) Stats() BulkheadStats {
+	return BulkheadStats{
+		MaxConcurrent: b.config.MaxConcurrent,
+		MaxQueue:      b.config.MaxQueue,
+		InFlight:      len(b.slots),
+		QueueDepth:    atomic.LoadInt64(&b.queued),
+		Accepted:      atomic.LoadUint64(&b.accepted),
+		Shed:          atomic.LoadUint64(&b.shed),
+	}
+}
+
+// Bulkheads keeps one bulkhead per upstream service
+type Bulkheads struct {
+	bulkheads map[string]*Bulkhead
+	mu        sync.Mutex
+	log       *logger.Logger
+}
+
+// NewBulkheads creates an empty bulkhead registry
+func NewBulkheads(log *logger.Logger) *Bulkheads {
+	return &Bulkheads{
+		bulkheads: make(map[string]*Bulkhead),
+		log:       log,
+	}
+}
+
+// get returns the bulkhead of a service, recreating it when its limits changed.
+// Requests holding a slot of a replaced bulkhead release it into the old one.
+func (b *Bulkheads) get(service *models.Service) *Bulkhead {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if service.Bulkhead == nil || service.Bulkhead.MaxConcurrent <= 0 {
+		delete(b.bulkheads, service.ID)
+		return nil
+	}
+
+	bulkhead, ok := b.bulkheads[service.ID]
+	if !ok || bulkhead.config != *service.Bulkhead {
+		bulkhead = NewBulkhead(*service.Bulkhead)
+		b.bulkheads[service.ID] = bulkhead
+	}
+	return bulkhead
+}
+
+// Middleware returns a middleware enforcing the bulkhead of the matched route's service
+func (b *Bulkheads) Middleware() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			service := ServiceFromContext(r.Context())
+			if service == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			bulkhead := b.get(service)
+			if bulkhead == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			release, reason := bulkhead.Acquire(r)
+			if release == nil {
+				b.log.Warn("Request shed by bulkhead", "serviceID", service.ID, "reason", reason, "path", r.URL.Path)
+				w.Header().Set(ShedReasonHeader, reason)
+				writeError(w, http.StatusServiceUnavailable, "Service is at capacity")
+				return
+			}
+			defer release()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Stats returns the state of every bulkhead keyed by service ID
+func (b *Bulkheads) Stats() interface{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stats := make(map[string]BulkheadStats, len(b.bulkheads))
+	for serviceID, bulkhead := range b.bulkheads {
+		stats[serviceID] = bulkhead.Stats()
+	}
+	return stats
+}
+
+// QueueDepth returns the number of requests waiting in all bulkheads
+func (b *Bulkheads) QueueDepth() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var depth int64
+	for _, bulkhead := range b.bulkheads {
+		depth += atomic.LoadInt64(&bulkhead.queued)
+	}
+	return depth
+}
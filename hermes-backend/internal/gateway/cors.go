@@ -0,0 +1,100 @@
+package gateway
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/security"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/logger"
+)
+
+// CORSHandler answers cross-origin requests on routes with a CORS policy.
+// Preflight requests are answered by the gateway and never reach upstreams,
+// and the CORS headers of upstream responses are replaced by the policy's.
+// Policies are compiled when routes are synced, not per request.
+type CORSHandler struct {
+	policies   map[string]*compiledCORS // by route ID
+	policiesMu sync.RWMutex
+
+	preflights uint64
+	rejected   uint64
+	log        *logger.Logger
+}
+
+// compiledCORS is the compiled CORS policy of a route, or the error
+// compiling it failed with
+type compiledCORS struct {
+	policy *security.CORS
+	err    error
+}
+
+// NewCORSHandler creates a new CORSHandler
+func NewCORSHandler(log *logger.Logger) *CORSHandler {
+	return &CORSHandler{
+		policies: make(map[string]*compiledCORS),
+		log:      log,
+	}
+}
+
+// Update compiles the CORS policies of routes, replacing those of the last update
+func (h *CORSHandler) Update(routes []*models.Route) {
+	policies := make(map[string]*compiledCORS)
+	for _, route := range routes {
+		if route.CORS == nil {
+			continue
+		}
+		policy, err := security.NewCORS(*route.CORS)
+		if err != nil {
+			h.log.Error("Invalid route CORS policy", "route", route.ID, "error", err)
+		}
+		policies[route.ID] = &compiledCORS{policy: policy, err: err}
+	}
+
+	h.policiesMu.Lock()
+	h.policies = policies
+	h.policiesMu.Unlock()
+}
+
+// Middleware returns a middleware applying the matched route's CORS policy.
+// Requests to routes whose policy is invalid or not compiled are rejected.
+func (h *CORSHandler) Middleware() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := RouteFromContext(r.Context())
+			if route == nil || route.CORS == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h.policiesMu.RLock()
+			compiled := h.policies[route.ID]
+			h.policiesMu.RUnlock()
+			if compiled == nil || compiled.err != nil {
+				writeError(w, http.StatusInternalServerError, "Invalid route CORS policy")
+				return
+			}
+			policy := compiled.policy
+
+			if !policy.Handle(w, r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			atomic.AddUint64(&h.preflights, 1)
+			if w.Header().Get("Access-Control-Allow-Origin") == "" {
+				atomic.AddUint64(&h.rejected, 1)
+				h.log.Debug("Preflight request rejected by CORS policy", "route", route.ID, "origin", r.Header.Get("Origin"),
+					"method", r.Header.Get("Access-Control-Request-Method"), "headers", r.Header.Get("Access-Control-Request-Headers"))
+			}
+		})
+	}
+}
+
+// Stats returns how many preflight requests were answered and rejected
+func (h *CORSHandler) Stats() interface{} {
+	return map[string]interface{}{
+		"preflights": atomic.LoadUint64(&h.preflights),
+		"rejected":   atomic.LoadUint64(&h.rejected),
+	}
+}
@@ -0,0 +1,75 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/logger"
+)
+
+func TestCORSHandlerUsesCompiledPolicies(t *testing.T) {
+	app := &models.Route{ID: "rte-1", CORS: &models.CORSPolicy{
+		AllowedOrigins: []string{"https://*.example.com"},
+		AllowedMethods: []string{"GET", "PUT"},
+		AllowedHeaders: []string{"X-Request-ID"},
+		MaxAge:         600,
+	}}
+	invalid := &models.Route{ID: "rte-2", CORS: &models.CORSPolicy{AllowedOrigins: []string{"*"}, AllowCredentials: true}}
+	unsynced := &models.Route{ID: "rte-3", CORS: &models.CORSPolicy{AllowedOrigins: []string{"*"}}}
+
+	cors := NewCORSHandler(logger.New("error"))
+	cors.Update([]*models.Route{app, invalid})
+	var upstream int
+	handler := cors.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstream++
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name        string
+		route       *models.Route
+		method      string
+		headers     map[string]string
+		want        int
+		allowOrigin string
+		upstream    bool
+	}{
+		{"allowed preflight", app, http.MethodOptions, map[string]string{
+			"Origin": "https://app.example.com", "Access-Control-Request-Method": "PUT", "Access-Control-Request-Headers": "x-request-id",
+		}, http.StatusNoContent, "https://app.example.com", false},
+		{"preflight from other origin", app, http.MethodOptions, map[string]string{
+			"Origin": "https://evil.test", "Access-Control-Request-Method": "PUT",
+		}, http.StatusForbidden, "", false},
+		{"preflight for other method", app, http.MethodOptions, map[string]string{
+			"Origin": "https://app.example.com", "Access-Control-Request-Method": "DELETE",
+		}, http.StatusForbidden, "", false},
+		{"simple request", app, http.MethodGet, map[string]string{"Origin": "https://app.example.com"}, http.StatusOK, "https://app.example.com", true},
+		{"simple request from other origin", app, http.MethodGet, map[string]string{"Origin": "https://evil.test"}, http.StatusOK, "", true},
+		{"invalid policy", invalid, http.MethodGet, map[string]string{"Origin": "https://app.example.com"}, http.StatusInternalServerError, "", false},
+		{"policy not compiled", unsynced, http.MethodGet, map[string]string{"Origin": "https://app.example.com"}, http.StatusInternalServerError, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upstream = 0
+			r := httptest.NewRequest(tt.method, "/", nil)
+			for name, value := range tt.headers {
+				r.Header.Set(name, value)
+			}
+			r = r.WithContext(WithRoute(r.Context(), tt.route))
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.allowOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.allowOrigin)
+			}
+			if (upstream > 0) != tt.upstream {
+				t.Errorf("reached upstream = %v, want %v", upstream > 0, tt.upstream)
+			}
+		})
+	}
+}
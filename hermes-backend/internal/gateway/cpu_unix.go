@@ -0,0 +1,19 @@
+//go:build unix
+
+package gateway
+
+import (
+	"syscall"
+	"time"
+)
+
+// processCPUTime returns the CPU time consumed by this process so far
+func processCPUTime() (time.Duration, bool) {
+	var usage syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage); err != nil {
+		return 0, false
+	}
+	user := time.Duration(usage.Utime.Nano())
+	system := time.Duration(usage.Stime.Nano())
+	return user + system, true
+}
@@ -0,0 +1,108 @@
+package gateway
+
+import (
+	"crypto/tls"
+	"math/rand"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/logger"
+)
+
+// FaultInjection returns a middleware that injects the fault configured on the
+// matched route. Delays are applied first and can be combined with an abort or
+// a connection reset.
+func FaultInjection(log *logger.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := RouteFromContext(r.Context())
+			if route == nil || route.Fault == nil || route.Fault.Expired(time.Now()) || !faultMatches(route.Fault, r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			fault := route.Fault
+
+			if fault.Delay != nil && roll(fault.Delay.Percentage) {
+				delay := faultDelay(fault.Delay)
+				log.Debug("Injecting delay", "route", route.ID, "delay", delay.String())
+
+				timer := time.NewTimer(delay)
+				select {
+				case <-timer.C:
+				case <-r.Context().Done():
+					timer.Stop()
+					return
+				}
+			}
+
+			if fault.Reset != nil && roll(fault.Reset.Percentage) {
+				log.Debug("Injecting connection reset", "route", route.ID)
+				resetConnection(w)
+				return
+			}
+
+			if fault.Abort != nil && roll(fault.Abort.Percentage) {
+				log.Debug("Injecting abort", "route", route.ID, "status", fault.Abort.StatusCode)
+				w.Header().Set("X-Hermes-Fault", "abort")
+				writeError(w, fault.Abort.StatusCode, "Fault injected")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// faultMatches reports whether the request carries the header the fault is limited to
+func faultMatches(fault *models.FaultInjection, r *http.Request) bool {
+	if fault.MatchHeader == "" {
+		return true
+	}
+	values, ok := r.Header[http.CanonicalHeaderKey(fault.MatchHeader)]
+	if !ok {
+		return false
+	}
+	if fault.MatchValue == "" {
+		return true
+	}
+	for _, value := range values {
+		if value == fault.MatchValue {
+			return true
+		}
+	}
+	return false
+}
+
+// faultDelay returns the fixed delay, or a random one when a maximum is set
+func faultDelay(delay *models.FaultDelay) time.Duration {
+	ms := delay.FixedMs
+	if delay.MaxMs > delay.FixedMs {
+		ms += rand.Intn(delay.MaxMs - delay.FixedMs + 1)
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// roll returns true for the given percentage of calls
+func roll(percentage float64) bool {
+	return rand.Float64()*100 < percentage
+}
+
+// resetConnection drops the client connection without a response.
+// On HTTP/1 the socket is closed with SO_LINGER 0 so the client sees a TCP RST.
+func resetConnection(w http.ResponseWriter) {
+	conn, _, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		// HTTP/2 streams cannot be hijacked, abort the stream instead
+		panic(http.ErrAbortHandler)
+	}
+
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		conn = tlsConn.NetConn()
+	}
+	if tcpConn, ok := conn.(*net.TCPConn); ok {
+		tcpConn.SetLinger(0)
+	}
+	conn.Close()
+}
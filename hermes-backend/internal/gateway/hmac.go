@@ -0,0 +1,384 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/logger"
+)
+
+// Headers of the hermes signature format
+const (
+	SignatureKeyIDHeader     = "X-Hermes-Key-Id"
+	SignatureTimestampHeader = "X-Hermes-Timestamp"
+	SignatureNonceHeader     = "X-Hermes-Nonce"
+	SignatureHeader          = "X-Hermes-Signature"
+)
+
+// CompactSignatureHeader carries signatures of the compact format
+const CompactSignatureHeader = "X-Signature"
+
+// authorizationScheme prefixes signatures of the authorization format
+const authorizationScheme = "HMAC-SHA256"
+
+// defaultMaxSkew is how far a signature's timestamp may be off by default
+const defaultMaxSkew = 5 * time.Minute
+
+// maxSignedBody is the largest request body signatures are verified over
+const maxSignedBody = 10 << 20
+
+// maxNonces bounds the nonces remembered in memory for replay protection
+const maxNonces = 1 << 20
+
+// NonceStore remembers the nonces of verified signatures. Every Hermes
+// instance serving the same routes must share it, or a captured request
+// could be replayed to another instance.
+type NonceStore interface {
+	// UseNonce stores a nonce until expiresAt and reports whether it was unused
+	UseNonce(ctx context.Context, nonce string, expiresAt time.Time) (bool, error)
+}
+
+// SigningKey is a consumer's decrypted shared secret
+type SigningKey struct {
+	ID         string
+	ConsumerID string
+	Secret     []byte
+	ExpiresAt  *time.Time
+}
+
+// signingKey is a shared secret with the consumer it authenticates
+type signingKey struct {
+	consumer  *models.Consumer
+	allowed   map[string]bool // Route IDs, every route if empty
+	secret    []byte
+	expiresAt *time.Time
+}
+
+// requestSignature is a signature with the values sent alongside it
+type requestSignature struct {
+	keyID     string
+	timestamp string
+	nonce     string
+	signature []byte
+}
+
+// SignatureAuth verifies the HMAC signatures of requests to routes that
+// require them and identifies the consumer that signed each request. Nonces
+// are remembered until their signature expires, so a signed request is only
+// accepted once. They are kept in memory unless a shared store is set.
+type SignatureAuth struct {
+	keys map[string]*signingKey // By secret ID
+	mu   sync.RWMutex
+
+	nonces NonceStore
+
+	verified uint64
+	rejected map[string]uint64 // By reason
+	statsMu  sync.Mutex
+
+	log *logger.Logger
+}
+
+// NewSignatureAuth creates a new signature verifier without secrets
+func NewSignatureAuth(log *logger.Logger) *SignatureAuth {
+	return &SignatureAuth{
+		keys:     make(map[string]*signingKey),
+		nonces:   newMemoryNonceStore(),
+		rejected: make(map[string]uint64),
+		log:      log,
+	}
+}
+
+// SetNonceStore replaces the in-memory nonces with a store shared by every
+// Hermes instance. It must be called before the gateway serves requests.
+func (a *SignatureAuth) SetNonceStore(store NonceStore) {
+	a.nonces = store
+}
+
+// Update replaces the consumers and their secrets. Secrets of consumers not
+// in consumers, such as inactive ones, are dropped.
+func (a *SignatureAuth) Update(consumers []*models.Consumer, keys []SigningKey) {
+	byID := make(map[string]*models.Consumer, len(consumers))
+	allowed := make(map[string]map[string]bool, len(consumers))
+	for _, consumer := range consumers {
+		byID[consumer.ID] = consumer
+		routes := make(map[string]bool, len(consumer.AllowedRoutes))
+		for _, id := range consumer.AllowedRoutes {
+			routes[id] = true
+		}
+		allowed[consumer.ID] = routes
+	}
+
+	byKeyID := make(map[string]*signingKey, len(keys))
+	for _, key := range keys {
+		consumer, ok := byID[key.ConsumerID]
+		if !ok {
+			continue
+		}
+		byKeyID[key.ID] = &signingKey{
+			consumer:  consumer,
+			allowed:   allowed[consumer.ID],
+			secret:    key.Secret,
+			expiresAt: key.ExpiresAt,
+		}
+	}
+
+	a.mu.Lock()
+	a.keys = byKeyID
+	a.mu.Unlock()
+
+	a.log.Debug("Updated gateway signing secrets", "secrets", len(byKeyID))
+}
+
+// Middleware returns a middleware rejecting requests to routes requiring a
+// signature unless they carry a fresh, valid signature of an allowed consumer
+func (a *SignatureAuth) Middleware() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := RouteFromContext(r.Context())
+			if route == nil || route.HMAC == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			format := route.HMAC.Format
+			if format == "" {
+				format = models.SignatureFormatHermes
+			}
+			maxSkew := defaultMaxSkew
+			if route.HMAC.MaxSkew > 0 {
+				maxSkew = time.Duration(route.HMAC.MaxSkew) * time.Second
+			}
+
+			reject := func(status int, reason, message string) {
+				a.recordRejected(reason)
+				a.log.Debug("Request signature rejected", "route", route.ID, "path", r.URL.Path, "reason", reason)
+				if status == http.StatusUnauthorized {
+					w.Header().Set("WWW-Authenticate", authorizationScheme)
+				}
+				writeError(w, status, message)
+			}
+
+			sig, ok := parseSignature(r, format)
+			if !ok {
+				reject(http.StatusUnauthorized, "missing", "Request signature required")
+				return
+			}
+
+			now := time.Now()
+			seconds, err := strconv.ParseInt(sig.timestamp, 10, 64)
+			if err != nil {
+				reject(http.StatusUnauthorized, "invalid_timestamp", "Invalid request signature")
+				return
+			}
+			signedAt := time.Unix(seconds, 0)
+			if signedAt.Before(now.Add(-maxSkew)) || signedAt.After(now.Add(maxSkew)) {
+				reject(http.StatusUnauthorized, "expired", "Request signature expired")
+				return
+			}
+
+			a.mu.RLock()
+			key := a.keys[sig.keyID]
+			a.mu.RUnlock()
+			if key == nil || (key.expiresAt != nil && now.After(*key.expiresAt)) {
+				reject(http.StatusUnauthorized, "unknown_key", "Invalid request signature")
+				return
+			}
+
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxSignedBody+1))
+			r.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+			if err != nil {
+				reject(http.StatusBadRequest, "unreadable_body", "Request body could not be read")
+				return
+			}
+			if len(body) > maxSignedBody {
+				reject(http.StatusRequestEntityTooLarge, "body_too_large", "Request body is too large to verify")
+				return
+			}
+
+			if !hmac.Equal(sig.signature, SignRequest(key.secret, r.Method, r.URL.RequestURI(), sig.timestamp, sig.nonce, body)) {
+				reject(http.StatusUnauthorized, "bad_signature", "Invalid request signature")
+				return
+			}
+
+			// Nonces are checked last so only validly signed requests take up
+			// room. They are hashed to bound their size in the store.
+			nonce := sha256.Sum256([]byte(sig.keyID + ":" + sig.nonce))
+			unused, err := a.nonces.UseNonce(r.Context(), hex.EncodeToString(nonce[:]), signedAt.Add(maxSkew))
+			if err != nil {
+				a.log.Error("Failed to record request nonce", "route", route.ID, "error", err)
+				reject(http.StatusServiceUnavailable, "nonce_store_unavailable", "Request signature could not be verified")
+				return
+			}
+			if !unused {
+				reject(http.StatusUnauthorized, "replayed", "Request was already received")
+				return
+			}
+
+			consumer := key.consumer
+			if len(key.allowed) > 0 && !key.allowed[route.ID] {
+				a.recordRejected("route_not_allowed")
+				a.log.Debug("Consumer not allowed on route", "consumer", consumer.Name, "route", route.ID)
+				writeError(w, http.StatusForbidden, "Consumer is not allowed on this route")
+				return
+			}
+
+			a.statsMu.Lock()
+			a.verified++
+			a.statsMu.Unlock()
+			r.Header.Set(ConsumerHeader, consumer.Name)
+			next.ServeHTTP(w, r.WithContext(WithConsumer(r.Context(), consumer)))
+		})
+	}
+}
+
+// Stats returns how many signatures were verified and why others were rejected
+func (a *SignatureAuth) Stats() interface{} {
+	a.mu.RLock()
+	secrets := len(a.keys)
+	a.mu.RUnlock()
+
+	a.statsMu.Lock()
+	defer a.statsMu.Unlock()
+	rejected := make(map[string]uint64, len(a.rejected))
+	for reason, count := range a.rejected {
+		rejected[reason] = count
+	}
+	stats := map[string]interface{}{
+		"verified": a.verified,
+		"rejected": rejected,
+		"secrets":  secrets,
+	}
+	if memory, ok := a.nonces.(*memoryNonceStore); ok {
+		stats["nonces"] = memory.len()
+	}
+	return stats
+}
+
+// SignRequest returns the HMAC-SHA256 signature of a request. The signed
+// string is the method, the path with its query, the timestamp, the nonce
+// and the hex SHA-256 of the body, each on its own line.
+func SignRequest(secret []byte, method, requestURI, timestamp, nonce string, body []byte) []byte {
+	bodyHash := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strings.ToUpper(method) + "\n" + requestURI + "\n" + timestamp + "\n" + nonce + "\n" + hex.EncodeToString(bodyHash[:])))
+	return mac.Sum(nil)
+}
+
+// memoryNonceStore keeps nonces in the memory of one Hermes instance
+type memoryNonceStore struct {
+	nonces    map[string]time.Time // Expiry by nonce
+	lastPrune time.Time
+	mu        sync.Mutex
+}
+
+func newMemoryNonceStore() *memoryNonceStore {
+	return &memoryNonceStore{nonces: make(map[string]time.Time)}
+}
+
+// UseNonce remembers a nonce until it expires and reports whether it was
+// unused. Expired nonces are dropped once a minute, or when there are too many.
+func (m *memoryNonceStore) UseNonce(ctx context.Context, nonce string, expiresAt time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(m.lastPrune) > time.Minute || len(m.nonces) >= maxNonces {
+		for key, expiry := range m.nonces {
+			if now.After(expiry) {
+				delete(m.nonces, key)
+			}
+		}
+		m.lastPrune = now
+	}
+
+	if expiry, ok := m.nonces[nonce]; ok && !now.After(expiry) {
+		return false, nil
+	}
+	if len(m.nonces) >= maxNonces {
+		// Refusing is safer than forgetting nonces that could be replayed
+		return false, nil
+	}
+	m.nonces[nonce] = expiresAt
+	return true, nil
+}
+
+func (m *memoryNonceStore) len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.nonces)
+}
+
+func (a *SignatureAuth) recordRejected(reason string) {
+	a.statsMu.Lock()
+	a.rejected[reason]++
+	a.statsMu.Unlock()
+}
+
+// parseSignature extracts the signature of a request in the given format
+func parseSignature(r *http.Request, format models.SignatureFormat) (*requestSignature, bool) {
+	var sig requestSignature
+	var encoded string
+	hexEncoded := true
+
+	switch format {
+	case models.SignatureFormatAuthorization:
+		scheme, params, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+		if !ok || !strings.EqualFold(scheme, authorizationScheme) {
+			return nil, false
+		}
+		values := signatureParams(params)
+		sig.keyID, sig.timestamp, sig.nonce, encoded = values["key_id"], values["timestamp"], values["nonce"], values["signature"]
+		hexEncoded = false
+	case models.SignatureFormatCompact:
+		values := signatureParams(r.Header.Get(CompactSignatureHeader))
+		sig.keyID, sig.timestamp, sig.nonce, encoded = values["k"], values["t"], values["n"], values["v1"]
+	default:
+		sig.keyID = r.Header.Get(SignatureKeyIDHeader)
+		sig.timestamp = r.Header.Get(SignatureTimestampHeader)
+		sig.nonce = r.Header.Get(SignatureNonceHeader)
+		encoded = r.Header.Get(SignatureHeader)
+	}
+
+	if sig.keyID == "" || sig.timestamp == "" || sig.nonce == "" || encoded == "" {
+		return nil, false
+	}
+	var err error
+	if hexEncoded {
+		sig.signature, err = hex.DecodeString(encoded)
+	} else {
+		sig.signature, err = base64.StdEncoding.DecodeString(encoded)
+	}
+	if err != nil {
+		// An undecodable signature is checked, and fails, like a wrong one
+		sig.signature = nil
+	}
+	return &sig, true
+}
+
+// signatureParams parses comma separated key=value pairs whose values may be quoted
+func signatureParams(value string) map[string]string {
+	params := make(map[string]string)
+	for _, part := range strings.Split(value, ",") {
+		key, val, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		params[strings.ToLower(strings.TrimSpace(key))] = strings.Trim(strings.TrimSpace(val), `"`)
+	}
+	return params
+}
@@ -0,0 +1,235 @@
+package gateway
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/logger"
+)
+
+var testSecret = []byte("shared-secret")
+
+func TestSignRequestCanonicalString(t *testing.T) {
+	body := []byte(`{"amount":10}`)
+	bodyHash := sha256.Sum256(body)
+	canonical := "POST\n/orders?id=1&b=2\n1700000000\nnonce-1\n" + hex.EncodeToString(bodyHash[:])
+	mac := hmac.New(sha256.New, testSecret)
+	mac.Write([]byte(canonical))
+	want := mac.Sum(nil)
+
+	if got := SignRequest(testSecret, "post", "/orders?id=1&b=2", "1700000000", "nonce-1", body); !hmac.Equal(got, want) {
+		t.Errorf("signature = %x, want %x", got, want)
+	}
+
+	// Every signed part changes the signature
+	base := SignRequest(testSecret, "POST", "/orders", "1700000000", "nonce-1", body)
+	variants := map[string][]byte{
+		"method":    SignRequest(testSecret, "PUT", "/orders", "1700000000", "nonce-1", body),
+		"path":      SignRequest(testSecret, "POST", "/orders/", "1700000000", "nonce-1", body),
+		"query":     SignRequest(testSecret, "POST", "/orders?x=1", "1700000000", "nonce-1", body),
+		"timestamp": SignRequest(testSecret, "POST", "/orders", "1700000001", "nonce-1", body),
+		"nonce":     SignRequest(testSecret, "POST", "/orders", "1700000000", "nonce-2", body),
+		"body":      SignRequest(testSecret, "POST", "/orders", "1700000000", "nonce-1", []byte(`{"amount":11}`)),
+		"secret":    SignRequest([]byte("other"), "POST", "/orders", "1700000000", "nonce-1", body),
+	}
+	for part, sig := range variants {
+		if hmac.Equal(sig, base) {
+			t.Errorf("changing the %s does not change the signature", part)
+		}
+	}
+}
+
+// signedRequest builds a request signed in format at signedAt
+func signedRequest(format models.SignatureFormat, keyID, nonce string, signedAt time.Time, body string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/orders?id=1", strings.NewReader(body))
+	timestamp := strconv.FormatInt(signedAt.Unix(), 10)
+	sig := SignRequest(testSecret, r.Method, r.URL.RequestURI(), timestamp, nonce, []byte(body))
+
+	switch format {
+	case models.SignatureFormatAuthorization:
+		r.Header.Set("Authorization", `HMAC-SHA256 key_id="`+keyID+`", timestamp="`+timestamp+`", nonce="`+nonce+`", signature="`+base64.StdEncoding.EncodeToString(sig)+`"`)
+	case models.SignatureFormatCompact:
+		r.Header.Set(CompactSignatureHeader, "k="+keyID+",t="+timestamp+",n="+nonce+",v1="+hex.EncodeToString(sig))
+	default:
+		r.Header.Set(SignatureKeyIDHeader, keyID)
+		r.Header.Set(SignatureTimestampHeader, timestamp)
+		r.Header.Set(SignatureNonceHeader, nonce)
+		r.Header.Set(SignatureHeader, hex.EncodeToString(sig))
+	}
+	return r
+}
+
+func newTestSignatureAuth(allowedRoutes ...string) *SignatureAuth {
+	a := NewSignatureAuth(logger.New("error"))
+	consumer := &models.Consumer{ID: "con-1", Name: "billing", Active: true, AllowedRoutes: allowedRoutes}
+	expired := time.Now().Add(-time.Minute)
+	a.Update([]*models.Consumer{consumer}, []SigningKey{
+		{ID: "sec-1", ConsumerID: "con-1", Secret: testSecret},
+		{ID: "sec-expired", ConsumerID: "con-1", Secret: testSecret, ExpiresAt: &expired},
+		{ID: "sec-orphan", ConsumerID: "con-gone", Secret: testSecret},
+	})
+	return a
+}
+
+// serveSigned passes a request through the middleware on a route requiring
+// signatures and returns the status and the consumer the upstream saw
+func serveSigned(a *SignatureAuth, format models.SignatureFormat, r *http.Request) (int, string) {
+	route := &models.Route{ID: "rt-1", HMAC: &models.HMACRequirement{Format: format, MaxSkew: 60}}
+	var consumer string
+	handler := a.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if c := ConsumerFromContext(r.Context()); c != nil {
+			consumer = c.Name
+		}
+		// The upstream still receives the whole body
+		if body, _ := io.ReadAll(r.Body); len(body) == 0 {
+			w.WriteHeader(http.StatusTeapot)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r.WithContext(WithRoute(r.Context(), route)))
+	return w.Code, consumer
+}
+
+func TestSignatureAuthFormats(t *testing.T) {
+	for _, format := range []models.SignatureFormat{models.SignatureFormatHermes, models.SignatureFormatAuthorization, models.SignatureFormatCompact} {
+		t.Run(string(format), func(t *testing.T) {
+			a := newTestSignatureAuth()
+			status, consumer := serveSigned(a, format, signedRequest(format, "sec-1", "n-1", time.Now(), `{"a":1}`))
+			if status != http.StatusOK || consumer != "billing" {
+				t.Errorf("status = %d, consumer = %q", status, consumer)
+			}
+		})
+	}
+}
+
+func TestSignatureAuthRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		request func() *http.Request
+		status  int
+	}{
+		{"unsigned", nil, func() *http.Request { return httptest.NewRequest(http.MethodGet, "/orders", nil) }, http.StatusUnauthorized},
+		{"unknown key", nil, func() *http.Request {
+			return signedRequest(models.SignatureFormatHermes, "sec-missing", "n-1", time.Now(), "{}")
+		}, http.StatusUnauthorized},
+		{"expired key", nil, func() *http.Request {
+			return signedRequest(models.SignatureFormatHermes, "sec-expired", "n-1", time.Now(), "{}")
+		}, http.StatusUnauthorized},
+		{"key of unknown consumer", nil, func() *http.Request {
+			return signedRequest(models.SignatureFormatHermes, "sec-orphan", "n-1", time.Now(), "{}")
+		}, http.StatusUnauthorized},
+		{"too old", nil, func() *http.Request {
+			return signedRequest(models.SignatureFormatHermes, "sec-1", "n-1", time.Now().Add(-2*time.Minute), "{}")
+		}, http.StatusUnauthorized},
+		{"from the future", nil, func() *http.Request {
+			return signedRequest(models.SignatureFormatHermes, "sec-1", "n-1", time.Now().Add(2*time.Minute), "{}")
+		}, http.StatusUnauthorized},
+		{"tampered body", nil, func() *http.Request {
+			r := signedRequest(models.SignatureFormatHermes, "sec-1", "n-1", time.Now(), `{"amount":10}`)
+			r.Body = io.NopCloser(strings.NewReader(`{"amount":99}`))
+			return r
+		}, http.StatusUnauthorized},
+		{"tampered query", nil, func() *http.Request {
+			r := signedRequest(models.SignatureFormatHermes, "sec-1", "n-1", time.Now(), "{}")
+			r.URL.RawQuery = "id=2"
+			return r
+		}, http.StatusUnauthorized},
+		{"undecodable signature", nil, func() *http.Request {
+			r := signedRequest(models.SignatureFormatHermes, "sec-1", "n-1", time.Now(), "{}")
+			r.Header.Set(SignatureHeader, "zz")
+			return r
+		}, http.StatusUnauthorized},
+		{"route not allowed", []string{"rt-other"}, func() *http.Request {
+			return signedRequest(models.SignatureFormatHermes, "sec-1", "n-1", time.Now(), "{}")
+		}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, consumer := serveSigned(newTestSignatureAuth(tt.allowed...), models.SignatureFormatHermes, tt.request())
+			if status != tt.status || consumer != "" {
+				t.Errorf("status = %d, consumer = %q, want %d", status, consumer, tt.status)
+			}
+		})
+	}
+}
+
+func TestSignatureAuthReplay(t *testing.T) {
+	a := newTestSignatureAuth()
+	signedAt := time.Now()
+
+	if status, _ := serveSigned(a, models.SignatureFormatHermes, signedRequest(models.SignatureFormatHermes, "sec-1", "n-1", signedAt, "{}")); status != http.StatusOK {
+		t.Fatalf("first request status = %d", status)
+	}
+	if status, _ := serveSigned(a, models.SignatureFormatHermes, signedRequest(models.SignatureFormatHermes, "sec-1", "n-1", signedAt, "{}")); status != http.StatusUnauthorized {
+		t.Errorf("replayed request status = %d, want 401", status)
+	}
+	if status, _ := serveSigned(a, models.SignatureFormatHermes, signedRequest(models.SignatureFormatHermes, "sec-1", "n-2", signedAt, "{}")); status != http.StatusOK {
+		t.Errorf("request with a new nonce status = %d", status)
+	}
+}
+
+// sharedNonces stands in for the database shared by Hermes instances
+type sharedNonces struct {
+	memory *memoryNonceStore
+	err    error
+}
+
+func (s *sharedNonces) UseNonce(ctx context.Context, nonce string, expiresAt time.Time) (bool, error) {
+	if s.err != nil {
+		return false, s.err
+	}
+	return s.memory.UseNonce(ctx, nonce, expiresAt)
+}
+
+func TestSignatureAuthSharedNonces(t *testing.T) {
+	store := &sharedNonces{memory: newMemoryNonceStore()}
+	first, second := newTestSignatureAuth(), newTestSignatureAuth()
+	first.SetNonceStore(store)
+	second.SetNonceStore(store)
+	signedAt := time.Now()
+
+	if status, _ := serveSigned(first, models.SignatureFormatHermes, signedRequest(models.SignatureFormatHermes, "sec-1", "n-1", signedAt, "{}")); status != http.StatusOK {
+		t.Fatalf("first instance status = %d", status)
+	}
+	if status, _ := serveSigned(second, models.SignatureFormatHermes, signedRequest(models.SignatureFormatHermes, "sec-1", "n-1", signedAt, "{}")); status != http.StatusUnauthorized {
+		t.Errorf("request replayed to another instance status = %d, want 401", status)
+	}
+
+	store.err = errors.New("database is down")
+	if status, _ := serveSigned(first, models.SignatureFormatHermes, signedRequest(models.SignatureFormatHermes, "sec-1", "n-2", signedAt, "{}")); status != http.StatusServiceUnavailable {
+		t.Errorf("status without a nonce store = %d, want 503", status)
+	}
+}
+
+func TestMemoryNonceStoreExpiry(t *testing.T) {
+	store := newMemoryNonceStore()
+	ctx := context.Background()
+
+	if ok, _ := store.UseNonce(ctx, "expired", time.Now().Add(-time.Second)); !ok {
+		t.Fatal("first use refused")
+	}
+	// Nonces whose signature expired may be stored again, the timestamp check
+	// rejects the signature itself
+	if ok, _ := store.UseNonce(ctx, "expired", time.Now().Add(time.Minute)); !ok {
+		t.Error("expired nonce refused")
+	}
+	if ok, _ := store.UseNonce(ctx, "expired", time.Now().Add(time.Minute)); ok {
+		t.Error("live nonce accepted twice")
+	}
+}
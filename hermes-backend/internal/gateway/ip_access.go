@@ -0,0 +1,117 @@
+package gateway
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/security"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/logger"
+)
+
+type clientIPContextKey struct{}
+
+// WithClientIP returns a copy of ctx carrying the address of the client that sent a request
+func WithClientIP(ctx context.Context, ip net.IP) context.Context {
+	return context.WithValue(ctx, clientIPContextKey{}, ip)
+}
+
+// ClientIPFromContext returns the address of the client that sent a request, if known
+func ClientIPFromContext(ctx context.Context) net.IP {
+	ip, _ := ctx.Value(clientIPContextKey{}).(net.IP)
+	return ip
+}
+
+// IPAccessControl rejects requests from client addresses a route's access
+// rules do not admit. Rules are compiled when routes are synced, not per request.
+type IPAccessControl struct {
+	rules   map[string]*compiledIPRules // by route ID
+	rulesMu sync.RWMutex
+
+	denied map[string]int64 // by route ID
+	mu     sync.Mutex
+	log    *logger.Logger
+}
+
+// compiledIPRules are the parsed access rules of a route, or the error
+// parsing them failed with
+type compiledIPRules struct {
+	rules *security.IPRules
+	err   error
+}
+
+// NewIPAccessControl creates a new IPAccessControl
+func NewIPAccessControl(log *logger.Logger) *IPAccessControl {
+	return &IPAccessControl{
+		rules:  make(map[string]*compiledIPRules),
+		denied: make(map[string]int64),
+		log:    log,
+	}
+}
+
+// Update compiles the access rules of routes, replacing those of the last update
+func (a *IPAccessControl) Update(routes []*models.Route) {
+	rules := make(map[string]*compiledIPRules)
+	for _, route := range routes {
+		if route.IPAccess == nil {
+			continue
+		}
+		parsed, err := security.ParseIPRules(route.IPAccess.Allow, route.IPAccess.Deny)
+		if err != nil {
+			a.log.Error("Invalid route access rules", "route", route.ID, "error", err)
+		}
+		rules[route.ID] = &compiledIPRules{rules: parsed, err: err}
+	}
+
+	a.rulesMu.Lock()
+	a.rules = rules
+	a.rulesMu.Unlock()
+}
+
+// Middleware returns a middleware enforcing the matched route's access rules.
+// Requests to routes whose rules are invalid or not compiled are rejected.
+func (a *IPAccessControl) Middleware() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := RouteFromContext(r.Context())
+			if route == nil || route.IPAccess == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			a.rulesMu.RLock()
+			compiled := a.rules[route.ID]
+			a.rulesMu.RUnlock()
+			if compiled == nil || compiled.err != nil {
+				writeError(w, http.StatusInternalServerError, "Invalid route access rules")
+				return
+			}
+
+			ip := net.ParseIP(clientIP(r))
+			if allowed, rule := compiled.rules.Check(ip); !allowed {
+				a.log.Warn("Request denied by access rules", "route", route.ID, "clientIP", ip.String(), "rule", rule)
+				a.mu.Lock()
+				a.denied[route.ID]++
+				a.mu.Unlock()
+				writeError(w, http.StatusForbidden, "Access denied")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Stats returns how many requests were denied on each route
+func (a *IPAccessControl) Stats() interface{} {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	denied := make(map[string]int64, len(a.denied))
+	for id, count := range a.denied {
+		denied[id] = count
+	}
+	return map[string]interface{}{"denied": denied}
+}
@@ -0,0 +1,59 @@
+package gateway
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/logger"
+)
+
+func TestIPAccessControlUsesCompiledRules(t *testing.T) {
+	restricted := &models.Route{ID: "rte-1", IPAccess: &models.IPAccessRules{Allow: []string{"10.0.0.0/8"}}}
+	invalid := &models.Route{ID: "rte-2", IPAccess: &models.IPAccessRules{Allow: []string{"not-an-address"}}}
+	open := &models.Route{ID: "rte-3"}
+	unsynced := &models.Route{ID: "rte-4", IPAccess: &models.IPAccessRules{Deny: []string{"10.0.0.1"}}}
+
+	access := NewIPAccessControl(logger.New("error"))
+	access.Update([]*models.Route{restricted, invalid, open})
+	handler := access.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name  string
+		route *models.Route
+		ip    string
+		want  int
+	}{
+		{"allowed", restricted, "10.1.2.3", http.StatusOK},
+		{"denied", restricted, "192.168.1.1", http.StatusForbidden},
+		{"invalid rules", invalid, "10.1.2.3", http.StatusInternalServerError},
+		{"no rules", open, "192.168.1.1", http.StatusOK},
+		{"rules not compiled", unsynced, "192.168.1.1", http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r = r.WithContext(WithClientIP(WithRoute(r.Context(), tt.route), net.ParseIP(tt.ip)))
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+
+	// Rules of a later sync replace the compiled ones
+	restricted = &models.Route{ID: "rte-1", IPAccess: &models.IPAccessRules{Allow: []string{"192.168.0.0/16"}}}
+	access.Update([]*models.Route{restricted})
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r = r.WithContext(WithClientIP(WithRoute(r.Context(), restricted), net.ParseIP("192.168.1.1")))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Errorf("status after update = %d, want 200", w.Code)
+	}
+}
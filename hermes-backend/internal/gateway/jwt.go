@@ -0,0 +1,262 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/security"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/logger"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// jwtLeeway tolerates clock skew between the gateway and token issuers
+const jwtLeeway = 30 * time.Second
+
+// jwtMethods are the signing algorithms accepted from issuers
+var jwtMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// JWTIssuer configures an issuer whose bearer tokens routes can require
+type JWTIssuer struct {
+	Name            string        // Name routes refer to the issuer by
+	Issuer          string        // Expected iss claim
+	JWKSURL         string        // Where the issuer publishes its keys
+	JWKSFile        string        // Local copy of the keys, used instead of JWKSURL
+	RefreshInterval time.Duration // How long keys are used before they are refetched
+}
+
+// jwtIssuer is a configured issuer with its cached keys
+type jwtIssuer struct {
+	name string
+	keys *security.JWKSCache
+}
+
+// jwtError is a rejected bearer token with the status to answer with
+type jwtError struct {
+	status int
+	code   string // OAuth 2.0 bearer token error code
+	reason string
+}
+
+func (e *jwtError) Error() string { return e.reason }
+
+// JWTValidator verifies the bearer tokens of routes that require one and
+// forwards verified claims upstream as headers
+type JWTValidator struct {
+	byName   map[string]*jwtIssuer
+	byIssuer map[string]*jwtIssuer
+	log      *logger.Logger
+}
+
+// NewJWTValidator creates a validator trusting the given issuers
+func NewJWTValidator(issuers []JWTIssuer, log *logger.Logger) (*JWTValidator, error) {
+	v := &JWTValidator{
+		byName:   make(map[string]*jwtIssuer, len(issuers)),
+		byIssuer: make(map[string]*jwtIssuer, len(issuers)),
+		log:      log,
+	}
+
+	for _, config := range issuers {
+		if config.Name == "" || config.Issuer == "" {
+			return nil, errors.New("JWT issuer requires a name and an issuer")
+		}
+		if _, ok := v.byName[config.Name]; ok {
+			return nil, fmt.Errorf("duplicate JWT issuer: %s", config.Name)
+		}
+
+		var keys *security.JWKSCache
+		switch {
+		case config.JWKSFile != "":
+			keys = security.NewJWKSFileCache(config.JWKSFile)
+		case config.JWKSURL != "":
+			keys = security.NewJWKSCache(config.JWKSURL, nil)
+		default:
+			return nil, fmt.Errorf("JWT issuer %s requires a JWKS URL or file", config.Name)
+		}
+		keys.SetRefreshInterval(config.RefreshInterval)
+
+		issuer := &jwtIssuer{name: config.Name, keys: keys}
+		v.byName[config.Name] = issuer
+		v.byIssuer[config.Issuer] = issuer
+	}
+	return v, nil
+}
+
+// HasIssuer reports whether an issuer with the given name is configured
+func (v *JWTValidator) HasIssuer(name string) bool {
+	_, ok := v.byName[name]
+	return ok
+}
+
+// HasIssuers reports whether any issuer is configured
+func (v *JWTValidator) HasIssuers() bool {
+	return len(v.byName) > 0
+}
+
+// Middleware returns a middleware rejecting requests to routes that require a
+// bearer token without a valid one
+func (v *JWTValidator) Middleware() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := RouteFromContext(r.Context())
+			if route == nil || route.JWT == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Forwarded claims may only come from a verified token
+			for header := range route.JWT.ForwardClaims {
+				r.Header.Del(header)
+			}
+
+			claims, err := v.verify(r.Context(), route.JWT, r.Header.Get("Authorization"))
+			if err != nil {
+				var jerr *jwtError
+				if !errors.As(err, &jerr) {
+					jerr = &jwtError{status: http.StatusUnauthorized, code: "invalid_token", reason: err.Error()}
+				}
+				v.log.Debug("Bearer token rejected", "route", route.ID, "reason", jerr.reason)
+
+				challenge := `Bearer error="` + jerr.code + `"`
+				if jerr.code == "insufficient_scope" {
+					challenge += `, scope="` + strings.Join(route.JWT.Scopes, " ") + `"`
+				}
+				w.Header().Set("WWW-Authenticate", challenge)
+				writeError(w, jerr.status, jerr.reason)
+				return
+			}
+
+			for header, claim := range route.JWT.ForwardClaims {
+				if value, ok := claims[claim]; ok {
+					r.Header.Set(header, claimString(value))
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// verify checks a bearer token against a route's requirements and returns its claims
+func (v *JWTValidator) verify(ctx context.Context, req *models.JWTRequirement, authHeader string) (jwt.MapClaims, error) {
+	tokenStr, ok := strings.CutPrefix(authHeader, "Bearer ")
+	if !ok || tokenStr == "" {
+		return nil, &jwtError{status: http.StatusUnauthorized, code: "invalid_request", reason: "Bearer token required"}
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
+		iss, _ := claims["iss"].(string)
+		issuer, ok := v.byIssuer[iss]
+		if !ok || !accepts(req.Issuers, issuer.name) {
+			return nil, fmt.Errorf("untrusted issuer: %s", iss)
+		}
+		kid, _ := token.Header["kid"].(string)
+		return issuer.keys.Key(ctx, kid)
+	},
+		jwt.WithValidMethods(jwtMethods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(jwtLeeway),
+	)
+	if err != nil {
+		return nil, &jwtError{status: http.StatusUnauthorized, code: "invalid_token", reason: "Invalid bearer token"}
+	}
+
+	if len(req.Audiences) > 0 {
+		audiences, _ := claims.GetAudience()
+		if !anyOf(audiences, req.Audiences) {
+			return nil, &jwtError{status: http.StatusUnauthorized, code: "invalid_token", reason: "Bearer token has the wrong audience"}
+		}
+	}
+
+	for name, want := range req.RequiredClaims {
+		value, ok := claims[name]
+		if !ok || (want != "" && !claimMatches(value, want)) {
+			return nil, &jwtError{status: http.StatusForbidden, code: "insufficient_scope", reason: "Bearer token lacks required claim: " + name}
+		}
+	}
+
+	granted := tokenScopes(claims)
+	for _, scope := range req.Scopes {
+		if !anyOf(granted, []string{scope}) {
+			return nil, &jwtError{status: http.StatusForbidden, code: "insufficient_scope", reason: "Bearer token lacks scope: " + scope}
+		}
+	}
+
+	return claims, nil
+}
+
+// accepts reports whether a route accepting the named issuers accepts name
+func accepts(names []string, name string) bool {
+	return len(names) == 0 || anyOf(names, []string{name})
+}
+
+// anyOf reports whether values contains any of wanted
+func anyOf(values, wanted []string) bool {
+	for _, v := range values {
+		for _, w := range wanted {
+			if v == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// tokenScopes returns the scopes of a token, from the space separated scope
+// claim or the scp claim some issuers use instead
+func tokenScopes(claims jwt.MapClaims) []string {
+	if scope, ok := claims["scope"].(string); ok {
+		return strings.Fields(scope)
+	}
+	switch scp := claims["scp"].(type) {
+	case string:
+		return strings.Fields(scp)
+	case []interface{}:
+		scopes := make([]string, 0, len(scp))
+		for _, s := range scp {
+			scopes = append(scopes, claimString(s))
+		}
+		return scopes
+	}
+	return nil
+}
+
+// claimMatches reports whether a claim equals want, or contains it if it is a list
+func claimMatches(value interface{}, want string) bool {
+	if list, ok := value.([]interface{}); ok {
+		for _, item := range list {
+			if claimString(item) == want {
+				return true
+			}
+		}
+		return false
+	}
+	return claimString(value) == want
+}
+
+// claimString formats a claim for comparison and for an upstream header
+func claimString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = claimString(item)
+		}
+		return strings.Join(items, ",")
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
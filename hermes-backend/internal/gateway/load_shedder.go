@@ -0,0 +1,217 @@
+package gateway
+
+import (
+	"math"
+	"net/http"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/logger"
+)
+
+// ShedReasonOverload is reported when the load shedder drops a request
+const ShedReasonOverload = "overload"
+
+// CriticalityHeader reports the criticality class assigned to a shed request
+const CriticalityHeader = "X-Hermes-Criticality"
+
+// defaultSheddableRatio is the load level at which sheddable traffic is dropped
+const defaultSheddableRatio = 0.8
+
+// cpuSampleInterval is how often the load shedder measures CPU usage
+const cpuSampleInterval = time.Second
+
+// LoadShedderConfig configures when the gateway considers itself overloaded.
+// A zero threshold disables that signal.
+type LoadShedderConfig struct {
+	MaxInFlight    int     // In-flight gateway requests
+	MaxQueueDepth  int     // Requests waiting in bulkhead queues
+	MaxCPUPercent  float64 // Process CPU usage across all cores
+	SheddableRatio float64 // Load level at which SHEDDABLE requests are shed
+}
+
+// LoadShedder drops lower-criticality requests first when the gateway is overloaded.
+// Its middleware runs after authentication, so that the consumer's class is known.
+// The load level is the highest ratio of a signal to its threshold: SHEDDABLE
+// requests are shed from SheddableRatio, DEFAULT requests from 1.0, and
+// CRITICAL requests are never shed.
+type LoadShedder struct {
+	config     LoadShedderConfig
+	queueDepth func() int64
+	log        *logger.Logger
+
+	inFlight   int64
+	cpuPercent uint64 // math.Float64bits of the last CPU sample
+
+	shedDefault   uint64
+	shedSheddable uint64
+
+	stopCh chan struct{}
+}
+
+// LoadShedderStats reports the current load and shed counters
+type LoadShedderStats struct {
+	Level         float64 `json:"level"`
+	InFlight      int64   `json:"in_flight"`
+	QueueDepth    int64   `json:"queue_depth"`
+	CPUPercent    float64 `json:"cpu_percent"`
+	ShedDefault   uint64  `json:"shed_default"`
+	ShedSheddable uint64  `json:"shed_sheddable"`
+}
+
+// NewLoadShedder creates a load shedder. queueDepth reports the number of
+// requests currently queued, for example by Bulkheads.QueueDepth.
+func NewLoadShedder(config LoadShedderConfig, queueDepth func() int64, log *logger.Logger) *LoadShedder {
+	if config.SheddableRatio <= 0 || config.SheddableRatio > 1 {
+		config.SheddableRatio = defaultSheddableRatio
+	}
+	return &LoadShedder{
+		config:     config,
+		queueDepth: queueDepth,
+		log:        log,
+		stopCh:     make(chan struct{}),
+	}
+}
+
+// Start samples CPU usage until Stop is called
+func (s *LoadShedder) Start() {
+	if s.config.MaxCPUPercent <= 0 {
+		return
+	}
+
+	lastCPU, ok := processCPUTime()
+	if !ok {
+		s.log.Warn("CPU based load shedding is not supported on this platform")
+		return
+	}
+	lastWall := time.Now()
+
+	ticker := time.NewTicker(cpuSampleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			cpu, _ := processCPUTime()
+			now := time.Now()
+			capacity := float64(now.Sub(lastWall)) * float64(runtime.NumCPU())
+			if capacity > 0 {
+				percent := float64(cpu-lastCPU) / capacity * 100
+				atomic.StoreUint64(&s.cpuPercent, math.Float64bits(percent))
+			}
+			lastCPU, lastWall = cpu, now
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+// Stop stops CPU sampling
+func (s *LoadShedder) Stop() {
+	close(s.stopCh)
+}
+
+// Level returns the current load level, where 1.0 means a threshold is reached
+func (s *LoadShedder) Level() float64 {
+	level := 0.0
+	if s.config.MaxInFlight > 0 {
+		level = math.Max(level, float64(atomic.LoadInt64(&s.inFlight))/float64(s.config.MaxInFlight))
+	}
+	if s.config.MaxQueueDepth > 0 && s.queueDepth != nil {
+		level = math.Max(level, float64(s.queueDepth())/float64(s.config.MaxQueueDepth))
+	}
+	if s.config.MaxCPUPercent > 0 {
+		level = math.Max(level, s.cpu()/s.config.MaxCPUPercent)
+	}
+	return level
+}
+
+func (s *LoadShedder) cpu() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&s.cpuPercent))
+}
+
+// shouldShed reports whether a request of the given criticality is shed at level
+func (s *LoadShedder) shouldShed(criticality models.Criticality, level float64) bool {
+	switch criticality {
+	case models.CriticalityCritical:
+		return false
+	case models.CriticalitySheddable:
+		return level >= s.config.SheddableRatio
+	default:
+		return level >= 1
+	}
+}
+
+// Middleware returns a middleware shedding requests by criticality under overload
+func (s *LoadShedder) Middleware() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			criticality := RequestCriticality(r)
+
+			if level := s.Level(); s.shouldShed(criticality, level) {
+				if criticality == models.CriticalitySheddable {
+					atomic.AddUint64(&s.shedSheddable, 1)
+				} else {
+					atomic.AddUint64(&s.shedDefault, 1)
+				}
+				s.log.Warn("Request shed under overload", "criticality", criticality, "level", level, "path", r.URL.Path)
+				w.Header().Set(ShedReasonHeader, ShedReasonOverload)
+				w.Header().Set(CriticalityHeader, string(criticality))
+				writeError(w, http.StatusServiceUnavailable, "Gateway is overloaded")
+				return
+			}
+
+			atomic.AddInt64(&s.inFlight, 1)
+			defer atomic.AddInt64(&s.inFlight, -1)
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Stats returns the current load and shed counters
+func (s *LoadShedder) Stats() interface{} {
+	var queueDepth int64
+	if s.queueDepth != nil {
+		queueDepth = s.queueDepth()
+	}
+	return LoadShedderStats{
+		Level:         s.Level(),
+		InFlight:      atomic.LoadInt64(&s.inFlight),
+		QueueDepth:    queueDepth,
+		CPUPercent:    s.cpu(),
+		ShedDefault:   atomic.LoadUint64(&s.shedDefault),
+		ShedSheddable: atomic.LoadUint64(&s.shedSheddable),
+	}
+}
+
+// RequestCriticality returns the criticality class of a request: the first
+// matching header rule of its route, else the class of its consumer, else the
+// route's class, else DEFAULT
+func RequestCriticality(r *http.Request) models.Criticality {
+	route := RouteFromContext(r.Context())
+	if route == nil {
+		return models.CriticalityDefault
+	}
+
+	for _, rule := range route.CriticalityRules {
+		value := r.Header.Get(rule.Header)
+		if value == "" {
+			continue
+		}
+		if rule.Value == "" || strings.EqualFold(rule.Value, value) {
+			return rule.Criticality
+		}
+	}
+
+	if consumer := ConsumerFromContext(r.Context()); consumer != nil && consumer.Criticality.Valid() {
+		return consumer.Criticality
+	}
+	if route.Criticality.Valid() {
+		return route.Criticality
+	}
+	return models.CriticalityDefault
+}
@@ -0,0 +1,93 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/logger"
+)
+
+func TestRequestCriticality(t *testing.T) {
+	route := &models.Route{
+		Criticality: models.CriticalityDefault,
+		CriticalityRules: []models.CriticalityRule{
+			{Header: "X-Checkout", Criticality: models.CriticalityCritical},
+			{Header: "X-Job", Value: "batch", Criticality: models.CriticalitySheddable},
+		},
+	}
+	crawler := &models.Consumer{Name: "crawler", Criticality: models.CriticalitySheddable}
+	tests := []struct {
+		name     string
+		route    *models.Route
+		consumer *models.Consumer
+		headers  map[string]string
+		want     models.Criticality
+	}{
+		{"no route", nil, nil, nil, models.CriticalityDefault},
+		{"route class", &models.Route{Criticality: models.CriticalityCritical}, nil, nil, models.CriticalityCritical},
+		{"invalid route class", &models.Route{}, nil, nil, models.CriticalityDefault},
+		{"header rule with any value", route, nil, map[string]string{"X-Checkout": "1"}, models.CriticalityCritical},
+		{"header rule with value", route, nil, map[string]string{"X-Job": "BATCH"}, models.CriticalitySheddable},
+		{"header rule value mismatch", route, nil, map[string]string{"X-Job": "nightly"}, models.CriticalityDefault},
+		{"consumer class", route, crawler, nil, models.CriticalitySheddable},
+		{"header rule before consumer", route, crawler, map[string]string{"X-Checkout": "1"}, models.CriticalityCritical},
+		{"consumer without class", route, &models.Consumer{Name: "app"}, nil, models.CriticalityDefault},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			for name, value := range tt.headers {
+				r.Header.Set(name, value)
+			}
+			ctx := r.Context()
+			if tt.route != nil {
+				ctx = WithRoute(ctx, tt.route)
+			}
+			if tt.consumer != nil {
+				ctx = WithConsumer(ctx, tt.consumer)
+			}
+			if got := RequestCriticality(r.WithContext(ctx)); got != tt.want {
+				t.Errorf("RequestCriticality = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadShedderShedsByCriticality(t *testing.T) {
+	tests := []struct {
+		inFlight    int64
+		criticality models.Criticality
+		shed        bool
+	}{
+		{0, models.CriticalitySheddable, false},
+		{7, models.CriticalitySheddable, false},
+		{8, models.CriticalitySheddable, true},
+		{8, models.CriticalityDefault, false},
+		{10, models.CriticalityDefault, true},
+		{100, models.CriticalityCritical, false},
+	}
+	for _, tt := range tests {
+		shedder := NewLoadShedder(LoadShedderConfig{MaxInFlight: 10}, nil, logger.New("error"))
+		shedder.inFlight = tt.inFlight
+		route := &models.Route{Criticality: models.CriticalityDefault}
+		consumer := &models.Consumer{Name: "client", Criticality: tt.criticality}
+
+		handler := shedder.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r = r.WithContext(WithConsumer(WithRoute(r.Context(), route), consumer))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		shed := w.Code == http.StatusServiceUnavailable
+		if shed != tt.shed {
+			t.Errorf("%s at %d in flight: shed = %v, want %v", tt.criticality, tt.inFlight, shed, tt.shed)
+		}
+		if shed && (w.Header().Get(ShedReasonHeader) != ShedReasonOverload || w.Header().Get(CriticalityHeader) != string(tt.criticality)) {
+			t.Errorf("shed headers = %v", w.Header())
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package gateway
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/security"
+)
+
+// MTLSTransport proxies requests to HTTPS upstreams over mutual TLS.
+// The gateway presents its own workload certificate and requires the upstream
+// to present the certificate of the service the route points at. Requests to
+// plain HTTP targets and to routes without a service use the base transport.
+type MTLSTransport struct {
+	identity *security.CertificateRotator
+	base     *http.Transport
+
+	transports map[string]*http.Transport
+	mu         sync.Mutex
+}
+
+// NewMTLSTransport creates a transport using identity for upstream connections
+func NewMTLSTransport(identity *security.CertificateRotator) *MTLSTransport {
+	return &MTLSTransport{
+		identity:   identity,
+		base:       http.DefaultTransport.(*http.Transport).Clone(),
+		transports: make(map[string]*http.Transport),
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *MTLSTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	service := ServiceFromContext(req.Context())
+	if service == nil || req.URL.Scheme != "https" {
+		return t.base.RoundTrip(req)
+	}
+	return t.transportFor(service.ID).RoundTrip(req)
+}
+
+// transportFor returns the transport verifying the identity of a service.
+// Connections are pooled per service so they are never shared across identities.
+func (t *MTLSTransport) transportFor(serviceID string) *http.Transport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	transport, ok := t.transports[serviceID]
+	if !ok {
+		transport = t.base.Clone()
+		transport.TLSClientConfig = t.identity.ClientTLSConfig(serviceID)
+		t.transports[serviceID] = transport
+	}
+	return transport
+}
+
+// UpdateServices closes the idle connections of services that are no longer routed to
+func (t *MTLSTransport) UpdateServices(serviceIDs []string) {
+	known := make(map[string]bool, len(serviceIDs))
+	for _, id := range serviceIDs {
+		known[id] = true
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for id, transport := range t.transports {
+		if !known[id] {
+			transport.CloseIdleConnections()
+			delete(t.transports, id)
+		}
+	}
+}
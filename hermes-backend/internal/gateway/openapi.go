@@ -0,0 +1,330 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// OpenAPISpec is a parsed OpenAPI 3 document requests can be validated against
+type OpenAPISpec struct {
+	Title   string
+	Version string
+
+	document   json.RawMessage
+	basePath   string
+	operations []*specOperation
+	components openAPIComponents
+	patterns   map[string]*regexp.Regexp
+}
+
+// specOperation is an operation of the document with its path compiled
+type specOperation struct {
+	method   string
+	template string
+	path     *regexp.Regexp
+	names    []string // names of the path parameters, in order
+	literal  int      // length of the template outside parameters, for precedence
+	params   []*openAPIParameter
+	body     *openAPIRequestBody
+}
+
+type openAPIDocument struct {
+	OpenAPI string `json:"openapi"`
+	Info    struct {
+		Title   string `json:"title"`
+		Version string `json:"version"`
+	} `json:"info"`
+	Servers []struct {
+		URL string `json:"url"`
+	} `json:"servers"`
+	Paths      map[string]*openAPIPathItem `json:"paths"`
+	Components openAPIComponents           `json:"components"`
+}
+
+type openAPIComponents struct {
+	Schemas       map[string]*schema             `json:"schemas"`
+	Parameters    map[string]*openAPIParameter   `json:"parameters"`
+	RequestBodies map[string]*openAPIRequestBody `json:"requestBodies"`
+}
+
+type openAPIPathItem struct {
+	Parameters []*openAPIParameter `json:"parameters"`
+	Get        *openAPIOperation   `json:"get"`
+	Put        *openAPIOperation   `json:"put"`
+	Post       *openAPIOperation   `json:"post"`
+	Delete     *openAPIOperation   `json:"delete"`
+	Options    *openAPIOperation   `json:"options"`
+	Head       *openAPIOperation   `json:"head"`
+	Patch      *openAPIOperation   `json:"patch"`
+	Trace      *openAPIOperation   `json:"trace"`
+}
+
+type openAPIOperation struct {
+	Parameters  []*openAPIParameter `json:"parameters"`
+	RequestBody *openAPIRequestBody `json:"requestBody"`
+}
+
+type openAPIParameter struct {
+	Ref      string  `json:"$ref"`
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Explode  *bool   `json:"explode"`
+	Schema   *schema `json:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Ref      string                       `json:"$ref"`
+	Required bool                         `json:"required"`
+	Content  map[string]*openAPIMediaType `json:"content"`
+}
+
+type openAPIMediaType struct {
+	Schema *schema `json:"schema"`
+}
+
+// pathParamPattern finds the parameters of a path template
+var pathParamPattern = regexp.MustCompile(`\{([^{}/]+)\}`)
+
+// ParseOpenAPI parses an OpenAPI 3 document written in JSON or YAML
+func ParseOpenAPI(data []byte) (*OpenAPISpec, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, errors.New("empty OpenAPI document")
+	}
+	if data[0] != '{' {
+		var doc interface{}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("invalid OpenAPI document: %w", err)
+		}
+		converted, err := json.Marshal(jsonCompatible(doc))
+		if err != nil {
+			return nil, fmt.Errorf("invalid OpenAPI document: %w", err)
+		}
+		data = converted
+	}
+
+	var doc openAPIDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document: %w", err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, errors.New("only OpenAPI 3 documents are supported")
+	}
+
+	spec := &OpenAPISpec{
+		Title:      doc.Info.Title,
+		Version:    doc.Info.Version,
+		document:   json.RawMessage(data),
+		components: doc.Components,
+		patterns:   make(map[string]*regexp.Regexp),
+	}
+	if len(doc.Servers) > 0 {
+		if u, err := url.Parse(doc.Servers[0].URL); err == nil {
+			spec.basePath = strings.TrimSuffix(u.Path, "/")
+		}
+	}
+
+	for template, item := range doc.Paths {
+		if item == nil {
+			continue
+		}
+		if !strings.HasPrefix(template, "/") {
+			return nil, fmt.Errorf("path %s must start with /", template)
+		}
+		for method, op := range map[string]*openAPIOperation{
+			"GET": item.Get, "PUT": item.Put, "POST": item.Post, "DELETE": item.Delete,
+			"OPTIONS": item.Options, "HEAD": item.Head, "PATCH": item.Patch, "TRACE": item.Trace,
+		} {
+			if op == nil {
+				continue
+			}
+			operation, err := spec.compileOperation(method, template, item.Parameters, op)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", method, template, err)
+			}
+			spec.operations = append(spec.operations, operation)
+		}
+	}
+
+	// Concrete paths take precedence over templated ones
+	sort.SliceStable(spec.operations, func(i, j int) bool {
+		a, b := spec.operations[i], spec.operations[j]
+		if len(a.names) != len(b.names) {
+			return len(a.names) < len(b.names)
+		}
+		return a.literal > b.literal
+	})
+
+	for name, s := range doc.Components.Schemas {
+		if err := spec.checkSchema(s, map[*schema]bool{}); err != nil {
+			return nil, fmt.Errorf("schema %s: %w", name, err)
+		}
+	}
+	return spec, nil
+}
+
+// Document returns the document as JSON
+func (s *OpenAPISpec) Document() json.RawMessage {
+	return s.document
+}
+
+// compileOperation resolves the parameters and request body of an operation
+// and compiles its path template
+func (s *OpenAPISpec) compileOperation(method, template string, shared []*openAPIParameter, op *openAPIOperation) (*specOperation, error) {
+	operation := &specOperation{method: method, template: template}
+
+	pattern := "^"
+	last := 0
+	for _, match := range pathParamPattern.FindAllStringSubmatchIndex(template, -1) {
+		pattern += regexp.QuoteMeta(template[last:match[0]]) + "([^/]+)"
+		operation.literal += match[0] - last
+		operation.names = append(operation.names, template[match[2]:match[3]])
+		last = match[1]
+	}
+	pattern += regexp.QuoteMeta(template[last:]) + "$"
+	operation.literal += len(template) - last
+	operation.path = regexp.MustCompile(pattern)
+
+	// Operation parameters override path item parameters of the same name and location
+	byKey := make(map[string]*openAPIParameter)
+	var order []string
+	for _, list := range [][]*openAPIParameter{shared, op.Parameters} {
+		for _, param := range list {
+			resolved, err := s.resolveParameter(param)
+			if err != nil {
+				return nil, err
+			}
+			if resolved.Name == "" || resolved.In == "" {
+				return nil, errors.New("parameters require a name and a location")
+			}
+			if err := s.checkSchema(resolved.Schema, map[*schema]bool{}); err != nil {
+				return nil, fmt.Errorf("parameter %s: %w", resolved.Name, err)
+			}
+			key := resolved.In + ":" + strings.ToLower(resolved.Name)
+			if _, ok := byKey[key]; !ok {
+				order = append(order, key)
+			}
+			byKey[key] = resolved
+		}
+	}
+	for _, key := range order {
+		operation.params = append(operation.params, byKey[key])
+	}
+
+	if op.RequestBody != nil {
+		body, err := s.resolveRequestBody(op.RequestBody)
+		if err != nil {
+			return nil, err
+		}
+		for mediaType, content := range body.Content {
+			if content == nil {
+				continue
+			}
+			if err := s.checkSchema(content.Schema, map[*schema]bool{}); err != nil {
+				return nil, fmt.Errorf("request body %s: %w", mediaType, err)
+			}
+		}
+		operation.body = body
+	}
+	return operation, nil
+}
+
+// match finds the operation serving a request. When the path is known but
+// the method is not, the returned operation is nil and known is true.
+func (s *OpenAPISpec) match(method, path string) (op *specOperation, values map[string]string, known bool) {
+	if s.basePath != "" {
+		trimmed, ok := strings.CutPrefix(path, s.basePath)
+		if !ok {
+			return nil, nil, false
+		}
+		path = trimmed
+		if path == "" {
+			path = "/"
+		}
+	}
+
+	for _, candidate := range s.operations {
+		groups := candidate.path.FindStringSubmatch(path)
+		if groups == nil {
+			continue
+		}
+		known = true
+		if candidate.method != method {
+			continue
+		}
+		values = make(map[string]string, len(candidate.names))
+		for i, name := range candidate.names {
+			value, err := url.PathUnescape(groups[i+1])
+			if err != nil {
+				value = groups[i+1]
+			}
+			values[name] = value
+		}
+		return candidate, values, true
+	}
+	return nil, nil, known
+}
+
+// resolveParameter follows a parameter reference into the components
+func (s *OpenAPISpec) resolveParameter(param *openAPIParameter) (*openAPIParameter, error) {
+	for depth := 0; param != nil && param.Ref != ""; depth++ {
+		name, ok := strings.CutPrefix(param.Ref, "#/components/parameters/")
+		if !ok || depth > maxRefDepth {
+			return nil, fmt.Errorf("unsupported parameter reference: %s", param.Ref)
+		}
+		param = s.components.Parameters[name]
+	}
+	if param == nil {
+		return nil, errors.New("parameter reference to a missing parameter")
+	}
+	return param, nil
+}
+
+// resolveRequestBody follows a request body reference into the components
+func (s *OpenAPISpec) resolveRequestBody(body *openAPIRequestBody) (*openAPIRequestBody, error) {
+	for depth := 0; body != nil && body.Ref != ""; depth++ {
+		name, ok := strings.CutPrefix(body.Ref, "#/components/requestBodies/")
+		if !ok || depth > maxRefDepth {
+			return nil, fmt.Errorf("unsupported request body reference: %s", body.Ref)
+		}
+		body = s.components.RequestBodies[name]
+	}
+	if body == nil {
+		return nil, errors.New("request body reference to a missing request body")
+	}
+	return body, nil
+}
+
+// jsonCompatible converts a decoded YAML document into values encoding/json
+// accepts, turning keys such as response codes into strings
+func jsonCompatible(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for key, item := range value {
+			value[key] = jsonCompatible(item)
+		}
+		return value
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			converted[fmt.Sprint(key)] = jsonCompatible(item)
+		}
+		return converted
+	case []interface{}:
+		for i, item := range value {
+			value[i] = jsonCompatible(item)
+		}
+		return value
+	default:
+		return v
+	}
+}
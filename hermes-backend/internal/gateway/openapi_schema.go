@@ -0,0 +1,391 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// maxRefDepth bounds reference chains and the nesting of validated values
+const maxRefDepth = 64
+
+// schema is the subset of an OpenAPI schema object the gateway enforces
+type schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 json.RawMessage    `json:"type"` // a name, or a list of names in OpenAPI 3.1
+	Nullable             bool               `json:"nullable"`
+	Format               string             `json:"format"`
+	Enum                 []interface{}      `json:"enum"`
+	Properties           map[string]*schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties json.RawMessage    `json:"additionalProperties"` // a boolean or a schema
+	MinProperties        *int               `json:"minProperties"`
+	MaxProperties        *int               `json:"maxProperties"`
+	Items                *schema            `json:"items"`
+	MinItems             *int               `json:"minItems"`
+	MaxItems             *int               `json:"maxItems"`
+	MinLength            *int               `json:"minLength"`
+	MaxLength            *int               `json:"maxLength"`
+	Pattern              string             `json:"pattern"`
+	Minimum              *float64           `json:"minimum"`
+	Maximum              *float64           `json:"maximum"`
+	ExclusiveMinimum     json.RawMessage    `json:"exclusiveMinimum"` // a boolean in OpenAPI 3.0, a number in 3.1
+	ExclusiveMaximum     json.RawMessage    `json:"exclusiveMaximum"`
+	MultipleOf           *float64           `json:"multipleOf"`
+	AllOf                []*schema          `json:"allOf"`
+	AnyOf                []*schema          `json:"anyOf"`
+	OneOf                []*schema          `json:"oneOf"`
+	Not                  *schema            `json:"not"`
+	ReadOnly             bool               `json:"readOnly"`
+
+	additional *schema // parsed AdditionalProperties when it is a schema
+	closed     bool    // AdditionalProperties is false
+}
+
+// Violation describes how a request breaks its API specification
+type Violation struct {
+	Location string `json:"location"`       // path, query, header, body, method or content-type
+	Name     string `json:"name,omitempty"` // parameter name or JSON pointer into the body
+	Message  string `json:"message"`
+}
+
+// types returns the types a schema allows, none meaning any
+func (s *schema) types() []string {
+	if len(s.Type) == 0 {
+		return nil
+	}
+	var name string
+	if json.Unmarshal(s.Type, &name) == nil {
+		if s.Nullable {
+			return []string{name, "null"}
+		}
+		return []string{name}
+	}
+	var names []string
+	json.Unmarshal(s.Type, &names)
+	return names
+}
+
+// checkSchema verifies that the references of a schema resolve and its
+// patterns compile, and prepares it for validation
+func (s *OpenAPISpec) checkSchema(sc *schema, seen map[*schema]bool) error {
+	if sc == nil || seen[sc] {
+		return nil
+	}
+	seen[sc] = true
+
+	if sc.Ref != "" {
+		resolved, err := s.resolveSchema(sc)
+		if err != nil {
+			return err
+		}
+		return s.checkSchema(resolved, seen)
+	}
+	if sc.Pattern != "" {
+		if _, ok := s.patterns[sc.Pattern]; !ok {
+			re, err := regexp.Compile(sc.Pattern)
+			if err != nil {
+				return fmt.Errorf("invalid pattern %q", sc.Pattern)
+			}
+			s.patterns[sc.Pattern] = re
+		}
+	}
+	if len(sc.AdditionalProperties) > 0 {
+		var allowed bool
+		if json.Unmarshal(sc.AdditionalProperties, &allowed) == nil {
+			sc.closed = !allowed
+		} else if err := json.Unmarshal(sc.AdditionalProperties, &sc.additional); err != nil {
+			return fmt.Errorf("invalid additionalProperties: %w", err)
+		}
+	}
+
+	children := []*schema{sc.Items, sc.Not, sc.additional}
+	children = append(children, sc.AllOf...)
+	children = append(children, sc.AnyOf...)
+	children = append(children, sc.OneOf...)
+	for _, property := range sc.Properties {
+		children = append(children, property)
+	}
+	for _, child := range children {
+		if err := s.checkSchema(child, seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveSchema follows a schema reference into the components
+func (s *OpenAPISpec) resolveSchema(sc *schema) (*schema, error) {
+	for depth := 0; sc != nil && sc.Ref != ""; depth++ {
+		name, ok := strings.CutPrefix(sc.Ref, "#/components/schemas/")
+		if !ok || depth > maxRefDepth {
+			return nil, fmt.Errorf("unsupported schema reference: %s", sc.Ref)
+		}
+		next, ok := s.components.Schemas[name]
+		if !ok || next == nil {
+			return nil, fmt.Errorf("reference to a missing schema: %s", sc.Ref)
+		}
+		sc = next
+	}
+	return sc, nil
+}
+
+// validateValue checks a decoded JSON value against a schema and appends
+// what is wrong with it to violations
+func (s *OpenAPISpec) validateValue(sc *schema, value interface{}, location, pointer string, depth int, violations *[]Violation) {
+	if sc == nil {
+		return
+	}
+	if depth > maxRefDepth {
+		*violations = append(*violations, Violation{Location: location, Name: pointer, Message: "value is nested too deeply"})
+		return
+	}
+	sc, err := s.resolveSchema(sc)
+	if err != nil {
+		*violations = append(*violations, Violation{Location: location, Name: pointer, Message: err.Error()})
+		return
+	}
+	fail := func(format string, args ...interface{}) {
+		*violations = append(*violations, Violation{Location: location, Name: pointer, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if types := sc.types(); len(types) > 0 {
+		kind := jsonKind(value)
+		matched := false
+		for _, t := range types {
+			if t == kind || (t == "number" && kind == "integer") {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			fail("expected %s, got %s", strings.Join(types, " or "), kind)
+			return
+		}
+	} else if value == nil && !sc.Nullable && len(sc.AllOf)+len(sc.AnyOf)+len(sc.OneOf) == 0 && sc.Not == nil {
+		// An untyped schema accepts null
+		return
+	}
+	if value == nil && sc.Nullable {
+		// Null is allowed by a nullable schema even when it has an enum
+		return
+	}
+
+	if len(sc.Enum) > 0 {
+		found := false
+		for _, allowed := range sc.Enum {
+			if jsonEqual(allowed, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("value is not one of the allowed values")
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		length := utf8.RuneCountInString(v)
+		if sc.MinLength != nil && length < *sc.MinLength {
+			fail("must be at least %d characters long", *sc.MinLength)
+		}
+		if sc.MaxLength != nil && length > *sc.MaxLength {
+			fail("must be at most %d characters long", *sc.MaxLength)
+		}
+		if sc.Pattern != "" {
+			if re := s.patterns[sc.Pattern]; re != nil && !re.MatchString(v) {
+				fail("must match pattern %s", sc.Pattern)
+			}
+		}
+		if !validFormat(sc.Format, v) {
+			fail("must be a valid %s", sc.Format)
+		}
+	case float64:
+		if sc.Minimum != nil && (v < *sc.Minimum || (v == *sc.Minimum && exclusiveFlag(sc.ExclusiveMinimum))) {
+			fail("must be at least %v", *sc.Minimum)
+		}
+		if sc.Maximum != nil && (v > *sc.Maximum || (v == *sc.Maximum && exclusiveFlag(sc.ExclusiveMaximum))) {
+			fail("must be at most %v", *sc.Maximum)
+		}
+		if bound, ok := exclusiveBound(sc.ExclusiveMinimum); ok && v <= bound {
+			fail("must be greater than %v", bound)
+		}
+		if bound, ok := exclusiveBound(sc.ExclusiveMaximum); ok && v >= bound {
+			fail("must be less than %v", bound)
+		}
+		if sc.MultipleOf != nil && *sc.MultipleOf > 0 {
+			if q := v / *sc.MultipleOf; math.Abs(q-math.Round(q)) > 1e-9 {
+				fail("must be a multiple of %v", *sc.MultipleOf)
+			}
+		}
+	case []interface{}:
+		if sc.MinItems != nil && len(v) < *sc.MinItems {
+			fail("must have at least %d items", *sc.MinItems)
+		}
+		if sc.MaxItems != nil && len(v) > *sc.MaxItems {
+			fail("must have at most %d items", *sc.MaxItems)
+		}
+		for i, item := range v {
+			s.validateValue(sc.Items, item, location, pointer+"/"+strconv.Itoa(i), depth+1, violations)
+		}
+	case map[string]interface{}:
+		if sc.MinProperties != nil && len(v) < *sc.MinProperties {
+			fail("must have at least %d properties", *sc.MinProperties)
+		}
+		if sc.MaxProperties != nil && len(v) > *sc.MaxProperties {
+			fail("must have at most %d properties", *sc.MaxProperties)
+		}
+		for _, name := range sc.Required {
+			if _, ok := v[name]; ok {
+				continue
+			}
+			// Read-only properties are only sent in responses
+			if property, err := s.resolveSchema(sc.Properties[name]); err == nil && property != nil && property.ReadOnly {
+				continue
+			}
+			*violations = append(*violations, Violation{Location: location, Name: pointer + "/" + escapePointer(name), Message: "is required"})
+		}
+		for name, item := range v {
+			child := pointer + "/" + escapePointer(name)
+			if property, ok := sc.Properties[name]; ok {
+				s.validateValue(property, item, location, child, depth+1, violations)
+			} else if sc.additional != nil {
+				s.validateValue(sc.additional, item, location, child, depth+1, violations)
+			} else if sc.closed {
+				*violations = append(*violations, Violation{Location: location, Name: child, Message: "is not an allowed property"})
+			}
+		}
+	}
+
+	for _, sub := range sc.AllOf {
+		s.validateValue(sub, value, location, pointer, depth+1, violations)
+	}
+	if len(sc.AnyOf) > 0 && s.countMatches(sc.AnyOf, value, depth) == 0 {
+		fail("must match at least one of the allowed schemas")
+	}
+	if len(sc.OneOf) > 0 && s.countMatches(sc.OneOf, value, depth) != 1 {
+		fail("must match exactly one of the allowed schemas")
+	}
+	if sc.Not != nil && s.countMatches([]*schema{sc.Not}, value, depth) == 1 {
+		fail("must not match the excluded schema")
+	}
+}
+
+// countMatches counts the schemas value is valid against
+func (s *OpenAPISpec) countMatches(schemas []*schema, value interface{}, depth int) int {
+	matches := 0
+	for _, sub := range schemas {
+		var violations []Violation
+		s.validateValue(sub, value, "", "", depth+1, &violations)
+		if len(violations) == 0 {
+			matches++
+		}
+	}
+	return matches
+}
+
+// jsonKind names the JSON type of a decoded value
+func jsonKind(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) && !math.IsInf(v, 0) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return "unknown"
+	}
+}
+
+// jsonEqual compares two decoded JSON values
+func jsonEqual(a, b interface{}) bool {
+	if fa, ok := toFloat(a); ok {
+		fb, ok := toFloat(b)
+		return ok && fa == fb
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// toFloat converts the numbers YAML and JSON decoding produce to float64
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// exclusiveFlag reads the OpenAPI 3.0 boolean form of an exclusive bound
+func exclusiveFlag(raw json.RawMessage) bool {
+	var flag bool
+	return len(raw) > 0 && json.Unmarshal(raw, &flag) == nil && flag
+}
+
+// exclusiveBound reads the OpenAPI 3.1 numeric form of an exclusive bound
+func exclusiveBound(raw json.RawMessage) (float64, bool) {
+	var bound float64
+	if len(raw) == 0 || json.Unmarshal(raw, &bound) != nil {
+		return 0, false
+	}
+	return bound, true
+}
+
+// escapePointer escapes a property name for a JSON pointer
+func escapePointer(name string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(name, "~", "~0"), "/", "~1")
+}
+
+// validFormat checks the string formats the gateway knows, others always pass
+func validFormat(format, value string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, value)
+		return err == nil
+	case "date":
+		_, err := time.Parse("2006-01-02", value)
+		return err == nil
+	case "email":
+		_, err := mail.ParseAddress(value)
+		return err == nil
+	case "uuid":
+		_, err := uuid.Parse(value)
+		return err == nil && len(value) == 36
+	case "ipv4":
+		ip := net.ParseIP(value)
+		return ip != nil && ip.To4() != nil && !strings.Contains(value, ":")
+	case "ipv6":
+		ip := net.ParseIP(value)
+		return ip != nil && strings.Contains(value, ":")
+	case "uri":
+		u, err := url.Parse(value)
+		return err == nil && u.IsAbs()
+	default:
+		return true
+	}
+}
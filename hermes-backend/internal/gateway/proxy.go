@@ -1,105 +1,303 @@
-// package gateway
-
-// import (
-// 	"fmt"
-// 	"net/http"
-// 	"net/http/httputil"
-// 	"net/url"
-// 	"strings"
-// 	"sync"
-
-// 	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
-// 	"github.com/amaydixit11/hermes/hermes-backend/pkg/logger"
-// )
-
-// // Proxy handles reverse proxying requests to backend services
-// type Proxy struct {
-// 	routes      map[string]*models.Route
-// 	routesMutex sync.RWMutex
-// 	log         logger.Logger
-// }
-
-// // NewProxy creates a new reverse proxy
-// func NewProxy(log logger.Logger) *Proxy {
-// 	return &Proxy{
-// 		routes: make(map[string]*models.Route),
-// 		log:    log,
-// 	}
-// }
-
-// // UpdateRoutes updates the proxy routes
-// func (p *Proxy) UpdateRoutes(routes []*models.Route) {
-// 	p.routesMutex.Lock()
-// 	defer p.routesMutex.Unlock()
-
-// 	// Reset routes
-// 	p.routes = make(map[string]*models.Route)
-
-// 	// Add new routes
-// 	for _, route := range routes {
-// 		p.routes[route.ID] = route
-// 	}
-
-// 	p.log.Info().Msgf("Updated proxy routes, total routes: %d", len(routes))
-// }
-
-// // Handler returns an HTTP handler for the proxy
-// func (p *Proxy) Handler() http.Handler {
-// 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-// 		p.routesMutex.RLock()
-// 		defer p.routesMutex.RUnlock()
-
-// 		// Find matching route
-// 		var matchedRoute *models.Route
-// 		for _, route := range p.routes {
-// 			if strings.HasPrefix(r.URL.Path, route.Path) {
-// 				matchedRoute = route
-// 				break
-// 			}
-// 		}
-
-// 		if matchedRoute == nil {
-// 			http.Error(w, "Route not found", http.StatusNotFound)
-// 			return
-// 		}
-
-// 		// Choose target based on load balancing strategy
-// 		targetURL, err := p.chooseTarget(matchedRoute)
-// 		if err != nil {
-// 			p.log.Error().Err(err).Str("path", r.URL.Path).Msg("Failed to choose target")
-// 			http.Error(w, "Failed to route request", http.StatusInternalServerError)
-// 			return
-// 		}
-
-// 		// Create reverse proxy
-// 		proxy := httputil.NewSingleHostReverseProxy(targetURL)
-
-// 		// Update request URL
-// 		r.URL.Host = targetURL.Host
-// 		r.URL.Scheme = targetURL.Scheme
-// 		r.Header.Set("X-Forwarded-Host", r.Host)
-// 		r.Header.Set("X-Forwarded-Proto", r.URL.Scheme)
-// 		r.Header.Set("X-Forwarded-For", r.RemoteAddr)
-
-// 		// Serve request
-// 		proxy.ServeHTTP(w, r)
-// 	})
-// }
-
-// // chooseTarget selects a target URL based on the route's load balancing strategy
-// func (p *Proxy) chooseTarget(route *models.Route) (*url.URL, error) {
-// 	if len(route.Targets) == 0 {
-// 		return nil, fmt.Errorf("no targets available for route: %s", route.Path)
-// 	}
-
-// 	// Simple round-robin for now
-// 	// This would be replaced with more sophisticated load balancing in a real implementation
-// 	target := route.Targets[0]
-
-// 	targetURL, err := url.Parse(target)
-// 	if err != nil {
-// 		return nil, fmt.Errorf("invalid target URL: %w", err)
-// 	}
-
-// 	return targetURL, nil
-// }
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sync"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/security"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/logger"
+)
+
+// Middleware wraps the handler that serves a matched route.
+// The matched route is available through RouteFromContext.
+type Middleware func(next http.Handler) http.Handler
+
+// StatsProvider is implemented by gateway components that expose runtime statistics
+type StatsProvider interface {
+	Stats() interface{}
+}
+
+// Proxy handles reverse proxying requests to backend services
+type Proxy struct {
+	table       *RouteTable
+	services    map[string]*models.Service
+	routesMutex sync.RWMutex
+
+	balancers      map[string]LoadBalancer
+	balancersMutex sync.Mutex
+	factory        LoadBalancerFactory
+
+	middlewares    []Middleware
+	trustedProxies []*net.IPNet
+	stats          map[string]StatsProvider
+	reverse        *httputil.ReverseProxy
+	log            *logger.Logger
+}
+
+type targetContextKey struct{}
+
+// NewProxy creates a new reverse proxy
+func NewProxy(log *logger.Logger) *Proxy {
+	p := &Proxy{
+		table:     NewRouteTable(nil),
+		services:  make(map[string]*models.Service),
+		balancers: make(map[string]LoadBalancer),
+		stats:     make(map[string]StatsProvider),
+		log:       log,
+	}
+	p.reverse = &httputil.ReverseProxy{
+		Rewrite:        p.rewrite,
+		ModifyResponse: p.modifyResponse,
+		ErrorHandler:   p.handleUpstreamError,
+	}
+	return p
+}
+
+// Use appends middlewares to the chain run for every matched route.
+// Middlewares run in the order they are added, before the request is proxied.
+func (p *Proxy) Use(middlewares ...Middleware) {
+	p.middlewares = append(p.middlewares, middlewares...)
+}
+
+// SetTransport sets the transport used for upstream requests, for example an MTLSTransport
+func (p *Proxy) SetTransport(transport http.RoundTripper) {
+	p.reverse.Transport = transport
+}
+
+// SetTrustedProxies sets the networks of proxies in front of the gateway.
+// Client addresses are taken from the X-Forwarded-For header of requests
+// sent by them, and the header is passed on to upstreams.
+func (p *Proxy) SetTrustedProxies(networks []*net.IPNet) {
+	p.trustedProxies = networks
+}
+
+// RegisterStats exposes a component's statistics under the given name
+func (p *Proxy) RegisterStats(name string, provider StatsProvider) {
+	p.stats[name] = provider
+}
+
+// Stats returns the statistics of every registered component
+func (p *Proxy) Stats() map[string]interface{} {
+	stats := make(map[string]interface{}, len(p.stats))
+	for name, provider := range p.stats {
+		stats[name] = provider.Stats()
+	}
+	return stats
+}
+
+// UpdateRoutes updates the proxy routes
+func (p *Proxy) UpdateRoutes(routes []*models.Route) {
+	table := NewRouteTable(routes)
+
+	p.routesMutex.Lock()
+	p.table = table
+	p.routesMutex.Unlock()
+
+	// Drop balancers of routes that no longer exist
+	known := make(map[string]bool, len(routes))
+	for _, route := range table.Routes() {
+		known[route.ID] = true
+	}
+	p.balancersMutex.Lock()
+	for id := range p.balancers {
+		if !known[id] {
+			delete(p.balancers, id)
+		}
+	}
+	p.balancersMutex.Unlock()
+
+	p.log.Debug("Updated proxy routes", "total", len(table.Routes()))
+}
+
+// UpdateServices updates the upstream services the proxy routes to
+func (p *Proxy) UpdateServices(services []*models.Service) {
+	byID := make(map[string]*models.Service, len(services))
+	ids := make([]string, 0, len(services))
+	for _, service := range services {
+		byID[service.ID] = service
+		ids = append(ids, service.ID)
+	}
+
+	p.routesMutex.Lock()
+	p.services = byID
+	p.routesMutex.Unlock()
+
+	if transport, ok := p.reverse.Transport.(*MTLSTransport); ok {
+		transport.UpdateServices(ids)
+	}
+}
+
+// Routes returns the routes currently served by the proxy
+func (p *Proxy) Routes() []*models.Route {
+	p.routesMutex.RLock()
+	defer p.routesMutex.RUnlock()
+	return p.table.Routes()
+}
+
+// Handler returns an HTTP handler for the proxy
+func (p *Proxy) Handler() http.Handler {
+	var next http.Handler = http.HandlerFunc(p.forward)
+	for i := len(p.middlewares) - 1; i >= 0; i-- {
+		next = p.middlewares[i](next)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p.routesMutex.RLock()
+		route := p.table.Match(r.URL.Path)
+		var service *models.Service
+		if route != nil {
+			service = p.services[route.ServiceID]
+		}
+		p.routesMutex.RUnlock()
+
+		if route == nil {
+			writeError(w, http.StatusNotFound, "Route not found")
+			return
+		}
+
+		ctx := WithRoute(r.Context(), route)
+		ctx = WithClientIP(ctx, security.ClientIP(r.RemoteAddr, r.Header.Values("X-Forwarded-For"), p.trustedProxies))
+		if service != nil {
+			ctx = WithService(ctx, service)
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// forward proxies the request to one of the matched route's targets
+func (p *Proxy) forward(w http.ResponseWriter, r *http.Request) {
+	route := RouteFromContext(r.Context())
+
+	target, release, err := p.chooseTarget(route)
+	if err != nil {
+		p.log.Error("Failed to choose target", "error", err, "path", r.URL.Path, "route", route.ID)
+		writeError(w, http.StatusBadGateway, "Failed to route request")
+		return
+	}
+	defer release()
+
+	ctx := context.WithValue(r.Context(), targetContextKey{}, target)
+	p.reverse.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// rewrite points the outgoing request at the chosen target
+func (p *Proxy) rewrite(pr *httputil.ProxyRequest) {
+	target := pr.In.Context().Value(targetContextKey{}).(*url.URL)
+	pr.SetURL(target)
+	if p.trustedPeer(pr.In) {
+		pr.Out.Header["X-Forwarded-For"] = pr.In.Header["X-Forwarded-For"]
+	}
+	pr.SetXForwarded()
+
+	if route := RouteFromContext(pr.In.Context()); route != nil {
+		for key, value := range route.Headers {
+			pr.Out.Header.Set(key, value)
+		}
+	}
+}
+
+// modifyResponse drops the CORS headers of upstreams on routes whose CORS
+// policy is applied by the gateway, so browsers only see the policy's
+func (p *Proxy) modifyResponse(resp *http.Response) error {
+	if route := RouteFromContext(resp.Request.Context()); route != nil && route.CORS != nil {
+		security.StripCORSHeaders(resp.Header)
+	}
+	return nil
+}
+
+// trustedPeer reports whether a request was sent by a trusted proxy
+func (p *Proxy) trustedPeer(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	return security.TrustedIP(p.trustedProxies, net.ParseIP(host))
+}
+
+// handleUpstreamError answers requests whose upstream call failed
+func (p *Proxy) handleUpstreamError(w http.ResponseWriter, r *http.Request, err error) {
+	p.log.Error("Upstream request failed", "error", err, "path", r.URL.Path)
+	writeError(w, http.StatusBadGateway, "Upstream request failed")
+}
+
+// chooseTarget selects a target URL based on the route's load balancing strategy.
+// The returned release function must be called once the request completes.
+func (p *Proxy) chooseTarget(route *models.Route) (*url.URL, func(), error) {
+	if len(route.Targets) == 0 {
+		return nil, nil, fmt.Errorf("no targets available for route: %s", route.Path)
+	}
+
+	balancer, err := p.balancerFor(route)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	target, err := balancer.NextTarget(route.Targets)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	release := func() {}
+	if lc, ok := balancer.(*LeastConnectionsBalancer); ok {
+		release = func() { lc.ReleaseConnection(target) }
+	}
+
+	targetURL, err := url.Parse(target)
+	if err != nil {
+		release()
+		return nil, nil, fmt.Errorf("invalid target URL: %w", err)
+	}
+
+	return targetURL, release, nil
+}
+
+// balancerFor returns the load balancer for a route, creating it on first use
+func (p *Proxy) balancerFor(route *models.Route) (LoadBalancer, error) {
+	p.balancersMutex.Lock()
+	defer p.balancersMutex.Unlock()
+
+	if balancer, ok := p.balancers[route.ID]; ok {
+		return balancer, nil
+	}
+
+	balancer, err := p.factory.NewLoadBalancer(RoundRobin)
+	if err != nil {
+		return nil, err
+	}
+	p.balancers[route.ID] = balancer
+	return balancer, nil
+}
+
+// writeError writes a JSON error response in the same shape as the management API
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
+
+// statusRecorder captures the status code written by the rest of the chain
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func newStatusRecorder(w http.ResponseWriter) *statusRecorder {
+	return &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,137 @@
+package gateway
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/logger"
+)
+
+// defaultRateLimitWindow is used when a route's rate limit has no window
+const defaultRateLimitWindow = time.Second
+
+// rateWindow counts the requests seen in one fixed window
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+// RateLimiter enforces the fixed-window rate limit configured on each route
+type RateLimiter struct {
+	windows   map[string]*rateWindow
+	lastSweep time.Time
+	mu        sync.Mutex
+	log       *logger.Logger
+}
+
+// NewRateLimiter creates a new rate limiter
+func NewRateLimiter(log *logger.Logger) *RateLimiter {
+	return &RateLimiter{
+		windows:   make(map[string]*rateWindow),
+		lastSweep: time.Now(),
+		log:       log,
+	}
+}
+
+// allow records a request against key and reports whether it is within limit.
+// It also returns the remaining requests and when the current window resets.
+func (l *RateLimiter) allow(key string, limit int, window time.Duration) (bool, int, time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweep(now, window)
+
+	w, ok := l.windows[key]
+	if !ok || now.Sub(w.start) >= window {
+		w = &rateWindow{start: now}
+		l.windows[key] = w
+	}
+
+	reset := w.start.Add(window)
+	if w.count >= limit {
+		return false, 0, reset
+	}
+	w.count++
+	return true, limit - w.count, reset
+}
+
+// sweep drops windows that ended long ago so per-IP keys don't accumulate
+func (l *RateLimiter) sweep(now time.Time, window time.Duration) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+
+	for key, w := range l.windows {
+		if now.Sub(w.start) > time.Minute && now.Sub(w.start) > window {
+			delete(l.windows, key)
+		}
+	}
+}
+
+// Middleware returns a middleware enforcing the matched route's rate limit,
+// or the limit of the request's consumer if it has one. It must run after
+// the KeyAuth middleware for consumer limits to apply.
+func (l *RateLimiter) Middleware() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := RouteFromContext(r.Context())
+			if route == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// A consumer's own limit replaces the route's and is counted separately
+			limit := route.RateLimit
+			key := route.ID
+			if consumer := ConsumerFromContext(r.Context()); consumer != nil && consumer.RateLimit != nil && consumer.RateLimit.Limit > 0 {
+				limit = consumer.RateLimit
+				key += "|consumer:" + consumer.ID
+			}
+			if limit == nil || limit.Limit <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			window := limit.Window
+			if window <= 0 {
+				window = defaultRateLimitWindow
+			}
+
+			if limit.PerIP {
+				key += "|" + clientIP(r)
+			}
+
+			allowed, remaining, reset := l.allow(key, limit.Limit, window)
+			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(limit.Limit))
+			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+
+			if !allowed {
+				retryAfter := int(time.Until(reset).Seconds()) + 1
+				w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+				l.log.Debug("Request rate limited", "route", route.ID, "key", key)
+				writeError(w, http.StatusTooManyRequests, "Rate limit exceeded")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientIP returns the address of the client that sent the request, as
+// resolved through trusted proxies by the Proxy, or else of the peer
+func clientIP(r *http.Request) string {
+	if ip := ClientIPFromContext(r.Context()); ip != nil {
+		return ip.String()
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,308 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/logger"
+)
+
+// maxValidatedBody is the largest request body checked against a specification
+const maxValidatedBody = 10 << 20
+
+// parsedSpec is a parsed specification with the version it was parsed from
+type parsedSpec struct {
+	updatedAt time.Time
+	spec      *OpenAPISpec
+}
+
+// RequestValidator checks the requests of routes with request validation
+// against the OpenAPI specification bound to the route
+type RequestValidator struct {
+	specs  map[string]*parsedSpec // by specification ID
+	routes map[string]string      // route ID to specification ID
+	mu     sync.RWMutex
+
+	validated uint64
+	rejected  uint64
+	reported  uint64
+	log       *logger.Logger
+}
+
+// NewRequestValidator creates a new RequestValidator
+func NewRequestValidator(log *logger.Logger) *RequestValidator {
+	return &RequestValidator{
+		specs:  make(map[string]*parsedSpec),
+		routes: make(map[string]string),
+		log:    log,
+	}
+}
+
+// Update replaces the specifications and the routes they apply to.
+// Specifications that did not change since the last update are not parsed again.
+func (v *RequestValidator) Update(specs []*models.APISpec, routes map[string]string) {
+	v.mu.RLock()
+	current := v.specs
+	v.mu.RUnlock()
+
+	parsed := make(map[string]*parsedSpec, len(specs))
+	for _, spec := range specs {
+		if cached, ok := current[spec.ID]; ok && cached.updatedAt.Equal(spec.UpdatedAt) {
+			parsed[spec.ID] = cached
+			continue
+		}
+		doc, err := ParseOpenAPI(spec.Document)
+		if err != nil {
+			v.log.Error("Failed to parse API specification", "spec", spec.ID, "error", err)
+			continue
+		}
+		parsed[spec.ID] = &parsedSpec{updatedAt: spec.UpdatedAt, spec: doc}
+	}
+
+	v.mu.Lock()
+	v.specs = parsed
+	v.routes = routes
+	v.mu.Unlock()
+}
+
+// Middleware returns a middleware rejecting requests that do not match the
+// specification of their route, or only logging them in report mode
+func (v *RequestValidator) Middleware() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := RouteFromContext(r.Context())
+			if route == nil || route.Validation == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			v.mu.RLock()
+			specID := v.routes[route.ID]
+			parsed := v.specs[specID]
+			v.mu.RUnlock()
+			if parsed == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			violations := parsed.spec.validateRequest(r)
+			atomic.AddUint64(&v.validated, 1)
+			if len(violations) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if route.Validation.Mode == models.ValidationModeReport {
+				atomic.AddUint64(&v.reported, 1)
+				v.log.Warn("Request violates API specification", "route", route.ID, "spec", specID, "method", r.Method, "path", r.URL.Path, "violations", violations)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			atomic.AddUint64(&v.rejected, 1)
+			v.log.Debug("Request rejected by API specification", "route", route.ID, "spec", specID, "method", r.Method, "path", r.URL.Path)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":      "Request does not match the API specification",
+				"violations": violations,
+			})
+		})
+	}
+}
+
+// Stats returns how many requests were validated, rejected and reported
+func (v *RequestValidator) Stats() interface{} {
+	v.mu.RLock()
+	specs := len(v.specs)
+	v.mu.RUnlock()
+
+	return map[string]interface{}{
+		"specs":     specs,
+		"validated": atomic.LoadUint64(&v.validated),
+		"rejected":  atomic.LoadUint64(&v.rejected),
+		"reported":  atomic.LoadUint64(&v.reported),
+	}
+}
+
+// validateRequest checks a request against the specification. The body of
+// the request is read and replaced so it can still be proxied.
+func (s *OpenAPISpec) validateRequest(r *http.Request) []Violation {
+	op, pathValues, known := s.match(r.Method, r.URL.Path)
+	if op == nil {
+		if known {
+			return []Violation{{Location: "method", Name: r.Method, Message: "method is not allowed on this path"}}
+		}
+		return []Violation{{Location: "path", Name: r.URL.Path, Message: "path is not defined by the API specification"}}
+	}
+
+	var violations []Violation
+	query := r.URL.Query()
+	for _, param := range op.params {
+		var values []string
+		switch param.In {
+		case "path":
+			if value, ok := pathValues[param.Name]; ok {
+				values = []string{value}
+			}
+		case "query":
+			values = query[param.Name]
+		case "header":
+			values = r.Header.Values(param.Name)
+		case "cookie":
+			if cookie, err := r.Cookie(param.Name); err == nil {
+				values = []string{cookie.Value}
+			}
+		default:
+			continue
+		}
+
+		if len(values) == 0 {
+			if param.Required || param.In == "path" {
+				violations = append(violations, Violation{Location: param.In, Name: param.Name, Message: "is required"})
+			}
+			continue
+		}
+		s.validateParameter(param, values, &violations)
+	}
+
+	if op.body != nil {
+		s.validateBody(op.body, r, &violations)
+	}
+	return violations
+}
+
+// validateParameter converts the raw values of a parameter to the type of its
+// schema and validates them
+func (s *OpenAPISpec) validateParameter(param *openAPIParameter, values []string, violations *[]Violation) {
+	sc, err := s.resolveSchema(param.Schema)
+	if err != nil || sc == nil {
+		return
+	}
+
+	var value interface{}
+	switch primaryType(sc) {
+	case "array":
+		// Query arrays repeat the parameter unless explode is off, other
+		// locations separate items with commas
+		if len(values) == 1 && (param.In != "query" || (param.Explode != nil && !*param.Explode)) {
+			values = strings.Split(values[0], ",")
+		}
+		items, _ := s.resolveSchema(sc.Items)
+		list := make([]interface{}, len(values))
+		for i, raw := range values {
+			list[i] = parameterValue(items, raw)
+		}
+		value = list
+	case "object":
+		// Serialized objects are passed through unchecked
+		return
+	default:
+		value = parameterValue(sc, values[0])
+	}
+
+	s.validateValue(sc, value, param.In, param.Name, 0, violations)
+}
+
+// validateBody checks the media type of a request body and validates JSON
+// bodies against their schema
+func (s *OpenAPISpec) validateBody(spec *openAPIRequestBody, r *http.Request, violations *[]Violation) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxValidatedBody+1))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+	if err != nil {
+		*violations = append(*violations, Violation{Location: "body", Message: "request body could not be read"})
+		return
+	}
+
+	if len(body) == 0 {
+		if spec.Required {
+			*violations = append(*violations, Violation{Location: "body", Message: "request body is required"})
+		}
+		return
+	}
+
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		*violations = append(*violations, Violation{Location: "content-type", Message: "a valid Content-Type is required"})
+		return
+	}
+	content, ok := mediaContent(spec.Content, mediaType)
+	if !ok {
+		*violations = append(*violations, Violation{Location: "content-type", Name: mediaType, Message: "media type is not accepted"})
+		return
+	}
+	if content == nil || content.Schema == nil || !isJSONMediaType(mediaType) {
+		return
+	}
+	if len(body) > maxValidatedBody {
+		*violations = append(*violations, Violation{Location: "body", Message: "request body is too large to validate"})
+		return
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		*violations = append(*violations, Violation{Location: "body", Message: "invalid JSON: " + err.Error()})
+		return
+	}
+	s.validateValue(content.Schema, value, "body", "", 0, violations)
+}
+
+// mediaContent finds the content a request body of mediaType is described
+// by, trying an exact match, then type/* and */*
+func mediaContent(content map[string]*openAPIMediaType, mediaType string) (*openAPIMediaType, bool) {
+	major, _, _ := strings.Cut(mediaType, "/")
+	for _, candidate := range []string{mediaType, major + "/*", "*/*"} {
+		for key, value := range content {
+			if strings.EqualFold(key, candidate) {
+				return value, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// isJSONMediaType reports whether bodies of mediaType are JSON
+func isJSONMediaType(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// primaryType returns the first non-null type of a schema, string if it has none
+func primaryType(sc *schema) string {
+	for _, t := range sc.types() {
+		if t != "null" {
+			return t
+		}
+	}
+	return "string"
+}
+
+// parameterValue converts a raw parameter value to the type of its schema.
+// Values that do not convert are kept as strings for validation to reject.
+func parameterValue(sc *schema, raw string) interface{} {
+	if sc == nil {
+		return raw
+	}
+	switch primaryType(sc) {
+	case "integer", "number":
+		if n, err := strconv.ParseFloat(raw, 64); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(raw); err == nil {
+			return b
+		}
+	}
+	return raw
+}
@@ -0,0 +1,83 @@
+package gateway
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+)
+
+// RouteTable matches request paths against the configured gateway routes
+type RouteTable struct {
+	routes []*models.Route
+}
+
+// NewRouteTable creates a route table from the given routes, ignoring inactive ones
+func NewRouteTable(routes []*models.Route) *RouteTable {
+	active := make([]*models.Route, 0, len(routes))
+	for _, route := range routes {
+		if route.Active {
+			active = append(active, route)
+		}
+	}
+
+	// Longest paths first so the most specific route wins
+	sort.Slice(active, func(i, j int) bool {
+		return len(active[i].Path) > len(active[j].Path)
+	})
+
+	return &RouteTable{routes: active}
+}
+
+// Match returns the most specific route whose path prefixes the given path
+func (t *RouteTable) Match(path string) *models.Route {
+	for _, route := range t.routes {
+		if matchPrefix(route.Path, path) {
+			return route
+		}
+	}
+	return nil
+}
+
+// Routes returns the active routes in the table
+func (t *RouteTable) Routes() []*models.Route {
+	return t.routes
+}
+
+// matchPrefix reports whether prefix matches path on a segment boundary
+func matchPrefix(prefix, path string) bool {
+	if !strings.HasPrefix(path, prefix) {
+		return false
+	}
+	if len(path) == len(prefix) || strings.HasSuffix(prefix, "/") {
+		return true
+	}
+	return path[len(prefix)] == '/'
+}
+
+type routeContextKey struct{}
+
+// WithRoute returns a copy of ctx carrying the matched route
+func WithRoute(ctx context.Context, route *models.Route) context.Context {
+	return context.WithValue(ctx, routeContextKey{}, route)
+}
+
+// RouteFromContext returns the route matched for the current request, if any
+func RouteFromContext(ctx context.Context) *models.Route {
+	route, _ := ctx.Value(routeContextKey{}).(*models.Route)
+	return route
+}
+
+type serviceContextKey struct{}
+
+// WithService returns a copy of ctx carrying the upstream service of the matched route
+func WithService(ctx context.Context, service *models.Service) context.Context {
+	return context.WithValue(ctx, serviceContextKey{}, service)
+}
+
+// ServiceFromContext returns the upstream service of the matched route, if known
+func ServiceFromContext(ctx context.Context) *models.Service {
+	service, _ := ctx.Value(serviceContextKey{}).(*models.Service)
+	return service
+}
@@ -0,0 +1,149 @@
+package mesh
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned when a call is rejected by an open circuit breaker
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitState is the state of a circuit breaker
+type CircuitState int
+
+const (
+	// CircuitClosed lets every call through
+	CircuitClosed CircuitState = iota
+
+	// CircuitOpen rejects every call until the open timeout elapses
+	CircuitOpen
+
+	// CircuitHalfOpen lets a limited number of trial calls through
+	CircuitHalfOpen
+)
+
+// String returns the name of the state
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreakerConfig configures when a circuit breaker trips and recovers
+type CircuitBreakerConfig struct {
+	FailureThreshold    int           // Consecutive failures that open the circuit, defaults to 5
+	OpenTimeout         time.Duration // Time the circuit stays open, defaults to 30s
+	HalfOpenMaxRequests int           // Trial calls allowed while half-open, defaults to 1
+}
+
+// withDefaults fills in unset fields
+func (c CircuitBreakerConfig) withDefaults() CircuitBreakerConfig {
+	if c.FailureThreshold <= 0 {
+		c.FailureThreshold = 5
+	}
+	if c.OpenTimeout <= 0 {
+		c.OpenTimeout = 30 * time.Second
+	}
+	if c.HalfOpenMaxRequests <= 0 {
+		c.HalfOpenMaxRequests = 1
+	}
+	return c
+}
+
+// CircuitBreaker stops calling a failing target for a while so it can recover
+type CircuitBreaker struct {
+	config CircuitBreakerConfig
+	mu     sync.Mutex
+
+	state            CircuitState
+	failures         int
+	openedAt         time.Time
+	halfOpenInFlight int
+}
+
+// NewCircuitBreaker creates a closed circuit breaker
+func NewCircuitBreaker(config CircuitBreakerConfig) *CircuitBreaker {
+	return &CircuitBreaker{
+		config: config.withDefaults(),
+		state:  CircuitClosed,
+	}
+}
+
+// Allow reports whether a call may proceed. Every allowed call must be
+// followed by Success or Failure, or by Cancel when it was not made.
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitOpen {
+		if time.Since(b.openedAt) < b.config.OpenTimeout {
+			return ErrCircuitOpen
+		}
+		b.state = CircuitHalfOpen
+		b.halfOpenInFlight = 0
+	}
+
+	if b.state == CircuitHalfOpen {
+		if b.halfOpenInFlight >= b.config.HalfOpenMaxRequests {
+			return ErrCircuitOpen
+		}
+		b.halfOpenInFlight++
+	}
+
+	return nil
+}
+
+// Success records a successful call
+func (b *CircuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	if b.state == CircuitHalfOpen {
+		b.state = CircuitClosed
+		b.halfOpenInFlight = 0
+	}
+}
+
+// Failure records a failed call
+func (b *CircuitBreaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.state == CircuitHalfOpen || b.failures >= b.config.FailureThreshold {
+		b.state = CircuitOpen
+		b.openedAt = time.Now()
+		b.halfOpenInFlight = 0
+	}
+}
+
+// Cancel records that an allowed call was not made, such as when its request
+// could not be built. It frees a trial call without deciding the state.
+func (b *CircuitBreaker) Cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitHalfOpen && b.halfOpenInFlight > 0 {
+		b.halfOpenInFlight--
+	}
+}
+
+// State returns the current state of the circuit breaker
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitOpen && time.Since(b.openedAt) >= b.config.OpenTimeout {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
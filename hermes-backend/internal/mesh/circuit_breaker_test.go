@@ -0,0 +1,97 @@
+package mesh
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerOpensAndRecovers(t *testing.T) {
+	b := NewCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 3, OpenTimeout: 20 * time.Millisecond})
+
+	for i := 0; i < 2; i++ {
+		if err := b.Allow(); err != nil {
+			t.Fatalf("Allow: %v", err)
+		}
+		b.Failure()
+	}
+	// A success resets the consecutive failures
+	b.Allow()
+	b.Success()
+	for i := 0; i < 2; i++ {
+		b.Allow()
+		b.Failure()
+	}
+	if b.State() != CircuitClosed {
+		t.Fatalf("state = %s after non-consecutive failures, want closed", b.State())
+	}
+
+	b.Allow()
+	b.Failure()
+	if b.State() != CircuitOpen {
+		t.Fatalf("state = %s, want open", b.State())
+	}
+	if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Allow while open = %v", err)
+	}
+
+	time.Sleep(25 * time.Millisecond)
+	if b.State() != CircuitHalfOpen {
+		t.Fatalf("state = %s after the open timeout, want half-open", b.State())
+	}
+	if err := b.Allow(); err != nil {
+		t.Fatalf("trial Allow: %v", err)
+	}
+	if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("second trial Allow = %v, want rejected", err)
+	}
+	b.Success()
+	if b.State() != CircuitClosed {
+		t.Fatalf("state = %s after a successful trial, want closed", b.State())
+	}
+}
+
+func TestCircuitBreakerFailedTrialReopens(t *testing.T) {
+	b := NewCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 1, OpenTimeout: 10 * time.Millisecond})
+	b.Allow()
+	b.Failure()
+	time.Sleep(15 * time.Millisecond)
+
+	if err := b.Allow(); err != nil {
+		t.Fatalf("trial Allow: %v", err)
+	}
+	b.Failure()
+	if b.State() != CircuitOpen {
+		t.Fatalf("state = %s after a failed trial, want open", b.State())
+	}
+}
+
+func TestCircuitBreakerCancelFreesTrial(t *testing.T) {
+	b := NewCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 1, OpenTimeout: 10 * time.Millisecond})
+	b.Allow()
+	b.Failure()
+	time.Sleep(15 * time.Millisecond)
+
+	if err := b.Allow(); err != nil {
+		t.Fatalf("trial Allow: %v", err)
+	}
+	b.Cancel()
+	if b.State() != CircuitHalfOpen {
+		t.Fatalf("state = %s after a canceled trial, want still half-open", b.State())
+	}
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Allow after a canceled trial = %v, want another trial", err)
+	}
+
+	// Canceling a call while closed does not count as a success
+	b = NewCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 2})
+	b.Allow()
+	b.Failure()
+	b.Allow()
+	b.Cancel()
+	b.Allow()
+	b.Failure()
+	if b.State() != CircuitOpen {
+		t.Fatalf("state = %s, want open: the canceled call must not reset the failures", b.State())
+	}
+}
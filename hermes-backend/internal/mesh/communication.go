@@ -0,0 +1,559 @@
+// Package mesh lets services call each other by name through the Hermes
+// registry, with client-side load balancing, retries, circuit breaking,
+// mutual TLS and propagation of request IDs and deadlines.
+package mesh
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/gateway"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/security"
+	"github.com/google/uuid"
+)
+
+// Headers used to propagate request context between services
+const (
+	RequestIDHeader = "X-Request-ID"
+	DeadlineHeader  = "X-Request-Deadline" // RFC 3339 timestamp with nanoseconds
+)
+
+// ErrServiceNotFound is returned when the registry has no service with the requested name
+var ErrServiceNotFound = errors.New("service not found")
+
+// ErrNoHealthyInstances is returned when none of the instances of a service can receive traffic
+var ErrNoHealthyInstances = errors.New("no healthy instances")
+
+// defaultCacheTTL is how long discovered services are cached when no TTL is configured
+const defaultCacheTTL = 30 * time.Second
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying a request ID for outgoing calls
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID carried by ctx, if any
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// Propagate is HTTP middleware for services using the Client. It puts the
+// request ID and deadline of incoming requests into the request context so
+// that calls made through the Client carry them on.
+func Propagate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if id == "" {
+			id = uuid.New().String()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		ctx := WithRequestID(r.Context(), id)
+
+		if value := r.Header.Get(DeadlineHeader); value != "" {
+			if deadline, err := time.Parse(time.RFC3339Nano, value); err == nil {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithDeadline(ctx, deadline)
+				defer cancel()
+			}
+		}
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Config configures a Client
+type Config struct {
+	HermesURL      string                   // Base URL of the Hermes management API
+	Token          string                   // Bearer token for the Hermes API, if it requires one
+	CacheTTL       time.Duration            // How long discovered services are cached, defaults to 30s
+	LoadBalancer   gateway.LoadBalancerType // Strategy used across a service's targets, defaults to round-robin
+	Retry          RetryPolicy
+	CircuitBreaker CircuitBreakerConfig
+	HTTPClient     *http.Client // Client used for all calls, defaults to a client without timeout
+
+	// Zone of the calling service. Healthy instances in the same zone are
+	// preferred over the others.
+	Zone string
+
+	// ServiceID is the registered ID of the calling service. When set, the
+	// client obtains a workload certificate from Hermes and calls HTTPS targets
+	// over mutual TLS, verifying that they present the called service's identity.
+	ServiceID string
+}
+
+// Client calls registered services by name
+type Client struct {
+	config  Config
+	http    *http.Client
+	factory gateway.LoadBalancerFactory
+
+	identity *security.CertificateRotator
+
+	mu          sync.Mutex
+	cache       map[string]*cachedService
+	breakers    map[string]*CircuitBreaker
+	mtlsClients map[string]*http.Client
+}
+
+// cachedService holds the discovered targets of a service
+type cachedService struct {
+	id       string
+	targets  []string
+	balancer gateway.LoadBalancer
+	expires  time.Time
+}
+
+// NewClient creates a new service-to-service client
+func NewClient(config Config) (*Client, error) {
+	if config.HermesURL == "" {
+		return nil, errors.New("hermes URL is required")
+	}
+	if _, err := url.Parse(config.HermesURL); err != nil {
+		return nil, fmt.Errorf("invalid hermes URL: %w", err)
+	}
+	if config.CacheTTL <= 0 {
+		config.CacheTTL = defaultCacheTTL
+	}
+	if config.LoadBalancer == "" {
+		config.LoadBalancer = gateway.RoundRobin
+	}
+	config.Retry = config.Retry.withDefaults()
+	config.CircuitBreaker = config.CircuitBreaker.withDefaults()
+
+	c := &Client{
+		config:      config,
+		http:        config.HTTPClient,
+		cache:       make(map[string]*cachedService),
+		breakers:    make(map[string]*CircuitBreaker),
+		mtlsClients: make(map[string]*http.Client),
+	}
+	if c.http == nil {
+		c.http = &http.Client{}
+	}
+	if config.ServiceID != "" {
+		c.identity = security.NewCertificateRotator(c.fetchCertificate)
+	}
+
+	// Fail early on unsupported strategies
+	if _, err := c.factory.NewLoadBalancer(config.LoadBalancer); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Get sends a GET request to the named service
+func (c *Client) Get(ctx context.Context, serviceName, path string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, serviceName, req)
+}
+
+// Post sends a POST request to the named service
+func (c *Client) Post(ctx context.Context, serviceName, path, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	return c.Do(ctx, serviceName, req)
+}
+
+// Do sends req to the named service. The request URL only needs a path and
+// query; the target is chosen from the service's registered endpoints.
+// Failed attempts are retried according to the retry policy.
+func (c *Client) Do(ctx context.Context, serviceName string, req *http.Request) (*http.Response, error) {
+	getBody, err := rewindableBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := c.config.Retry
+	for attempt := 1; ; attempt++ {
+		resp, err := c.attempt(ctx, serviceName, req, getBody)
+
+		if attempt >= policy.MaxAttempts || !policy.retryable(req.WithContext(ctx), resp, err) {
+			return resp, err
+		}
+
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if err := sleep(ctx, policy.backoff(attempt)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Invalidate drops the cached targets of a service
+func (c *Client) Invalidate(serviceName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.cache, serviceName)
+}
+
+// attempt sends a single request to one of the service's targets
+func (c *Client) attempt(ctx context.Context, serviceName string, req *http.Request, getBody func() (io.ReadCloser, error)) (*http.Response, error) {
+	service, err := c.resolve(ctx, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	target, breaker, release, err := c.pick(service)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", serviceName, err)
+	}
+
+	out := req.Clone(ctx)
+	out.URL = joinURL(target, req.URL)
+	out.Host = ""
+	out.RequestURI = ""
+	if out.Body, err = getBody(); err != nil {
+		// The target was never called, so its health is still unknown
+		release()
+		breaker.Cancel()
+		return nil, err
+	}
+	propagate(ctx, out)
+
+	resp, err := c.clientFor(service, out.URL).Do(out)
+	if err != nil || resp.StatusCode >= http.StatusInternalServerError {
+		breaker.Failure()
+	} else {
+		breaker.Success()
+	}
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// resolve returns the cached targets of a service, refreshing them from Hermes when stale.
+// If Hermes cannot be reached, stale targets are used rather than failing the call.
+func (c *Client) resolve(ctx context.Context, serviceName string) (*cachedService, error) {
+	c.mu.Lock()
+	cached, ok := c.cache[serviceName]
+	c.mu.Unlock()
+
+	if ok && time.Now().Before(cached.expires) {
+		return cached, nil
+	}
+
+	id, targets, err := c.discover(ctx, serviceName)
+	if err != nil {
+		if ok && !errors.Is(err, ErrServiceNotFound) {
+			return cached, nil
+		}
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if ok && cached.id == id && equalTargets(cached.targets, targets) {
+		cached.expires = time.Now().Add(c.config.CacheTTL)
+		return cached, nil
+	}
+
+	balancer, err := c.factory.NewLoadBalancer(c.config.LoadBalancer)
+	if err != nil {
+		return nil, err
+	}
+	cached = &cachedService{
+		id:       id,
+		targets:  targets,
+		balancer: balancer,
+		expires:  time.Now().Add(c.config.CacheTTL),
+	}
+	c.cache[serviceName] = cached
+	return cached, nil
+}
+
+// discover looks up a service's ID and targets through the Hermes API. The
+// targets are the endpoints of its healthy instances, or the endpoint of the
+// service itself when it registered no instances.
+func (c *Client) discover(ctx context.Context, serviceName string) (string, []string, error) {
+	resp, err := c.hermes(ctx, http.MethodGet, "/api/v1/services/by-name/"+url.PathEscape(serviceName))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to discover service %s: %w", serviceName, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", nil, fmt.Errorf("%s: %w", serviceName, ErrServiceNotFound)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, fmt.Errorf("failed to discover service %s: unexpected status %d", serviceName, resp.StatusCode)
+	}
+
+	var service models.Service
+	if err := json.NewDecoder(resp.Body).Decode(&service); err != nil {
+		return "", nil, fmt.Errorf("failed to decode service %s: %w", serviceName, err)
+	}
+
+	instances, err := c.instances(ctx, service.ID)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to discover instances of service %s: %w", serviceName, err)
+	}
+	if len(instances) > 0 {
+		targets := weightedTargets(instances, c.config.Zone)
+		if len(targets) == 0 {
+			return "", nil, fmt.Errorf("%s: %w", serviceName, ErrNoHealthyInstances)
+		}
+		return service.ID, targets, nil
+	}
+
+	if service.Endpoint == "" {
+		return "", nil, fmt.Errorf("service %s has no endpoint", serviceName)
+	}
+	return service.ID, []string{service.Endpoint}, nil
+}
+
+// instances retrieves every instance of a service through the Hermes API
+func (c *Client) instances(ctx context.Context, serviceID string) ([]*models.ServiceInstance, error) {
+	resp, err := c.hermes(ctx, http.MethodGet, "/api/v1/services/"+url.PathEscape(serviceID)+"/instances")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	var list struct {
+		Instances []*models.ServiceInstance `json:"instances"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, fmt.Errorf("failed to decode instances: %w", err)
+	}
+	return list.Instances, nil
+}
+
+// weightedTargets returns the endpoints of the healthy instances, those in
+// zone when there are any. Each endpoint is repeated in proportion to the
+// instance weight, so that the load balancers spread calls by weight.
+func weightedTargets(instances []*models.ServiceInstance, zone string) []string {
+	var healthy, local []*models.ServiceInstance
+	for _, instance := range instances {
+		if !instance.Routable() {
+			continue
+		}
+		healthy = append(healthy, instance)
+		if zone != "" && instance.Zone == zone {
+			local = append(local, instance)
+		}
+	}
+	if len(local) > 0 {
+		healthy = local
+	}
+
+	divisor := 0
+	for _, instance := range healthy {
+		divisor = gcd(divisor, max(instance.Weight, 1))
+	}
+	var targets []string
+	for _, instance := range healthy {
+		for i := 0; i < max(instance.Weight, 1)/divisor; i++ {
+			targets = append(targets, instance.Endpoint)
+		}
+	}
+	return targets
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// fetchCertificate obtains a workload certificate for the calling service from Hermes
+func (c *Client) fetchCertificate(ctx context.Context) (*security.WorkloadCertificate, error) {
+	resp, err := c.hermes(ctx, http.MethodPost, "/api/v1/services/"+url.PathEscape(c.config.ServiceID)+"/certificate")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	var cert security.WorkloadCertificate
+	if err := json.NewDecoder(resp.Body).Decode(&cert); err != nil {
+		return nil, fmt.Errorf("failed to decode certificate: %w", err)
+	}
+	return &cert, nil
+}
+
+// hermes sends a request to the Hermes API
+func (c *Client) hermes(ctx context.Context, method, path string) (*http.Response, error) {
+	endpoint := strings.TrimRight(c.config.HermesURL, "/") + path
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.config.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.config.Token)
+	}
+	propagate(ctx, req)
+
+	return c.http.Do(req)
+}
+
+// clientFor returns the HTTP client for calling a target of service.
+// HTTPS targets are called over mutual TLS when the client has an identity.
+func (c *Client) clientFor(service *cachedService, target *url.URL) *http.Client {
+	if c.identity == nil || target.Scheme != "https" {
+		return c.http
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	client, ok := c.mtlsClients[service.id]
+	if !ok {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = c.identity.ClientTLSConfig(service.id)
+		client = &http.Client{
+			Transport:     transport,
+			CheckRedirect: c.http.CheckRedirect,
+			Jar:           c.http.Jar,
+			Timeout:       c.http.Timeout,
+		}
+		c.mtlsClients[service.id] = client
+	}
+	return client
+}
+
+// pick chooses a target whose circuit breaker allows a call
+func (c *Client) pick(service *cachedService) (*url.URL, *CircuitBreaker, func(), error) {
+	for range service.targets {
+		target, err := service.balancer.NextTarget(service.targets)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		release := func() {}
+		if lc, ok := service.balancer.(*gateway.LeastConnectionsBalancer); ok {
+			release = func() { lc.ReleaseConnection(target) }
+		}
+
+		breaker := c.breaker(target)
+		if err := breaker.Allow(); err != nil {
+			release()
+			continue
+		}
+
+		targetURL, err := url.Parse(target)
+		if err != nil {
+			release()
+			breaker.Failure()
+			return nil, nil, nil, fmt.Errorf("invalid target URL: %w", err)
+		}
+		return targetURL, breaker, release, nil
+	}
+
+	return nil, nil, nil, ErrCircuitOpen
+}
+
+// breaker returns the circuit breaker of a target
+func (c *Client) breaker(target string) *CircuitBreaker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	breaker, ok := c.breakers[target]
+	if !ok {
+		breaker = NewCircuitBreaker(c.config.CircuitBreaker)
+		c.breakers[target] = breaker
+	}
+	return breaker
+}
+
+// propagate copies the request ID and deadline of ctx into outgoing headers
+func propagate(ctx context.Context, req *http.Request) {
+	id := RequestIDFromContext(ctx)
+	if id == "" {
+		id = req.Header.Get(RequestIDHeader)
+	}
+	if id == "" {
+		id = uuid.New().String()
+	}
+	req.Header.Set(RequestIDHeader, id)
+
+	if deadline, ok := ctx.Deadline(); ok {
+		req.Header.Set(DeadlineHeader, deadline.UTC().Format(time.RFC3339Nano))
+	}
+}
+
+// rewindableBody returns a function producing a fresh copy of the request body for each attempt
+func rewindableBody(req *http.Request) (func() (io.ReadCloser, error), error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return func() (io.ReadCloser, error) { return http.NoBody, nil }, nil
+	}
+	if req.GetBody != nil {
+		return req.GetBody, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	return func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}, nil
+}
+
+// joinURL combines a target base URL with the path and query of a request URL
+func joinURL(target *url.URL, ref *url.URL) *url.URL {
+	u := *target
+	u.Path = strings.TrimRight(target.Path, "/") + "/" + strings.TrimLeft(ref.Path, "/")
+	u.RawPath = ""
+	u.RawQuery = ref.RawQuery
+	return &u
+}
+
+func equalTargets(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// releaseOnClose releases a load balancer connection once the response body is closed
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+func (r *releaseOnClose) Close() error {
+	r.once.Do(r.release)
+	return r.ReadCloser.Close()
+}
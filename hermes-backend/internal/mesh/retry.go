@@ -0,0 +1,98 @@
+package mesh
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy controls how failed calls to another service are retried
+type RetryPolicy struct {
+	MaxAttempts     int           // Total attempts including the first, defaults to 3
+	InitialBackoff  time.Duration // Backoff before the first retry, defaults to 100ms
+	MaxBackoff      time.Duration // Upper bound for the backoff, defaults to 2s
+	Multiplier      float64       // Backoff growth per attempt, defaults to 2
+	RetryableStatus []int         // Response codes worth retrying, defaults to 502, 503 and 504
+
+	// RetryNonIdempotent allows retrying POST and PATCH requests that carry no Idempotency-Key
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy returns the retry policy used when none is configured
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{}.withDefaults()
+}
+
+// withDefaults fills in unset fields
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = 3
+	}
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = 100 * time.Millisecond
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = 2 * time.Second
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = 2
+	}
+	if p.RetryableStatus == nil {
+		p.RetryableStatus = []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+	}
+	return p
+}
+
+// backoff returns the wait before the given retry (1 for the first retry),
+// using full jitter so that callers don't retry in lockstep
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	backoff := float64(p.InitialBackoff)
+	for i := 1; i < retry; i++ {
+		backoff *= p.Multiplier
+		if backoff >= float64(p.MaxBackoff) {
+			backoff = float64(p.MaxBackoff)
+			break
+		}
+	}
+	return time.Duration(rand.Int63n(int64(backoff) + 1))
+}
+
+// retryable reports whether the outcome of an attempt is worth retrying
+func (p RetryPolicy) retryable(req *http.Request, resp *http.Response, err error) bool {
+	if !p.RetryNonIdempotent && !idempotent(req) {
+		return false
+	}
+	if err != nil {
+		// Don't retry once the caller gave up
+		return req.Context().Err() == nil
+	}
+	for _, status := range p.RetryableStatus {
+		if resp.StatusCode == status {
+			return true
+		}
+	}
+	return false
+}
+
+// idempotent reports whether a request can safely be sent more than once
+func idempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete, http.MethodTrace:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,87 @@
+// internal/repository/postgres/api_spec.go
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+// APISpecRepository implements the repository.APISpecRepository interface
+type APISpecRepository struct {
+	db *gorm.DB
+}
+
+// NewAPISpecRepository creates a new APISpecRepository
+func NewAPISpecRepository(db *gorm.DB) repository.APISpecRepository {
+	return &APISpecRepository{db: db}
+}
+
+// Create adds a new API specification to the database
+func (r *APISpecRepository) Create(ctx context.Context, spec *models.APISpec) error {
+	return r.db.WithContext(ctx).Create(spec).Error
+}
+
+// GetByID retrieves an API specification by its ID
+func (r *APISpecRepository) GetByID(ctx context.Context, id string) (*models.APISpec, error) {
+	return r.first(ctx, "id = ?", id)
+}
+
+// GetByName retrieves an API specification by its name
+func (r *APISpecRepository) GetByName(ctx context.Context, name string) (*models.APISpec, error) {
+	return r.first(ctx, "name = ?", name)
+}
+
+// GetByIDs retrieves the API specifications with the given IDs
+func (r *APISpecRepository) GetByIDs(ctx context.Context, ids []string) ([]*models.APISpec, error) {
+	var specs []*models.APISpec
+	if len(ids) == 0 {
+		return specs, nil
+	}
+	err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&specs).Error
+	return specs, err
+}
+
+// List retrieves all API specifications ordered by name, without their documents
+func (r *APISpecRepository) List(ctx context.Context) ([]*models.APISpec, error) {
+	var specs []*models.APISpec
+	err := r.db.WithContext(ctx).Omit("document").Order("name").Find(&specs).Error
+	return specs, err
+}
+
+// Update modifies an existing API specification
+func (r *APISpecRepository) Update(ctx context.Context, spec *models.APISpec) error {
+	return r.db.WithContext(ctx).Save(spec).Error
+}
+
+// Delete removes an API specification by its ID
+func (r *APISpecRepository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.APISpec{}).Error
+}
+
+// InUse reports whether a route or a service version references an API specification
+func (r *APISpecRepository) InUse(ctx context.Context, id string) (bool, error) {
+	var routes, versions int64
+	if err := r.db.WithContext(ctx).Model(&models.Route{}).Where("validation->>'spec_id' = ?", id).Count(&routes).Error; err != nil {
+		return false, err
+	}
+	if err := r.db.WithContext(ctx).Model(&models.ServiceVersion{}).Where("spec_id = ?", id).Count(&versions).Error; err != nil {
+		return false, err
+	}
+	return routes+versions > 0, nil
+}
+
+// first retrieves the API specification matching a condition, or nil if there is none
+func (r *APISpecRepository) first(ctx context.Context, query string, args ...interface{}) (*models.APISpec, error) {
+	var spec models.APISpec
+	if err := r.db.WithContext(ctx).Where(query, args...).First(&spec).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &spec, nil
+}
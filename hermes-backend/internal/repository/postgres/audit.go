@@ -0,0 +1,89 @@
+// internal/repository/postgres/audit.go
+package postgres
+
+import (
+	"context"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+// AuditRepository implements the repository.AuditRepository interface. The
+// audit log is append-only, so there is no way to change or remove entries.
+type AuditRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditRepository creates a new AuditRepository
+func NewAuditRepository(db *gorm.DB) repository.AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// Create appends an entry to the audit log
+func (r *AuditRepository) Create(ctx context.Context, entry *models.AuditEntry) error {
+	return r.db.WithContext(ctx).Create(entry).Error
+}
+
+// List retrieves a page of matching entries, newest first, and the number of matches
+func (r *AuditRepository) List(ctx context.Context, params models.AuditQueryParams) ([]*models.AuditEntry, int64, error) {
+	var entries []*models.AuditEntry
+	var count int64
+
+	query := r.filter(ctx, params)
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Offset(params.Offset).Limit(params.Limit).Order("timestamp desc, id desc").Find(&entries).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return entries, count, nil
+}
+
+// ForEach calls fn with every matching entry, oldest first, without loading
+// them all into memory
+func (r *AuditRepository) ForEach(ctx context.Context, params models.AuditQueryParams, fn func(*models.AuditEntry) error) error {
+	rows, err := r.filter(ctx, params).Order("timestamp, id").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry models.AuditEntry
+		if err := r.db.ScanRows(rows, &entry); err != nil {
+			return err
+		}
+		if err := fn(&entry); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// filter builds a query for the entries matching params
+func (r *AuditRepository) filter(ctx context.Context, params models.AuditQueryParams) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&models.AuditEntry{})
+
+	if params.ActorID != "" {
+		query = query.Where("actor_id = ?", params.ActorID)
+	}
+	if params.Action != "" {
+		query = query.Where("action = ?", params.Action)
+	}
+	if params.ResourceType != "" {
+		query = query.Where("resource_type = ?", params.ResourceType)
+	}
+	if params.ResourceID != "" {
+		query = query.Where("resource_id = ?", params.ResourceID)
+	}
+	if !params.StartTime.IsZero() {
+		query = query.Where("timestamp >= ?", params.StartTime)
+	}
+	if !params.EndTime.IsZero() {
+		query = query.Where("timestamp <= ?", params.EndTime)
+	}
+	return query
+}
@@ -0,0 +1,153 @@
+// internal/repository/postgres/consumer.go
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+// ConsumerRepository implements the repository.ConsumerRepository interface
+type ConsumerRepository struct {
+	db *gorm.DB
+}
+
+// NewConsumerRepository creates a new ConsumerRepository
+func NewConsumerRepository(db *gorm.DB) repository.ConsumerRepository {
+	return &ConsumerRepository{db: db}
+}
+
+// Create adds a new consumer to the database
+func (r *ConsumerRepository) Create(ctx context.Context, consumer *models.Consumer) error {
+	return r.db.WithContext(ctx).Create(consumer).Error
+}
+
+// GetByID retrieves a consumer by its ID
+func (r *ConsumerRepository) GetByID(ctx context.Context, id string) (*models.Consumer, error) {
+	return r.first(ctx, "id = ?", id)
+}
+
+// GetByName retrieves a consumer by its name
+func (r *ConsumerRepository) GetByName(ctx context.Context, name string) (*models.Consumer, error) {
+	return r.first(ctx, "name = ?", name)
+}
+
+// List retrieves all consumers ordered by name
+func (r *ConsumerRepository) List(ctx context.Context) ([]*models.Consumer, error) {
+	var consumers []*models.Consumer
+	err := r.db.WithContext(ctx).Order("name").Find(&consumers).Error
+	return consumers, err
+}
+
+// ListActive retrieves the consumers that may call the gateway
+func (r *ConsumerRepository) ListActive(ctx context.Context) ([]*models.Consumer, error) {
+	var consumers []*models.Consumer
+	err := r.db.WithContext(ctx).Where("active = ?", true).Find(&consumers).Error
+	return consumers, err
+}
+
+// Update modifies an existing consumer
+func (r *ConsumerRepository) Update(ctx context.Context, consumer *models.Consumer) error {
+	return r.db.WithContext(ctx).Save(consumer).Error
+}
+
+// Delete removes a consumer by its ID, together with its keys
+func (r *ConsumerRepository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.Consumer{}).Error
+}
+
+// CreateKey adds a new API key to the database
+func (r *ConsumerRepository) CreateKey(ctx context.Context, key *models.ConsumerKey) error {
+	return r.db.WithContext(ctx).Create(key).Error
+}
+
+// GetKey retrieves an API key by its ID
+func (r *ConsumerRepository) GetKey(ctx context.Context, id string) (*models.ConsumerKey, error) {
+	var key models.ConsumerKey
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+// ListKeys retrieves the API keys of the given consumers
+func (r *ConsumerRepository) ListKeys(ctx context.Context, consumerIDs ...string) ([]*models.ConsumerKey, error) {
+	var keys []*models.ConsumerKey
+	if len(consumerIDs) == 0 {
+		return keys, nil
+	}
+	err := r.db.WithContext(ctx).Where("consumer_id IN ?", consumerIDs).Order("created_at").Find(&keys).Error
+	return keys, err
+}
+
+// DeleteKey removes an API key by its ID
+func (r *ConsumerRepository) DeleteKey(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.ConsumerKey{}).Error
+}
+
+// CreateSecret adds a new signing secret to the database
+func (r *ConsumerRepository) CreateSecret(ctx context.Context, secret *models.ConsumerSecret) error {
+	return r.db.WithContext(ctx).Create(secret).Error
+}
+
+// GetSecret retrieves a signing secret by its ID
+func (r *ConsumerRepository) GetSecret(ctx context.Context, id string) (*models.ConsumerSecret, error) {
+	var secret models.ConsumerSecret
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&secret).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &secret, nil
+}
+
+// ListSecrets retrieves the signing secrets of the given consumers
+func (r *ConsumerRepository) ListSecrets(ctx context.Context, consumerIDs ...string) ([]*models.ConsumerSecret, error) {
+	var secrets []*models.ConsumerSecret
+	if len(consumerIDs) == 0 {
+		return secrets, nil
+	}
+	err := r.db.WithContext(ctx).Where("consumer_id IN ?", consumerIDs).Order("created_at").Find(&secrets).Error
+	return secrets, err
+}
+
+// DeleteSecret removes a signing secret by its ID
+func (r *ConsumerRepository) DeleteSecret(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.ConsumerSecret{}).Error
+}
+
+// UseNonce stores a nonce until expiresAt and reports whether it was unused.
+// The primary key makes concurrent uses on different instances race safely;
+// an expired nonce that was not cleaned up yet can be used again.
+func (r *ConsumerRepository) UseNonce(ctx context.Context, nonce string, expiresAt time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Exec(
+		`INSERT INTO gateway_nonces (nonce, expires_at) VALUES (?, ?)
+		ON CONFLICT (nonce) DO UPDATE SET expires_at = EXCLUDED.expires_at
+		WHERE gateway_nonces.expires_at < NOW()`,
+		nonce, expiresAt)
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeleteExpiredNonces removes the nonces whose signatures expired
+func (r *ConsumerRepository) DeleteExpiredNonces(ctx context.Context) error {
+	return r.db.WithContext(ctx).Exec("DELETE FROM gateway_nonces WHERE expires_at < NOW()").Error
+}
+
+func (r *ConsumerRepository) first(ctx context.Context, query string, args ...interface{}) (*models.Consumer, error) {
+	var consumer models.Consumer
+	if err := r.db.WithContext(ctx).Where(query, args...).First(&consumer).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &consumer, nil
+}
@@ -0,0 +1,108 @@
+// internal/repository/postgres/encryption.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EncryptionRepository implements the repository.EncryptionRepository interface
+type EncryptionRepository struct {
+	db *gorm.DB
+}
+
+// NewEncryptionRepository creates a new EncryptionRepository
+func NewEncryptionRepository(db *gorm.DB) repository.EncryptionRepository {
+	return &EncryptionRepository{db: db}
+}
+
+// RewriteSealed passes every value of a binary column to rewrite and stores
+// the values it replaces
+func (r *EncryptionRepository) RewriteSealed(ctx context.Context, table, column string, rewrite func(sealed []byte) ([]byte, bool, error)) (int, error) {
+	return r.rewrite(ctx, table, column, func(value []byte) (interface{}, bool, error) {
+		return rewrite(value)
+	})
+}
+
+// RewriteSealedString passes every non-empty value of a text column to
+// rewrite and stores the values it replaces
+func (r *EncryptionRepository) RewriteSealedString(ctx context.Context, table, column string, rewrite func(sealed string) (string, bool, error)) (int, error) {
+	return r.rewrite(ctx, table, column, func(value []byte) (interface{}, bool, error) {
+		if len(value) == 0 {
+			return nil, false, nil
+		}
+		return rewrite(string(value))
+	})
+}
+
+// RewriteSealedMap passes every value of a JSON object column to rewrite and
+// stores the objects it changes
+func (r *EncryptionRepository) RewriteSealedMap(ctx context.Context, table, column string, rewrite func(values map[string]string) (bool, error)) (int, error) {
+	return r.rewrite(ctx, table, column, func(value []byte) (interface{}, bool, error) {
+		var values map[string]string
+		if err := json.Unmarshal(value, &values); err != nil || len(values) == 0 {
+			return nil, false, nil
+		}
+		changed, err := rewrite(values)
+		if err != nil || !changed {
+			return nil, false, err
+		}
+		data, err := json.Marshal(values)
+		if err != nil {
+			return nil, false, err
+		}
+		return string(data), true, nil
+	})
+}
+
+// rewrite passes the value of each row of a column to rewrite and stores
+// the values it replaces. Every row is locked while it is rewritten, in its
+// own transaction so progress is kept on failure, so that a value written by
+// the API in the meantime is rewritten rather than overwritten.
+func (r *EncryptionRepository) rewrite(ctx context.Context, table, column string, rewrite func(value []byte) (interface{}, bool, error)) (int, error) {
+	// Keys are compared as text since some tables have serial IDs
+	var ids []string
+	if err := r.db.WithContext(ctx).Table(table).Where(column+" IS NOT NULL").Pluck("id::text", &ids).Error; err != nil {
+		return 0, err
+	}
+
+	replaced := 0
+	for _, id := range ids {
+		changed := false
+		err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var value []byte
+			err := tx.Table(table).Select(column).
+				Where("id::text = ? AND "+column+" IS NOT NULL", id).
+				Clauses(clause.Locking{Strength: "UPDATE"}).
+				Row().Scan(&value)
+			if errors.Is(err, sql.ErrNoRows) {
+				// Deleted or cleared since the IDs were listed
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			rewritten, ok, err := rewrite(value)
+			if err != nil || !ok {
+				return err
+			}
+			changed = true
+			return tx.Table(table).Where("id::text = ?", id).Update(column, rewritten).Error
+		})
+		if err != nil {
+			return replaced, fmt.Errorf("%s %s: %w", table, id, err)
+		}
+		if changed {
+			replaced++
+		}
+	}
+	return replaced, nil
+}
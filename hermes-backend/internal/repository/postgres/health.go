@@ -118,6 +118,15 @@ func (r *HealthRepositoryGorm) GetHealthThresholds(ctx context.Context, serviceI
 	return thresholds, err
 }
 
+func (r *HealthRepositoryGorm) GetHealthThreshold(ctx context.Context, id uint) (*models.HealthThreshold, error) {
+	var threshold models.HealthThreshold
+	err := r.db.WithContext(ctx).First(&threshold, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &threshold, nil
+}
+
 func (r *HealthRepositoryGorm) UpdateHealthThreshold(ctx context.Context, threshold *models.HealthThreshold) error {
 	return r.db.WithContext(ctx).Save(threshold).Error
 }
@@ -0,0 +1,93 @@
+// internal/repository/postgres/namespace.go
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+// NamespaceRepository implements the repository.NamespaceRepository interface
+type NamespaceRepository struct {
+	db *gorm.DB
+}
+
+// NewNamespaceRepository creates a new NamespaceRepository
+func NewNamespaceRepository(db *gorm.DB) repository.NamespaceRepository {
+	return &NamespaceRepository{db: db}
+}
+
+// Create adds a new namespace to the database
+func (r *NamespaceRepository) Create(ctx context.Context, namespace *models.Namespace) error {
+	return r.db.WithContext(ctx).Create(namespace).Error
+}
+
+// GetByName retrieves a namespace by its name
+func (r *NamespaceRepository) GetByName(ctx context.Context, name string) (*models.Namespace, error) {
+	var namespace models.Namespace
+	if err := r.db.WithContext(ctx).Where("name = ?", name).First(&namespace).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &namespace, nil
+}
+
+// List retrieves all namespaces ordered by name
+func (r *NamespaceRepository) List(ctx context.Context) ([]*models.Namespace, error) {
+	var namespaces []*models.Namespace
+	err := r.db.WithContext(ctx).Order("name").Find(&namespaces).Error
+	return namespaces, err
+}
+
+// Update modifies an existing namespace
+func (r *NamespaceRepository) Update(ctx context.Context, namespace *models.Namespace) error {
+	return r.db.WithContext(ctx).Save(namespace).Error
+}
+
+// Delete removes a namespace by its name, together with its role bindings
+func (r *NamespaceRepository) Delete(ctx context.Context, name string) error {
+	return r.db.WithContext(ctx).Where("name = ?", name).Delete(&models.Namespace{}).Error
+}
+
+// CreateBinding adds a new role binding to the database
+func (r *NamespaceRepository) CreateBinding(ctx context.Context, binding *models.NamespaceBinding) error {
+	return r.db.WithContext(ctx).Create(binding).Error
+}
+
+// GetBinding retrieves a role binding by its ID
+func (r *NamespaceRepository) GetBinding(ctx context.Context, id string) (*models.NamespaceBinding, error) {
+	var binding models.NamespaceBinding
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&binding).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &binding, nil
+}
+
+// ListBindings retrieves the role bindings of a namespace
+func (r *NamespaceRepository) ListBindings(ctx context.Context, namespace string) ([]*models.NamespaceBinding, error) {
+	var bindings []*models.NamespaceBinding
+	err := r.db.WithContext(ctx).Where("namespace = ?", namespace).Order("created_at").Find(&bindings).Error
+	return bindings, err
+}
+
+// ListBindingsForUser retrieves the role bindings of a user, including those
+// of the teams the user belongs to
+func (r *NamespaceRepository) ListBindingsForUser(ctx context.Context, userID string) ([]*models.NamespaceBinding, error) {
+	var bindings []*models.NamespaceBinding
+	teams := r.db.Model(&models.TeamMember{}).Select("team_id").Where("user_id = ?", userID)
+	err := r.db.WithContext(ctx).Where("user_id = ? OR team_id IN (?)", userID, teams).Find(&bindings).Error
+	return bindings, err
+}
+
+// DeleteBinding removes a role binding by its ID
+func (r *NamespaceRepository) DeleteBinding(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.NamespaceBinding{}).Error
+}
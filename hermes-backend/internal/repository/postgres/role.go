@@ -0,0 +1,55 @@
+// internal/repository/postgres/role.go
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+// RoleRepository implements the repository.RoleRepository interface
+type RoleRepository struct {
+	db *gorm.DB
+}
+
+// NewRoleRepository creates a new RoleRepository
+func NewRoleRepository(db *gorm.DB) repository.RoleRepository {
+	return &RoleRepository{db: db}
+}
+
+// Create adds a new role to the database
+func (r *RoleRepository) Create(ctx context.Context, role *models.RoleDefinition) error {
+	return r.db.WithContext(ctx).Create(role).Error
+}
+
+// GetByName retrieves a role by its name
+func (r *RoleRepository) GetByName(ctx context.Context, name models.Role) (*models.RoleDefinition, error) {
+	var role models.RoleDefinition
+	if err := r.db.WithContext(ctx).Where("name = ?", name).First(&role).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &role, nil
+}
+
+// List retrieves all roles ordered by name
+func (r *RoleRepository) List(ctx context.Context) ([]*models.RoleDefinition, error) {
+	var roles []*models.RoleDefinition
+	err := r.db.WithContext(ctx).Order("name").Find(&roles).Error
+	return roles, err
+}
+
+// Update modifies an existing role
+func (r *RoleRepository) Update(ctx context.Context, role *models.RoleDefinition) error {
+	return r.db.WithContext(ctx).Save(role).Error
+}
+
+// Delete removes a role by its name
+func (r *RoleRepository) Delete(ctx context.Context, name models.Role) error {
+	return r.db.WithContext(ctx).Where("name = ?", name).Delete(&models.RoleDefinition{}).Error
+}
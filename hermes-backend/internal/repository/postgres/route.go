@@ -0,0 +1,85 @@
+// internal/repository/postgres/route.go
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+// RouteRepository implements the repository.RouteRepository interface
+type RouteRepository struct {
+	db *gorm.DB
+}
+
+// NewRouteRepository creates a new RouteRepository
+func NewRouteRepository(db *gorm.DB) repository.RouteRepository {
+	return &RouteRepository{db: db}
+}
+
+// Create adds a new route to the database
+func (r *RouteRepository) Create(ctx context.Context, route *models.Route) error {
+	return r.db.WithContext(ctx).Create(route).Error
+}
+
+// GetByID retrieves a route by its ID
+func (r *RouteRepository) GetByID(ctx context.Context, id string) (*models.Route, error) {
+	var route models.Route
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&route).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &route, nil
+}
+
+// GetByPath retrieves a route by its path
+func (r *RouteRepository) GetByPath(ctx context.Context, path string) (*models.Route, error) {
+	var route models.Route
+	if err := r.db.WithContext(ctx).Where("path = ?", path).First(&route).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &route, nil
+}
+
+// List retrieves all routes, or only those in the given namespaces
+func (r *RouteRepository) List(ctx context.Context, namespaces ...string) ([]*models.Route, error) {
+	var routes []*models.Route
+	query := r.db.WithContext(ctx)
+	if len(namespaces) > 0 {
+		query = query.Where("namespace IN ?", namespaces)
+	}
+	err := query.Order("path").Find(&routes).Error
+	return routes, err
+}
+
+// ListActive retrieves the routes the gateway should serve
+func (r *RouteRepository) ListActive(ctx context.Context) ([]*models.Route, error) {
+	var routes []*models.Route
+	err := r.db.WithContext(ctx).Where("active = ?", true).Find(&routes).Error
+	return routes, err
+}
+
+// Update modifies an existing route
+func (r *RouteRepository) Update(ctx context.Context, route *models.Route) error {
+	return r.db.WithContext(ctx).Save(route).Error
+}
+
+// Delete removes a route by its ID
+func (r *RouteRepository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.Route{}).Error
+}
+
+// CountByNamespace counts the routes in a namespace
+func (r *RouteRepository) CountByNamespace(ctx context.Context, namespace string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Route{}).Where("namespace = ?", namespace).Count(&count).Error
+	return count, err
+}
@@ -44,10 +44,10 @@ func (r *ServiceRepository) GetByID(ctx context.Context, id string) (*models.Ser
 	return &service, nil
 }
 
-// GetByName retrieves a service by its name
-func (r *ServiceRepository) GetByName(ctx context.Context, name string) (*models.Service, error) {
+// GetByName retrieves a service by its name within a namespace
+func (r *ServiceRepository) GetByName(ctx context.Context, namespace, name string) (*models.Service, error) {
 	var service models.Service
-	if err := r.db.WithContext(ctx).Where("name = ?", name).First(&service).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("namespace = ? AND name = ?", namespace, name).First(&service).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
@@ -56,6 +56,16 @@ func (r *ServiceRepository) GetByName(ctx context.Context, name string) (*models
 	return &service, nil
 }
 
+// GetByIDs retrieves the services with the given IDs
+func (r *ServiceRepository) GetByIDs(ctx context.Context, ids []string) ([]*models.Service, error) {
+	var services []*models.Service
+	if len(ids) == 0 {
+		return services, nil
+	}
+	err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&services).Error
+	return services, err
+}
+
 // List retrieves services with filtering and pagination
 func (r *ServiceRepository) List(ctx context.Context, params models.ServiceQueryParams) ([]*models.Service, int64, error) {
 	var services []*models.Service
@@ -76,6 +86,10 @@ func (r *ServiceRepository) List(ctx context.Context, params models.ServiceQuery
 	if params.Search != "" {
 		query = query.Where("name LIKE ?", "%"+params.Search+"%")
 	}
+	if !params.IncludeDeregistered {
+		query = query.Where("deregistered_at IS NULL")
+	}
+	query = applyVisibility(query, params)
 
 	// Count total number of records (for pagination)
 	if err := query.Count(&total).Error; err != nil {
@@ -105,9 +119,50 @@ func (r *ServiceRepository) UpdateStatus(ctx context.Context, id string, status
 	return r.db.WithContext(ctx).Model(&models.Service{}).Where("id = ?", id).Update("status", status).Error
 }
 
-// UpdateLastSeen updates the LastSeen timestamp of a service
-func (r *ServiceRepository) UpdateLastSeen(ctx context.Context, id string) error {
-	return r.db.WithContext(ctx).Model(&models.Service{}).Where("id = ?", id).Update("last_seen", time.Now()).Error
+// RecordHeartbeat marks a service as seen now with the reported status,
+// registering it again if it was deregistered. A zero TTL keeps the current one.
+func (r *ServiceRepository) RecordHeartbeat(ctx context.Context, id string, status models.ServiceStatus, ttl int) error {
+	updates := map[string]interface{}{
+		"last_seen":       time.Now(),
+		"status":          status,
+		"deregistered_at": nil,
+	}
+	if ttl > 0 {
+		updates["heartbeat_ttl"] = ttl
+	}
+	return r.db.WithContext(ctx).Model(&models.Service{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// ListMissedHeartbeats retrieves the registered services whose last heartbeat
+// is older than their TTL
+func (r *ServiceRepository) ListMissedHeartbeats(ctx context.Context, now time.Time) ([]*models.Service, error) {
+	var services []*models.Service
+	err := r.db.WithContext(ctx).
+		Where("heartbeat_ttl > 0 AND deregistered_at IS NULL").
+		Where("last_seen + heartbeat_ttl * INTERVAL '1 second' < ?", now).
+		Find(&services).Error
+	return services, err
+}
+
+// ExpireHeartbeat sets the status of a service that missed its heartbeats and
+// optionally deregisters it. Nothing changes if a heartbeat arrived since
+// lastSeen, which it reports.
+func (r *ServiceRepository) ExpireHeartbeat(ctx context.Context, id string, lastSeen time.Time, status models.ServiceStatus, deregister bool) (bool, error) {
+	updates := map[string]interface{}{"status": status}
+	if deregister {
+		updates["deregistered_at"] = time.Now()
+	}
+	result := r.db.WithContext(ctx).Model(&models.Service{}).
+		Where("id = ? AND last_seen = ? AND deregistered_at IS NULL", id, lastSeen).
+		Updates(updates)
+	return result.RowsAffected > 0, result.Error
+}
+
+// CountByNamespace counts the services in a namespace
+func (r *ServiceRepository) CountByNamespace(ctx context.Context, namespace string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Service{}).Where("namespace = ?", namespace).Count(&count).Error
+	return count, err
 }
 
 // internal/infrastructure/database/service_repository.go
@@ -138,6 +193,11 @@ func (r *ServiceRepository) AdvancedSearch(ctx context.Context, params models.Ad
 		query = query.Where("name ILIKE ? OR description ILIKE ?", "%"+params.Search+"%", "%"+params.Search+"%")
 	}
 
+	if !params.IncludeDeregistered {
+		query = query.Where("deregistered_at IS NULL")
+	}
+	query = applyVisibility(query, params.ServiceQueryParams)
+
 	// Apply health-aware filters
 	if len(params.HealthStatus) > 0 {
 		query = query.Where("status IN ?", params.HealthStatus)
@@ -260,6 +320,28 @@ func (r *ServiceRepository) AdvancedSearch(ctx context.Context, params models.Ad
 	return services, count, nil
 }
 
+// applyVisibility filters by owner team and namespace and restricts a query
+// to the services the viewer may see
+func applyVisibility(query *gorm.DB, params models.ServiceQueryParams) *gorm.DB {
+	if params.Owner != "" {
+		query = query.Where("owner_team_id = ?", params.Owner)
+	}
+	if params.Namespace != "" {
+		query = query.Where("namespace = ?", params.Namespace)
+	}
+	if params.Namespaces != nil {
+		// No namespace is named "", it keeps the list valid when none is permitted
+		query = query.Where("namespace IN ?", append([]string{""}, params.Namespaces...))
+	}
+	if params.AllVisible {
+		return query
+	}
+	if len(params.ViewerTeams) == 0 {
+		return query.Where("visibility <> ?", models.VisibilityPrivate)
+	}
+	return query.Where("(visibility <> ? OR owner_team_id IN ?)", models.VisibilityPrivate, params.ViewerTeams)
+}
+
 func (r *ServiceRepository) CreateVersion(ctx context.Context, version *models.ServiceVersion) error {
 	return r.db.WithContext(ctx).Create(version).Error
 }
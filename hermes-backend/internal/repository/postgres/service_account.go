@@ -0,0 +1,70 @@
+// internal/repository/postgres/service_account.go
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+// ServiceAccountRepository implements the repository.ServiceAccountRepository interface
+type ServiceAccountRepository struct {
+	db *gorm.DB
+}
+
+// NewServiceAccountRepository creates a new ServiceAccountRepository
+func NewServiceAccountRepository(db *gorm.DB) repository.ServiceAccountRepository {
+	return &ServiceAccountRepository{db: db}
+}
+
+// Create adds a new service account to the database
+func (r *ServiceAccountRepository) Create(ctx context.Context, account *models.ServiceAccount) error {
+	return r.db.WithContext(ctx).Create(account).Error
+}
+
+// GetByID retrieves a service account by its ID
+func (r *ServiceAccountRepository) GetByID(ctx context.Context, id string) (*models.ServiceAccount, error) {
+	return r.first(ctx, "id = ?", id)
+}
+
+// GetByName retrieves a service account of a service by name
+func (r *ServiceAccountRepository) GetByName(ctx context.Context, serviceID, name string) (*models.ServiceAccount, error) {
+	return r.first(ctx, "service_id = ? AND name = ?", serviceID, name)
+}
+
+// ListByService retrieves the service accounts of a service
+func (r *ServiceAccountRepository) ListByService(ctx context.Context, serviceID string) ([]*models.ServiceAccount, error) {
+	var accounts []*models.ServiceAccount
+	err := r.db.WithContext(ctx).Where("service_id = ?", serviceID).Order("name").Find(&accounts).Error
+	return accounts, err
+}
+
+// Update modifies an existing service account
+func (r *ServiceAccountRepository) Update(ctx context.Context, account *models.ServiceAccount) error {
+	return r.db.WithContext(ctx).Save(account).Error
+}
+
+// Delete removes a service account by its ID
+func (r *ServiceAccountRepository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.ServiceAccount{}).Error
+}
+
+// UpdateLastUsed records when a service account last minted a token
+func (r *ServiceAccountRepository) UpdateLastUsed(ctx context.Context, id string, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.ServiceAccount{}).Where("id = ?", id).Update("last_used_at", at).Error
+}
+
+func (r *ServiceAccountRepository) first(ctx context.Context, query string, args ...interface{}) (*models.ServiceAccount, error) {
+	var account models.ServiceAccount
+	if err := r.db.WithContext(ctx).Where(query, args...).First(&account).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &account, nil
+}
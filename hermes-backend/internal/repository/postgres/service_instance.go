@@ -0,0 +1,147 @@
+// internal/repository/postgres/service_instance.go
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+// ServiceInstanceRepository implements the repository.ServiceInstanceRepository interface
+type ServiceInstanceRepository struct {
+	db *gorm.DB
+}
+
+// NewServiceInstanceRepository creates a new ServiceInstanceRepository
+func NewServiceInstanceRepository(db *gorm.DB) repository.ServiceInstanceRepository {
+	return &ServiceInstanceRepository{db: db}
+}
+
+// Create adds a new service instance to the database
+func (r *ServiceInstanceRepository) Create(ctx context.Context, instance *models.ServiceInstance) error {
+	return r.db.WithContext(ctx).Create(instance).Error
+}
+
+// GetByID retrieves a service instance by its ID
+func (r *ServiceInstanceRepository) GetByID(ctx context.Context, id string) (*models.ServiceInstance, error) {
+	return r.first(ctx, "id = ?", id)
+}
+
+// GetByEndpoint retrieves the instance of a service at an endpoint
+func (r *ServiceInstanceRepository) GetByEndpoint(ctx context.Context, serviceID, endpoint string) (*models.ServiceInstance, error) {
+	return r.first(ctx, "service_id = ? AND endpoint = ?", serviceID, endpoint)
+}
+
+// ListByService retrieves the instances of a service
+func (r *ServiceInstanceRepository) ListByService(ctx context.Context, serviceID string, params models.InstanceQueryParams) ([]*models.ServiceInstance, error) {
+	var instances []*models.ServiceInstance
+
+	query := r.db.WithContext(ctx).Where("service_id = ?", serviceID)
+	if params.Status != "" {
+		query = query.Where("status = ?", params.Status)
+	}
+	if params.Zone != "" {
+		query = query.Where("zone = ?", params.Zone)
+	}
+	if params.Healthy {
+		query = query.Where("status IN ?", []models.ServiceStatus{models.ServiceStatusHealthy, models.ServiceStatusWarning})
+	}
+
+	err := query.Order("created_at").Find(&instances).Error
+	return instances, err
+}
+
+// ListByServices retrieves the instances of the given services
+func (r *ServiceInstanceRepository) ListByServices(ctx context.Context, serviceIDs []string) ([]*models.ServiceInstance, error) {
+	var instances []*models.ServiceInstance
+	if len(serviceIDs) == 0 {
+		return instances, nil
+	}
+	err := r.db.WithContext(ctx).Where("service_id IN ?", serviceIDs).Order("created_at").Find(&instances).Error
+	return instances, err
+}
+
+// Update modifies an existing service instance
+func (r *ServiceInstanceRepository) Update(ctx context.Context, instance *models.ServiceInstance) error {
+	return r.db.WithContext(ctx).Save(instance).Error
+}
+
+// Delete removes a service instance by its ID
+func (r *ServiceInstanceRepository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.ServiceInstance{}).Error
+}
+
+// UpdateStatus updates the health status of a service instance
+func (r *ServiceInstanceRepository) UpdateStatus(ctx context.Context, id string, status models.ServiceStatus) error {
+	return r.db.WithContext(ctx).Model(&models.ServiceInstance{}).Where("id = ?", id).Update("status", status).Error
+}
+
+// IncrementFailures counts a failed active health check of an instance and
+// returns its consecutive failures
+func (r *ServiceInstanceRepository) IncrementFailures(ctx context.Context, id string) (int, error) {
+	var count int
+	err := r.db.WithContext(ctx).
+		Raw("UPDATE service_instances SET failures = failures + 1 WHERE id = ? RETURNING failures", id).
+		Scan(&count).Error
+	return count, err
+}
+
+// ResetFailures clears the consecutive failed health checks of an instance
+func (r *ServiceInstanceRepository) ResetFailures(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Model(&models.ServiceInstance{}).Where("id = ?", id).Update("failures", 0).Error
+}
+
+// RecordHeartbeat marks an instance as seen now with the reported status. A
+// zero TTL keeps the current one.
+func (r *ServiceInstanceRepository) RecordHeartbeat(ctx context.Context, id string, status models.ServiceStatus, ttl int) error {
+	updates := map[string]interface{}{
+		"last_seen": time.Now(),
+		"status":    status,
+	}
+	if ttl > 0 {
+		updates["heartbeat_ttl"] = ttl
+	}
+	return r.db.WithContext(ctx).Model(&models.ServiceInstance{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// ListMissedHeartbeats retrieves the instances whose last heartbeat is older than their TTL
+func (r *ServiceInstanceRepository) ListMissedHeartbeats(ctx context.Context, now time.Time) ([]*models.ServiceInstance, error) {
+	var instances []*models.ServiceInstance
+	err := r.db.WithContext(ctx).
+		Where("heartbeat_ttl > 0").
+		Where("last_seen + heartbeat_ttl * INTERVAL '1 second' < ?", now).
+		Find(&instances).Error
+	return instances, err
+}
+
+// ExpireHeartbeat sets the status of an instance that missed its heartbeats.
+// Nothing changes if a heartbeat arrived since lastSeen, which it reports.
+func (r *ServiceInstanceRepository) ExpireHeartbeat(ctx context.Context, id string, lastSeen time.Time, status models.ServiceStatus) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.ServiceInstance{}).
+		Where("id = ? AND last_seen = ?", id, lastSeen).
+		Update("status", status)
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeleteExpired removes an instance that missed its heartbeats, unless a
+// heartbeat arrived since lastSeen, which it reports
+func (r *ServiceInstanceRepository) DeleteExpired(ctx context.Context, id string, lastSeen time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Where("id = ? AND last_seen = ?", id, lastSeen).Delete(&models.ServiceInstance{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// first retrieves the first service instance matching a condition
+func (r *ServiceInstanceRepository) first(ctx context.Context, query string, args ...interface{}) (*models.ServiceInstance, error) {
+	var instance models.ServiceInstance
+	if err := r.db.WithContext(ctx).Where(query, args...).First(&instance).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &instance, nil
+}
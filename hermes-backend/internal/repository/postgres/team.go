@@ -0,0 +1,91 @@
+// internal/repository/postgres/team.go
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TeamRepository implements the repository.TeamRepository interface
+type TeamRepository struct {
+	db *gorm.DB
+}
+
+// NewTeamRepository creates a new TeamRepository
+func NewTeamRepository(db *gorm.DB) repository.TeamRepository {
+	return &TeamRepository{db: db}
+}
+
+// Create adds a new team to the database
+func (r *TeamRepository) Create(ctx context.Context, team *models.Team) error {
+	return r.db.WithContext(ctx).Create(team).Error
+}
+
+// GetByID retrieves a team by its ID
+func (r *TeamRepository) GetByID(ctx context.Context, id string) (*models.Team, error) {
+	return r.first(ctx, "id = ?", id)
+}
+
+// GetByName retrieves a team by its name
+func (r *TeamRepository) GetByName(ctx context.Context, name string) (*models.Team, error) {
+	return r.first(ctx, "name = ?", name)
+}
+
+// List retrieves all teams ordered by name
+func (r *TeamRepository) List(ctx context.Context) ([]*models.Team, error) {
+	var teams []*models.Team
+	err := r.db.WithContext(ctx).Order("name").Find(&teams).Error
+	return teams, err
+}
+
+// Update modifies an existing team
+func (r *TeamRepository) Update(ctx context.Context, team *models.Team) error {
+	return r.db.WithContext(ctx).Save(team).Error
+}
+
+// Delete removes a team by its ID, together with its memberships
+func (r *TeamRepository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.Team{}).Error
+}
+
+// AddMember adds a user to a team, doing nothing if the user is already a member
+func (r *TeamRepository) AddMember(ctx context.Context, member *models.TeamMember) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(member).Error
+}
+
+// RemoveMember removes a user from a team
+func (r *TeamRepository) RemoveMember(ctx context.Context, teamID, userID string) error {
+	return r.db.WithContext(ctx).
+		Where("team_id = ? AND user_id = ?", teamID, userID).
+		Delete(&models.TeamMember{}).Error
+}
+
+// ListMembers retrieves the members of a team
+func (r *TeamRepository) ListMembers(ctx context.Context, teamID string) ([]*models.TeamMember, error) {
+	var members []*models.TeamMember
+	err := r.db.WithContext(ctx).Where("team_id = ?", teamID).Order("created_at").Find(&members).Error
+	return members, err
+}
+
+// ListTeamIDsForUser retrieves the IDs of the teams a user belongs to
+func (r *TeamRepository) ListTeamIDsForUser(ctx context.Context, userID string) ([]string, error) {
+	var ids []string
+	err := r.db.WithContext(ctx).Model(&models.TeamMember{}).Where("user_id = ?", userID).Pluck("team_id", &ids).Error
+	return ids, err
+}
+
+func (r *TeamRepository) first(ctx context.Context, query string, args ...interface{}) (*models.Team, error) {
+	var team models.Team
+	if err := r.db.WithContext(ctx).Where(query, args...).First(&team).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &team, nil
+}
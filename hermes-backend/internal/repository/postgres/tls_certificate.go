@@ -0,0 +1,67 @@
+// internal/repository/postgres/tls_certificate.go
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+// TLSCertificateRepository implements the repository.TLSCertificateRepository interface
+type TLSCertificateRepository struct {
+	db *gorm.DB
+}
+
+// NewTLSCertificateRepository creates a new TLSCertificateRepository
+func NewTLSCertificateRepository(db *gorm.DB) repository.TLSCertificateRepository {
+	return &TLSCertificateRepository{db: db}
+}
+
+// Create adds a new certificate to the database
+func (r *TLSCertificateRepository) Create(ctx context.Context, cert *models.TLSCertificate) error {
+	return r.db.WithContext(ctx).Create(cert).Error
+}
+
+// GetByID retrieves a certificate by its ID
+func (r *TLSCertificateRepository) GetByID(ctx context.Context, id string) (*models.TLSCertificate, error) {
+	var cert models.TLSCertificate
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&cert).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &cert, nil
+}
+
+// GetByName retrieves a certificate by its name
+func (r *TLSCertificateRepository) GetByName(ctx context.Context, name string) (*models.TLSCertificate, error) {
+	var cert models.TLSCertificate
+	if err := r.db.WithContext(ctx).Where("name = ?", name).First(&cert).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &cert, nil
+}
+
+// List retrieves all certificates, soonest to expire first
+func (r *TLSCertificateRepository) List(ctx context.Context) ([]*models.TLSCertificate, error) {
+	var certs []*models.TLSCertificate
+	err := r.db.WithContext(ctx).Order("not_after").Find(&certs).Error
+	return certs, err
+}
+
+// Update modifies an existing certificate
+func (r *TLSCertificateRepository) Update(ctx context.Context, cert *models.TLSCertificate) error {
+	return r.db.WithContext(ctx).Save(cert).Error
+}
+
+// Delete removes a certificate by its ID
+func (r *TLSCertificateRepository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.TLSCertificate{}).Error
+}
@@ -0,0 +1,214 @@
+// internal/repository/postgres/token.go
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+// TokenRepository implements the repository.TokenRepository interface
+type TokenRepository struct {
+	db *gorm.DB
+}
+
+// NewTokenRepository creates a new TokenRepository
+func NewTokenRepository(db *gorm.DB) repository.TokenRepository {
+	return &TokenRepository{db: db}
+}
+
+// CreateSigningKey stores a new signing key
+func (r *TokenRepository) CreateSigningKey(ctx context.Context, key *models.SigningKey) error {
+	return r.db.WithContext(ctx).Create(key).Error
+}
+
+// ListSigningKeys retrieves the signing keys that have not expired
+func (r *TokenRepository) ListSigningKeys(ctx context.Context) ([]*models.SigningKey, error) {
+	var keys []*models.SigningKey
+	err := r.db.WithContext(ctx).Where("expires_at > ?", time.Now()).Order("created_at").Find(&keys).Error
+	return keys, err
+}
+
+// RetireSigningKeys stops every key that is still signing from signing after
+// at and from verifying after expiresAt
+func (r *TokenRepository) RetireSigningKeys(ctx context.Context, at, expiresAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.SigningKey{}).
+		Where("retires_at > ?", at).
+		Updates(map[string]interface{}{"retires_at": at, "expires_at": expiresAt}).Error
+}
+
+// DeleteExpiredSigningKeys removes keys that no longer verify any token
+func (r *TokenRepository) DeleteExpiredSigningKeys(ctx context.Context) error {
+	return r.db.WithContext(ctx).Where("expires_at <= ?", time.Now()).Delete(&models.SigningKey{}).Error
+}
+
+// CreateRefreshToken stores a new refresh token
+func (r *TokenRepository) CreateRefreshToken(ctx context.Context, token *models.RefreshToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+// GetRefreshTokenByHash retrieves a refresh token by the hash of its value
+func (r *TokenRepository) GetRefreshTokenByHash(ctx context.Context, hash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	if err := r.db.WithContext(ctx).Where("token_hash = ?", hash).First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// RotateRefreshToken revokes a refresh token in favour of its replacement.
+// It reports false if the token had already been revoked, e.g. by a concurrent refresh.
+func (r *TokenRepository) RotateRefreshToken(ctx context.Context, id, replacedBy string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{"revoked_at": time.Now(), "replaced_by": replacedBy})
+	return result.RowsAffected == 1, result.Error
+}
+
+// RevokeRefreshTokenFamily revokes every token descending from the same login
+func (r *TokenRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	return r.db.WithContext(ctx).Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// DeleteExpiredRefreshTokens removes refresh tokens that can no longer be used
+func (r *TokenRepository) DeleteExpiredRefreshTokens(ctx context.Context) error {
+	return r.db.WithContext(ctx).Where("expires_at <= ?", time.Now()).Delete(&models.RefreshToken{}).Error
+}
+
+// CreateSession stores a new session
+func (r *TokenRepository) CreateSession(ctx context.Context, session *models.Session) error {
+	return r.db.WithContext(ctx).Create(session).Error
+}
+
+// GetSession retrieves a session by its ID
+func (r *TokenRepository) GetSession(ctx context.Context, id string) (*models.Session, error) {
+	var session models.Session
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+// ListUserSessions retrieves the sessions of a user that are neither revoked
+// nor expired, most recently used first
+func (r *TokenRepository) ListUserSessions(ctx context.Context, userID string) ([]*models.Session, error) {
+	var sessions []*models.Session
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// TouchSession records the use of a session and extends it
+func (r *TokenRepository) TouchSession(ctx context.Context, id string, lastSeen, expiresAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.Session{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"last_seen_at": lastSeen, "expires_at": expiresAt}).Error
+}
+
+// RevokeSession revokes a session and its refresh tokens
+func (r *TokenRepository) RevokeSession(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(&models.Session{}).Where("id = ? AND revoked_at IS NULL", id).
+			Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.RefreshToken{}).Where("family_id = ? AND revoked_at IS NULL", id).
+			Update("revoked_at", now).Error
+	})
+}
+
+// RevokeUserSessions revokes every session and refresh token of a user but
+// the session keepID, if any, and returns the IDs of the sessions that were active
+func (r *TokenRepository) RevokeUserSessions(ctx context.Context, userID, keepID string) ([]string, error) {
+	var ids []string
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(&models.Session{}).
+			Where("user_id = ? AND id <> ? AND revoked_at IS NULL AND expires_at > ?", userID, keepID, now).
+			Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Session{}).Where("user_id = ? AND id <> ? AND revoked_at IS NULL", userID, keepID).
+			Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.RefreshToken{}).Where("user_id = ? AND family_id <> ? AND revoked_at IS NULL", userID, keepID).
+			Update("revoked_at", now).Error
+	})
+	return ids, err
+}
+
+// DeleteExpiredSessions removes sessions that can no longer be refreshed
+func (r *TokenRepository) DeleteExpiredSessions(ctx context.Context) error {
+	return r.db.WithContext(ctx).Where("expires_at <= ?", time.Now()).Delete(&models.Session{}).Error
+}
+
+// CreateMFAChallenge stores a login waiting for its second factor
+func (r *TokenRepository) CreateMFAChallenge(ctx context.Context, challenge *models.MFAChallenge) error {
+	return r.db.WithContext(ctx).Create(challenge).Error
+}
+
+// GetMFAChallengeByHash retrieves a challenge by the hash of its token
+func (r *TokenRepository) GetMFAChallengeByHash(ctx context.Context, hash string) (*models.MFAChallenge, error) {
+	var challenge models.MFAChallenge
+	if err := r.db.WithContext(ctx).Where("token_hash = ?", hash).First(&challenge).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &challenge, nil
+}
+
+// RecordMFAChallengeAttempt counts a wrong second factor and returns the
+// attempts made on the challenge
+func (r *TokenRepository) RecordMFAChallengeAttempt(ctx context.Context, id string) (int, error) {
+	var attempts int
+	err := r.db.WithContext(ctx).
+		Raw("UPDATE mfa_challenges SET attempts = attempts + 1 WHERE id = ? RETURNING attempts", id).
+		Scan(&attempts).Error
+	return attempts, err
+}
+
+// DeleteMFAChallenge removes a challenge. It reports false if it was already
+// removed, e.g. by a concurrent login completing it.
+func (r *TokenRepository) DeleteMFAChallenge(ctx context.Context, id string) (bool, error) {
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.MFAChallenge{})
+	return result.RowsAffected == 1, result.Error
+}
+
+// DeleteExpiredMFAChallenges removes challenges that were never completed
+func (r *TokenRepository) DeleteExpiredMFAChallenges(ctx context.Context) error {
+	return r.db.WithContext(ctx).Where("expires_at <= ?", time.Now()).Delete(&models.MFAChallenge{}).Error
+}
+
+// CreateRevokedToken adds an access token or session to the revocation list
+func (r *TokenRepository) CreateRevokedToken(ctx context.Context, token *models.RevokedToken) error {
+	return r.db.WithContext(ctx).Save(token).Error
+}
+
+// ListRevokedTokens retrieves the revoked access tokens that have not expired
+func (r *TokenRepository) ListRevokedTokens(ctx context.Context) ([]*models.RevokedToken, error) {
+	var tokens []*models.RevokedToken
+	err := r.db.WithContext(ctx).Where("expires_at > ?", time.Now()).Find(&tokens).Error
+	return tokens, err
+}
+
+// DeleteExpiredRevokedTokens removes revocations of tokens that have expired anyway
+func (r *TokenRepository) DeleteExpiredRevokedTokens(ctx context.Context) error {
+	return r.db.WithContext(ctx).Where("expires_at <= ?", time.Now()).Delete(&models.RevokedToken{}).Error
+}
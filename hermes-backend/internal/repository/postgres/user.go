@@ -0,0 +1,123 @@
+// internal/repository/postgres/user.go
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+// UserRepository implements the repository.UserRepository interface
+type UserRepository struct {
+	db *gorm.DB
+}
+
+// NewUserRepository creates a new UserRepository
+func NewUserRepository(db *gorm.DB) repository.UserRepository {
+	return &UserRepository{db: db}
+}
+
+// Create adds a new user to the database
+func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
+	return r.db.WithContext(ctx).Create(user).Error
+}
+
+// GetByID retrieves a user by its ID
+func (r *UserRepository) GetByID(ctx context.Context, id string) (*models.User, error) {
+	return r.first(ctx, "id = ?", id)
+}
+
+// GetByUsername retrieves a user by username
+func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	return r.first(ctx, "username = ?", username)
+}
+
+// GetByEmail retrieves a user by email address, ignoring case
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	return r.first(ctx, "LOWER(email) = LOWER(?)", email)
+}
+
+// GetByExternalID retrieves a user by their single sign-on identity
+func (r *UserRepository) GetByExternalID(ctx context.Context, externalID string) (*models.User, error) {
+	return r.first(ctx, "external_id = ?", externalID)
+}
+
+// Count returns the number of users
+func (r *UserRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.User{}).Count(&count).Error
+	return count, err
+}
+
+// CountByRole returns the number of users with a role
+func (r *UserRepository) CountByRole(ctx context.Context, role models.Role) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.User{}).Where("role = ?", role).Count(&count).Error
+	return count, err
+}
+
+// Update modifies an existing user
+func (r *UserRepository) Update(ctx context.Context, user *models.User) error {
+	return r.db.WithContext(ctx).Save(user).Error
+}
+
+// RecordLogin records when a user last logged in and clears their failed logins
+func (r *UserRepository) RecordLogin(ctx context.Context, id string, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"last_login": at, "failed_logins": 0, "locked_until": nil}).Error
+}
+
+// RecordFailedLogin counts a failed login of a user and returns their failed
+// logins since the last successful one
+func (r *UserRepository) RecordFailedLogin(ctx context.Context, id string) (int, error) {
+	var count int
+	err := r.db.WithContext(ctx).
+		Raw("UPDATE users SET failed_logins = failed_logins + 1 WHERE id = ? RETURNING failed_logins", id).
+		Scan(&count).Error
+	return count, err
+}
+
+// Lock rejects logins of a user until the given time and clears their failed logins
+func (r *UserRepository) Lock(ctx context.Context, id string, until time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"locked_until": until, "failed_logins": 0}).Error
+}
+
+// Unlock lifts the lockout of a user
+func (r *UserRepository) Unlock(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"locked_until": nil, "failed_logins": 0}).Error
+}
+
+// UseTOTPStep marks a TOTP time step as used. It reports false if the step,
+// or a later one, was already used, so each code is only accepted once.
+func (r *UserRepository) UseTOTPStep(ctx context.Context, id string, step int64) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ? AND mfa_last_step < ?", id, step).
+		Update("mfa_last_step", step)
+	return result.RowsAffected == 1, result.Error
+}
+
+// UseRecoveryCode removes a recovery code by its hash. It reports false if
+// the user has no such unused code.
+func (r *UserRepository) UseRecoveryCode(ctx context.Context, id, hash string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ? AND ? = ANY(recovery_codes)", id, hash).
+		Update("recovery_codes", gorm.Expr("array_remove(recovery_codes, ?)", hash))
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *UserRepository) first(ctx context.Context, query string, args ...interface{}) (*models.User, error) {
+	var user models.User
+	if err := r.db.WithContext(ctx).Where(query, args...).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &user, nil
+}
@@ -0,0 +1,287 @@
+package security
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultTrustDomain is the SPIFFE trust domain used when none is configured
+const DefaultTrustDomain = "hermes"
+
+// defaultCertTTL is the lifetime of workload certificates when none is configured
+const defaultCertTTL = 24 * time.Hour
+
+// caLifetime is the lifetime of a generated root certificate
+const caLifetime = 10 * 365 * 24 * time.Hour
+
+// CAConfig configures the certificate authority
+type CAConfig struct {
+	TrustDomain string        // SPIFFE trust domain, defaults to "hermes"
+	CertFile    string        // PEM root certificate, generated when missing
+	KeyFile     string        // PEM root private key, generated when missing
+	CertTTL     time.Duration // Lifetime of issued workload certificates, defaults to 24h
+}
+
+// WorkloadCertificate is a certificate issued to a workload together with the
+// trust bundle needed to verify its peers
+type WorkloadCertificate struct {
+	SpiffeID    string    `json:"spiffe_id"`
+	Certificate string    `json:"certificate"`           // PEM
+	PrivateKey  string    `json:"private_key,omitempty"` // PEM, empty when issued for a CSR
+	TrustBundle string    `json:"trust_bundle"`          // PEM
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// CertificateAuthority issues short-lived workload certificates identified by
+// a SPIFFE ID in their URI SAN
+type CertificateAuthority struct {
+	cert        *x509.Certificate
+	key         crypto.Signer
+	bundle      string
+	trustDomain string
+	ttl         time.Duration
+}
+
+// NewCertificateAuthority loads the root certificate and key from disk,
+// generating and saving a new root when they don't exist yet
+func NewCertificateAuthority(config CAConfig) (*CertificateAuthority, error) {
+	if config.CertFile == "" || config.KeyFile == "" {
+		return nil, errors.New("CA certificate and key files are required")
+	}
+	if config.TrustDomain == "" {
+		config.TrustDomain = DefaultTrustDomain
+	}
+	if config.CertTTL <= 0 {
+		config.CertTTL = defaultCertTTL
+	}
+
+	certPEM, keyPEM, err := loadOrCreateRoot(config)
+	if err != nil {
+		return nil, err
+	}
+
+	cert, err := parseCertificatePEM(certPEM)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CA certificate: %w", err)
+	}
+	key, err := parsePrivateKeyPEM(keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CA key: %w", err)
+	}
+
+	return &CertificateAuthority{
+		cert:        cert,
+		key:         key,
+		bundle:      string(certPEM),
+		trustDomain: config.TrustDomain,
+		ttl:         config.CertTTL,
+	}, nil
+}
+
+// TrustBundle returns the PEM encoded root certificate
+func (ca *CertificateAuthority) TrustBundle() string {
+	return ca.bundle
+}
+
+// ServiceSpiffeID returns the SPIFFE ID of a registered service
+func (ca *CertificateAuthority) ServiceSpiffeID(serviceID string) string {
+	return ServiceSpiffeID(ca.trustDomain, serviceID)
+}
+
+// GatewaySpiffeID returns the SPIFFE ID of the Hermes gateway
+func (ca *CertificateAuthority) GatewaySpiffeID() string {
+	return (&url.URL{Scheme: "spiffe", Host: ca.trustDomain, Path: "/gateway"}).String()
+}
+
+// Issue issues a workload certificate for spiffeID. When csrPEM is empty a new
+// key pair is generated and returned with the certificate; otherwise the
+// public key of the CSR is certified. The identity is always the one chosen
+// by the caller, never the subject or SANs requested in the CSR.
+func (ca *CertificateAuthority) Issue(spiffeID string, csrPEM string) (*WorkloadCertificate, error) {
+	id, err := url.Parse(spiffeID)
+	if err != nil || id.Scheme != "spiffe" || id.Host != ca.trustDomain {
+		return nil, fmt.Errorf("invalid SPIFFE ID: %s", spiffeID)
+	}
+
+	var public crypto.PublicKey
+	var keyPEM []byte
+	if csrPEM == "" {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate key: %w", err)
+		}
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode key: %w", err)
+		}
+		public = key.Public()
+		keyPEM = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	} else {
+		block, _ := pem.Decode([]byte(csrPEM))
+		if block == nil || block.Type != "CERTIFICATE REQUEST" {
+			return nil, errors.New("invalid certificate signing request")
+		}
+		csr, err := x509.ParseCertificateRequest(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid certificate signing request: %w", err)
+		}
+		if err := csr.CheckSignature(); err != nil {
+			return nil, fmt.Errorf("invalid certificate signing request signature: %w", err)
+		}
+		public = csr.PublicKey
+	}
+
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	notAfter := now.Add(ca.ttl)
+	if notAfter.After(ca.cert.NotAfter) {
+		notAfter = ca.cert.NotAfter
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{Organization: []string{"Hermes"}},
+		URIs:         []*url.URL{id},
+		NotBefore:    now.Add(-time.Minute), // tolerate clock skew between workloads
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, public, ca.key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign certificate: %w", err)
+	}
+
+	return &WorkloadCertificate{
+		SpiffeID:    spiffeID,
+		Certificate: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		PrivateKey:  string(keyPEM),
+		TrustBundle: ca.bundle,
+		ExpiresAt:   notAfter,
+	}, nil
+}
+
+// ServiceSpiffeID returns the SPIFFE ID of a service in a trust domain
+func ServiceSpiffeID(trustDomain, serviceID string) string {
+	return (&url.URL{Scheme: "spiffe", Host: trustDomain, Path: "/service/" + serviceID}).String()
+}
+
+// loadOrCreateRoot reads the root certificate and key, creating them if neither exists
+func loadOrCreateRoot(config CAConfig) ([]byte, []byte, error) {
+	certPEM, certErr := os.ReadFile(config.CertFile)
+	keyPEM, keyErr := os.ReadFile(config.KeyFile)
+	if certErr == nil && keyErr == nil {
+		return certPEM, keyPEM, nil
+	}
+	if !os.IsNotExist(certErr) || !os.IsNotExist(keyErr) {
+		return nil, nil, errors.New("CA certificate and key must either both exist or both be missing")
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate CA key: %w", err)
+	}
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"Hermes"}, CommonName: "Hermes Root CA"},
+		URIs:                  []*url.URL{{Scheme: "spiffe", Host: config.TrustDomain}},
+		NotBefore:             now.Add(-time.Minute),
+		NotAfter:              now.Add(caLifetime),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create CA certificate: %w", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode CA key: %w", err)
+	}
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+
+	for _, dir := range []string{filepath.Dir(config.CertFile), filepath.Dir(config.KeyFile)} {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return nil, nil, fmt.Errorf("failed to create CA directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(config.KeyFile, keyPEM, 0o600); err != nil {
+		return nil, nil, fmt.Errorf("failed to write CA key: %w", err)
+	}
+	if err := os.WriteFile(config.CertFile, certPEM, 0o644); err != nil {
+		return nil, nil, fmt.Errorf("failed to write CA certificate: %w", err)
+	}
+
+	return certPEM, keyPEM, nil
+}
+
+func randomSerial() (*big.Int, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+	return serial, nil
+}
+
+func parseCertificatePEM(data []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("no certificate found")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+func parsePrivateKeyPEM(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no private key found")
+	}
+
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported private key type: %s", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("private key cannot sign")
+	}
+	return signer, nil
+}
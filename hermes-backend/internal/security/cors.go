@@ -0,0 +1,263 @@
+package security
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+)
+
+// defaultCORSMethods are allowed when a policy names no methods
+var defaultCORSMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost}
+
+// originPattern is an allowed origin. Wildcard patterns match any subdomain
+// in place of the * and nothing else.
+type originPattern struct {
+	prefix string // the whole origin unless wildcard
+	suffix string
+	wild   bool
+}
+
+// CORS answers cross-origin requests according to a policy
+type CORS struct {
+	anyOrigin   bool
+	origins     []originPattern
+	methods     map[string]bool
+	anyHeader   bool
+	headers     map[string]bool
+	credentials bool
+
+	allowMethods  string
+	allowHeaders  string
+	exposeHeaders string
+	maxAge        string
+}
+
+// NewCORS compiles a CORS policy
+func NewCORS(policy models.CORSPolicy) (*CORS, error) {
+	c := &CORS{
+		methods:     make(map[string]bool),
+		headers:     make(map[string]bool),
+		credentials: policy.AllowCredentials,
+	}
+
+	for _, origin := range policy.AllowedOrigins {
+		origin = strings.ToLower(strings.TrimSpace(origin))
+		if origin == "*" {
+			c.anyOrigin = true
+			continue
+		}
+		pattern, err := parseOriginPattern(origin)
+		if err != nil {
+			return nil, err
+		}
+		c.origins = append(c.origins, pattern)
+	}
+	if c.anyOrigin && c.credentials {
+		return nil, fmt.Errorf("credentials cannot be allowed for every origin, list the allowed origins instead")
+	}
+
+	methods := policy.AllowedMethods
+	if len(methods) == 0 {
+		methods = defaultCORSMethods
+	}
+	names := make([]string, 0, len(methods))
+	for _, method := range methods {
+		method = strings.ToUpper(strings.TrimSpace(method))
+		if method == "" || strings.ContainsAny(method, " ,*") {
+			return nil, fmt.Errorf("invalid CORS method: %q", method)
+		}
+		if !c.methods[method] {
+			c.methods[method] = true
+			names = append(names, method)
+		}
+	}
+	c.allowMethods = strings.Join(names, ", ")
+
+	names = make([]string, 0, len(policy.AllowedHeaders))
+	for _, header := range policy.AllowedHeaders {
+		header = strings.TrimSpace(header)
+		if header == "*" {
+			c.anyHeader = true
+			continue
+		}
+		if header == "" || strings.ContainsAny(header, " ,") {
+			return nil, fmt.Errorf("invalid CORS header: %q", header)
+		}
+		c.headers[strings.ToLower(header)] = true
+		names = append(names, http.CanonicalHeaderKey(header))
+	}
+	c.allowHeaders = strings.Join(names, ", ")
+
+	for _, header := range policy.ExposedHeaders {
+		if header = strings.TrimSpace(header); header == "" || strings.ContainsAny(header, " ,") {
+			return nil, fmt.Errorf("invalid CORS exposed header: %q", header)
+		}
+	}
+	c.exposeHeaders = strings.Join(policy.ExposedHeaders, ", ")
+
+	if policy.MaxAge < 0 {
+		return nil, fmt.Errorf("CORS max age must not be negative")
+	}
+	if policy.MaxAge > 0 {
+		c.maxAge = strconv.Itoa(policy.MaxAge)
+	}
+	return c, nil
+}
+
+// Handle adds the CORS headers of a request's response. It answers
+// preflight requests itself and reports whether it did, in which case the
+// request must not be handled further. Requests from origins the policy does
+// not allow get no CORS headers, so browsers keep their responses from scripts.
+func (c *CORS) Handle(w http.ResponseWriter, r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+	header := w.Header()
+	if !c.anyOrigin || c.credentials {
+		header.Add("Vary", "Origin")
+	}
+	if origin == "" {
+		return false
+	}
+
+	if !c.AllowsOrigin(origin) {
+		if preflight {
+			w.WriteHeader(http.StatusForbidden)
+			return true
+		}
+		return false
+	}
+
+	if !preflight {
+		c.allowOrigin(header, origin)
+		if c.exposeHeaders != "" {
+			header.Set("Access-Control-Expose-Headers", c.exposeHeaders)
+		}
+		return false
+	}
+
+	header.Add("Vary", "Access-Control-Request-Method")
+	header.Add("Vary", "Access-Control-Request-Headers")
+	method := r.Header.Get("Access-Control-Request-Method")
+	requested := requestedHeaders(r.Header.Get("Access-Control-Request-Headers"))
+	if !c.methods[method] || !c.allowsHeaders(requested) {
+		w.WriteHeader(http.StatusForbidden)
+		return true
+	}
+
+	c.allowOrigin(header, origin)
+	header.Set("Access-Control-Allow-Methods", c.allowMethods)
+	if c.anyHeader {
+		// A literal * is not honored for requests with credentials, so the
+		// requested headers are echoed instead
+		if len(requested) > 0 {
+			header.Set("Access-Control-Allow-Headers", strings.Join(requested, ", "))
+		}
+	} else if c.allowHeaders != "" {
+		header.Set("Access-Control-Allow-Headers", c.allowHeaders)
+	}
+	if c.maxAge != "" {
+		header.Set("Access-Control-Max-Age", c.maxAge)
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return true
+}
+
+// AllowsOrigin reports whether the policy allows requests from origin
+func (c *CORS) AllowsOrigin(origin string) bool {
+	if c.anyOrigin {
+		return true
+	}
+	origin = strings.ToLower(origin)
+	for _, pattern := range c.origins {
+		if pattern.matches(origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowOrigin adds the headers letting origin read the response
+func (c *CORS) allowOrigin(header http.Header, origin string) {
+	if c.anyOrigin && !c.credentials {
+		header.Set("Access-Control-Allow-Origin", "*")
+		return
+	}
+	header.Set("Access-Control-Allow-Origin", origin)
+	if c.credentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// allowsHeaders reports whether every requested header is allowed
+func (c *CORS) allowsHeaders(requested []string) bool {
+	if c.anyHeader {
+		return true
+	}
+	for _, header := range requested {
+		if !c.headers[header] {
+			return false
+		}
+	}
+	return true
+}
+
+// StripCORSHeaders removes the CORS headers of a response, for example an
+// upstream's when the gateway answers cross-origin requests itself
+func StripCORSHeaders(header http.Header) {
+	for key := range header {
+		if strings.HasPrefix(key, "Access-Control-") {
+			delete(header, key)
+		}
+	}
+}
+
+// requestedHeaders splits the headers a preflight request asks for
+func requestedHeaders(value string) []string {
+	var headers []string
+	for _, header := range strings.Split(value, ",") {
+		if header = strings.ToLower(strings.TrimSpace(header)); header != "" {
+			headers = append(headers, header)
+		}
+	}
+	return headers
+}
+
+// parseOriginPattern parses an allowed origin such as https://app.example.com
+// or https://*.example.com
+func parseOriginPattern(origin string) (originPattern, error) {
+	if strings.Count(origin, "*") > 1 {
+		return originPattern{}, fmt.Errorf("invalid CORS origin %q: only one wildcard is allowed", origin)
+	}
+	u, err := url.Parse(strings.Replace(origin, "*", "wildcard", 1))
+	if err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" {
+		return originPattern{}, fmt.Errorf("invalid CORS origin %q: expected scheme://host[:port]", origin)
+	}
+	origin = strings.TrimSuffix(origin, "/")
+
+	prefix, suffix, wild := strings.Cut(origin, "*")
+	if wild && (!strings.HasSuffix(prefix, "://") || !strings.HasPrefix(suffix, ".")) {
+		return originPattern{}, fmt.Errorf("invalid CORS origin %q: the wildcard must be the leading subdomain", origin)
+	}
+	return originPattern{prefix: prefix, suffix: suffix, wild: wild}, nil
+}
+
+// matches reports whether a lowercase origin matches the pattern
+func (p originPattern) matches(origin string) bool {
+	if !p.wild {
+		return origin == p.prefix
+	}
+	if len(origin) <= len(p.prefix)+len(p.suffix) || !strings.HasPrefix(origin, p.prefix) || !strings.HasSuffix(origin, p.suffix) {
+		return false
+	}
+	subdomain := origin[len(p.prefix) : len(origin)-len(p.suffix)]
+	for _, r := range subdomain {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '.') {
+			return false
+		}
+	}
+	return !strings.HasPrefix(subdomain, ".") && !strings.HasSuffix(subdomain, ".")
+}
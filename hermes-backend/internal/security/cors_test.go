@@ -0,0 +1,54 @@
+package security
+
+import (
+	"testing"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+)
+
+func TestCORSAllowsOrigin(t *testing.T) {
+	policy, err := NewCORS(models.CORSPolicy{AllowedOrigins: []string{"https://app.example.com", "https://*.example.org"}})
+	if err != nil {
+		t.Fatalf("NewCORS: %v", err)
+	}
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{"https://app.example.com", true},
+		{"HTTPS://APP.EXAMPLE.COM", true},
+		{"http://app.example.com", false},
+		{"https://app.example.com:8443", false},
+		{"https://other.example.com", false},
+		{"https://a.example.org", true},
+		{"https://a.b.example.org", true},
+		{"https://example.org", false},
+		{"https://.example.org", false},
+		{"https://evil.com/.example.org", false},
+		{"https://evilexample.org", false},
+		{"null", false},
+	}
+	for _, tt := range tests {
+		if got := policy.AllowsOrigin(tt.origin); got != tt.want {
+			t.Errorf("AllowsOrigin(%q) = %v, want %v", tt.origin, got, tt.want)
+		}
+	}
+}
+
+func TestNewCORSRejectsInvalidPolicies(t *testing.T) {
+	tests := map[string]models.CORSPolicy{
+		"credentials for every origin": {AllowedOrigins: []string{"*"}, AllowCredentials: true},
+		"origin without scheme":        {AllowedOrigins: []string{"app.example.com"}},
+		"origin with path":             {AllowedOrigins: []string{"https://app.example.com/app"}},
+		"two wildcards":                {AllowedOrigins: []string{"https://*.*.example.com"}},
+		"inner wildcard":               {AllowedOrigins: []string{"https://app.*.com"}},
+		"method list":                  {AllowedMethods: []string{"GET, POST"}},
+		"header list":                  {AllowedHeaders: []string{"X-A, X-B"}},
+		"negative max age":             {MaxAge: -1},
+	}
+	for name, policy := range tests {
+		if _, err := NewCORS(policy); err == nil {
+			t.Errorf("%s: NewCORS accepted %+v", name, policy)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package security
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ipRule is one CIDR of an access list, with the text it was written as
+type ipRule struct {
+	text    string
+	network *net.IPNet
+}
+
+// IPRules decides which client addresses may pass. Deny rules win over
+// allow rules, and when there are allow rules only addresses matching one
+// of them pass.
+type IPRules struct {
+	allow []ipRule
+	deny  []ipRule
+}
+
+// ParseIPRules parses allow and deny lists of CIDRs or single addresses
+func ParseIPRules(allow, deny []string) (*IPRules, error) {
+	rules := &IPRules{}
+	var err error
+	if rules.allow, err = parseIPRules(allow); err != nil {
+		return nil, err
+	}
+	if rules.deny, err = parseIPRules(deny); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// Empty reports whether the rules let every address pass
+func (r *IPRules) Empty() bool {
+	return r == nil || (len(r.allow) == 0 && len(r.deny) == 0)
+}
+
+// Check reports whether ip may pass and describes the rule that decided,
+// which is empty when no rule applied
+func (r *IPRules) Check(ip net.IP) (bool, string) {
+	if r.Empty() {
+		return true, ""
+	}
+	if ip == nil {
+		return false, "unknown client address"
+	}
+	for _, rule := range r.deny {
+		if rule.network.Contains(ip) {
+			return false, "deny " + rule.text
+		}
+	}
+	if len(r.allow) == 0 {
+		return true, ""
+	}
+	for _, rule := range r.allow {
+		if rule.network.Contains(ip) {
+			return true, "allow " + rule.text
+		}
+	}
+	return false, "not in allow list"
+}
+
+// ParseCIDRs parses a list of CIDRs or single addresses
+func ParseCIDRs(cidrs []string) ([]*net.IPNet, error) {
+	rules, err := parseIPRules(cidrs)
+	if err != nil {
+		return nil, err
+	}
+	networks := make([]*net.IPNet, len(rules))
+	for i, rule := range rules {
+		networks[i] = rule.network
+	}
+	return networks, nil
+}
+
+// ClientIP returns the address of the client that sent a request. The peer
+// address is used unless the peer is a trusted proxy, in which case
+// X-Forwarded-For is walked from the right, past the trusted proxies, to the
+// first address that was not added by one of them.
+func ClientIP(remoteAddr string, forwardedFor []string, trusted []*net.IPNet) net.IP {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !TrustedIP(trusted, ip) {
+		return ip
+	}
+
+	var hops []string
+	for _, header := range forwardedFor {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := net.ParseIP(strings.TrimSpace(hops[i]))
+		if hop == nil {
+			// A malformed entry cannot be traced further, trust what was verified
+			return ip
+		}
+		ip = hop
+		if !TrustedIP(trusted, hop) {
+			break
+		}
+	}
+	return ip
+}
+
+// TrustedIP reports whether ip is in one of networks
+func TrustedIP(networks []*net.IPNet, ip net.IP) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseIPRules parses CIDRs, treating a single address as a network of one
+func parseIPRules(cidrs []string) ([]ipRule, error) {
+	rules := make([]ipRule, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		cidr = strings.TrimSpace(cidr)
+		if !strings.Contains(cidr, "/") {
+			ip := net.ParseIP(cidr)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP address or CIDR: %q", cidr)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			rules = append(rules, ipRule{text: cidr, network: &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IP address or CIDR: %q", cidr)
+		}
+		rules = append(rules, ipRule{text: cidr, network: network})
+	}
+	return rules, nil
+}
@@ -0,0 +1,92 @@
+package security
+
+import (
+	"net"
+	"testing"
+)
+
+func TestIPRulesCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		allow   []string
+		deny    []string
+		ip      string
+		allowed bool
+		rule    string
+	}{
+		{"no rules", nil, nil, "203.0.113.7", true, ""},
+		{"allowed network", []string{"10.0.0.0/8"}, nil, "10.1.2.3", true, "allow 10.0.0.0/8"},
+		{"outside allow list", []string{"10.0.0.0/8"}, nil, "192.168.1.1", false, "not in allow list"},
+		{"single address", []string{" 192.168.1.1 "}, nil, "192.168.1.1", true, "allow 192.168.1.1"},
+		{"denied network", nil, []string{"203.0.113.0/24"}, "203.0.113.7", false, "deny 203.0.113.0/24"},
+		{"outside deny list", nil, []string{"203.0.113.0/24"}, "198.51.100.1", true, ""},
+		{"deny wins over allow", []string{"10.0.0.0/8"}, []string{"10.0.0.5"}, "10.0.0.5", false, "deny 10.0.0.5"},
+		{"IPv6 network", []string{"2001:db8::/32"}, nil, "2001:db8::1", true, "allow 2001:db8::/32"},
+		{"IPv6 outside", []string{"2001:db8::/32"}, nil, "2001:db9::1", false, "not in allow list"},
+		{"IPv4-mapped IPv6 address", []string{"10.0.0.0/8"}, nil, "::ffff:10.0.0.1", true, "allow 10.0.0.0/8"},
+		{"unknown address", []string{"10.0.0.0/8"}, nil, "", false, "unknown client address"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := ParseIPRules(tt.allow, tt.deny)
+			if err != nil {
+				t.Fatalf("ParseIPRules: %v", err)
+			}
+			allowed, rule := rules.Check(net.ParseIP(tt.ip))
+			if allowed != tt.allowed || rule != tt.rule {
+				t.Errorf("Check(%s) = %v %q, want %v %q", tt.ip, allowed, rule, tt.allowed, tt.rule)
+			}
+		})
+	}
+}
+
+func TestParseIPRulesRejectsInvalidEntries(t *testing.T) {
+	for _, entry := range []string{"", "10.0.0", "10.0.0.0/33", "example.com", "2001:db8::/129"} {
+		if _, err := ParseIPRules([]string{entry}, nil); err == nil {
+			t.Errorf("ParseIPRules accepted allow entry %q", entry)
+		}
+		if _, err := ParseIPRules(nil, []string{entry}); err == nil {
+			t.Errorf("ParseIPRules accepted deny entry %q", entry)
+		}
+		if _, err := ParseCIDRs([]string{entry}); err == nil {
+			t.Errorf("ParseCIDRs accepted %q", entry)
+		}
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	trusted, err := ParseCIDRs([]string{"10.0.0.0/8", "192.168.0.1"})
+	if err != nil {
+		t.Fatalf("ParseCIDRs: %v", err)
+	}
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor []string
+		want         string
+	}{
+		{"direct client", "203.0.113.7:5000", nil, "203.0.113.7"},
+		{"untrusted peer cannot forward", "203.0.113.7:5000", []string{"198.51.100.1"}, "203.0.113.7"},
+		{"trusted proxy without header", "10.0.0.1:5000", nil, "10.0.0.1"},
+		{"trusted proxy", "10.0.0.1:5000", []string{"198.51.100.1"}, "198.51.100.1"},
+		{"chain of trusted proxies", "10.0.0.1:5000", []string{"198.51.100.1, 192.168.0.1, 10.0.0.2"}, "198.51.100.1"},
+		{"spoofed leftmost entry", "10.0.0.1:5000", []string{"1.2.3.4, 198.51.100.1"}, "198.51.100.1"},
+		{"several headers", "10.0.0.1:5000", []string{"1.2.3.4", "198.51.100.1"}, "198.51.100.1"},
+		{"malformed entry", "10.0.0.1:5000", []string{"198.51.100.1, garbage, 10.0.0.2"}, "10.0.0.2"},
+		{"all hops trusted", "10.0.0.1:5000", []string{"10.0.0.3, 10.0.0.2"}, "10.0.0.3"},
+		{"address without port", "203.0.113.7", nil, "203.0.113.7"},
+		{"IPv6 peer", "[2001:db8::1]:5000", []string{"198.51.100.1"}, "2001:db8::1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ClientIP(tt.remoteAddr, tt.forwardedFor, trusted)
+			if !got.Equal(net.ParseIP(tt.want)) {
+				t.Errorf("ClientIP = %v, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if got := ClientIP("10.0.0.1:5000", []string{"198.51.100.1"}, nil); !got.Equal(net.ParseIP("10.0.0.1")) {
+		t.Errorf("ClientIP without trusted proxies = %v, want the peer", got)
+	}
+}
@@ -0,0 +1,257 @@
+package security
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// jwksMinRefresh limits how often the keys are refetched
+const jwksMinRefresh = time.Minute
+
+// defaultJWKSRefresh is how long fetched keys are used before they are refetched
+const defaultJWKSRefresh = time.Hour
+
+// JWKSCache fetches and caches the public keys published at a JWKS URL or
+// stored in a local file. Keys are refetched when a token names a key ID that
+// is not cached and once the refresh interval passes, so key rotation at the
+// issuer is picked up without a restart.
+type JWKSCache struct {
+	source      string
+	load        func(ctx context.Context) ([]byte, error)
+	refresh     time.Duration
+	keys        map[string]crypto.PublicKey
+	fetchedAt   time.Time // Last successful fetch
+	attemptedAt time.Time // Last fetch, successful or not
+	inflight    *jwksFetch
+	mu          sync.Mutex
+}
+
+// jwksFetch is a fetch of the keys that other lookups can wait for
+type jwksFetch struct {
+	done chan struct{}
+	err  error
+}
+
+// NewJWKSCache creates a cache for the JWKS at url
+func NewJWKSCache(url string, client *http.Client) *JWKSCache {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return newJWKSCache(url, func(ctx context.Context) ([]byte, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("failed to fetch JWKS: status %d", resp.StatusCode)
+		}
+		return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	})
+}
+
+// NewJWKSFileCache creates a cache for the JWKS stored in the file at path
+func NewJWKSFileCache(path string) *JWKSCache {
+	return newJWKSCache(path, func(ctx context.Context) ([]byte, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JWKS: %w", err)
+		}
+		return data, nil
+	})
+}
+
+func newJWKSCache(source string, load func(ctx context.Context) ([]byte, error)) *JWKSCache {
+	return &JWKSCache{
+		source:  source,
+		load:    load,
+		refresh: defaultJWKSRefresh,
+		keys:    make(map[string]crypto.PublicKey),
+	}
+}
+
+// SetRefreshInterval sets how long fetched keys are used before they are refetched
+func (c *JWKSCache) SetRefreshInterval(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	c.mu.Lock()
+	c.refresh = interval
+	c.mu.Unlock()
+}
+
+// Key returns the public key with the given key ID. The keys are fetched
+// without holding the lock, so lookups of cached keys are not held up by a
+// slow issuer, and lookups needing the fetch wait for the one in flight.
+func (c *JWKSCache) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	c.mu.Lock()
+	key, ok := c.keys[kid]
+	if ok && time.Since(c.fetchedAt) < c.refresh {
+		c.mu.Unlock()
+		return key, nil
+	}
+	if f := c.inflight; f != nil {
+		c.mu.Unlock()
+		if ok {
+			// Keep verifying with the cached key while it is refetched
+			return key, nil
+		}
+		return c.wait(ctx, f, kid)
+	}
+	if time.Since(c.attemptedAt) < jwksMinRefresh {
+		c.mu.Unlock()
+		if ok {
+			return key, nil
+		}
+		return nil, fmt.Errorf("unknown signing key: %s", kid)
+	}
+
+	f := &jwksFetch{done: make(chan struct{})}
+	c.inflight = f
+	c.attemptedAt = time.Now()
+	attemptedAt := c.attemptedAt
+	c.mu.Unlock()
+
+	keys, err := c.fetch(ctx)
+
+	c.mu.Lock()
+	if err == nil {
+		c.keys = keys
+		c.fetchedAt = attemptedAt
+	}
+	c.inflight = nil
+	f.err = err
+	close(f.done)
+	c.mu.Unlock()
+
+	if err != nil {
+		// Keep verifying with the cached key while the issuer is unreachable
+		if ok {
+			return key, nil
+		}
+		return nil, err
+	}
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key: %s", kid)
+}
+
+// wait waits for a fetch in flight and looks the key up in its result
+func (c *JWKSCache) wait(ctx context.Context, f *jwksFetch, kid string) (crypto.PublicKey, error) {
+	select {
+	case <-f.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+	c.mu.Lock()
+	key, ok := c.keys[kid]
+	c.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key: %s", kid)
+	}
+	return key, nil
+}
+
+func (c *JWKSCache) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	data, err := c.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var set JWKS
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to decode JWKS from %s: %w", c.source, err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.PublicKey()
+		if err != nil {
+			// Skip key types we cannot use instead of failing the whole set
+			continue
+		}
+		keys[jwk.KeyID] = key
+	}
+	return keys, nil
+}
+
+// PublicKey decodes the public key of a JWK
+func (k JWK) PublicKey() (crypto.PublicKey, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve: %s", k.Curve)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Curve != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve: %s", k.Curve)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type: %s", k.KeyType)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, errors.New("invalid JWK parameter")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,158 @@
+package security
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testJWKS returns the JWKS publishing a new Ed25519 key for each key ID
+func testJWKS(t *testing.T, kids ...string) []byte {
+	t.Helper()
+	var set JWKS
+	for _, kid := range kids {
+		key, err := GenerateSigningKey(AlgorithmEdDSA, time.Now().Add(time.Hour), time.Now().Add(2*time.Hour))
+		if err != nil {
+			t.Fatalf("GenerateSigningKey: %v", err)
+		}
+		key.ID = kid
+		set.Keys = append(set.Keys, key.jwk())
+	}
+	data, err := json.Marshal(set)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	return data
+}
+
+func TestJWKSCacheFetchesUnknownKeys(t *testing.T) {
+	ctx := context.Background()
+	data := testJWKS(t, "a")
+	var fetches int
+	cache := newJWKSCache("test", func(ctx context.Context) ([]byte, error) {
+		fetches++
+		return data, nil
+	})
+
+	if _, err := cache.Key(ctx, "a"); err != nil {
+		t.Fatalf("Key(a): %v", err)
+	}
+	if _, err := cache.Key(ctx, "a"); err != nil || fetches != 1 {
+		t.Fatalf("cached Key(a) = %v after %d fetches", err, fetches)
+	}
+
+	// Unknown keys are refetched at most once per jwksMinRefresh
+	if _, err := cache.Key(ctx, "b"); err == nil {
+		t.Fatal("Key(b) found a key that is not published")
+	}
+	if fetches != 1 {
+		t.Fatalf("%d fetches within the minimum refresh interval", fetches)
+	}
+
+	data = testJWKS(t, "a", "b")
+	cache.attemptedAt = time.Now().Add(-jwksMinRefresh)
+	if _, err := cache.Key(ctx, "b"); err != nil {
+		t.Fatalf("Key(b) after rotation: %v", err)
+	}
+	if fetches != 2 {
+		t.Fatalf("%d fetches, want 2", fetches)
+	}
+}
+
+func TestJWKSCacheKeepsKeysWhileIssuerIsDown(t *testing.T) {
+	ctx := context.Background()
+	data := testJWKS(t, "a")
+	fail := false
+	cache := newJWKSCache("test", func(ctx context.Context) ([]byte, error) {
+		if fail {
+			return nil, errors.New("issuer is down")
+		}
+		return data, nil
+	})
+	if _, err := cache.Key(ctx, "a"); err != nil {
+		t.Fatalf("Key(a): %v", err)
+	}
+
+	fail = true
+	cache.fetchedAt = time.Now().Add(-2 * defaultJWKSRefresh)
+	cache.attemptedAt = cache.fetchedAt
+	if _, err := cache.Key(ctx, "a"); err != nil {
+		t.Errorf("expired Key(a) while the issuer is down: %v", err)
+	}
+	cache.attemptedAt = time.Now().Add(-jwksMinRefresh)
+	if _, err := cache.Key(ctx, "b"); err == nil {
+		t.Error("Key(b) succeeded while the issuer is down")
+	}
+}
+
+func TestJWKSCacheFetchDoesNotBlockCachedKeys(t *testing.T) {
+	ctx := context.Background()
+	data := testJWKS(t, "a")
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	cache := newJWKSCache("test", func(ctx context.Context) ([]byte, error) {
+		if fetches.Add(1) > 1 {
+			started <- struct{}{}
+			<-release
+		}
+		return data, nil
+	})
+	if _, err := cache.Key(ctx, "a"); err != nil {
+		t.Fatalf("Key(a): %v", err)
+	}
+
+	// Several lookups of a new key share one slow fetch
+	data = testJWKS(t, "a", "b")
+	cache.attemptedAt = time.Now().Add(-jwksMinRefresh)
+	var wg sync.WaitGroup
+	errs := make(chan error, 3)
+	lookup := func() {
+		defer wg.Done()
+		_, err := cache.Key(ctx, "b")
+		errs <- err
+	}
+	wg.Add(1)
+	go lookup()
+	<-started
+	wg.Add(2)
+	go lookup()
+	go lookup()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := cache.Key(ctx, "a")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Key(a) during a fetch: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("a cached key lookup waited for the fetch of another key")
+	}
+
+	// A lookup waiting for the fetch gives up with its context
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := cache.Key(canceled, "b"); !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled Key(b) = %v", err)
+	}
+
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Key(b): %v", err)
+		}
+	}
+	if n := fetches.Load(); n != 2 {
+		t.Errorf("%d fetches, want 2", n)
+	}
+}
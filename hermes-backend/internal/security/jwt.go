@@ -0,0 +1,357 @@
+package security
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// Supported signing algorithms
+const (
+	AlgorithmEdDSA = "EdDSA"
+	AlgorithmRS256 = "RS256"
+)
+
+// rsaKeyBits is the size of generated RSA signing keys
+const rsaKeyBits = 2048
+
+// ErrTokenRevoked is returned when a token is on the revocation list
+var ErrTokenRevoked = errors.New("token has been revoked")
+
+// Claims are the claims of Hermes access tokens. Tokens of service accounts
+// carry a service ID and scopes instead of a role.
+type Claims struct {
+	Username         string `json:"username,omitempty"`
+	Email            string `json:"email,omitempty"`
+	Role             string `json:"role,omitempty"`
+	ServiceID        string `json:"service_id,omitempty"`
+	Scope            string `json:"scope,omitempty"` // Space separated, as in OAuth 2.0
+	SessionID        string `json:"sid,omitempty"`   // Session of user tokens, revoked with it
+	MFA              bool   `json:"mfa,omitempty"`   // Whether the session verified a second factor
+	SecretGeneration int    `json:"gen,omitempty"`   // Service account secret the token was minted with
+	jwt.RegisteredClaims
+}
+
+// IsServiceAccount reports whether the token belongs to a service account
+func (c *Claims) IsServiceAccount() bool {
+	return c.ServiceID != ""
+}
+
+// Scopes returns the scopes of the token
+func (c *Claims) Scopes() []string {
+	return strings.Fields(c.Scope)
+}
+
+// SigningKey is an asymmetric key used to sign access tokens
+type SigningKey struct {
+	ID         string
+	Algorithm  string
+	PrivateKey crypto.Signer
+	RetiresAt  time.Time // No tokens are signed after this time
+	ExpiresAt  time.Time // Removed from the key set after this time
+}
+
+// GenerateSigningKey creates a new signing key for algorithm
+func GenerateSigningKey(algorithm string, retiresAt, expiresAt time.Time) (*SigningKey, error) {
+	var signer crypto.Signer
+	var err error
+	switch algorithm {
+	case AlgorithmEdDSA:
+		_, signer, err = ed25519.GenerateKey(rand.Reader)
+	case AlgorithmRS256:
+		signer, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm: %s", algorithm)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+
+	return &SigningKey{
+		ID:         uuid.New().String(),
+		Algorithm:  algorithm,
+		PrivateKey: signer,
+		RetiresAt:  retiresAt,
+		ExpiresAt:  expiresAt,
+	}, nil
+}
+
+// MarshalPrivateKey encodes the private key as PKCS #8 PEM
+func (k *SigningKey) MarshalPrivateKey() ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(k.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// ParseSigningKey decodes a key produced by MarshalPrivateKey
+func ParseSigningKey(id, algorithm string, privatePEM []byte, retiresAt, expiresAt time.Time) (*SigningKey, error) {
+	signer, err := parsePrivateKeyPEM(privatePEM)
+	if err != nil {
+		return nil, err
+	}
+	return &SigningKey{
+		ID:         id,
+		Algorithm:  algorithm,
+		PrivateKey: signer,
+		RetiresAt:  retiresAt,
+		ExpiresAt:  expiresAt,
+	}, nil
+}
+
+func (k *SigningKey) method() jwt.SigningMethod {
+	if k.Algorithm == AlgorithmRS256 {
+		return jwt.SigningMethodRS256
+	}
+	return jwt.SigningMethodEdDSA
+}
+
+// JWK is a public key in JSON Web Key format
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// jwk returns the public half of the key as a JWK
+func (k *SigningKey) jwk() JWK {
+	key := JWK{KeyID: k.ID, Use: "sig", Algorithm: k.Algorithm}
+	switch public := k.PrivateKey.Public().(type) {
+	case ed25519.PublicKey:
+		key.KeyType = "OKP"
+		key.Curve = "Ed25519"
+		key.X = base64.RawURLEncoding.EncodeToString(public)
+	case *rsa.PublicKey:
+		key.KeyType = "RSA"
+		key.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		key.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	}
+	return key
+}
+
+// KeySet holds the signing keys that tokens are signed and verified with
+type KeySet struct {
+	keys    map[string]*SigningKey
+	current *SigningKey
+	mu      sync.RWMutex
+}
+
+// NewKeySet creates an empty key set
+func NewKeySet() *KeySet {
+	return &KeySet{keys: make(map[string]*SigningKey)}
+}
+
+// Replace sets the keys of the set. The newest key that has not retired signs new tokens.
+func (s *KeySet) Replace(keys []*SigningKey) {
+	now := time.Now()
+	byID := make(map[string]*SigningKey, len(keys))
+	var current *SigningKey
+	for _, key := range keys {
+		if now.After(key.ExpiresAt) {
+			continue
+		}
+		byID[key.ID] = key
+		if now.Before(key.RetiresAt) && (current == nil || key.RetiresAt.After(current.RetiresAt)) {
+			current = key
+		}
+	}
+
+	s.mu.Lock()
+	s.keys = byID
+	s.current = current
+	s.mu.Unlock()
+}
+
+// Current returns the key new tokens are signed with
+func (s *KeySet) Current() *SigningKey {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.current
+}
+
+// JWKS returns the public keys of the set, for downstream verification
+func (s *KeySet) JWKS() JWKS {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	set := JWKS{Keys: make([]JWK, 0, len(s.keys))}
+	for _, key := range s.keys {
+		set.Keys = append(set.Keys, key.jwk())
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].KeyID < set.Keys[j].KeyID })
+	return set
+}
+
+// lookup returns the key with an ID, unless it has expired since the set
+// was last replaced
+func (s *KeySet) lookup(id string) *SigningKey {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	key := s.keys[id]
+	if key == nil || time.Now().After(key.ExpiresAt) {
+		return nil
+	}
+	return key
+}
+
+// RevocationList holds the IDs of revoked tokens and sessions until the
+// access tokens carrying them expire
+type RevocationList struct {
+	entries map[string]time.Time
+	mu      sync.RWMutex
+}
+
+// NewRevocationList creates an empty revocation list
+func NewRevocationList() *RevocationList {
+	return &RevocationList{entries: make(map[string]time.Time)}
+}
+
+// Add revokes a token ID until expiresAt
+func (l *RevocationList) Add(id string, expiresAt time.Time) {
+	l.mu.Lock()
+	l.entries[id] = expiresAt
+	l.mu.Unlock()
+}
+
+// Replace sets the revoked token IDs and their expiry times
+func (l *RevocationList) Replace(entries map[string]time.Time) {
+	l.mu.Lock()
+	l.entries = entries
+	l.mu.Unlock()
+}
+
+// Contains reports whether a token ID is revoked
+func (l *RevocationList) Contains(id string) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	_, ok := l.entries[id]
+	return ok
+}
+
+// TokenIssuer issues and verifies Hermes access tokens
+type TokenIssuer struct {
+	keys    *KeySet
+	revoked *RevocationList
+	issuer  string
+	ttl     time.Duration
+}
+
+// NewTokenIssuer creates a token issuer signing with the current key of keys
+func NewTokenIssuer(keys *KeySet, revoked *RevocationList, issuer string, ttl time.Duration) *TokenIssuer {
+	return &TokenIssuer{
+		keys:    keys,
+		revoked: revoked,
+		issuer:  issuer,
+		ttl:     ttl,
+	}
+}
+
+// Issue creates a signed access token with the given identity claims for a
+// user's session
+func (i *TokenIssuer) Issue(subject, username, email, role, sessionID string, mfa bool) (string, *Claims, error) {
+	claims := &Claims{
+		Username:  username,
+		Email:     email,
+		Role:      role,
+		SessionID: sessionID,
+		MFA:       mfa,
+	}
+	claims.Subject = subject
+	return i.sign(claims)
+}
+
+// IssueServiceToken creates a signed access token for a service account,
+// limited to scopes on the service it is bound to. generation identifies the
+// account secret, so rotating it revokes the token.
+func (i *TokenIssuer) IssueServiceToken(accountID, serviceID string, generation int, scopes []string) (string, *Claims, error) {
+	if serviceID == "" {
+		return "", nil, errors.New("service account token requires a service ID")
+	}
+	claims := &Claims{
+		ServiceID:        serviceID,
+		Scope:            strings.Join(scopes, " "),
+		SecretGeneration: generation,
+	}
+	claims.Subject = accountID
+	return i.sign(claims)
+}
+
+// sign completes the registered claims and signs them with the current key
+func (i *TokenIssuer) sign(claims *Claims) (string, *Claims, error) {
+	key := i.keys.Current()
+	if key == nil {
+		return "", nil, errors.New("no signing key available")
+	}
+
+	now := time.Now()
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        uuid.New().String(),
+		Issuer:    i.issuer,
+		Subject:   claims.Subject,
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(i.ttl)),
+	}
+
+	token := jwt.NewWithClaims(key.method(), claims)
+	token.Header["kid"] = key.ID
+	signed, err := token.SignedString(key.PrivateKey)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to sign token: %w", err)
+	}
+	return signed, claims, nil
+}
+
+// Verify parses an access token, checking its signature, lifetime, issuer and revocation
+func (i *TokenIssuer) Verify(tokenStr string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key := i.keys.lookup(kid)
+		if key == nil {
+			return nil, fmt.Errorf("unknown signing key: %s", kid)
+		}
+		if token.Method.Alg() != key.method().Alg() {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return key.PrivateKey.Public(), nil
+	},
+		jwt.WithValidMethods([]string{AlgorithmEdDSA, AlgorithmRS256}),
+		jwt.WithIssuer(i.issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if i.revoked.Contains(claims.ID) || (claims.SessionID != "" && i.revoked.Contains(claims.SessionID)) {
+		return nil, ErrTokenRevoked
+	}
+	return claims, nil
+}
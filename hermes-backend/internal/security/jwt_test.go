@@ -0,0 +1,179 @@
+package security
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestIssuer(t *testing.T, algorithm string) (*TokenIssuer, *KeySet, *RevocationList) {
+	t.Helper()
+	key, err := GenerateSigningKey(algorithm, time.Now().Add(time.Hour), time.Now().Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("GenerateSigningKey: %v", err)
+	}
+	keys := NewKeySet()
+	keys.Replace([]*SigningKey{key})
+	revoked := NewRevocationList()
+	return NewTokenIssuer(keys, revoked, "hermes", time.Minute), keys, revoked
+}
+
+func TestTokenIssuerIssueVerify(t *testing.T) {
+	for _, algorithm := range []string{AlgorithmEdDSA, AlgorithmRS256} {
+		t.Run(algorithm, func(t *testing.T) {
+			issuer, _, _ := newTestIssuer(t, algorithm)
+
+			token, issued, err := issuer.Issue("usr-1234", "alice", "alice@example.com", "ADMIN", "ses-1", true)
+			if err != nil {
+				t.Fatalf("Issue: %v", err)
+			}
+			if issued.Subject != "usr-1234" {
+				t.Errorf("issued subject = %q, want usr-1234", issued.Subject)
+			}
+
+			claims, err := issuer.Verify(token)
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if claims.Subject != "usr-1234" {
+				t.Errorf("subject = %q, want usr-1234", claims.Subject)
+			}
+			if claims.Username != "alice" || claims.Email != "alice@example.com" || claims.Role != "ADMIN" {
+				t.Errorf("identity claims = %q %q %q", claims.Username, claims.Email, claims.Role)
+			}
+			if claims.SessionID != "ses-1" || !claims.MFA {
+				t.Errorf("session claims = %q %v", claims.SessionID, claims.MFA)
+			}
+			if claims.IsServiceAccount() {
+				t.Error("user token reported as a service account")
+			}
+		})
+	}
+}
+
+func TestTokenIssuerServiceToken(t *testing.T) {
+	issuer, _, _ := newTestIssuer(t, AlgorithmEdDSA)
+
+	if _, _, err := issuer.IssueServiceToken("sa-1", "", 0, nil); err == nil {
+		t.Error("IssueServiceToken without a service ID succeeded")
+	}
+
+	token, _, err := issuer.IssueServiceToken("sa-1", "svc-1", 0, []string{string(ScopeHealthReportSelf)})
+	if err != nil {
+		t.Fatalf("IssueServiceToken: %v", err)
+	}
+	claims, err := issuer.Verify(token)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if claims.Subject != "sa-1" || claims.ServiceID != "svc-1" || !claims.IsServiceAccount() {
+		t.Errorf("claims = %+v", claims)
+	}
+	if !ScopeGrants(claims.Scopes(), PermHealthReport) || ScopeGrants(claims.Scopes(), PermMetricsWrite) {
+		t.Errorf("scopes = %v", claims.Scopes())
+	}
+}
+
+func TestTokenIssuerVerifyRejects(t *testing.T) {
+	issuer, keys, revoked := newTestIssuer(t, AlgorithmEdDSA)
+	other, _, _ := newTestIssuer(t, AlgorithmEdDSA)
+
+	tests := []struct {
+		name  string
+		token func(t *testing.T) string
+		want  error
+	}{
+		{
+			name: "revoked token",
+			token: func(t *testing.T) string {
+				token, claims, _ := issuer.Issue("usr-1", "alice", "", "USER", "", false)
+				revoked.Add(claims.ID, claims.ExpiresAt.Time)
+				return token
+			},
+			want: ErrTokenRevoked,
+		},
+		{
+			name: "revoked session",
+			token: func(t *testing.T) string {
+				token, _, _ := issuer.Issue("usr-1", "alice", "", "USER", "ses-revoked", false)
+				revoked.Add("ses-revoked", time.Now().Add(time.Hour))
+				return token
+			},
+			want: ErrTokenRevoked,
+		},
+		{
+			name: "unknown signing key",
+			token: func(t *testing.T) string {
+				token, _, _ := other.Issue("usr-1", "alice", "", "USER", "", false)
+				return token
+			},
+		},
+		{
+			name: "wrong issuer",
+			token: func(t *testing.T) string {
+				token, _, _ := NewTokenIssuer(keys, revoked, "someone-else", time.Minute).Issue("usr-1", "alice", "", "USER", "", false)
+				return token
+			},
+		},
+		{
+			name: "expired",
+			token: func(t *testing.T) string {
+				token, _, _ := NewTokenIssuer(keys, revoked, "hermes", -time.Minute).Issue("usr-1", "alice", "", "USER", "", false)
+				return token
+			},
+		},
+		{
+			name:  "malformed",
+			token: func(t *testing.T) string { return "not.a.token" },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := issuer.Verify(tt.token(t))
+			if err == nil {
+				t.Fatal("Verify succeeded")
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeySetRotation(t *testing.T) {
+	now := time.Now()
+	retired, _ := GenerateSigningKey(AlgorithmEdDSA, now.Add(-time.Minute), now.Add(time.Hour))
+	current, _ := GenerateSigningKey(AlgorithmEdDSA, now.Add(time.Hour), now.Add(2*time.Hour))
+	expired, _ := GenerateSigningKey(AlgorithmEdDSA, now.Add(-2*time.Hour), now.Add(-time.Hour))
+
+	keys := NewKeySet()
+	keys.Replace([]*SigningKey{retired, expired})
+	issuer := NewTokenIssuer(keys, NewRevocationList(), "hermes", time.Minute)
+	if _, _, err := issuer.Issue("usr-1", "alice", "", "USER", "", false); err == nil {
+		t.Fatal("Issue succeeded without a current key")
+	}
+
+	// A token signed before rotation still verifies while its key is in the set
+	keys.Replace([]*SigningKey{current})
+	old, _, err := issuer.Issue("usr-1", "alice", "", "USER", "", false)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	next, _ := GenerateSigningKey(AlgorithmEdDSA, now.Add(2*time.Hour), now.Add(3*time.Hour))
+	keys.Replace([]*SigningKey{retired, current, next, expired})
+	if keys.Current().ID != next.ID {
+		t.Errorf("current key = %s, want the newest", keys.Current().ID)
+	}
+	if _, err := issuer.Verify(old); err != nil {
+		t.Errorf("Verify after rotation: %v", err)
+	}
+	if n := len(keys.JWKS().Keys); n != 3 {
+		t.Errorf("JWKS has %d keys, want 3 without the expired one", n)
+	}
+
+	keys.Replace([]*SigningKey{next})
+	if _, err := issuer.Verify(old); err == nil {
+		t.Error("Verify succeeded after the signing key was removed")
+	}
+}
@@ -0,0 +1,102 @@
+package security
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// DefaultKeyID names the key derived from a single configured encryption key
+const DefaultKeyID = "default"
+
+// KeyProvider supplies the key encryption keys of a SecretBox. Keys are
+// identified so values sealed with an older key can still be opened after a
+// new key is introduced.
+type KeyProvider interface {
+	// CurrentKeyID returns the ID of the key new values are sealed with
+	CurrentKeyID() string
+	// Key returns the key with the given ID
+	Key(id string) ([]byte, bool)
+}
+
+// Keyring is a fixed set of keys, the first of which seals new values
+type Keyring struct {
+	current string
+	keys    map[string][]byte
+}
+
+// ParseKeyring parses keys written as "<id>:<key>". The first key seals new
+// values, the others are only used to open values sealed with them.
+func ParseKeyring(entries []string) (*Keyring, error) {
+	ring := &Keyring{keys: make(map[string][]byte)}
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, key, ok := strings.Cut(entry, ":")
+		id, key = strings.TrimSpace(id), strings.TrimSpace(key)
+		if !ok || id == "" || key == "" {
+			return nil, errors.New("encryption keys must be written as <id>:<key>")
+		}
+		if len(id) > maxKeyIDLength {
+			return nil, fmt.Errorf("encryption key IDs are limited to %d bytes", maxKeyIDLength)
+		}
+		if _, exists := ring.keys[id]; exists {
+			return nil, fmt.Errorf("duplicate encryption key ID: %s", id)
+		}
+		if ring.current == "" {
+			ring.current = id
+		}
+		ring.keys[id] = []byte(key)
+	}
+	if ring.current == "" {
+		return nil, errors.New("no encryption keys are configured")
+	}
+	return ring, nil
+}
+
+// LoadKeyFile reads a keyring from a file with one "<id>:<key>" entry per
+// line. Empty lines and lines starting with # are ignored.
+func LoadKeyFile(path string) (*Keyring, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open key file: %w", err)
+	}
+	defer file.Close()
+
+	var entries []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entries = append(entries, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	return ParseKeyring(entries)
+}
+
+// SingleKey returns a keyring holding one key under DefaultKeyID
+func SingleKey(secret string) (*Keyring, error) {
+	if secret == "" {
+		return nil, errors.New("encryption key is required")
+	}
+	return &Keyring{current: DefaultKeyID, keys: map[string][]byte{DefaultKeyID: []byte(secret)}}, nil
+}
+
+// CurrentKeyID returns the ID of the key new values are sealed with
+func (r *Keyring) CurrentKeyID() string {
+	return r.current
+}
+
+// Key returns the key with the given ID
+func (r *Keyring) Key(id string) ([]byte, bool) {
+	key, ok := r.keys[id]
+	return key, ok
+}
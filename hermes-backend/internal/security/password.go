@@ -0,0 +1,31 @@
+package security
+
+import (
+	"golang.org/x/crypto/bcrypt"
+)
+
+// passwordCost is the bcrypt work factor for password hashes
+const passwordCost = 12
+
+// dummyHash is compared against when a user does not exist, so that failed
+// logins take the same time whether or not the username is known
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("hermes-dummy-password"), passwordCost)
+
+// HashPassword returns the bcrypt hash of a password
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), passwordCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// CheckPassword reports whether password matches hash. An empty hash is
+// treated as a missing user and always fails after a full comparison.
+func CheckPassword(hash, password string) bool {
+	if hash == "" {
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
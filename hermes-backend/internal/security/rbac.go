@@ -0,0 +1,218 @@
+package security
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+)
+
+// Permission allows an action on a kind of resource, written as resource:action
+type Permission string
+
+// Permissions checked by the API
+const (
+	PermServicesRead      Permission = "services:read"
+	PermServicesWrite     Permission = "services:write"
+	PermServicesDelete    Permission = "services:delete"
+	PermServicesAdmin     Permission = "services:admin" // Bypasses team ownership and visibility
+	PermInstancesWrite    Permission = "instances:write"
+	PermHealthRead        Permission = "health:read"
+	PermHealthReport      Permission = "health:report"
+	PermHealthWrite       Permission = "health:write"
+	PermMetricsWrite      Permission = "metrics:write"
+	PermCertificatesIssue Permission = "certificates:issue"
+	PermMeshRead          Permission = "mesh:read"
+	PermGatewayRead       Permission = "gateway:read"
+	PermGatewayAdmin      Permission = "gateway:admin"
+	PermTLSAdmin          Permission = "tls:admin"
+	PermUsersAdmin        Permission = "users:admin"
+	PermTeamsRead         Permission = "teams:read"
+	PermTeamsAdmin        Permission = "teams:admin"
+	PermRolesAdmin        Permission = "roles:admin"
+	PermTokensAdmin       Permission = "tokens:admin"
+	PermAuditRead         Permission = "audit:read"
+	PermNamespacesAdmin   Permission = "namespaces:admin"
+
+	// PermServiceAccountsAdmin manages the credentials services act with
+	PermServiceAccountsAdmin Permission = "serviceaccounts:admin"
+
+	// PermAll grants every permission
+	PermAll Permission = "*"
+)
+
+// permissions lists every permission that can be granted
+var permissions = []Permission{
+	PermServicesRead, PermServicesWrite, PermServicesDelete, PermServicesAdmin, PermInstancesWrite,
+	PermHealthRead, PermHealthReport, PermHealthWrite, PermMetricsWrite,
+	PermCertificatesIssue, PermMeshRead,
+	PermGatewayRead, PermGatewayAdmin,
+	PermTLSAdmin, PermUsersAdmin, PermRolesAdmin, PermTokensAdmin,
+	PermTeamsRead, PermTeamsAdmin,
+	PermAuditRead, PermNamespacesAdmin, PermServiceAccountsAdmin,
+}
+
+// builtinRoles are the permissions of the built-in roles, which cannot be
+// changed or deleted
+var builtinRoles = map[models.Role][]Permission{
+	models.RoleAdmin: {PermAll},
+	// Reporting health and metrics and issuing workload certificates are
+	// left to service accounts, as USER accounts can be self-registered
+	models.RoleUser: {
+		PermServicesRead, PermServicesWrite, PermInstancesWrite,
+		PermHealthRead, PermHealthWrite, PermMeshRead, PermGatewayRead, PermTeamsRead,
+	},
+	models.RoleGuest: {PermServicesRead, PermHealthRead, PermGatewayRead, PermTeamsRead},
+}
+
+// Scope grants a service account token a permission on its own service only
+type Scope string
+
+// Scopes service accounts can be given
+const (
+	ScopeHealthReportSelf   Scope = "health:report:self"
+	ScopeMetricsWriteSelf   Scope = "metrics:write:self"
+	ScopeInstancesWriteSelf Scope = "instances:write:self"
+)
+
+// selfScopeSuffix binds a scope to the service of the token
+const selfScopeSuffix = ":self"
+
+// serviceScopes lists every scope a service account can be given
+var serviceScopes = []Scope{ScopeHealthReportSelf, ScopeMetricsWriteSelf, ScopeInstancesWriteSelf}
+
+// ServiceScopes returns every scope a service account can be given
+func ServiceScopes() []Scope {
+	return append([]Scope(nil), serviceScopes...)
+}
+
+// ValidScope reports whether s is a known service account scope
+func ValidScope(s Scope) bool {
+	for _, known := range serviceScopes {
+		if s == known {
+			return true
+		}
+	}
+	return false
+}
+
+// ScopeGrants reports whether one of scopes grants permission on the token's
+// own service
+func ScopeGrants(scopes []string, permission Permission) bool {
+	for _, s := range scopes {
+		if Scope(s) == Scope(permission)+selfScopeSuffix {
+			return true
+		}
+	}
+	return false
+}
+
+// Permissions returns every permission that can be granted
+func Permissions() []Permission {
+	return append([]Permission(nil), permissions...)
+}
+
+// ValidPermission reports whether p is a known permission or a wildcard
+// such as services:* that covers at least one
+func ValidPermission(p Permission) bool {
+	if p == PermAll {
+		return true
+	}
+	for _, known := range permissions {
+		if grants(p, known) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsBuiltinRole reports whether role is one of the built-in roles
+func IsBuiltinRole(role models.Role) bool {
+	_, ok := builtinRoles[role]
+	return ok
+}
+
+// Policy maps roles to the permissions they grant. Custom roles are loaded
+// from the database next to the built-in ones.
+type Policy struct {
+	roles map[models.Role][]Permission
+	mu    sync.RWMutex
+}
+
+// NewPolicy creates a policy with the built-in roles
+func NewPolicy() *Policy {
+	p := &Policy{}
+	p.Replace(nil)
+	return p
+}
+
+// Replace sets the custom roles of the policy. Custom roles cannot redefine built-in roles.
+func (p *Policy) Replace(custom map[models.Role][]Permission) {
+	roles := make(map[models.Role][]Permission, len(builtinRoles)+len(custom))
+	for name, perms := range custom {
+		roles[name] = perms
+	}
+	for name, perms := range builtinRoles {
+		roles[name] = perms
+	}
+
+	p.mu.Lock()
+	p.roles = roles
+	p.mu.Unlock()
+}
+
+// Allows reports whether role grants permission
+func (p *Policy) Allows(role models.Role, permission Permission) bool {
+	p.mu.RLock()
+	perms, ok := p.roles[role]
+	p.mu.RUnlock()
+	if !ok {
+		return false
+	}
+
+	for _, granted := range perms {
+		if grants(granted, permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasRole reports whether role is defined
+func (p *Policy) HasRole(role models.Role) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	_, ok := p.roles[role]
+	return ok
+}
+
+// RolePermissions returns the permissions granted by each role
+func (p *Policy) RolePermissions() map[models.Role][]Permission {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	roles := make(map[models.Role][]Permission, len(p.roles))
+	for name, perms := range p.roles {
+		sorted := append([]Permission(nil), perms...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		roles[name] = sorted
+	}
+	return roles
+}
+
+// IsAdminPermission reports whether permission administers Hermes itself
+// rather than the services registered in it
+func IsAdminPermission(permission Permission) bool {
+	return permission == PermAll || strings.HasSuffix(string(permission), ":admin")
+}
+
+// grants reports whether the granted permission covers the requested one.
+// "*" covers everything and "resource:*" covers every action on resource.
+func grants(granted, requested Permission) bool {
+	if granted == PermAll || granted == requested {
+		return true
+	}
+	resource, ok := strings.CutSuffix(string(granted), ":*")
+	return ok && strings.HasPrefix(string(requested), resource+":")
+}
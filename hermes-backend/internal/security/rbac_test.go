@@ -0,0 +1,82 @@
+package security
+
+import (
+	"testing"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+)
+
+func TestPolicyAllows(t *testing.T) {
+	policy := NewPolicy()
+	policy.Replace(map[models.Role][]Permission{
+		"OPERATOR": {"health:*", PermServicesRead},
+		// Custom roles cannot redefine built-in ones
+		models.RoleGuest: {PermAll},
+	})
+
+	tests := []struct {
+		role       models.Role
+		permission Permission
+		want       bool
+	}{
+		{models.RoleAdmin, PermUsersAdmin, true},
+		{models.RoleAdmin, PermCertificatesIssue, true},
+		{models.RoleUser, PermServicesWrite, true},
+		{models.RoleUser, PermHealthWrite, true},
+		{models.RoleUser, PermHealthReport, false},
+		{models.RoleUser, PermMetricsWrite, false},
+		{models.RoleUser, PermCertificatesIssue, false},
+		{models.RoleUser, PermUsersAdmin, false},
+		{models.RoleUser, PermServiceAccountsAdmin, false},
+		{models.RoleAdmin, PermServiceAccountsAdmin, true},
+		{models.RoleGuest, PermServicesRead, true},
+		{models.RoleGuest, PermServicesWrite, false},
+		{"OPERATOR", PermHealthReport, true},
+		{"OPERATOR", PermHealthRead, true},
+		{"OPERATOR", PermServicesWrite, false},
+		{"UNKNOWN", PermServicesRead, false},
+	}
+	for _, tt := range tests {
+		if got := policy.Allows(tt.role, tt.permission); got != tt.want {
+			t.Errorf("Allows(%s, %s) = %v, want %v", tt.role, tt.permission, got, tt.want)
+		}
+	}
+}
+
+func TestValidPermission(t *testing.T) {
+	tests := []struct {
+		permission Permission
+		want       bool
+	}{
+		{PermAll, true},
+		{PermServicesRead, true},
+		{PermServiceAccountsAdmin, true},
+		{"services:*", true},
+		{"services:explode", false},
+		{"nothing:*", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := ValidPermission(tt.permission); got != tt.want {
+			t.Errorf("ValidPermission(%q) = %v, want %v", tt.permission, got, tt.want)
+		}
+	}
+}
+
+func TestScopeGrants(t *testing.T) {
+	scopes := []string{string(ScopeHealthReportSelf), "services:write"}
+	tests := []struct {
+		permission Permission
+		want       bool
+	}{
+		{PermHealthReport, true},
+		{PermMetricsWrite, false},
+		// Only :self scopes grant anything to service accounts
+		{PermServicesWrite, false},
+	}
+	for _, tt := range tests {
+		if got := ScopeGrants(scopes, tt.permission); got != tt.want {
+			t.Errorf("ScopeGrants(%s) = %v, want %v", tt.permission, got, tt.want)
+		}
+	}
+}
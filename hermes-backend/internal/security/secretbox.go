@@ -0,0 +1,264 @@
+package security
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// envelopeMagic starts every value sealed with envelope encryption. Values
+// without it were sealed directly with the legacy key.
+var envelopeMagic = []byte("hxe1")
+
+// sealedStringPrefix marks sealed values stored in text columns
+const sealedStringPrefix = "enc:v1:"
+
+// maxKeyIDLength bounds key IDs, which are stored in every sealed value
+const maxKeyIDLength = 255
+
+// dataKeySize is the size of the random key each value is encrypted with
+const dataKeySize = 32
+
+// SecretBox encrypts and authenticates secrets with envelope encryption:
+// every value is encrypted with its own random data key using AES-256-GCM,
+// and the data key is encrypted with a key encryption key of the key
+// provider. Sealed values name the key that wraps their data key, so keys
+// can be rotated by adding a new current key and re-sealing old values.
+type SecretBox struct {
+	keys   KeyProvider
+	aeads  map[string]cipher.AEAD // By key ID
+	mu     sync.Mutex
+	legacy cipher.AEAD
+}
+
+// NewSecretBox creates a SecretBox sealing with the keys of provider. Values
+// sealed before envelope encryption are opened with legacySecret, if set.
+func NewSecretBox(keys KeyProvider, legacySecret string) (*SecretBox, error) {
+	if _, ok := keys.Key(keys.CurrentKeyID()); !ok {
+		return nil, errors.New("the current encryption key is missing")
+	}
+	b := &SecretBox{keys: keys, aeads: make(map[string]cipher.AEAD)}
+	if legacySecret != "" {
+		aead, err := deriveAEAD([]byte(legacySecret))
+		if err != nil {
+			return nil, err
+		}
+		b.legacy = aead
+	}
+	return b, nil
+}
+
+// Seal encrypts plaintext with a new data key wrapped by the current key
+func (b *SecretBox) Seal(plaintext []byte) ([]byte, error) {
+	keyID := b.keys.CurrentKeyID()
+	kek, err := b.aead(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey := make([]byte, dataKeySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	wrapped, err := seal(kek, dataKey)
+	if err != nil {
+		return nil, err
+	}
+	dek, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := seal(dek, plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, len(envelopeMagic)+2+len(keyID)+len(wrapped)+len(payload))
+	out = append(out, envelopeMagic...)
+	out = append(out, byte(len(keyID)))
+	out = append(out, keyID...)
+	out = append(out, byte(len(wrapped)))
+	out = append(out, wrapped...)
+	return append(out, payload...), nil
+}
+
+// Open decrypts a value produced by Seal, or sealed with the legacy key
+func (b *SecretBox) Open(sealed []byte) ([]byte, error) {
+	keyID, wrapped, payload, ok := parseEnvelope(sealed)
+	if ok {
+		if plaintext, err := b.openEnvelope(keyID, wrapped, payload); err == nil {
+			return plaintext, nil
+		} else if b.legacy == nil {
+			return nil, err
+		}
+	}
+	if b.legacy == nil {
+		return nil, errors.New("sealed value has no envelope and no legacy key is configured")
+	}
+	return open(b.legacy, sealed)
+}
+
+// Current reports whether a sealed value is wrapped by the current key, so
+// re-sealing it would not change the key protecting it
+func (b *SecretBox) Current(sealed []byte) bool {
+	keyID, _, _, ok := parseEnvelope(sealed)
+	return ok && keyID == b.keys.CurrentKeyID()
+}
+
+// Reseal opens a sealed value and seals it again with the current key
+func (b *SecretBox) Reseal(sealed []byte) ([]byte, error) {
+	plaintext, err := b.Open(sealed)
+	if err != nil {
+		return nil, err
+	}
+	return b.Seal(plaintext)
+}
+
+// SealString seals a value stored in a text column
+func (b *SecretBox) SealString(plaintext string) (string, error) {
+	sealed, err := b.Seal([]byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	return sealedStringPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// OpenString opens a value sealed with SealString. Values that were never
+// sealed are returned as they are.
+func (b *SecretBox) OpenString(value string) (string, error) {
+	sealed, ok, err := decodeSealedString(value)
+	if err != nil || !ok {
+		return value, err
+	}
+	plaintext, err := b.Open(sealed)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// CurrentString reports whether a text value is sealed with the current key
+func (b *SecretBox) CurrentString(value string) bool {
+	sealed, ok, err := decodeSealedString(value)
+	return err == nil && ok && b.Current(sealed)
+}
+
+// IsSealedString reports whether a text value was sealed with SealString
+func IsSealedString(value string) bool {
+	return strings.HasPrefix(value, sealedStringPrefix)
+}
+
+// openEnvelope unwraps the data key of an envelope and decrypts its payload
+func (b *SecretBox) openEnvelope(keyID string, wrapped, payload []byte) ([]byte, error) {
+	kek, err := b.aead(keyID)
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := open(kek, wrapped)
+	if err != nil {
+		return nil, err
+	}
+	dek, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return open(dek, payload)
+}
+
+// aead returns the cipher of a key encryption key
+func (b *SecretBox) aead(keyID string) (cipher.AEAD, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if aead, ok := b.aeads[keyID]; ok {
+		return aead, nil
+	}
+	key, ok := b.keys.Key(keyID)
+	if !ok {
+		return nil, fmt.Errorf("unknown encryption key: %s", keyID)
+	}
+	aead, err := deriveAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	b.aeads[keyID] = aead
+	return aead, nil
+}
+
+// parseEnvelope splits a sealed value into the ID of its key, its wrapped
+// data key and its payload
+func parseEnvelope(sealed []byte) (keyID string, wrapped, payload []byte, ok bool) {
+	rest, found := bytes.CutPrefix(sealed, envelopeMagic)
+	if !found || len(rest) < 1 {
+		return "", nil, nil, false
+	}
+	idLength := int(rest[0])
+	if len(rest) < 1+idLength+1 {
+		return "", nil, nil, false
+	}
+	keyID = string(rest[1 : 1+idLength])
+	rest = rest[1+idLength:]
+	wrappedLength := int(rest[0])
+	if len(rest) < 1+wrappedLength {
+		return "", nil, nil, false
+	}
+	return keyID, rest[1 : 1+wrappedLength], rest[1+wrappedLength:], true
+}
+
+// decodeSealedString decodes a text value sealed with SealString, reporting
+// whether it was sealed
+func decodeSealedString(value string) ([]byte, bool, error) {
+	encoded, ok := strings.CutPrefix(value, sealedStringPrefix)
+	if !ok {
+		return nil, false, nil
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, true, errors.New("sealed value is not valid base64")
+	}
+	return sealed, true, nil
+}
+
+// deriveAEAD creates an AES-256-GCM cipher whose key is derived from secret
+func deriveAEAD(secret []byte) (cipher.AEAD, error) {
+	key := sha256.Sum256(secret)
+	return newAEAD(key[:])
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts plaintext, prefixing the result with a random nonce
+func seal(aead cipher.AEAD, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// open decrypts a value produced by seal
+func open(aead cipher.AEAD, sealed []byte) ([]byte, error) {
+	size := aead.NonceSize()
+	if len(sealed) < size {
+		return nil, errors.New("sealed value is too short")
+	}
+	plaintext, err := aead.Open(nil, sealed[:size], sealed[size:], nil)
+	if err != nil {
+		return nil, errors.New("failed to decrypt sealed value")
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,191 @@
+package security
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestBox(t *testing.T, entries ...string) *SecretBox {
+	t.Helper()
+	ring, err := ParseKeyring(entries)
+	if err != nil {
+		t.Fatalf("ParseKeyring: %v", err)
+	}
+	box, err := NewSecretBox(ring, "")
+	if err != nil {
+		t.Fatalf("NewSecretBox: %v", err)
+	}
+	return box
+}
+
+func TestParseKeyring(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []string
+		current string
+		wantErr bool
+	}{
+		{"first key is current", []string{"k2:new-secret", "k1:old-secret"}, "k2", false},
+		{"blank entries skipped", []string{"", " k1 : secret "}, "k1", false},
+		{"no keys", []string{""}, "", true},
+		{"missing separator", []string{"secret"}, "", true},
+		{"empty key", []string{"k1:"}, "", true},
+		{"duplicate ID", []string{"k1:a", "k1:b"}, "", true},
+		{"ID too long", []string{strings.Repeat("k", maxKeyIDLength+1) + ":secret"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ring, err := ParseKeyring(tt.entries)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("ParseKeyring succeeded")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseKeyring: %v", err)
+			}
+			if ring.CurrentKeyID() != tt.current {
+				t.Errorf("current key = %s, want %s", ring.CurrentKeyID(), tt.current)
+			}
+		})
+	}
+}
+
+func TestLoadKeyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys")
+	if err := os.WriteFile(path, []byte("# rotated 2026-01\nk2:new-secret\n\nk1:old-secret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ring, err := LoadKeyFile(path)
+	if err != nil {
+		t.Fatalf("LoadKeyFile: %v", err)
+	}
+	if ring.CurrentKeyID() != "k2" {
+		t.Errorf("current key = %s, want k2", ring.CurrentKeyID())
+	}
+	if key, ok := ring.Key("k1"); !ok || string(key) != "old-secret" {
+		t.Errorf("k1 = %q, %v", key, ok)
+	}
+}
+
+func TestSecretBoxRoundTrip(t *testing.T) {
+	box := newTestBox(t, "k1:secret")
+	plaintext := []byte("client secret")
+
+	first, err := box.Seal(plaintext)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	second, _ := box.Seal(plaintext)
+	if bytes.Equal(first, second) {
+		t.Error("sealing twice gave the same ciphertext")
+	}
+	if bytes.Contains(first, plaintext) {
+		t.Error("sealed value contains the plaintext")
+	}
+
+	opened, err := box.Open(first)
+	if err != nil || !bytes.Equal(opened, plaintext) {
+		t.Fatalf("Open = %q, %v", opened, err)
+	}
+
+	tampered := append([]byte(nil), first...)
+	tampered[len(tampered)-1] ^= 1
+	if _, err := box.Open(tampered); err == nil {
+		t.Error("Open accepted a tampered value")
+	}
+	if _, err := newTestBox(t, "k1:other-secret").Open(first); err == nil {
+		t.Error("Open succeeded with another key under the same ID")
+	}
+}
+
+func TestSecretBoxRotation(t *testing.T) {
+	old := newTestBox(t, "k1:old-secret")
+	sealed, err := old.Seal([]byte("token"))
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if !old.Current(sealed) {
+		t.Error("value is not current under its own key")
+	}
+
+	rotated := newTestBox(t, "k2:new-secret", "k1:old-secret")
+	if rotated.Current(sealed) {
+		t.Error("value sealed with the old key reported current")
+	}
+	resealed, err := rotated.Reseal(sealed)
+	if err != nil {
+		t.Fatalf("Reseal: %v", err)
+	}
+	if !rotated.Current(resealed) {
+		t.Error("resealed value is not current")
+	}
+
+	// Once the old key is retired only resealed values open
+	retired := newTestBox(t, "k2:new-secret")
+	if _, err := retired.Open(sealed); err == nil {
+		t.Error("value sealed with a retired key opened")
+	}
+	if opened, err := retired.Open(resealed); err != nil || string(opened) != "token" {
+		t.Errorf("Open resealed = %q, %v", opened, err)
+	}
+}
+
+func TestSecretBoxLegacy(t *testing.T) {
+	legacy, err := deriveAEAD([]byte("legacy-secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := seal(legacy, []byte("from before envelopes"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ring, _ := ParseKeyring([]string{"k1:secret"})
+	box, err := NewSecretBox(ring, "legacy-secret")
+	if err != nil {
+		t.Fatalf("NewSecretBox: %v", err)
+	}
+	opened, err := box.Open(sealed)
+	if err != nil || string(opened) != "from before envelopes" {
+		t.Fatalf("Open legacy = %q, %v", opened, err)
+	}
+	if box.Current(sealed) {
+		t.Error("legacy value reported current")
+	}
+	if _, err := newTestBox(t, "k1:secret").Open(sealed); err == nil {
+		t.Error("legacy value opened without the legacy key")
+	}
+}
+
+func TestSecretBoxStrings(t *testing.T) {
+	box := newTestBox(t, "k1:secret")
+
+	sealed, err := box.SealString("hunter2")
+	if err != nil {
+		t.Fatalf("SealString: %v", err)
+	}
+	if !IsSealedString(sealed) || !box.CurrentString(sealed) {
+		t.Errorf("sealed string %q not recognized", sealed)
+	}
+
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{sealed, "hunter2", false},
+		{"plain value", "plain value", false},
+		{sealedStringPrefix + "!!not base64", "", true},
+	}
+	for _, tt := range tests {
+		got, err := box.OpenString(tt.value)
+		if (err != nil) != tt.wantErr || (!tt.wantErr && got != tt.want) {
+			t.Errorf("OpenString(%q) = %q, %v", tt.value, got, err)
+		}
+	}
+}
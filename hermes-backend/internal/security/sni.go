@@ -0,0 +1,70 @@
+package security
+
+import (
+	"crypto/tls"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// CertificateStore selects the certificate for a TLS connection by its SNI
+// host name. Exact names win over wildcards such as *.example.com, which match
+// a single label. The store can be replaced at any time without restarting
+// listeners.
+type CertificateStore struct {
+	byName   map[string]*tls.Certificate
+	fallback *tls.Certificate
+	mu       sync.RWMutex
+}
+
+// NewCertificateStore creates an empty certificate store
+func NewCertificateStore() *CertificateStore {
+	return &CertificateStore{byName: make(map[string]*tls.Certificate)}
+}
+
+// Update replaces the served certificates. hostnames maps each host name or
+// wildcard to its certificate. fallback is served to clients without SNI and
+// may be nil.
+func (s *CertificateStore) Update(hostnames map[string]*tls.Certificate, fallback *tls.Certificate) {
+	byName := make(map[string]*tls.Certificate, len(hostnames))
+	for name, cert := range hostnames {
+		byName[strings.ToLower(name)] = cert
+	}
+
+	s.mu.Lock()
+	s.byName = byName
+	s.fallback = fallback
+	s.mu.Unlock()
+}
+
+// GetCertificate implements tls.Config.GetCertificate
+func (s *CertificateStore) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	name := strings.ToLower(strings.TrimSuffix(hello.ServerName, "."))
+	if name == "" {
+		if s.fallback != nil {
+			return s.fallback, nil
+		}
+		return nil, errors.New("no certificate for connections without SNI")
+	}
+
+	if cert, ok := s.byName[name]; ok {
+		return cert, nil
+	}
+	if i := strings.IndexByte(name, '.'); i > 0 {
+		if cert, ok := s.byName["*"+name[i:]]; ok {
+			return cert, nil
+		}
+	}
+	return nil, errors.New("no certificate for host " + name)
+}
+
+// TLSConfig returns a server TLS configuration serving certificates from the store
+func (s *CertificateStore) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: s.GetCertificate,
+	}
+}
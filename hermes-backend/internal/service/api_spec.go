@@ -0,0 +1,161 @@
+// internal/service/api_spec.go
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/repository"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/gateway"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/errors"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// ErrAPISpecNotFound is returned when an API specification does not exist
+var ErrAPISpecNotFound = errors.New("API specification not found")
+
+// ErrAPISpecInUse is returned when deleting a specification routes or service versions still use
+var ErrAPISpecInUse = errors.New("API specification is in use")
+
+// APISpecService handles business logic for the OpenAPI documents gateway
+// requests are validated against
+type APISpecService struct {
+	repo   repository.APISpecRepository
+	routes *RouteService
+	audit  *AuditService
+	log    *logger.Logger
+}
+
+// NewAPISpecService creates a new APISpecService
+func NewAPISpecService(repo repository.APISpecRepository, routes *RouteService, audit *AuditService, log *logger.Logger) *APISpecService {
+	return &APISpecService{
+		repo:   repo,
+		routes: routes,
+		audit:  audit,
+		log:    log,
+	}
+}
+
+// CreateSpec parses and stores an OpenAPI document written in JSON or YAML
+func (s *APISpecService) CreateSpec(ctx context.Context, name, description string, document []byte) (*models.APISpec, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("specification name is required")
+	}
+	existing, err := s.repo.GetByName(ctx, name)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to check specification name")
+	}
+	if existing != nil {
+		return nil, errors.New("specification with this name already exists")
+	}
+
+	parsed, err := gateway.ParseOpenAPI(document)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := &models.APISpec{
+		ID:          "spec-" + uuid.New().String()[:8],
+		Name:        name,
+		Description: description,
+		Title:       parsed.Title,
+		Version:     parsed.Version,
+		Document:    parsed.Document(),
+	}
+	if err := s.repo.Create(ctx, spec); err != nil {
+		return nil, errors.Wrap(err, "failed to create specification")
+	}
+
+	s.log.Info("API specification created", "id", spec.ID, "name", spec.Name, "version", spec.Version)
+	s.audit.Record(ctx, models.AuditActionCreate, AuditResourceAPISpec, spec.ID, nil, spec)
+	return spec, nil
+}
+
+// GetSpec retrieves an API specification by its ID
+func (s *APISpecService) GetSpec(ctx context.Context, id string) (*models.APISpec, error) {
+	spec, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve specification")
+	}
+	if spec == nil {
+		return nil, ErrAPISpecNotFound
+	}
+	return spec, nil
+}
+
+// GetDocument retrieves the document of an API specification as JSON
+func (s *APISpecService) GetDocument(ctx context.Context, id string) (json.RawMessage, error) {
+	spec, err := s.GetSpec(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return spec.Document, nil
+}
+
+// ListSpecs lists all API specifications without their documents
+func (s *APISpecService) ListSpecs(ctx context.Context) ([]*models.APISpec, error) {
+	specs, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list specifications")
+	}
+	return specs, nil
+}
+
+// UpdateSpec replaces the document of an API specification. Routes using it
+// validate against the new document right away.
+func (s *APISpecService) UpdateSpec(ctx context.Context, id string, description *string, document []byte) (*models.APISpec, error) {
+	spec, err := s.GetSpec(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	before := auditSnapshot(spec)
+
+	if len(document) > 0 {
+		parsed, err := gateway.ParseOpenAPI(document)
+		if err != nil {
+			return nil, err
+		}
+		spec.Title = parsed.Title
+		spec.Version = parsed.Version
+		spec.Document = parsed.Document()
+	}
+	if description != nil {
+		spec.Description = *description
+	}
+
+	if err := s.repo.Update(ctx, spec); err != nil {
+		return nil, errors.Wrap(err, "failed to update specification")
+	}
+
+	s.log.Info("API specification updated", "id", spec.ID, "name", spec.Name, "version", spec.Version)
+	s.audit.Record(ctx, models.AuditActionUpdate, AuditResourceAPISpec, spec.ID, before, spec)
+	s.routes.syncAfterChange(ctx)
+	return spec, nil
+}
+
+// DeleteSpec deletes an API specification no route or service version uses
+func (s *APISpecService) DeleteSpec(ctx context.Context, id string) error {
+	spec, err := s.GetSpec(ctx, id)
+	if err != nil {
+		return err
+	}
+	inUse, err := s.repo.InUse(ctx, spec.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to check specification usage")
+	}
+	if inUse {
+		return ErrAPISpecInUse
+	}
+
+	if err := s.repo.Delete(ctx, spec.ID); err != nil {
+		return errors.Wrap(err, "failed to delete specification")
+	}
+
+	s.log.Info("API specification deleted", "id", spec.ID, "name", spec.Name)
+	s.audit.Record(ctx, models.AuditActionDelete, AuditResourceAPISpec, spec.ID, spec, nil)
+	return nil
+}
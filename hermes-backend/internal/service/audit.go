@@ -0,0 +1,182 @@
+// internal/service/audit.go
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/repository"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/errors"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/logger"
+)
+
+// Resource types recorded in the audit log
+const (
+	AuditResourceService     = "service"
+	AuditResourceInstance    = "service_instance"
+	AuditResourceVersion     = "service_version"
+	AuditResourceDependency  = "service_dependency"
+	AuditResourceHealthCheck = "health_check"
+	AuditResourceThreshold   = "health_threshold"
+	AuditResourceRoute       = "route"
+	AuditResourceUser        = "user"
+	AuditResourceNamespace   = "namespace"
+	AuditResourceBinding     = "namespace_binding"
+	AuditResourceAPISpec     = "api_spec"
+	AuditResourceSession     = "session"
+
+	AuditResourceServiceAccount      = "service_account"
+	AuditResourceServiceAccountToken = "service_account_token"
+)
+
+// maxAuditPageSize caps how many entries one query returns
+const maxAuditPageSize = 1000
+
+// auditCSVHeader are the columns of an audit log export
+var auditCSVHeader = []string{
+	"id", "timestamp", "actor_id", "actor_name", "actor_role", "source_ip",
+	"action", "resource_type", "resource_id", "before", "after",
+}
+
+type auditActorContextKey struct{}
+
+// WithAuditActor returns a context carrying who is making changes
+func WithAuditActor(ctx context.Context, actor models.AuditActor) context.Context {
+	return context.WithValue(ctx, auditActorContextKey{}, actor)
+}
+
+// AuditActorFromContext returns who is making changes, or an anonymous actor
+// for changes made outside of an API request
+func AuditActorFromContext(ctx context.Context) models.AuditActor {
+	actor, _ := ctx.Value(auditActorContextKey{}).(models.AuditActor)
+	return actor
+}
+
+// AuditService records changes made through the management API and lets
+// auditors search and export them
+type AuditService struct {
+	repo repository.AuditRepository
+	log  *logger.Logger
+}
+
+// NewAuditService creates a new AuditService
+func NewAuditService(repo repository.AuditRepository, log *logger.Logger) *AuditService {
+	return &AuditService{
+		repo: repo,
+		log:  log,
+	}
+}
+
+// Record appends a change to the audit log. before is nil for creations and
+// after is nil for deletions. The change has already been made, so a failure
+// to record it is logged rather than returned.
+func (s *AuditService) Record(ctx context.Context, action models.AuditAction, resourceType, resourceID string, before, after interface{}) {
+	actor := AuditActorFromContext(ctx)
+	entry := &models.AuditEntry{
+		Timestamp:    time.Now(),
+		ActorID:      actor.ID,
+		ActorName:    actor.Name,
+		ActorRole:    actor.Role,
+		SourceIP:     actor.SourceIP,
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Before:       auditSnapshot(before),
+		After:        auditSnapshot(after),
+	}
+
+	// Still record the change if the request was cancelled after making it
+	if err := s.repo.Create(context.WithoutCancel(ctx), entry); err != nil {
+		s.log.Error("Failed to record audit entry", "error", err,
+			"action", action, "resource_type", resourceType, "resource_id", resourceID, "actor", actor.ID)
+	}
+}
+
+// ListEntries searches the audit log, newest entries first
+func (s *AuditService) ListEntries(ctx context.Context, params models.AuditQueryParams) ([]*models.AuditEntry, int64, error) {
+	if params.Limit <= 0 || params.Limit > maxAuditPageSize {
+		params.Limit = maxAuditPageSize
+	}
+	if params.Offset < 0 {
+		params.Offset = 0
+	}
+
+	entries, total, err := s.repo.List(ctx, params)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to list audit entries")
+	}
+	return entries, total, nil
+}
+
+// ExportCSV writes every entry matching params as CSV, oldest first.
+// Pagination parameters are ignored.
+func (s *AuditService) ExportCSV(ctx context.Context, params models.AuditQueryParams, w io.Writer) error {
+	out := csv.NewWriter(w)
+	if err := out.Write(auditCSVHeader); err != nil {
+		return err
+	}
+
+	err := s.repo.ForEach(ctx, params, func(entry *models.AuditEntry) error {
+		return out.Write(csvSafe([]string{
+			strconv.FormatUint(uint64(entry.ID), 10),
+			entry.Timestamp.UTC().Format(time.RFC3339),
+			entry.ActorID,
+			entry.ActorName,
+			entry.ActorRole,
+			entry.SourceIP,
+			string(entry.Action),
+			entry.ResourceType,
+			entry.ResourceID,
+			auditJSONField(entry.Before),
+			auditJSONField(entry.After),
+		}))
+	})
+	if err != nil {
+		s.log.Error("Failed to export audit entries", "error", err)
+		return errors.Wrap(err, "failed to export audit entries")
+	}
+
+	out.Flush()
+	return out.Error()
+}
+
+// csvSafe prefixes cells that spreadsheets would run as formulas with a
+// quote, since actor names and resource IDs come from API clients
+func csvSafe(record []string) []string {
+	for i, cell := range record {
+		if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+			record[i] = "'" + cell
+		}
+	}
+	return record
+}
+
+// auditSnapshot captures the state of a resource as JSON. Take it before
+// changing a resource in place, the resource itself can be passed afterwards.
+func auditSnapshot(v interface{}) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	if raw, ok := v.(json.RawMessage); ok {
+		return raw
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// auditJSONField formats a snapshot for a CSV column
+func auditJSONField(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+	return string(raw)
+}
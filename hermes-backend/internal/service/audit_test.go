@@ -0,0 +1,74 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+)
+
+func TestAuditExportCSVEscapesFormulas(t *testing.T) {
+	repo := &fakeAuditRepo{entries: []*models.AuditEntry{{
+		ID:           1,
+		Timestamp:    time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		ActorID:      "usr-1",
+		ActorName:    "=HYPERLINK(\"http://evil.example\")",
+		ActorRole:    "USER",
+		SourceIP:     "10.0.0.1",
+		Action:       models.AuditActionUpdate,
+		ResourceType: AuditResourceService,
+		ResourceID:   "@SUM(A1:A9)",
+		Before:       json.RawMessage(`{"name":"api"}`),
+		After:        json.RawMessage(`{"name":"+cmd"}`),
+	}}}
+	s := NewAuditService(repo, newTestLogger())
+
+	var buf bytes.Buffer
+	if err := s.ExportCSV(context.Background(), models.AuditQueryParams{}, &buf); err != nil {
+		t.Fatalf("ExportCSV: %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("%d records, want header and one entry", len(records))
+	}
+
+	row := records[1]
+	if row[3] != "'=HYPERLINK(\"http://evil.example\")" {
+		t.Errorf("actor name = %q", row[3])
+	}
+	if row[8] != "'@SUM(A1:A9)" {
+		t.Errorf("resource ID = %q", row[8])
+	}
+	if row[9] != `{"name":"api"}` || row[10] != `{"name":"+cmd"}` {
+		t.Errorf("snapshots = %q %q", row[9], row[10])
+	}
+}
+
+func TestCSVSafe(t *testing.T) {
+	tests := []struct {
+		cell string
+		want string
+	}{
+		{"", ""},
+		{"alice", "alice"},
+		{"=1+1", "'=1+1"},
+		{"+1", "'+1"},
+		{"-1", "'-1"},
+		{"@cmd", "'@cmd"},
+		{"\tcmd", "'\tcmd"},
+		{"\rcmd", "'\rcmd"},
+		{"a=b", "a=b"},
+	}
+	for _, tt := range tests {
+		if got := csvSafe([]string{tt.cell})[0]; got != tt.want {
+			t.Errorf("csvSafe(%q) = %q, want %q", tt.cell, got, tt.want)
+		}
+	}
+}
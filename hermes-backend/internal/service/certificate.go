@@ -0,0 +1,65 @@
+// internal/service/certificate.go
+package service
+
+import (
+	"context"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/repository"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/security"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/errors"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/logger"
+)
+
+// ErrCANotEnabled is returned when mTLS is disabled and no certificate authority is running
+var ErrCANotEnabled = errors.New("certificate authority is not enabled")
+
+// ErrServiceNotFound is returned when a certificate or heartbeat is for an unknown service
+var ErrServiceNotFound = errors.New("service not found")
+
+// CertificateService issues workload certificates to registered services
+type CertificateService struct {
+	ca          *security.CertificateAuthority
+	serviceRepo repository.ServiceRepository
+	log         *logger.Logger
+}
+
+// NewCertificateService creates a new CertificateService. ca may be nil when mTLS is disabled.
+func NewCertificateService(ca *security.CertificateAuthority, serviceRepo repository.ServiceRepository, log *logger.Logger) *CertificateService {
+	return &CertificateService{
+		ca:          ca,
+		serviceRepo: serviceRepo,
+		log:         log,
+	}
+}
+
+// IssueServiceCertificate issues a short-lived certificate identifying a service.
+// When csr is empty the key pair is generated and returned as well.
+func (s *CertificateService) IssueServiceCertificate(ctx context.Context, serviceID string, csr string) (*security.WorkloadCertificate, error) {
+	if s.ca == nil {
+		return nil, ErrCANotEnabled
+	}
+
+	service, err := s.serviceRepo.GetByID(ctx, serviceID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve service")
+	}
+	if service == nil {
+		return nil, ErrServiceNotFound
+	}
+
+	cert, err := s.ca.Issue(s.ca.ServiceSpiffeID(service.ID), csr)
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Issued workload certificate", "service_id", service.ID, "spiffe_id", cert.SpiffeID, "expires_at", cert.ExpiresAt)
+	return cert, nil
+}
+
+// TrustBundle returns the PEM encoded roots that workload certificates chain to
+func (s *CertificateService) TrustBundle() (string, error) {
+	if s.ca == nil {
+		return "", ErrCANotEnabled
+	}
+	return s.ca.TrustBundle(), nil
+}
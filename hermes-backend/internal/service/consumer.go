@@ -0,0 +1,412 @@
+// internal/service/consumer.go
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/repository"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/gateway"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/security"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/errors"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// ErrConsumerNotFound is returned when a consumer does not exist
+var ErrConsumerNotFound = errors.New("consumer not found")
+
+// ErrConsumerKeyNotFound is returned when an API key does not exist
+var ErrConsumerKeyNotFound = errors.New("API key not found")
+
+// ErrConsumerSecretNotFound is returned when a signing secret does not exist
+var ErrConsumerSecretNotFound = errors.New("signing secret not found")
+
+// apiKeyPrefix makes leaked API keys easy to recognize
+const apiKeyPrefix = "hk_"
+
+// apiKeyShownPrefix is how much of a key is kept to tell keys apart
+const apiKeyShownPrefix = len(apiKeyPrefix) + 8
+
+// ConsumerService handles business logic for gateway consumers and their API keys
+type ConsumerService struct {
+	repo          repository.ConsumerRepository
+	routeRepo     repository.RouteRepository
+	keyAuth       *gateway.KeyAuth
+	signatureAuth *gateway.SignatureAuth
+	box           *security.SecretBox
+	log           *logger.Logger
+}
+
+// NewConsumerService creates a new ConsumerService
+func NewConsumerService(repo repository.ConsumerRepository, routeRepo repository.RouteRepository, keyAuth *gateway.KeyAuth, signatureAuth *gateway.SignatureAuth, box *security.SecretBox, log *logger.Logger) *ConsumerService {
+	return &ConsumerService{
+		repo:          repo,
+		routeRepo:     routeRepo,
+		keyAuth:       keyAuth,
+		signatureAuth: signatureAuth,
+		box:           box,
+		log:           log,
+	}
+}
+
+// CreateConsumer creates a new consumer without keys
+func (s *ConsumerService) CreateConsumer(ctx context.Context, req models.ConsumerRequest) (*models.Consumer, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("consumer name is required")
+	}
+	existing, err := s.repo.GetByName(ctx, name)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to check consumer name")
+	}
+	if existing != nil {
+		return nil, errors.New("consumer with this name already exists")
+	}
+
+	if err := s.checkRoutes(ctx, req.AllowedRoutes); err != nil {
+		return nil, err
+	}
+	if err := validateRateLimit(req.RateLimit); err != nil {
+		return nil, err
+	}
+	if err := validateConsumerCriticality(req.Criticality); err != nil {
+		return nil, err
+	}
+
+	consumer := &models.Consumer{
+		ID:            "con-" + uuid.New().String()[:8],
+		Name:          name,
+		Description:   req.Description,
+		Active:        true,
+		AllowedRoutes: req.AllowedRoutes,
+		RateLimit:     req.RateLimit,
+		Criticality:   req.Criticality,
+	}
+	if err := s.repo.Create(ctx, consumer); err != nil {
+		return nil, errors.Wrap(err, "failed to create consumer")
+	}
+
+	s.log.Info("Consumer created", "id", consumer.ID, "name", consumer.Name)
+	s.syncAfterChange(ctx)
+	return consumer, nil
+}
+
+// GetConsumer retrieves a consumer by ID
+func (s *ConsumerService) GetConsumer(ctx context.Context, id string) (*models.Consumer, error) {
+	consumer, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve consumer")
+	}
+	if consumer == nil {
+		return nil, ErrConsumerNotFound
+	}
+	return consumer, nil
+}
+
+// ListConsumers lists all consumers
+func (s *ConsumerService) ListConsumers(ctx context.Context) ([]*models.Consumer, error) {
+	consumers, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list consumers")
+	}
+	return consumers, nil
+}
+
+// UpdateConsumer updates a consumer. Deactivating a consumer cuts it off on
+// every route.
+func (s *ConsumerService) UpdateConsumer(ctx context.Context, id string, req models.ConsumerUpdateRequest) (*models.Consumer, error) {
+	consumer, err := s.GetConsumer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Description != nil {
+		consumer.Description = *req.Description
+	}
+	if req.Active != nil {
+		consumer.Active = *req.Active
+	}
+	if req.AllowedRoutes != nil {
+		if err := s.checkRoutes(ctx, req.AllowedRoutes); err != nil {
+			return nil, err
+		}
+		consumer.AllowedRoutes = req.AllowedRoutes
+	}
+	if req.RateLimit != nil {
+		if err := validateRateLimit(req.RateLimit); err != nil {
+			return nil, err
+		}
+		consumer.RateLimit = req.RateLimit
+	}
+	if req.Criticality != nil {
+		if err := validateConsumerCriticality(*req.Criticality); err != nil {
+			return nil, err
+		}
+		consumer.Criticality = *req.Criticality
+	}
+
+	if err := s.repo.Update(ctx, consumer); err != nil {
+		return nil, errors.Wrap(err, "failed to update consumer")
+	}
+
+	s.log.Info("Consumer updated", "id", consumer.ID, "name", consumer.Name, "active", consumer.Active)
+	s.syncAfterChange(ctx)
+	return consumer, nil
+}
+
+// DeleteConsumer deletes a consumer and its keys
+func (s *ConsumerService) DeleteConsumer(ctx context.Context, id string) error {
+	consumer, err := s.GetConsumer(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, consumer.ID); err != nil {
+		return errors.Wrap(err, "failed to delete consumer")
+	}
+
+	s.log.Info("Consumer deleted", "id", consumer.ID, "name", consumer.Name)
+	s.syncAfterChange(ctx)
+	return nil
+}
+
+// CreateKey creates an API key for a consumer. The key is only returned here.
+func (s *ConsumerService) CreateKey(ctx context.Context, consumerID string, req models.ConsumerKeyRequest) (*models.ConsumerKeyResponse, error) {
+	consumer, err := s.GetConsumer(ctx, consumerID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := security.RandomToken(32)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate API key")
+	}
+	apiKey := apiKeyPrefix + secret
+
+	key := &models.ConsumerKey{
+		ID:         "ck-" + uuid.New().String()[:8],
+		ConsumerID: consumer.ID,
+		Name:       strings.TrimSpace(req.Name),
+		Prefix:     apiKey[:apiKeyShownPrefix],
+		KeyHash:    gateway.HashAPIKey(apiKey),
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		key.ExpiresAt = &expiresAt
+	}
+	if err := s.repo.CreateKey(ctx, key); err != nil {
+		return nil, errors.Wrap(err, "failed to create API key")
+	}
+
+	s.log.Info("API key created", "id", key.ID, "consumer", consumer.Name, "prefix", key.Prefix)
+	s.syncAfterChange(ctx)
+	return &models.ConsumerKeyResponse{
+		Key:    key,
+		APIKey: apiKey,
+	}, nil
+}
+
+// ListKeys lists the API keys of a consumer
+func (s *ConsumerService) ListKeys(ctx context.Context, consumerID string) ([]*models.ConsumerKey, error) {
+	consumer, err := s.GetConsumer(ctx, consumerID)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := s.repo.ListKeys(ctx, consumer.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list API keys")
+	}
+	return keys, nil
+}
+
+// RevokeKey deletes an API key of a consumer
+func (s *ConsumerService) RevokeKey(ctx context.Context, consumerID, keyID string) error {
+	key, err := s.repo.GetKey(ctx, keyID)
+	if err != nil {
+		return errors.Wrap(err, "failed to retrieve API key")
+	}
+	if key == nil || key.ConsumerID != consumerID {
+		return ErrConsumerKeyNotFound
+	}
+
+	if err := s.repo.DeleteKey(ctx, key.ID); err != nil {
+		return errors.Wrap(err, "failed to revoke API key")
+	}
+
+	s.log.Info("API key revoked", "id", key.ID, "consumer_id", consumerID, "prefix", key.Prefix)
+	s.syncAfterChange(ctx)
+	return nil
+}
+
+// CreateSecret creates a signing secret for a consumer. The secret is only
+// returned here.
+func (s *ConsumerService) CreateSecret(ctx context.Context, consumerID string, req models.ConsumerSecretRequest) (*models.ConsumerSecretResponse, error) {
+	consumer, err := s.GetConsumer(ctx, consumerID)
+	if err != nil {
+		return nil, err
+	}
+
+	signingSecret, err := security.RandomToken(32)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate signing secret")
+	}
+	sealed, err := s.box.Seal([]byte(signingSecret))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encrypt signing secret")
+	}
+
+	secret := &models.ConsumerSecret{
+		ID:              "cs-" + uuid.New().String()[:8],
+		ConsumerID:      consumer.ID,
+		Name:            strings.TrimSpace(req.Name),
+		EncryptedSecret: sealed,
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		secret.ExpiresAt = &expiresAt
+	}
+	if err := s.repo.CreateSecret(ctx, secret); err != nil {
+		return nil, errors.Wrap(err, "failed to create signing secret")
+	}
+
+	s.log.Info("Signing secret created", "id", secret.ID, "consumer", consumer.Name)
+	s.syncAfterChange(ctx)
+	return &models.ConsumerSecretResponse{
+		Secret:        secret,
+		SigningSecret: signingSecret,
+	}, nil
+}
+
+// ListSecrets lists the signing secrets of a consumer
+func (s *ConsumerService) ListSecrets(ctx context.Context, consumerID string) ([]*models.ConsumerSecret, error) {
+	consumer, err := s.GetConsumer(ctx, consumerID)
+	if err != nil {
+		return nil, err
+	}
+
+	secrets, err := s.repo.ListSecrets(ctx, consumer.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list signing secrets")
+	}
+	return secrets, nil
+}
+
+// RevokeSecret deletes a signing secret of a consumer
+func (s *ConsumerService) RevokeSecret(ctx context.Context, consumerID, secretID string) error {
+	secret, err := s.repo.GetSecret(ctx, secretID)
+	if err != nil {
+		return errors.Wrap(err, "failed to retrieve signing secret")
+	}
+	if secret == nil || secret.ConsumerID != consumerID {
+		return ErrConsumerSecretNotFound
+	}
+
+	if err := s.repo.DeleteSecret(ctx, secret.ID); err != nil {
+		return errors.Wrap(err, "failed to revoke signing secret")
+	}
+
+	s.log.Info("Signing secret revoked", "id", secret.ID, "consumer_id", consumerID)
+	s.syncAfterChange(ctx)
+	return nil
+}
+
+// SyncConsumers loads the active consumers with their keys and signing
+// secrets into the gateway
+func (s *ConsumerService) SyncConsumers(ctx context.Context) error {
+	consumers, err := s.repo.ListActive(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to load active consumers")
+	}
+
+	ids := make([]string, len(consumers))
+	for i, consumer := range consumers {
+		ids[i] = consumer.ID
+	}
+	keys, err := s.repo.ListKeys(ctx, ids...)
+	if err != nil {
+		return errors.Wrap(err, "failed to load API keys")
+	}
+
+	secrets, err := s.repo.ListSecrets(ctx, ids...)
+	if err != nil {
+		return errors.Wrap(err, "failed to load signing secrets")
+	}
+	signingKeys := make([]gateway.SigningKey, 0, len(secrets))
+	for _, secret := range secrets {
+		plaintext, err := s.box.Open(secret.EncryptedSecret)
+		if err != nil {
+			s.log.Error("Failed to decrypt signing secret", "id", secret.ID, "error", err)
+			continue
+		}
+		signingKeys = append(signingKeys, gateway.SigningKey{
+			ID:         secret.ID,
+			ConsumerID: secret.ConsumerID,
+			Secret:     plaintext,
+			ExpiresAt:  secret.ExpiresAt,
+		})
+	}
+
+	s.keyAuth.Update(consumers, keys)
+	s.signatureAuth.Update(consumers, signingKeys)
+
+	if err := s.repo.DeleteExpiredNonces(ctx); err != nil {
+		s.log.Error("Failed to delete expired request nonces", "error", err)
+	}
+	return nil
+}
+
+// UseNonce records the nonce of a signed request in the database, so that
+// it is rejected when replayed to any Hermes instance
+func (s *ConsumerService) UseNonce(ctx context.Context, nonce string, expiresAt time.Time) (bool, error) {
+	unused, err := s.repo.UseNonce(ctx, nonce, expiresAt)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to record request nonce")
+	}
+	return unused, nil
+}
+
+// syncAfterChange pushes consumer changes to the gateway right away instead
+// of waiting for the next periodic sync
+func (s *ConsumerService) syncAfterChange(ctx context.Context) {
+	if err := s.SyncConsumers(ctx); err != nil {
+		s.log.Error("Failed to sync gateway consumers", "error", err)
+	}
+}
+
+// checkRoutes checks that every allowed route exists
+func (s *ConsumerService) checkRoutes(ctx context.Context, routeIDs []string) error {
+	for _, id := range routeIDs {
+		route, err := s.routeRepo.GetByID(ctx, id)
+		if err != nil {
+			return errors.Wrap(err, "failed to retrieve route")
+		}
+		if route == nil {
+			return errors.New("route not found: " + id)
+		}
+	}
+	return nil
+}
+
+// validateConsumerCriticality checks a consumer's criticality class, which
+// is empty when the route's class applies
+func validateConsumerCriticality(criticality models.Criticality) error {
+	if criticality != "" && !criticality.Valid() {
+		return errors.New("criticality must be one of: CRITICAL, DEFAULT, SHEDDABLE")
+	}
+	return nil
+}
+
+// validateRateLimit checks a consumer's rate limit override
+func validateRateLimit(limit *models.RateLimit) error {
+	if limit == nil {
+		return nil
+	}
+	if limit.Limit < 0 || limit.Window < 0 {
+		return errors.New("rate limit and window must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+// internal/service/encryption.go
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/repository"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/security"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/errors"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/logger"
+)
+
+// sealedColumns are the binary columns holding values sealed with the secret box
+var sealedColumns = []struct{ table, column string }{
+	{"tls_certificates", "encrypted_key"},
+	{"signing_keys", "encrypted_key"},
+	{"consumer_secrets", "encrypted_secret"},
+}
+
+// sealedStringColumns are the text columns holding strings sealed with the secret box
+var sealedStringColumns = []struct{ table, column string }{
+	{"users", "mfa_secret"},
+}
+
+// EncryptionService encrypts sensitive fields stored as text, such as health
+// check headers and secret service metadata, and re-encrypts every sealed
+// value after a key rotation
+type EncryptionService struct {
+	repo           repository.EncryptionRepository
+	box            *security.SecretBox
+	secretMetadata []string
+	log            *logger.Logger
+}
+
+// NewEncryptionService creates a new EncryptionService. Service metadata keys
+// containing one of secretMetadataKeys, ignoring case, are encrypted.
+func NewEncryptionService(repo repository.EncryptionRepository, box *security.SecretBox, secretMetadataKeys []string, log *logger.Logger) *EncryptionService {
+	patterns := make([]string, 0, len(secretMetadataKeys))
+	for _, key := range secretMetadataKeys {
+		if key = strings.ToLower(strings.TrimSpace(key)); key != "" {
+			patterns = append(patterns, key)
+		}
+	}
+	return &EncryptionService{
+		repo:           repo,
+		box:            box,
+		secretMetadata: patterns,
+		log:            log,
+	}
+}
+
+// SealHeaders encrypts the values of health check headers. Values that are
+// already encrypted, such as ones read back from the API, are kept.
+func (s *EncryptionService) SealHeaders(headers map[string]string) error {
+	return s.sealValues(headers, func(string) bool { return true })
+}
+
+// OpenHeaders decrypts the values of health check headers
+func (s *EncryptionService) OpenHeaders(headers map[string]string) (map[string]string, error) {
+	opened := make(map[string]string, len(headers))
+	for key, value := range headers {
+		plaintext, err := s.box.OpenString(value)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decrypt header "+key)
+		}
+		opened[key] = plaintext
+	}
+	return opened, nil
+}
+
+// SealMetadata encrypts the values of secret service metadata keys
+func (s *EncryptionService) SealMetadata(metadata map[string]string) error {
+	return s.sealValues(metadata, s.SecretMetadataKey)
+}
+
+// SecretMetadataKey reports whether the value of a service metadata key is encrypted
+func (s *EncryptionService) SecretMetadataKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, pattern := range s.secretMetadata {
+		if strings.Contains(key, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// Reencrypt seals every encrypted value again with the current key, and
+// encrypts sensitive fields stored before they were encrypted. Run it after
+// making a new key current, before dropping the old one. It returns how many
+// values were rewritten in each column.
+func (s *EncryptionService) Reencrypt(ctx context.Context) (map[string]int, error) {
+	counts := make(map[string]int)
+
+	for _, sealed := range sealedColumns {
+		name := sealed.table + "." + sealed.column
+		count, err := s.repo.RewriteSealed(ctx, sealed.table, sealed.column, func(value []byte) ([]byte, bool, error) {
+			if s.box.Current(value) {
+				return nil, false, nil
+			}
+			resealed, err := s.box.Reseal(value)
+			return resealed, err == nil, err
+		})
+		counts[name] = count
+		if err != nil {
+			return counts, errors.Wrap(err, "failed to re-encrypt "+name)
+		}
+		s.log.Info("Re-encrypted sealed values", "column", name, "count", count)
+	}
+
+	for _, sealed := range sealedStringColumns {
+		name := sealed.table + "." + sealed.column
+		count, err := s.repo.RewriteSealedString(ctx, sealed.table, sealed.column, func(value string) (string, bool, error) {
+			if s.box.CurrentString(value) {
+				return "", false, nil
+			}
+			plaintext, err := s.box.OpenString(value)
+			if err != nil {
+				return "", false, err
+			}
+			resealed, err := s.box.SealString(plaintext)
+			return resealed, err == nil, err
+		})
+		counts[name] = count
+		if err != nil {
+			return counts, errors.Wrap(err, "failed to re-encrypt "+name)
+		}
+		s.log.Info("Re-encrypted sealed values", "column", name, "count", count)
+	}
+
+	maps := []struct {
+		table, column string
+		secret        func(key string) bool
+	}{
+		{"health_checks", "headers", func(string) bool { return true }},
+		{"services", "metadata", s.SecretMetadataKey},
+		{"service_instances", "metadata", s.SecretMetadataKey},
+	}
+	for _, sealed := range maps {
+		name := sealed.table + "." + sealed.column
+		count, err := s.repo.RewriteSealedMap(ctx, sealed.table, sealed.column, func(values map[string]string) (bool, error) {
+			return s.resealValues(values, sealed.secret)
+		})
+		counts[name] = count
+		if err != nil {
+			return counts, errors.Wrap(err, "failed to re-encrypt "+name)
+		}
+		s.log.Info("Re-encrypted sealed values", "column", name, "count", count)
+	}
+	return counts, nil
+}
+
+// sealValues encrypts the plaintext values of the keys secret selects
+func (s *EncryptionService) sealValues(values map[string]string, secret func(key string) bool) error {
+	for key, value := range values {
+		if !secret(key) || security.IsSealedString(value) {
+			continue
+		}
+		sealed, err := s.box.SealString(value)
+		if err != nil {
+			return errors.Wrap(err, "failed to encrypt "+key)
+		}
+		values[key] = sealed
+	}
+	return nil
+}
+
+// resealValues seals encrypted values again with the current key and
+// encrypts plaintext values of the keys secret selects, reporting whether
+// any value changed
+func (s *EncryptionService) resealValues(values map[string]string, secret func(key string) bool) (bool, error) {
+	changed := false
+	for key, value := range values {
+		if security.IsSealedString(value) {
+			if s.box.CurrentString(value) {
+				continue
+			}
+			plaintext, err := s.box.OpenString(value)
+			if err != nil {
+				return false, errors.Wrap(err, "failed to decrypt "+key)
+			}
+			value = plaintext
+		} else if !secret(key) {
+			continue
+		}
+		sealed, err := s.box.SealString(value)
+		if err != nil {
+			return false, errors.Wrap(err, "failed to encrypt "+key)
+		}
+		values[key] = sealed
+		changed = true
+	}
+	return changed, nil
+}
@@ -0,0 +1,587 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/repository"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/security"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/logger"
+)
+
+// In-memory repositories for service tests. They embed the repository
+// interface, so calling a method a test does not expect panics.
+
+type fakeUserRepo struct {
+	repository.UserRepository
+	mu    sync.Mutex
+	users map[string]*models.User
+}
+
+func newFakeUserRepo(users ...*models.User) *fakeUserRepo {
+	r := &fakeUserRepo{users: make(map[string]*models.User)}
+	for _, u := range users {
+		r.users[u.ID] = u
+	}
+	return r
+}
+
+func (r *fakeUserRepo) find(match func(*models.User) bool) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.users {
+		if match(u) {
+			copied := *u
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeUserRepo) Create(ctx context.Context, user *models.User) error {
+	return r.Update(ctx, user)
+}
+
+func (r *fakeUserRepo) Update(ctx context.Context, user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *user
+	r.users[user.ID] = &copied
+	return nil
+}
+
+func (r *fakeUserRepo) GetByID(ctx context.Context, id string) (*models.User, error) {
+	return r.find(func(u *models.User) bool { return u.ID == id })
+}
+
+func (r *fakeUserRepo) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	return r.find(func(u *models.User) bool { return u.Username == username })
+}
+
+func (r *fakeUserRepo) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	return r.find(func(u *models.User) bool { return u.Email == email })
+}
+
+func (r *fakeUserRepo) GetByExternalID(ctx context.Context, externalID string) (*models.User, error) {
+	return r.find(func(u *models.User) bool { return u.ExternalID != nil && *u.ExternalID == externalID })
+}
+
+func (r *fakeUserRepo) RecordLogin(ctx context.Context, id string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users[id].LastLogin = &at
+	r.users[id].FailedLogins = 0
+	return nil
+}
+
+func (r *fakeUserRepo) RecordFailedLogin(ctx context.Context, id string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users[id].FailedLogins++
+	return r.users[id].FailedLogins, nil
+}
+
+func (r *fakeUserRepo) Lock(ctx context.Context, id string, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users[id].LockedUntil = &until
+	return nil
+}
+
+func (r *fakeUserRepo) UseTOTPStep(ctx context.Context, id string, step int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if step <= r.users[id].MFALastStep {
+		return false, nil
+	}
+	r.users[id].MFALastStep = step
+	return true, nil
+}
+
+type fakeTokenRepo struct {
+	repository.TokenRepository
+	mu       sync.Mutex
+	keys     []*models.SigningKey
+	sessions []*models.Session
+	refresh  []*models.RefreshToken
+	revoked  []*models.RevokedToken
+}
+
+func (r *fakeTokenRepo) CreateSigningKey(ctx context.Context, key *models.SigningKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys = append(r.keys, key)
+	return nil
+}
+
+func (r *fakeTokenRepo) ListSigningKeys(ctx context.Context) ([]*models.SigningKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var keys []*models.SigningKey
+	for _, key := range r.keys {
+		if key.ExpiresAt.After(time.Now()) {
+			copied := *key
+			keys = append(keys, &copied)
+		}
+	}
+	return keys, nil
+}
+
+func (r *fakeTokenRepo) RetireSigningKeys(ctx context.Context, at, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, key := range r.keys {
+		if key.RetiresAt.After(at) {
+			key.RetiresAt = at
+			key.ExpiresAt = expiresAt
+		}
+	}
+	return nil
+}
+
+func (r *fakeTokenRepo) CreateRevokedToken(ctx context.Context, token *models.RevokedToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.revoked = append(r.revoked, token)
+	return nil
+}
+
+func (r *fakeTokenRepo) ListRevokedTokens(ctx context.Context) ([]*models.RevokedToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*models.RevokedToken(nil), r.revoked...), nil
+}
+
+func (r *fakeTokenRepo) CreateSession(ctx context.Context, session *models.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sessions = append(r.sessions, session)
+	return nil
+}
+
+func (r *fakeTokenRepo) GetSession(ctx context.Context, id string) (*models.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, session := range r.sessions {
+		if session.ID == id {
+			copied := *session
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeTokenRepo) ListUserSessions(ctx context.Context, userID string) ([]*models.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var sessions []*models.Session
+	for _, session := range r.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			copied := *session
+			sessions = append(sessions, &copied)
+		}
+	}
+	return sessions, nil
+}
+
+func (r *fakeTokenRepo) TouchSession(ctx context.Context, id string, lastSeen, expiresAt time.Time) error {
+	return nil
+}
+
+func (r *fakeTokenRepo) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for _, token := range r.refresh {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (r *fakeTokenRepo) RevokeUserSessions(ctx context.Context, userID, keepID string) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	var ids []string
+	for _, session := range r.sessions {
+		if session.UserID == userID && session.ID != keepID && session.RevokedAt == nil {
+			session.RevokedAt = &now
+			ids = append(ids, session.ID)
+		}
+	}
+	for _, token := range r.refresh {
+		if token.UserID == userID && token.FamilyID != keepID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+	return ids, nil
+}
+
+func (r *fakeTokenRepo) CreateRefreshToken(ctx context.Context, token *models.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.refresh = append(r.refresh, token)
+	return nil
+}
+
+func (r *fakeTokenRepo) GetRefreshTokenByHash(ctx context.Context, hash string) (*models.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, token := range r.refresh {
+		if token.TokenHash == hash {
+			copied := *token
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeTokenRepo) RotateRefreshToken(ctx context.Context, id, replacedBy string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, token := range r.refresh {
+		if token.ID == id && token.RevokedAt == nil {
+			now := time.Now()
+			token.RevokedAt = &now
+			token.ReplacedBy = replacedBy
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type fakeAuditRepo struct {
+	repository.AuditRepository
+	mu      sync.Mutex
+	entries []*models.AuditEntry
+}
+
+func (r *fakeAuditRepo) Create(ctx context.Context, entry *models.AuditEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func (r *fakeAuditRepo) ForEach(ctx context.Context, params models.AuditQueryParams, fn func(*models.AuditEntry) error) error {
+	for _, entry := range r.entries {
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type fakeServiceRepo struct {
+	repository.ServiceRepository
+	mu       sync.Mutex
+	services map[string]*models.Service
+	deleted  []string
+}
+
+func newFakeServiceRepo(services ...*models.Service) *fakeServiceRepo {
+	r := &fakeServiceRepo{services: make(map[string]*models.Service)}
+	for _, svc := range services {
+		copied := *svc
+		r.services[svc.ID] = &copied
+	}
+	return r
+}
+
+func (r *fakeServiceRepo) GetByID(ctx context.Context, id string) (*models.Service, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if svc, ok := r.services[id]; ok {
+		copied := *svc
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (r *fakeServiceRepo) GetByName(ctx context.Context, namespace, name string) (*models.Service, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, svc := range r.services {
+		if svc.Namespace == namespace && svc.Name == name {
+			copied := *svc
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeServiceRepo) UpdateStatus(ctx context.Context, id string, status models.ServiceStatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if svc, ok := r.services[id]; ok {
+		svc.Status = status
+	}
+	return nil
+}
+
+// ExpireHeartbeat changes the service only while it was last seen at lastSeen,
+// like the conditional update of the database repository
+func (r *fakeServiceRepo) ExpireHeartbeat(ctx context.Context, id string, lastSeen time.Time, status models.ServiceStatus, deregister bool) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	svc, ok := r.services[id]
+	if !ok || !svc.LastSeen.Equal(lastSeen) {
+		return false, nil
+	}
+	svc.Status = status
+	if deregister {
+		now := time.Now()
+		svc.DeregisteredAt = &now
+	}
+	return true, nil
+}
+
+func (r *fakeServiceRepo) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.services, id)
+	r.deleted = append(r.deleted, id)
+	return nil
+}
+
+type fakeHealthRepo struct {
+	repository.HealthRepository
+	mu       sync.Mutex
+	checks   []*models.HealthCheck
+	history  []*models.HealthHistory
+	services *fakeServiceRepo // Receives status updates, if set
+}
+
+func (r *fakeHealthRepo) GetHealthChecks(ctx context.Context, serviceID string) ([]*models.HealthCheck, error) {
+	var checks []*models.HealthCheck
+	for _, check := range r.checks {
+		if check.ServiceID == serviceID {
+			checks = append(checks, check)
+		}
+	}
+	return checks, nil
+}
+
+func (r *fakeHealthRepo) UpdateHealthStatus(ctx context.Context, serviceID string, status models.ServiceStatus, message string) error {
+	return r.services.UpdateStatus(ctx, serviceID, status)
+}
+
+func (r *fakeHealthRepo) RecordHealthHistory(ctx context.Context, history *models.HealthHistory) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.history = append(r.history, history)
+	return nil
+}
+
+type fakeInstanceRepo struct {
+	repository.ServiceInstanceRepository
+	mu        sync.Mutex
+	instances []*models.ServiceInstance // In creation order
+}
+
+func (r *fakeInstanceRepo) Create(ctx context.Context, instance *models.ServiceInstance) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *instance
+	r.instances = append(r.instances, &copied)
+	return nil
+}
+
+func (r *fakeInstanceRepo) find(match func(*models.ServiceInstance) bool) (*models.ServiceInstance, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, instance := range r.instances {
+		if match(instance) {
+			copied := *instance
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeInstanceRepo) GetByID(ctx context.Context, id string) (*models.ServiceInstance, error) {
+	return r.find(func(i *models.ServiceInstance) bool { return i.ID == id })
+}
+
+func (r *fakeInstanceRepo) GetByEndpoint(ctx context.Context, serviceID, endpoint string) (*models.ServiceInstance, error) {
+	return r.find(func(i *models.ServiceInstance) bool { return i.ServiceID == serviceID && i.Endpoint == endpoint })
+}
+
+func (r *fakeInstanceRepo) ListByService(ctx context.Context, serviceID string, params models.InstanceQueryParams) ([]*models.ServiceInstance, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var instances []*models.ServiceInstance
+	for _, instance := range r.instances {
+		if instance.ServiceID == serviceID {
+			copied := *instance
+			instances = append(instances, &copied)
+		}
+	}
+	return instances, nil
+}
+
+func (r *fakeInstanceRepo) Update(ctx context.Context, instance *models.ServiceInstance) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, stored := range r.instances {
+		if stored.ID == instance.ID {
+			copied := *instance
+			r.instances[i] = &copied
+		}
+	}
+	return nil
+}
+
+func (r *fakeInstanceRepo) UpdateStatus(ctx context.Context, id string, status models.ServiceStatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, instance := range r.instances {
+		if instance.ID == id {
+			instance.Status = status
+		}
+	}
+	return nil
+}
+
+func (r *fakeInstanceRepo) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, instance := range r.instances {
+		if instance.ID == id {
+			r.instances = append(r.instances[:i], r.instances[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+type fakeNamespaceRepo struct {
+	repository.NamespaceRepository
+	namespaces []*models.Namespace
+	bindings   []*models.NamespaceBinding
+}
+
+func (r *fakeNamespaceRepo) GetByName(ctx context.Context, name string) (*models.Namespace, error) {
+	for _, namespace := range r.namespaces {
+		if namespace.Name == name {
+			return namespace, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeNamespaceRepo) List(ctx context.Context) ([]*models.Namespace, error) {
+	return append([]*models.Namespace(nil), r.namespaces...), nil
+}
+
+// ListBindingsForUser returns the bindings of the user itself; team
+// membership is resolved by the database repository
+func (r *fakeNamespaceRepo) ListBindingsForUser(ctx context.Context, userID string) ([]*models.NamespaceBinding, error) {
+	var bindings []*models.NamespaceBinding
+	for _, binding := range r.bindings {
+		if binding.UserID != nil && *binding.UserID == userID {
+			bindings = append(bindings, binding)
+		}
+	}
+	return bindings, nil
+}
+
+type fakeServiceAccountRepo struct {
+	repository.ServiceAccountRepository
+	accounts map[string]*models.ServiceAccount
+}
+
+func newFakeServiceAccountRepo() *fakeServiceAccountRepo {
+	return &fakeServiceAccountRepo{accounts: make(map[string]*models.ServiceAccount)}
+}
+
+func (r *fakeServiceAccountRepo) Create(ctx context.Context, account *models.ServiceAccount) error {
+	return r.Update(ctx, account)
+}
+
+func (r *fakeServiceAccountRepo) GetByID(ctx context.Context, id string) (*models.ServiceAccount, error) {
+	account, ok := r.accounts[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *account
+	return &copied, nil
+}
+
+func (r *fakeServiceAccountRepo) GetByName(ctx context.Context, serviceID, name string) (*models.ServiceAccount, error) {
+	for _, account := range r.accounts {
+		if account.ServiceID == serviceID && account.Name == name {
+			copied := *account
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeServiceAccountRepo) Update(ctx context.Context, account *models.ServiceAccount) error {
+	copied := *account
+	r.accounts[account.ID] = &copied
+	return nil
+}
+
+func (r *fakeServiceAccountRepo) Delete(ctx context.Context, id string) error {
+	delete(r.accounts, id)
+	return nil
+}
+
+func (r *fakeServiceAccountRepo) UpdateLastUsed(ctx context.Context, id string, at time.Time) error {
+	if account, ok := r.accounts[id]; ok {
+		account.LastUsedAt = &at
+	}
+	return nil
+}
+
+func newTestLogger() *logger.Logger {
+	return logger.New("error")
+}
+
+func newTestSecretBox(t *testing.T) *security.SecretBox {
+	t.Helper()
+	keys, err := security.SingleKey("test-encryption-key-0123456789abcdef")
+	if err != nil {
+		t.Fatalf("SingleKey: %v", err)
+	}
+	box, err := security.NewSecretBox(keys, "")
+	if err != nil {
+		t.Fatalf("NewSecretBox: %v", err)
+	}
+	return box
+}
+
+// newTestTokenIssuer creates a token issuer with a fresh signing key
+func newTestTokenIssuer(t *testing.T) (*security.KeySet, *security.RevocationList, *security.TokenIssuer) {
+	t.Helper()
+	key, err := security.GenerateSigningKey(security.AlgorithmEdDSA, time.Now().Add(time.Hour), time.Now().Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("GenerateSigningKey: %v", err)
+	}
+	keys := security.NewKeySet()
+	keys.Replace([]*security.SigningKey{key})
+	revoked := security.NewRevocationList()
+	return keys, revoked, security.NewTokenIssuer(keys, revoked, "hermes", time.Minute)
+}
+
+// newTestTokenService creates a token service with a fresh signing key
+func newTestTokenService(t *testing.T, users repository.UserRepository) (*TokenService, *fakeTokenRepo) {
+	t.Helper()
+	keys, revoked, issuer := newTestTokenIssuer(t)
+
+	repo := &fakeTokenRepo{}
+	config := TokenConfig{
+		Algorithm:       security.AlgorithmEdDSA,
+		AccessTokenTTL:  time.Minute,
+		RefreshTokenTTL: time.Hour,
+		KeyRotation:     time.Hour,
+	}
+	return NewTokenService(repo, users, newTestSecretBox(t), keys, revoked, issuer, config, newTestLogger()), repo
+}
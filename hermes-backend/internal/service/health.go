@@ -5,7 +5,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
@@ -13,9 +18,15 @@ import (
 	"github.com/amaydixit11/hermes/hermes-backend/pkg/logger"
 )
 
+// maxHealthCheckBody bounds how much of a response is searched for the expected body
+const maxHealthCheckBody = 1 << 20
+
 type HealthService struct {
 	healthRepo  repository.HealthRepository
 	serviceRepo repository.ServiceRepository
+	instances   *InstanceService
+	encryption  *EncryptionService
+	audit       *AuditService
 	log         *logger.Logger
 	httpClient  *http.Client
 }
@@ -23,11 +34,17 @@ type HealthService struct {
 func NewHealthService(
 	healthRepo repository.HealthRepository,
 	serviceRepo repository.ServiceRepository,
+	instances *InstanceService,
+	encryption *EncryptionService,
+	audit *AuditService,
 	log *logger.Logger,
 ) *HealthService {
 	return &HealthService{
 		healthRepo:  healthRepo,
 		serviceRepo: serviceRepo,
+		instances:   instances,
+		encryption:  encryption,
+		audit:       audit,
 		log:         log,
 		httpClient:  &http.Client{},
 	}
@@ -41,6 +58,11 @@ func (s *HealthService) CreateHealthCheck(ctx context.Context, serviceID string,
 		return nil, fmt.Errorf("service not found: %w", err)
 	}
 
+	// Header values often carry credentials, so they are stored encrypted
+	if err := s.encryption.SealHeaders(req.Headers); err != nil {
+		return nil, fmt.Errorf("failed to encrypt headers: %w", err)
+	}
+
 	// Create health check
 	headersJSON, _ := json.Marshal(req.Headers)
 	check := &models.HealthCheck{
@@ -65,6 +87,7 @@ func (s *HealthService) CreateHealthCheck(ctx context.Context, serviceID string,
 	}
 
 	s.log.Info("Created health check id=%d for service=%s", check.ID, serviceID)
+	s.audit.Record(ctx, models.AuditActionCreate, AuditResourceHealthCheck, strconv.FormatUint(uint64(check.ID), 10), nil, check)
 	return check, nil
 }
 
@@ -80,6 +103,7 @@ func (s *HealthService) UpdateHealthCheck(ctx context.Context, id uint, req mode
 	if err != nil {
 		return nil, fmt.Errorf("health check not found: %w", err)
 	}
+	before := auditSnapshot(check)
 
 	// Update fields
 	if req.Name != "" {
@@ -107,6 +131,9 @@ func (s *HealthService) UpdateHealthCheck(ctx context.Context, id uint, req mode
 		check.ExpectedBody = req.ExpectedBody
 	}
 	if req.Headers != nil {
+		if err := s.encryption.SealHeaders(req.Headers); err != nil {
+			return nil, fmt.Errorf("failed to encrypt headers: %w", err)
+		}
 		headersJSON, _ := json.Marshal(req.Headers)
 		check.Headers = string(headersJSON)
 	}
@@ -126,11 +153,22 @@ func (s *HealthService) UpdateHealthCheck(ctx context.Context, id uint, req mode
 	}
 
 	s.log.Info("Updated health check id=%d", id)
+	s.audit.Record(ctx, models.AuditActionUpdate, AuditResourceHealthCheck, strconv.FormatUint(uint64(id), 10), before, check)
 	return check, nil
 }
 
 func (s *HealthService) DeleteHealthCheck(ctx context.Context, id uint) error {
-	return s.healthRepo.DeleteHealthCheck(ctx, id)
+	check, err := s.healthRepo.GetHealthCheck(ctx, id)
+	if err != nil {
+		return fmt.Errorf("health check not found: %w", err)
+	}
+
+	if err := s.healthRepo.DeleteHealthCheck(ctx, id); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, models.AuditActionDelete, AuditResourceHealthCheck, strconv.FormatUint(uint64(id), 10), check, nil)
+	return nil
 }
 
 // Health reporting
@@ -216,6 +254,7 @@ func (s *HealthService) CreateHealthThreshold(ctx context.Context, serviceID str
 		return nil, fmt.Errorf("failed to create health threshold: %w", err)
 	}
 
+	s.audit.Record(ctx, models.AuditActionCreate, AuditResourceThreshold, strconv.FormatUint(uint64(threshold.ID), 10), nil, threshold)
 	return threshold, nil
 }
 
@@ -225,22 +264,11 @@ func (s *HealthService) GetHealthThresholds(ctx context.Context, serviceID strin
 
 func (s *HealthService) UpdateHealthThreshold(ctx context.Context, id uint, req models.HealthThresholdRequest) (*models.HealthThreshold, error) {
 	// Get the existing threshold
-	thresholds, err := s.healthRepo.GetHealthThresholds(ctx, "")
+	threshold, err := s.healthRepo.GetHealthThreshold(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve health thresholds: %w", err)
-	}
-
-	var threshold *models.HealthThreshold
-	for _, t := range thresholds {
-		if t.ID == id {
-			threshold = t
-			break
-		}
-	}
-
-	if threshold == nil {
-		return nil, fmt.Errorf("health threshold with ID %d not found", id)
+		return nil, fmt.Errorf("health threshold with ID %d not found: %w", id, err)
 	}
+	before := auditSnapshot(threshold)
 
 	// Update fields
 	if req.MetricName != "" {
@@ -257,57 +285,56 @@ func (s *HealthService) UpdateHealthThreshold(ctx context.Context, id uint, req
 		return nil, fmt.Errorf("failed to update health threshold: %w", err)
 	}
 
+	s.audit.Record(ctx, models.AuditActionUpdate, AuditResourceThreshold, strconv.FormatUint(uint64(id), 10), before, threshold)
 	return threshold, nil
 }
 
 func (s *HealthService) DeleteHealthThreshold(ctx context.Context, id uint) error {
-	return s.healthRepo.DeleteHealthThreshold(ctx, id)
+	threshold, err := s.healthRepo.GetHealthThreshold(ctx, id)
+	if err != nil {
+		return fmt.Errorf("health threshold with ID %d not found: %w", id, err)
+	}
+
+	if err := s.healthRepo.DeleteHealthThreshold(ctx, id); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, models.AuditActionDelete, AuditResourceThreshold, strconv.FormatUint(uint64(id), 10), threshold, nil)
+	return nil
 }
 
 // Active health checking
+//
+// RunActiveHealthCheck probes every instance of the service, resolving the
+// check endpoint's path against the instance endpoint, or the check endpoint
+// itself for services without instances.
 func (s *HealthService) RunActiveHealthCheck(ctx context.Context, check *models.HealthCheck) error {
 	s.log.Debug("Running active health check for check_id=%d service_id=%s", check.ID, check.ServiceID)
 
-	// Create HTTP request with timeout
-	reqCtx, cancel := context.WithTimeout(ctx, time.Duration(check.Timeout)*time.Second)
-	defer cancel()
-
-	// Create request
-	req, err := http.NewRequestWithContext(reqCtx, check.Method, check.Endpoint, nil)
+	instances, err := s.instances.ListInstances(ctx, check.ServiceID, models.InstanceQueryParams{})
 	if err != nil {
-		s.log.Error("Failed to create request: %v", err)
-		return s.handleHealthCheckFailure(ctx, check)
+		return err
 	}
-
-	// Add headers if specified
-	if check.Headers != "" {
-		var headers map[string]string
-		if err := json.Unmarshal([]byte(check.Headers), &headers); err == nil {
-			for key, value := range headers {
-				req.Header.Add(key, value)
-			}
+	if len(instances) > 0 {
+		var wg sync.WaitGroup
+		for _, instance := range instances {
+			wg.Add(1)
+			go func(instance *models.ServiceInstance) {
+				defer wg.Done()
+				if err := s.runInstanceHealthCheck(ctx, check, instance); err != nil {
+					s.log.Error("Failed to run health check for instance %s: %v", instance.ID, err)
+				}
+			}(instance)
 		}
+		wg.Wait()
+		return nil
 	}
 
-	// Execute request and measure time
-	startTime := time.Now()
-	resp, err := s.httpClient.Do(req)
-	responseTime := time.Since(startTime).Milliseconds()
-
-	// Handle request errors
+	statusCode, responseTime, err := s.probe(ctx, check, check.Endpoint)
 	if err != nil {
 		s.log.Error("Health check request failed: %v", err)
 		return s.handleHealthCheckFailure(ctx, check)
 	}
-	defer resp.Body.Close()
-
-	// Check if response meets expected status code
-	if check.ExpectedStatus > 0 && resp.StatusCode != check.ExpectedStatus {
-		s.log.Warn("Health check status code mismatch: expected=%d got=%d", check.ExpectedStatus, resp.StatusCode)
-		return s.handleHealthCheckFailure(ctx, check)
-	}
-
-	// TODO: Check expected body if configured
 
 	// Health check passed, reset failure count
 	err = s.healthRepo.ResetHealthCheckFailures(ctx, check.ID)
@@ -320,8 +347,8 @@ func (s *HealthService) RunActiveHealthCheck(ctx context.Context, check *models.
 		ServiceID:      check.ServiceID,
 		CheckID:        check.ID,
 		Status:         models.ServiceStatusHealthy,
-		ResponseTimeMs: int(responseTime),
-		StatusCode:     resp.StatusCode,
+		ResponseTimeMs: responseTime,
+		StatusCode:     statusCode,
 		Timestamp:      time.Now(),
 	}
 	err = s.healthRepo.RecordHealthHistory(ctx, history)
@@ -331,7 +358,7 @@ func (s *HealthService) RunActiveHealthCheck(ctx context.Context, check *models.
 
 	// Update service status to healthy if needed
 	service, err := s.serviceRepo.GetByID(ctx, check.ServiceID)
-	if err != nil {
+	if err != nil || service == nil {
 		s.log.Error("Failed to get service: %v", err)
 		return nil
 	}
@@ -347,6 +374,103 @@ func (s *HealthService) RunActiveHealthCheck(ctx context.Context, check *models.
 	return nil
 }
 
+// runInstanceHealthCheck probes one instance and records the result
+func (s *HealthService) runInstanceHealthCheck(ctx context.Context, check *models.HealthCheck, instance *models.ServiceInstance) error {
+	statusCode, responseTime, probeErr := s.probe(ctx, check, instanceTarget(instance.Endpoint, check.Endpoint))
+
+	history := &models.HealthHistory{
+		ServiceID:      check.ServiceID,
+		CheckID:        check.ID,
+		InstanceID:     instance.ID,
+		Status:         models.ServiceStatusHealthy,
+		ResponseTimeMs: responseTime,
+		StatusCode:     statusCode,
+		Timestamp:      time.Now(),
+	}
+	if probeErr != nil {
+		history.Status = models.ServiceStatusUnhealthy
+		history.Message = "Health check failed: " + probeErr.Error()
+	}
+	if err := s.healthRepo.RecordHealthHistory(ctx, history); err != nil {
+		s.log.Error("Failed to record health history: %v", err)
+	}
+
+	if probeErr != nil {
+		return s.instances.checkFailed(ctx, instance, check)
+	}
+	return s.instances.checkPassed(ctx, instance)
+}
+
+// probe sends a health check request to target and returns the status code
+// and response time in milliseconds
+func (s *HealthService) probe(ctx context.Context, check *models.HealthCheck, target string) (int, int, error) {
+	// Create HTTP request with timeout
+	reqCtx, cancel := context.WithTimeout(ctx, time.Duration(check.Timeout)*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, check.Method, target, nil)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Add headers if specified
+	if check.Headers != "" {
+		var headers map[string]string
+		if err := json.Unmarshal([]byte(check.Headers), &headers); err == nil {
+			headers, err = s.encryption.OpenHeaders(headers)
+			if err != nil {
+				return 0, 0, fmt.Errorf("failed to decrypt health check headers: %w", err)
+			}
+			for key, value := range headers {
+				req.Header.Add(key, value)
+			}
+		}
+	}
+
+	// Execute request and measure time
+	startTime := time.Now()
+	resp, err := s.httpClient.Do(req)
+	responseTime := int(time.Since(startTime).Milliseconds())
+	if err != nil {
+		return 0, responseTime, err
+	}
+	defer resp.Body.Close()
+
+	// Check if response meets expected status code
+	if check.ExpectedStatus > 0 && resp.StatusCode != check.ExpectedStatus {
+		return resp.StatusCode, responseTime, fmt.Errorf("status code mismatch: expected=%d got=%d", check.ExpectedStatus, resp.StatusCode)
+	}
+
+	// Check if response body contains the expected text
+	if check.ExpectedBody != "" {
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxHealthCheckBody))
+		if err != nil {
+			return resp.StatusCode, responseTime, fmt.Errorf("failed to read response body: %w", err)
+		}
+		if !strings.Contains(string(body), check.ExpectedBody) {
+			return resp.StatusCode, responseTime, fmt.Errorf("response body does not contain %q", check.ExpectedBody)
+		}
+	}
+	return resp.StatusCode, responseTime, nil
+}
+
+// instanceTarget resolves the path and query of a health check endpoint
+// against an instance endpoint
+func instanceTarget(instanceEndpoint, checkEndpoint string) string {
+	if checkEndpoint == "" {
+		return instanceEndpoint
+	}
+	ref, err := url.Parse(checkEndpoint)
+	if err != nil {
+		return instanceEndpoint
+	}
+	target := strings.TrimRight(instanceEndpoint, "/") + "/" + strings.TrimLeft(ref.Path, "/")
+	if ref.RawQuery != "" {
+		target += "?" + ref.RawQuery
+	}
+	return target
+}
+
 func (s *HealthService) handleHealthCheckFailure(ctx context.Context, check *models.HealthCheck) error {
 	// Increment failure count
 	err := s.healthRepo.IncrementHealthCheckFailures(ctx, check.ID)
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+)
+
+func TestHealthProbe(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/healthz":
+			w.Write([]byte(`{"status":"ok","db":"up"}`))
+		case "/degraded":
+			w.Write([]byte(`{"status":"degraded"}`))
+		case "/large":
+			w.Write([]byte(strings.Repeat(" ", maxHealthCheckBody) + "ok"))
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer upstream.Close()
+
+	tests := []struct {
+		name     string
+		path     string
+		status   int
+		body     string
+		wantCode int
+		wantErr  bool
+	}{
+		{"any response", "/healthz", 0, "", http.StatusOK, false},
+		{"expected status", "/healthz", http.StatusOK, "", http.StatusOK, false},
+		{"unexpected status", "/down", http.StatusOK, "", http.StatusServiceUnavailable, true},
+		{"expected body", "/healthz", http.StatusOK, `"status":"ok"`, http.StatusOK, false},
+		{"unexpected body", "/degraded", http.StatusOK, `"status":"ok"`, http.StatusOK, true},
+		{"body past the read limit", "/large", 0, "ok", http.StatusOK, true},
+	}
+	s := NewHealthService(nil, nil, nil, nil, nil, newTestLogger())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := &models.HealthCheck{Method: http.MethodGet, Timeout: 5, ExpectedStatus: tt.status, ExpectedBody: tt.body}
+			code, _, err := s.probe(context.Background(), check, upstream.URL+tt.path)
+			if code != tt.wantCode || (err != nil) != tt.wantErr {
+				t.Errorf("probe = %d, %v, want %d, error %v", code, err, tt.wantCode, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,262 @@
+// internal/service/heartbeat.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/repository"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/errors"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/logger"
+)
+
+// ErrInvalidHeartbeatStatus is returned when a heartbeat reports an unknown status
+var ErrInvalidHeartbeatStatus = errors.New("invalid heartbeat status, must be one of: HEALTHY, WARNING, UNHEALTHY")
+
+// ErrInvalidHeartbeatTTL is returned for a negative heartbeat TTL
+var ErrInvalidHeartbeatTTL = errors.New("heartbeat ttl must not be negative")
+
+// HeartbeatPolicy decides what happens to services that stop sending
+// heartbeats. Steps are counted in TTLs missed since the last heartbeat, and
+// a zero step is skipped.
+type HeartbeatPolicy struct {
+	DefaultTTL      int  // in seconds, for services sending heartbeats without a TTL
+	UnknownAfter    int  // Missed TTLs before the service is marked UNKNOWN
+	UnhealthyAfter  int  // Missed TTLs before the service is marked UNHEALTHY
+	DeregisterAfter int  // Missed TTLs before the service is deregistered
+	Delete          bool // Delete deregistered services instead of keeping them until their next heartbeat
+}
+
+// step returns the status reached after missing heartbeats, and whether the
+// service or instance is deregistered
+func (p HeartbeatPolicy) step(status models.ServiceStatus, missed int) (models.ServiceStatus, bool) {
+	switch {
+	case p.DeregisterAfter > 0 && missed >= p.DeregisterAfter:
+		return models.ServiceStatusUnhealthy, true
+	case p.UnhealthyAfter > 0 && missed >= p.UnhealthyAfter:
+		return models.ServiceStatusUnhealthy, false
+	case p.UnknownAfter > 0 && missed >= p.UnknownAfter:
+		// An unhealthy service stays unhealthy until it is heard from again
+		if status == models.ServiceStatusUnhealthy {
+			return status, false
+		}
+		return models.ServiceStatusUnknown, false
+	default:
+		return status, false
+	}
+}
+
+// HeartbeatService records service heartbeats and expires services that
+// stopped sending them, so that crashed services and instances do not stay
+// registered. Instances missing their heartbeats are always deleted when
+// deregistered, a restarted replica registers again.
+type HeartbeatService struct {
+	serviceRepo repository.ServiceRepository
+	healthRepo  repository.HealthRepository
+	instances   *InstanceService
+	policy      HeartbeatPolicy
+	audit       *AuditService
+	log         *logger.Logger
+}
+
+// NewHeartbeatService creates a new HeartbeatService
+func NewHeartbeatService(serviceRepo repository.ServiceRepository, healthRepo repository.HealthRepository, instances *InstanceService, policy HeartbeatPolicy, audit *AuditService, log *logger.Logger) *HeartbeatService {
+	return &HeartbeatService{
+		serviceRepo: serviceRepo,
+		healthRepo:  healthRepo,
+		instances:   instances,
+		policy:      policy,
+		audit:       audit,
+		log:         log,
+	}
+}
+
+// Heartbeat marks a service as alive with the reported status. A service
+// deregistered after missing its heartbeats is registered again.
+func (s *HeartbeatService) Heartbeat(ctx context.Context, serviceID string, req models.ServiceHeartbeat) (*models.Service, error) {
+	req, err := validateHeartbeat(req)
+	if err != nil {
+		return nil, err
+	}
+
+	service, err := s.serviceRepo.GetByID(ctx, serviceID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve service")
+	}
+	if service == nil {
+		return nil, ErrServiceNotFound
+	}
+
+	ttl := req.TTL
+	if ttl == 0 && service.HeartbeatTTL == 0 {
+		ttl = s.policy.DefaultTTL
+	}
+	if err := s.serviceRepo.RecordHeartbeat(ctx, serviceID, req.Status, ttl); err != nil {
+		return nil, errors.Wrap(err, "failed to record heartbeat")
+	}
+
+	if service.DeregisteredAt != nil {
+		s.log.Info("Deregistered service sent a heartbeat, registering it again", "id", service.ID, "name", service.Name)
+	}
+	if service.Status != req.Status || service.DeregisteredAt != nil {
+		message := req.Message
+		if message == "" {
+			message = "Heartbeat received"
+		}
+		s.recordHistory(ctx, service.ID, req.Status, message)
+	}
+
+	updated, err := s.serviceRepo.GetByID(ctx, serviceID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve service")
+	}
+	if updated == nil {
+		return nil, ErrServiceNotFound
+	}
+	if service.DeregisteredAt != nil {
+		s.audit.Record(ctx, models.AuditActionUpdate, AuditResourceService, service.ID, service, updated)
+	}
+	return updated, nil
+}
+
+// InstanceHeartbeat marks an instance of a service as alive with the reported status
+func (s *HeartbeatService) InstanceHeartbeat(ctx context.Context, serviceID, instanceID string, req models.ServiceHeartbeat) (*models.ServiceInstance, error) {
+	req, err := validateHeartbeat(req)
+	if err != nil {
+		return nil, err
+	}
+
+	instance, err := s.instances.GetInstance(ctx, serviceID, instanceID)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := req.TTL
+	if ttl == 0 && instance.HeartbeatTTL == 0 {
+		ttl = s.policy.DefaultTTL
+	}
+	if err := s.instances.recordHeartbeat(ctx, instance, req.Status, ttl, req.Message); err != nil {
+		return nil, err
+	}
+	return s.instances.GetInstance(ctx, serviceID, instanceID)
+}
+
+// ExpireHeartbeats moves services and instances that missed their heartbeats
+// through the steps of the policy. Failures are logged per service or
+// instance so that one does not hold back the others.
+func (s *HeartbeatService) ExpireHeartbeats(ctx context.Context) error {
+	now := time.Now()
+	services, err := s.serviceRepo.ListMissedHeartbeats(ctx, now)
+	if err != nil {
+		return errors.Wrap(err, "failed to list services with missed heartbeats")
+	}
+	for _, service := range services {
+		if err := s.expire(ctx, service, missedHeartbeats(now, service.LastSeen, service.HeartbeatTTL)); err != nil {
+			s.log.Error("Failed to expire service heartbeat", "id", service.ID, "name", service.Name, "error", err)
+		}
+	}
+
+	instances, err := s.instances.listMissedHeartbeats(ctx, now)
+	if err != nil {
+		return err
+	}
+	for _, instance := range instances {
+		missed := missedHeartbeats(now, instance.LastSeen, instance.HeartbeatTTL)
+		status, deregister := s.policy.step(instance.Status, missed)
+		if status == instance.Status && !deregister {
+			continue
+		}
+		if err := s.instances.expireHeartbeat(ctx, instance, status, deregister, missed); err != nil {
+			s.log.Error("Failed to expire instance heartbeat", "id", instance.ID, "service_id", instance.ServiceID, "error", err)
+		}
+	}
+	return nil
+}
+
+// expire applies the furthest step of the policy reached after missed TTLs
+func (s *HeartbeatService) expire(ctx context.Context, service *models.Service, missed int) error {
+	status, deregister := s.policy.step(service.Status, missed)
+	if deregister {
+		return s.deregister(ctx, service, missed)
+	}
+	if service.Status == status {
+		return nil
+	}
+
+	changed, err := s.serviceRepo.ExpireHeartbeat(ctx, service.ID, service.LastSeen, status, false)
+	if err != nil {
+		return errors.Wrap(err, "failed to update service status")
+	}
+	if !changed {
+		return nil
+	}
+	s.log.Warn("Service missed its heartbeats", "id", service.ID, "name", service.Name, "missed", missed, "status", status)
+	s.recordHistory(ctx, service.ID, status, fmt.Sprintf("Missed %d heartbeats", missed))
+	return nil
+}
+
+// deregister hides a service that missed too many heartbeats from discovery,
+// or deletes it when the policy says so
+func (s *HeartbeatService) deregister(ctx context.Context, service *models.Service, missed int) error {
+	changed, err := s.serviceRepo.ExpireHeartbeat(ctx, service.ID, service.LastSeen, models.ServiceStatusUnhealthy, true)
+	if err != nil {
+		return errors.Wrap(err, "failed to deregister service")
+	}
+	if !changed {
+		return nil
+	}
+
+	if s.policy.Delete {
+		if err := s.serviceRepo.Delete(ctx, service.ID); err != nil {
+			return errors.Wrap(err, "failed to delete service")
+		}
+		s.log.Warn("Service deleted after missing its heartbeats", "id", service.ID, "name", service.Name, "missed", missed)
+		s.audit.Record(ctx, models.AuditActionDelete, AuditResourceService, service.ID, service, nil)
+		return nil
+	}
+
+	s.log.Warn("Service deregistered after missing its heartbeats", "id", service.ID, "name", service.Name, "missed", missed)
+	s.recordHistory(ctx, service.ID, models.ServiceStatusUnhealthy, fmt.Sprintf("Deregistered after missing %d heartbeats", missed))
+	before := auditSnapshot(service)
+	now := time.Now()
+	service.Status = models.ServiceStatusUnhealthy
+	service.DeregisteredAt = &now
+	s.audit.Record(ctx, models.AuditActionUpdate, AuditResourceService, service.ID, before, service)
+	return nil
+}
+
+// validateHeartbeat checks a heartbeat, defaulting its status to HEALTHY
+func validateHeartbeat(req models.ServiceHeartbeat) (models.ServiceHeartbeat, error) {
+	if req.Status == "" {
+		req.Status = models.ServiceStatusHealthy
+	}
+	switch req.Status {
+	case models.ServiceStatusHealthy, models.ServiceStatusWarning, models.ServiceStatusUnhealthy:
+	default:
+		return req, ErrInvalidHeartbeatStatus
+	}
+	if req.TTL < 0 {
+		return req, ErrInvalidHeartbeatTTL
+	}
+	return req, nil
+}
+
+// missedHeartbeats returns how many TTLs passed since lastSeen
+func missedHeartbeats(now, lastSeen time.Time, ttl int) int {
+	return int(now.Sub(lastSeen) / (time.Duration(ttl) * time.Second))
+}
+
+// recordHistory adds a heartbeat status change to the health history of a service
+func (s *HeartbeatService) recordHistory(ctx context.Context, serviceID string, status models.ServiceStatus, message string) {
+	history := &models.HealthHistory{
+		ServiceID: serviceID,
+		Status:    status,
+		Message:   message,
+		Timestamp: time.Now(),
+	}
+	if err := s.healthRepo.RecordHealthHistory(ctx, history); err != nil {
+		s.log.Error("Failed to record health history", "id", serviceID, "error", err)
+	}
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+)
+
+var testHeartbeatPolicy = HeartbeatPolicy{
+	DefaultTTL:      30,
+	UnknownAfter:    1,
+	UnhealthyAfter:  3,
+	DeregisterAfter: 10,
+}
+
+func TestHeartbeatPolicyStep(t *testing.T) {
+	tests := []struct {
+		name           string
+		policy         HeartbeatPolicy
+		status         models.ServiceStatus
+		missed         int
+		wantStatus     models.ServiceStatus
+		wantDeregister bool
+	}{
+		{"on time", testHeartbeatPolicy, models.ServiceStatusHealthy, 0, models.ServiceStatusHealthy, false},
+		{"one missed", testHeartbeatPolicy, models.ServiceStatusHealthy, 1, models.ServiceStatusUnknown, false},
+		{"warning becomes unknown", testHeartbeatPolicy, models.ServiceStatusWarning, 2, models.ServiceStatusUnknown, false},
+		{"unhealthy stays unhealthy", testHeartbeatPolicy, models.ServiceStatusUnhealthy, 1, models.ServiceStatusUnhealthy, false},
+		{"unhealthy threshold", testHeartbeatPolicy, models.ServiceStatusUnknown, 3, models.ServiceStatusUnhealthy, false},
+		{"deregister threshold", testHeartbeatPolicy, models.ServiceStatusUnhealthy, 10, models.ServiceStatusUnhealthy, true},
+		{"far past deregister", testHeartbeatPolicy, models.ServiceStatusHealthy, 100, models.ServiceStatusUnhealthy, true},
+		{"skipped unknown step", HeartbeatPolicy{UnhealthyAfter: 2}, models.ServiceStatusHealthy, 1, models.ServiceStatusHealthy, false},
+		{"skipped deregister step", HeartbeatPolicy{UnhealthyAfter: 2}, models.ServiceStatusHealthy, 100, models.ServiceStatusUnhealthy, false},
+		{"empty policy", HeartbeatPolicy{}, models.ServiceStatusHealthy, 100, models.ServiceStatusHealthy, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, deregister := tt.policy.step(tt.status, tt.missed)
+			if status != tt.wantStatus || deregister != tt.wantDeregister {
+				t.Errorf("step(%s, %d) = %s, %v, want %s, %v", tt.status, tt.missed, status, deregister, tt.wantStatus, tt.wantDeregister)
+			}
+		})
+	}
+}
+
+func TestMissedHeartbeats(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		since time.Duration
+		ttl   int
+		want  int
+	}{
+		{0, 30, 0},
+		{29 * time.Second, 30, 0},
+		{30 * time.Second, 30, 1},
+		{95 * time.Second, 30, 3},
+		{time.Hour, 60, 60},
+	}
+	for _, tt := range tests {
+		if got := missedHeartbeats(now, now.Add(-tt.since), tt.ttl); got != tt.want {
+			t.Errorf("missedHeartbeats(%s ago, ttl %d) = %d, want %d", tt.since, tt.ttl, got, tt.want)
+		}
+	}
+}
+
+func TestHeartbeatExpire(t *testing.T) {
+	lastSeen := time.Now().Add(-time.Hour).Truncate(time.Second)
+	tests := []struct {
+		name           string
+		policy         HeartbeatPolicy
+		status         models.ServiceStatus
+		missed         int
+		wantStatus     models.ServiceStatus
+		wantDeregister bool
+		wantDeleted    bool
+		wantHistory    int
+		wantAudit      models.AuditAction
+	}{
+		{"unchanged", testHeartbeatPolicy, models.ServiceStatusHealthy, 0, models.ServiceStatusHealthy, false, false, 0, ""},
+		{"unknown", testHeartbeatPolicy, models.ServiceStatusHealthy, 1, models.ServiceStatusUnknown, false, false, 1, ""},
+		{"already unknown", testHeartbeatPolicy, models.ServiceStatusUnknown, 2, models.ServiceStatusUnknown, false, false, 0, ""},
+		{"unhealthy", testHeartbeatPolicy, models.ServiceStatusUnknown, 5, models.ServiceStatusUnhealthy, false, false, 1, ""},
+		{"deregistered", testHeartbeatPolicy, models.ServiceStatusUnhealthy, 10, models.ServiceStatusUnhealthy, true, false, 1, models.AuditActionUpdate},
+		{"deleted", HeartbeatPolicy{DeregisterAfter: 2, Delete: true}, models.ServiceStatusHealthy, 2, "", false, true, 0, models.AuditActionDelete},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &models.Service{ID: "svc-1", Name: "api", Status: tt.status, LastSeen: lastSeen, HeartbeatTTL: 30}
+			services := newFakeServiceRepo(service)
+			health := &fakeHealthRepo{}
+			audits := &fakeAuditRepo{}
+			s := NewHeartbeatService(services, health, nil, tt.policy, NewAuditService(audits, newTestLogger()), newTestLogger())
+
+			if err := s.expire(context.Background(), service, tt.missed); err != nil {
+				t.Fatalf("expire: %v", err)
+			}
+
+			stored, _ := services.GetByID(context.Background(), "svc-1")
+			if tt.wantDeleted {
+				if stored != nil || len(services.deleted) != 1 {
+					t.Fatalf("service was not deleted")
+				}
+			} else {
+				if stored == nil {
+					t.Fatalf("service was deleted")
+				}
+				if stored.Status != tt.wantStatus {
+					t.Errorf("status = %s, want %s", stored.Status, tt.wantStatus)
+				}
+				if (stored.DeregisteredAt != nil) != tt.wantDeregister {
+					t.Errorf("deregistered = %v, want %v", stored.DeregisteredAt != nil, tt.wantDeregister)
+				}
+			}
+			if len(health.history) != tt.wantHistory {
+				t.Errorf("%d history entries, want %d", len(health.history), tt.wantHistory)
+			}
+			switch {
+			case tt.wantAudit == "" && len(audits.entries) != 0:
+				t.Errorf("%d audit entries, want none", len(audits.entries))
+			case tt.wantAudit != "" && (len(audits.entries) != 1 || audits.entries[0].Action != tt.wantAudit):
+				t.Errorf("audit entries = %+v, want one %s", audits.entries, tt.wantAudit)
+			}
+		})
+	}
+}
+
+func TestHeartbeatExpireSkipsServicesHeardFromSinceListing(t *testing.T) {
+	listed := &models.Service{ID: "svc-1", Name: "api", Status: models.ServiceStatusHealthy, LastSeen: time.Now().Add(-time.Hour), HeartbeatTTL: 30}
+	// A heartbeat arrived after the missed services were listed
+	current := *listed
+	current.LastSeen = time.Now()
+	services := newFakeServiceRepo(&current)
+	health := &fakeHealthRepo{}
+	audits := &fakeAuditRepo{}
+	s := NewHeartbeatService(services, health, nil, testHeartbeatPolicy, NewAuditService(audits, newTestLogger()), newTestLogger())
+
+	for _, missed := range []int{1, 10} {
+		if err := s.expire(context.Background(), listed, missed); err != nil {
+			t.Fatalf("expire: %v", err)
+		}
+	}
+	stored, _ := services.GetByID(context.Background(), "svc-1")
+	if stored.Status != models.ServiceStatusHealthy || stored.DeregisteredAt != nil {
+		t.Errorf("service changed to %s, deregistered %v", stored.Status, stored.DeregisteredAt)
+	}
+	if len(health.history) != 0 || len(audits.entries) != 0 {
+		t.Errorf("recorded %d history and %d audit entries", len(health.history), len(audits.entries))
+	}
+}
+
+func TestGetServiceByNameHidesDeregisteredServices(t *testing.T) {
+	deregisteredAt := time.Now()
+	services := newFakeServiceRepo(
+		&models.Service{ID: "svc-1", Namespace: models.DefaultNamespace, Name: "live"},
+		&models.Service{ID: "svc-2", Namespace: models.DefaultNamespace, Name: "gone", DeregisteredAt: &deregisteredAt},
+	)
+	s := NewServiceService(services, nil, nil, nil, nil, nil, newTestLogger())
+
+	live, err := s.GetServiceByName(context.Background(), "", "live")
+	if err != nil || live == nil || live.ID != "svc-1" {
+		t.Errorf("GetServiceByName(live) = %+v, %v", live, err)
+	}
+	gone, err := s.GetServiceByName(context.Background(), "", "gone")
+	if err != nil || gone != nil {
+		t.Errorf("GetServiceByName(gone) = %+v, %v, want not found", gone, err)
+	}
+}
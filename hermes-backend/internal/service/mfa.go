@@ -0,0 +1,371 @@
+// internal/service/mfa.go
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/repository"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/security"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/errors"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidMFACode is returned when a TOTP or recovery code is wrong or was already used
+var ErrInvalidMFACode = errors.New("invalid authentication code")
+
+// ErrInvalidMFAToken is returned when a login's MFA token is unknown or expired
+var ErrInvalidMFAToken = errors.New("invalid or expired MFA token")
+
+// ErrMFAAlreadyEnabled is returned when enrolling a user who already has MFA
+var ErrMFAAlreadyEnabled = errors.New("multi-factor authentication is already enabled")
+
+// ErrMFANotEnabled is returned when changing MFA of a user who has none
+var ErrMFANotEnabled = errors.New("multi-factor authentication is not enabled")
+
+// ErrMFANotEnrolled is returned when confirming MFA before enrolling
+var ErrMFANotEnrolled = errors.New("no TOTP enrollment is pending")
+
+// mfaChallengeTTL is how long a login may wait for its second factor
+const mfaChallengeTTL = 5 * time.Minute
+
+// mfaChallengeAttempts is how many wrong codes a login may send before it has to start over
+const mfaChallengeAttempts = 5
+
+// recoveryCodeCount is how many recovery codes a user gets
+const recoveryCodeCount = 10
+
+// MFAService manages TOTP enrollment and recovery codes of users, and the
+// second step of their logins. Wrong codes count towards the lockout of the
+// account like failed logins, so codes cannot be guessed with a stolen
+// access token either.
+type MFAService struct {
+	userRepo  repository.UserRepository
+	tokenRepo repository.TokenRepository
+	box       *security.SecretBox
+	issuer    string // Shown by authenticator apps
+	lockout   LockoutPolicy
+	audit     *AuditService
+	log       *logger.Logger
+}
+
+// NewMFAService creates a new MFAService. TOTP secrets are stored encrypted with box.
+func NewMFAService(userRepo repository.UserRepository, tokenRepo repository.TokenRepository, box *security.SecretBox, issuer string, lockout LockoutPolicy, audit *AuditService, log *logger.Logger) *MFAService {
+	return &MFAService{
+		userRepo:  userRepo,
+		tokenRepo: tokenRepo,
+		box:       box,
+		issuer:    issuer,
+		lockout:   lockout,
+		audit:     audit,
+		log:       log,
+	}
+}
+
+// EnrollTOTP generates a TOTP secret for a user's authenticator app. MFA is
+// only enabled once a code from it is confirmed; enrolling again replaces
+// the pending secret. Users with a password must confirm it, so a stolen
+// access token cannot enroll an attacker's authenticator.
+func (s *MFAService) EnrollTOTP(ctx context.Context, userID, password string) (*models.TOTPEnrollment, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.MFAEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if user.PasswordHash != "" && !security.CheckPassword(user.PasswordHash, password) {
+		return nil, ErrInvalidCredentials
+	}
+
+	secret, err := security.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := s.box.SealString(secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encrypt TOTP secret")
+	}
+	user.MFASecret = sealed
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, errors.Wrap(err, "failed to store TOTP secret")
+	}
+
+	s.log.Info("TOTP enrollment started", "id", user.ID, "username", user.Username)
+	return &models.TOTPEnrollment{
+		Secret: secret,
+		URL:    security.TOTPURL(s.issuer, user.Username, secret),
+	}, nil
+}
+
+// ConfirmTOTP enables MFA once code matches the pending secret and returns
+// the user's recovery codes
+func (s *MFAService) ConfirmTOTP(ctx context.Context, userID, code string) (*models.RecoveryCodesResponse, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.MFAEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if user.MFASecret == "" {
+		return nil, ErrMFANotEnrolled
+	}
+	if accountLocked(user) {
+		return nil, ErrAccountLocked
+	}
+
+	step, ok := s.verifyTOTP(user, code)
+	if !ok {
+		s.failCode(ctx, user)
+		return nil, ErrInvalidMFACode
+	}
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	before := auditSnapshot(user)
+	user.MFAEnabled = true
+	user.MFALastStep = step
+	user.RecoveryCodes = hashes
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, errors.Wrap(err, "failed to enable MFA")
+	}
+
+	s.log.Info("MFA enabled", "id", user.ID, "username", user.Username)
+	s.audit.Record(ctx, models.AuditActionUpdate, AuditResourceUser, user.ID, before, user)
+	return &models.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// DisableTOTP turns MFA off after verifying a TOTP or recovery code
+func (s *MFAService) DisableTOTP(ctx context.Context, userID string, req models.MFACodeRequest) error {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.MFAEnabled {
+		return ErrMFANotEnabled
+	}
+	if accountLocked(user) {
+		return ErrAccountLocked
+	}
+
+	ok, err := s.Verify(ctx, user, req)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		s.failCode(ctx, user)
+		return ErrInvalidMFACode
+	}
+	return s.disable(ctx, user)
+}
+
+// RegenerateRecoveryCodes replaces a user's recovery codes after verifying a TOTP code
+func (s *MFAService) RegenerateRecoveryCodes(ctx context.Context, userID, code string) (*models.RecoveryCodesResponse, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.MFAEnabled {
+		return nil, ErrMFANotEnabled
+	}
+	if accountLocked(user) {
+		return nil, ErrAccountLocked
+	}
+
+	ok, err := s.Verify(ctx, user, models.MFACodeRequest{Code: code})
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		s.failCode(ctx, user)
+		return nil, ErrInvalidMFACode
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	// Reloaded so the TOTP step just used is kept
+	user, err = s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	user.RecoveryCodes = hashes
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, errors.Wrap(err, "failed to store recovery codes")
+	}
+
+	s.log.Info("Recovery codes regenerated", "id", user.ID, "username", user.Username)
+	return &models.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// ResetMFA turns MFA off for a user who lost their authenticator and
+// recovery codes, so they can enroll again
+func (s *MFAService) ResetMFA(ctx context.Context, userID string) error {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.MFAEnabled && user.MFASecret == "" {
+		return ErrMFANotEnabled
+	}
+	return s.disable(ctx, user)
+}
+
+// Verify checks a TOTP code or, if none is given, a recovery code of a user
+// with MFA enabled. Each code is accepted only once.
+func (s *MFAService) Verify(ctx context.Context, user *models.User, req models.MFACodeRequest) (bool, error) {
+	if req.Code != "" {
+		step, ok := s.verifyTOTP(user, req.Code)
+		if !ok {
+			return false, nil
+		}
+		used, err := s.userRepo.UseTOTPStep(ctx, user.ID, step)
+		if err != nil {
+			return false, errors.Wrap(err, "failed to record TOTP code")
+		}
+		return used, nil
+	}
+
+	if req.RecoveryCode != "" {
+		hash := hashToken(security.NormalizeRecoveryCode(req.RecoveryCode))
+		used, err := s.userRepo.UseRecoveryCode(ctx, user.ID, hash)
+		if err != nil {
+			return false, errors.Wrap(err, "failed to record recovery code")
+		}
+		if used {
+			s.log.Warn("Recovery code used", "id", user.ID, "username", user.Username, "remaining", len(user.RecoveryCodes)-1)
+		}
+		return used, nil
+	}
+	return false, nil
+}
+
+// Challenge holds a login whose password, or single sign-on, succeeded until
+// the user sends their second factor with the returned token
+func (s *MFAService) Challenge(ctx context.Context, user *models.User, method string) (*models.MFAChallengeResponse, error) {
+	token, err := newOpaqueToken()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate MFA token")
+	}
+
+	challenge := &models.MFAChallenge{
+		ID:        "mfa-" + uuid.New().String()[:8],
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		Method:    method,
+		ExpiresAt: time.Now().Add(mfaChallengeTTL),
+	}
+	if err := s.tokenRepo.CreateMFAChallenge(ctx, challenge); err != nil {
+		return nil, errors.Wrap(err, "failed to store MFA challenge")
+	}
+
+	return &models.MFAChallengeResponse{
+		MFARequired: true,
+		MFAToken:    token,
+		ExpiresAt:   challenge.ExpiresAt,
+	}, nil
+}
+
+// GetChallenge retrieves the pending login of an MFA token
+func (s *MFAService) GetChallenge(ctx context.Context, token string) (*models.MFAChallenge, error) {
+	challenge, err := s.tokenRepo.GetMFAChallengeByHash(ctx, hashToken(token))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve MFA challenge")
+	}
+	if challenge == nil || time.Now().After(challenge.ExpiresAt) {
+		return nil, ErrInvalidMFAToken
+	}
+	return challenge, nil
+}
+
+// FailChallenge counts a wrong code sent for a login, dropping the login once
+// it had too many
+func (s *MFAService) FailChallenge(ctx context.Context, challenge *models.MFAChallenge) {
+	attempts, err := s.tokenRepo.RecordMFAChallengeAttempt(ctx, challenge.ID)
+	if err != nil {
+		s.log.Error("Failed to record MFA attempt", "challenge_id", challenge.ID, "error", err)
+		return
+	}
+	if attempts >= mfaChallengeAttempts {
+		if _, err := s.tokenRepo.DeleteMFAChallenge(ctx, challenge.ID); err != nil {
+			s.log.Error("Failed to delete MFA challenge", "challenge_id", challenge.ID, "error", err)
+		}
+	}
+}
+
+// ConsumeChallenge ends a login's challenge once its second factor was
+// verified. It reports false if the challenge was already used.
+func (s *MFAService) ConsumeChallenge(ctx context.Context, challenge *models.MFAChallenge) (bool, error) {
+	deleted, err := s.tokenRepo.DeleteMFAChallenge(ctx, challenge.ID)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to delete MFA challenge")
+	}
+	return deleted, nil
+}
+
+// failCode counts a wrong code sent by a signed in user towards the lockout
+// of their account
+func (s *MFAService) failCode(ctx context.Context, user *models.User) {
+	s.log.Warn("Failed MFA code check", "id", user.ID, "username", user.Username)
+	s.lockout.recordFailure(ctx, s.userRepo, user, s.log)
+}
+
+// disable clears a user's TOTP secret and recovery codes
+func (s *MFAService) disable(ctx context.Context, user *models.User) error {
+	before := auditSnapshot(user)
+	user.MFAEnabled = false
+	user.MFASecret = ""
+	user.MFALastStep = 0
+	user.RecoveryCodes = nil
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return errors.Wrap(err, "failed to disable MFA")
+	}
+
+	s.log.Warn("MFA disabled", "id", user.ID, "username", user.Username)
+	s.audit.Record(ctx, models.AuditActionUpdate, AuditResourceUser, user.ID, before, user)
+	return nil
+}
+
+// verifyTOTP checks a code against a user's TOTP secret and returns its time step
+func (s *MFAService) verifyTOTP(user *models.User, code string) (int64, bool) {
+	secret, err := s.box.OpenString(user.MFASecret)
+	if err != nil {
+		s.log.Error("Failed to decrypt TOTP secret", "id", user.ID, "error", err)
+		return 0, false
+	}
+	step, ok := security.VerifyTOTP(secret, code, time.Now())
+	if !ok || step <= user.MFALastStep {
+		return 0, false
+	}
+	return step, true
+}
+
+func (s *MFAService) getUser(ctx context.Context, id string) (*models.User, error) {
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve user")
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+// newRecoveryCodes returns new recovery codes and the hashes stored for them
+func newRecoveryCodes() ([]string, []string, error) {
+	codes, err := security.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, nil, err
+	}
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = hashToken(security.NormalizeRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
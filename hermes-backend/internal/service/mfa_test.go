@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/security"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/errors"
+)
+
+const testPassword = "correct horse battery"
+
+// newMFATestUser returns a user with a TOTP secret, enabled or pending
+func newMFATestUser(t *testing.T, box *security.SecretBox, enabled bool) (*models.User, string) {
+	t.Helper()
+	secret, err := security.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := box.SealString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, err := security.HashPassword(testPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &models.User{
+		ID:           "usr-1",
+		Username:     "alice",
+		Email:        "alice@example.com",
+		PasswordHash: hash,
+		Role:         models.RoleUser,
+		Active:       true,
+		MFAEnabled:   enabled,
+		MFASecret:    sealed,
+	}, secret
+}
+
+func currentTOTP(t *testing.T, secret string) string {
+	t.Helper()
+	code, err := security.TOTPCode(secret, security.TOTPStep(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+// wrongTOTP returns a code that no step around now accepts
+func wrongTOTP(t *testing.T, secret string) string {
+	t.Helper()
+	for _, candidate := range []string{"000000", "111111", "222222", "333333"} {
+		if _, ok := security.VerifyTOTP(secret, candidate, time.Now()); !ok {
+			return candidate
+		}
+	}
+	t.Fatal("no wrong code found")
+	return ""
+}
+
+func TestMFACodeChecksCountTowardsLockout(t *testing.T) {
+	tests := []struct {
+		name    string
+		enabled bool
+		check   func(s *MFAService, code string) error
+	}{
+		{
+			name:    "disable",
+			enabled: true,
+			check: func(s *MFAService, code string) error {
+				return s.DisableTOTP(context.Background(), "usr-1", models.MFACodeRequest{Code: code})
+			},
+		},
+		{
+			name:    "regenerate recovery codes",
+			enabled: true,
+			check: func(s *MFAService, code string) error {
+				_, err := s.RegenerateRecoveryCodes(context.Background(), "usr-1", code)
+				return err
+			},
+		},
+		{
+			name:    "confirm enrollment",
+			enabled: false,
+			check: func(s *MFAService, code string) error {
+				_, err := s.ConfirmTOTP(context.Background(), "usr-1", code)
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			box := newTestSecretBox(t)
+			user, secret := newMFATestUser(t, box, tt.enabled)
+			users := newFakeUserRepo(user)
+			lockout := LockoutPolicy{MaxAttempts: 3, Duration: time.Minute}
+			log := newTestLogger()
+			s := NewMFAService(users, &fakeTokenRepo{}, box, "Hermes", lockout, NewAuditService(&fakeAuditRepo{}, log), log)
+
+			wrong := wrongTOTP(t, secret)
+			for i := 0; i < lockout.MaxAttempts; i++ {
+				if err := tt.check(s, wrong); !errors.Is(err, ErrInvalidMFACode) {
+					t.Fatalf("attempt %d: error = %v, want ErrInvalidMFACode", i+1, err)
+				}
+			}
+
+			// Even the right code is refused once the account is locked
+			if err := tt.check(s, currentTOTP(t, secret)); !errors.Is(err, ErrAccountLocked) {
+				t.Fatalf("error = %v, want ErrAccountLocked", err)
+			}
+			stored, _ := users.GetByID(context.Background(), "usr-1")
+			if !accountLocked(stored) || stored.MFAEnabled != tt.enabled {
+				t.Errorf("locked = %v, MFA enabled = %v", accountLocked(stored), stored.MFAEnabled)
+			}
+		})
+	}
+}
+
+func TestMFAVerifyRejectsReusedCode(t *testing.T) {
+	box := newTestSecretBox(t)
+	user, secret := newMFATestUser(t, box, true)
+	users := newFakeUserRepo(user)
+	log := newTestLogger()
+	s := NewMFAService(users, &fakeTokenRepo{}, box, "Hermes", LockoutPolicy{}, NewAuditService(&fakeAuditRepo{}, log), log)
+	ctx := context.Background()
+
+	code := currentTOTP(t, secret)
+	if ok, err := s.Verify(ctx, user, models.MFACodeRequest{Code: code}); err != nil || !ok {
+		t.Fatalf("first Verify = %v, %v", ok, err)
+	}
+	stored, _ := users.GetByID(ctx, user.ID)
+	if ok, _ := s.Verify(ctx, stored, models.MFACodeRequest{Code: code}); ok {
+		t.Error("code accepted twice")
+	}
+}
+
+func TestLoginDoesNotRevealLockedAccounts(t *testing.T) {
+	box := newTestSecretBox(t)
+	user, _ := newMFATestUser(t, box, false)
+	until := time.Now().Add(time.Minute)
+	user.LockedUntil = &until
+	users := newFakeUserRepo(user)
+	tokens, _ := newTestTokenService(t, users)
+	log := newTestLogger()
+	audit := NewAuditService(&fakeAuditRepo{}, log)
+	lockout := LockoutPolicy{MaxAttempts: 3, Duration: time.Minute}
+	mfa := NewMFAService(users, &fakeTokenRepo{}, box, "Hermes", lockout, audit, log)
+	s := NewUserService(users, tokens, mfa, lockout, audit, log)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+	}{
+		{"locked, right password", "alice", testPassword},
+		{"locked, wrong password", "alice", "wrong password"},
+		{"unknown user", "bob", testPassword},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := s.Login(ctx, models.UserLogin{Username: tt.username, Password: tt.password}, models.SessionMetadata{})
+			if !errors.Is(err, ErrInvalidCredentials) {
+				t.Errorf("error = %v, want ErrInvalidCredentials", err)
+			}
+		})
+	}
+
+	stored, _ := users.GetByID(ctx, user.ID)
+	stored.LockedUntil = nil
+	users.Update(ctx, stored)
+	resp, _, err := s.Login(ctx, models.UserLogin{Username: "alice", Password: testPassword}, models.SessionMetadata{})
+	if err != nil || resp == nil {
+		t.Fatalf("Login after unlock = %v", err)
+	}
+}
@@ -0,0 +1,405 @@
+// internal/service/namespace.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/repository"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/security"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/errors"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/logger"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrNamespaceNotFound is returned when a namespace does not exist
+	ErrNamespaceNotFound = errors.New("namespace not found")
+
+	// ErrNamespaceForbidden is returned when the caller has no permission in a namespace
+	ErrNamespaceForbidden = errors.New("no permission in namespace")
+
+	// ErrNamespaceQuotaExceeded is returned when a namespace is full
+	ErrNamespaceQuotaExceeded = errors.New("namespace quota exceeded")
+
+	// ErrNamespaceBindingNotFound is returned when a role binding does not exist
+	ErrNamespaceBindingNotFound = errors.New("namespace binding not found")
+)
+
+// namespaceNamePattern restricts namespace names to DNS labels
+var namespaceNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+type namespaceScopeContextKey struct{}
+
+// WithNamespaceScope restricts the request to the given namespaces, for
+// callers whose permission comes from namespace bindings only
+func WithNamespaceScope(ctx context.Context, namespaces []string) context.Context {
+	return context.WithValue(ctx, namespaceScopeContextKey{}, namespaces)
+}
+
+// namespaceScope returns the namespaces the request is restricted to, nil
+// when it is not restricted
+func namespaceScope(ctx context.Context) []string {
+	namespaces, _ := ctx.Value(namespaceScopeContextKey{}).([]string)
+	return namespaces
+}
+
+// NamespaceAllowed reports whether the request may act in namespace
+func NamespaceAllowed(ctx context.Context, namespace string) bool {
+	scope, ok := ctx.Value(namespaceScopeContextKey{}).([]string)
+	if !ok {
+		return true
+	}
+	for _, allowed := range scope {
+		if allowed == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// NamespaceService handles business logic for namespaces and their role bindings
+type NamespaceService struct {
+	repo        repository.NamespaceRepository
+	serviceRepo repository.ServiceRepository
+	routeRepo   repository.RouteRepository
+	userRepo    repository.UserRepository
+	teamRepo    repository.TeamRepository
+	policy      *security.Policy
+	audit       *AuditService
+	log         *logger.Logger
+}
+
+// NewNamespaceService creates a new NamespaceService
+func NewNamespaceService(repo repository.NamespaceRepository, serviceRepo repository.ServiceRepository, routeRepo repository.RouteRepository, userRepo repository.UserRepository, teamRepo repository.TeamRepository, policy *security.Policy, audit *AuditService, log *logger.Logger) *NamespaceService {
+	return &NamespaceService{
+		repo:        repo,
+		serviceRepo: serviceRepo,
+		routeRepo:   routeRepo,
+		userRepo:    userRepo,
+		teamRepo:    teamRepo,
+		policy:      policy,
+		audit:       audit,
+		log:         log,
+	}
+}
+
+// CreateNamespace creates a new namespace
+func (s *NamespaceService) CreateNamespace(ctx context.Context, req models.NamespaceRequest) (*models.Namespace, error) {
+	if !namespaceNamePattern.MatchString(req.Name) {
+		return nil, errors.New("namespace name must be a lowercase DNS label")
+	}
+	existing, err := s.repo.GetByName(ctx, req.Name)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to check namespace")
+	}
+	if existing != nil {
+		return nil, errors.New("namespace already exists")
+	}
+
+	namespace := &models.Namespace{
+		Name:        req.Name,
+		Description: req.Description,
+	}
+	if err := s.applyLimits(ctx, namespace, req.Quota, req.AllowedDependencies); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, namespace); err != nil {
+		return nil, errors.Wrap(err, "failed to create namespace")
+	}
+
+	s.log.Info("Namespace created", "name", namespace.Name)
+	s.audit.Record(ctx, models.AuditActionCreate, AuditResourceNamespace, namespace.Name, nil, namespace)
+	return namespace, nil
+}
+
+// GetNamespace retrieves a namespace the caller may see
+func (s *NamespaceService) GetNamespace(ctx context.Context, name string) (*models.Namespace, error) {
+	if !NamespaceAllowed(ctx, name) {
+		return nil, ErrNamespaceNotFound
+	}
+	return s.get(ctx, name)
+}
+
+// ListNamespaces lists the namespaces the caller may see
+func (s *NamespaceService) ListNamespaces(ctx context.Context) ([]*models.Namespace, error) {
+	namespaces, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list namespaces")
+	}
+
+	visible := namespaces[:0]
+	for _, namespace := range namespaces {
+		if NamespaceAllowed(ctx, namespace.Name) {
+			visible = append(visible, namespace)
+		}
+	}
+	return visible, nil
+}
+
+// UpdateNamespace changes the description, quota or dependency rules of a namespace
+func (s *NamespaceService) UpdateNamespace(ctx context.Context, name string, req models.NamespaceUpdateRequest) (*models.Namespace, error) {
+	namespace, err := s.get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	before := *namespace
+
+	if req.Description != nil {
+		namespace.Description = *req.Description
+	}
+	quota := namespace.Quota
+	if req.Quota != nil {
+		quota = req.Quota
+	}
+	dependencies := []string(namespace.AllowedDependencies)
+	if req.AllowedDependencies != nil {
+		dependencies = req.AllowedDependencies
+	}
+	if err := s.applyLimits(ctx, namespace, quota, dependencies); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, namespace); err != nil {
+		return nil, errors.Wrap(err, "failed to update namespace")
+	}
+
+	s.log.Info("Namespace updated", "name", namespace.Name)
+	s.audit.Record(ctx, models.AuditActionUpdate, AuditResourceNamespace, namespace.Name, &before, namespace)
+	return namespace, nil
+}
+
+// DeleteNamespace deletes an empty namespace along with its role bindings
+func (s *NamespaceService) DeleteNamespace(ctx context.Context, name string) error {
+	if name == models.DefaultNamespace {
+		return errors.New("the default namespace cannot be deleted")
+	}
+	namespace, err := s.get(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	services, err := s.serviceRepo.CountByNamespace(ctx, name)
+	if err != nil {
+		return errors.Wrap(err, "failed to check namespace services")
+	}
+	routes, err := s.routeRepo.CountByNamespace(ctx, name)
+	if err != nil {
+		return errors.Wrap(err, "failed to check namespace routes")
+	}
+	if services > 0 || routes > 0 {
+		return errors.New("namespace still holds services or routes, move or delete them first")
+	}
+
+	if err := s.repo.Delete(ctx, name); err != nil {
+		return errors.Wrap(err, "failed to delete namespace")
+	}
+
+	s.log.Info("Namespace deleted", "name", name)
+	s.audit.Record(ctx, models.AuditActionDelete, AuditResourceNamespace, name, namespace, nil)
+	return nil
+}
+
+// CreateBinding grants a user or a team a role within a namespace
+func (s *NamespaceService) CreateBinding(ctx context.Context, namespace string, req models.NamespaceBindingRequest) (*models.NamespaceBinding, error) {
+	if _, err := s.get(ctx, namespace); err != nil {
+		return nil, err
+	}
+	if !s.policy.HasRole(req.Role) {
+		return nil, errors.New("unknown role: " + string(req.Role))
+	}
+
+	binding := &models.NamespaceBinding{
+		ID:        "nsb-" + uuid.New().String()[:8],
+		Namespace: namespace,
+		Role:      req.Role,
+	}
+	switch {
+	case req.UserID != "" && req.TeamID != "":
+		return nil, errors.New("binding requires either a user or a team, not both")
+	case req.UserID != "":
+		user, err := s.userRepo.GetByID(ctx, req.UserID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to retrieve user")
+		}
+		if user == nil {
+			return nil, ErrUserNotFound
+		}
+		binding.UserID = &req.UserID
+	case req.TeamID != "":
+		team, err := s.teamRepo.GetByID(ctx, req.TeamID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to retrieve team")
+		}
+		if team == nil {
+			return nil, ErrTeamNotFound
+		}
+		binding.TeamID = &req.TeamID
+	default:
+		return nil, errors.New("binding requires a user or a team")
+	}
+
+	if err := s.repo.CreateBinding(ctx, binding); err != nil {
+		return nil, errors.Wrap(err, "failed to create namespace binding")
+	}
+
+	s.log.Info("Namespace binding created", "id", binding.ID, "namespace", namespace, "role", binding.Role)
+	s.audit.Record(ctx, models.AuditActionCreate, AuditResourceBinding, binding.ID, nil, binding)
+	return binding, nil
+}
+
+// ListBindings lists the role bindings of a namespace
+func (s *NamespaceService) ListBindings(ctx context.Context, namespace string) ([]*models.NamespaceBinding, error) {
+	if _, err := s.get(ctx, namespace); err != nil {
+		return nil, err
+	}
+	bindings, err := s.repo.ListBindings(ctx, namespace)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list namespace bindings")
+	}
+	return bindings, nil
+}
+
+// DeleteBinding removes a role binding from a namespace
+func (s *NamespaceService) DeleteBinding(ctx context.Context, namespace, id string) error {
+	binding, err := s.repo.GetBinding(ctx, id)
+	if err != nil {
+		return errors.Wrap(err, "failed to retrieve namespace binding")
+	}
+	if binding == nil || binding.Namespace != namespace {
+		return ErrNamespaceBindingNotFound
+	}
+
+	if err := s.repo.DeleteBinding(ctx, id); err != nil {
+		return errors.Wrap(err, "failed to delete namespace binding")
+	}
+
+	s.log.Info("Namespace binding deleted", "id", id, "namespace", namespace)
+	s.audit.Record(ctx, models.AuditActionDelete, AuditResourceBinding, id, binding, nil)
+	return nil
+}
+
+// PermittedNamespaces returns the namespaces in which the bindings of a user,
+// directly or through their teams, grant permission
+func (s *NamespaceService) PermittedNamespaces(ctx context.Context, userID string, permission security.Permission) ([]string, error) {
+	bindings, err := s.repo.ListBindingsForUser(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list namespace bindings")
+	}
+
+	seen := make(map[string]bool)
+	var namespaces []string
+	for _, binding := range bindings {
+		if !seen[binding.Namespace] && s.policy.Allows(binding.Role, permission) {
+			seen[binding.Namespace] = true
+			namespaces = append(namespaces, binding.Namespace)
+		}
+	}
+	sort.Strings(namespaces)
+	return namespaces, nil
+}
+
+// resolve returns the namespace something is created in, the default one
+// when none is given, and checks that the caller may act in it
+func (s *NamespaceService) resolve(ctx context.Context, name string) (*models.Namespace, error) {
+	if name == "" {
+		name = models.DefaultNamespace
+	}
+	if !NamespaceAllowed(ctx, name) {
+		return nil, fmt.Errorf("%w: %s", ErrNamespaceForbidden, name)
+	}
+	return s.get(ctx, name)
+}
+
+// checkServiceQuota fails when a namespace cannot hold another service
+func (s *NamespaceService) checkServiceQuota(ctx context.Context, namespace *models.Namespace) error {
+	if namespace.Quota == nil || namespace.Quota.MaxServices == 0 {
+		return nil
+	}
+	count, err := s.serviceRepo.CountByNamespace(ctx, namespace.Name)
+	if err != nil {
+		return errors.Wrap(err, "failed to count namespace services")
+	}
+	if count >= int64(namespace.Quota.MaxServices) {
+		return fmt.Errorf("%w: %s allows %d services", ErrNamespaceQuotaExceeded, namespace.Name, namespace.Quota.MaxServices)
+	}
+	return nil
+}
+
+// checkRouteQuota fails when a namespace cannot hold another route
+func (s *NamespaceService) checkRouteQuota(ctx context.Context, namespace *models.Namespace) error {
+	if namespace.Quota == nil || namespace.Quota.MaxRoutes == 0 {
+		return nil
+	}
+	count, err := s.routeRepo.CountByNamespace(ctx, namespace.Name)
+	if err != nil {
+		return errors.Wrap(err, "failed to count namespace routes")
+	}
+	if count >= int64(namespace.Quota.MaxRoutes) {
+		return fmt.Errorf("%w: %s allows %d routes", ErrNamespaceQuotaExceeded, namespace.Name, namespace.Quota.MaxRoutes)
+	}
+	return nil
+}
+
+// checkDependency fails when services of one namespace may not depend on
+// services of another
+func (s *NamespaceService) checkDependency(ctx context.Context, from, to string) error {
+	if from == to {
+		return nil
+	}
+	namespace, err := s.get(ctx, from)
+	if err != nil {
+		return err
+	}
+	if !namespace.AllowsDependencyOn(to) {
+		return fmt.Errorf("%w: %s may not depend on services in %s", ErrNamespaceForbidden, from, to)
+	}
+	return nil
+}
+
+// get retrieves a namespace regardless of the caller's scope
+func (s *NamespaceService) get(ctx context.Context, name string) (*models.Namespace, error) {
+	namespace, err := s.repo.GetByName(ctx, name)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve namespace")
+	}
+	if namespace == nil {
+		return nil, ErrNamespaceNotFound
+	}
+	return namespace, nil
+}
+
+// applyLimits validates and sets the quota and dependency rules of a namespace
+func (s *NamespaceService) applyLimits(ctx context.Context, namespace *models.Namespace, quota *models.NamespaceQuota, dependencies []string) error {
+	if quota != nil {
+		if quota.MaxServices < 0 || quota.MaxRoutes < 0 {
+			return errors.New("namespace quota cannot be negative")
+		}
+		if *quota == (models.NamespaceQuota{}) {
+			quota = nil
+		}
+	}
+	namespace.Quota = quota
+
+	allowed := make([]string, 0, len(dependencies))
+	for _, name := range dependencies {
+		name = strings.TrimSpace(name)
+		if name == "" || name == namespace.Name {
+			continue
+		}
+		if _, err := s.get(ctx, name); err != nil {
+			if errors.Is(err, ErrNamespaceNotFound) {
+				return errors.New("unknown namespace in allowed dependencies: " + name)
+			}
+			return err
+		}
+		allowed = append(allowed, name)
+	}
+	namespace.AllowedDependencies = allowed
+	return nil
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/security"
+)
+
+func TestNamespaceAllowed(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		namespace string
+		want      bool
+	}{
+		{"unrestricted", ctx, "payments", true},
+		{"in scope", WithNamespaceScope(ctx, []string{"orders", "payments"}), "payments", true},
+		{"out of scope", WithNamespaceScope(ctx, []string{"orders"}), "payments", false},
+		{"default namespace out of scope", WithNamespaceScope(ctx, []string{"orders"}), models.DefaultNamespace, false},
+		{"empty scope", WithNamespaceScope(ctx, []string{}), "orders", false},
+		{"names match exactly", WithNamespaceScope(ctx, []string{"orders"}), "Orders", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NamespaceAllowed(tt.ctx, tt.namespace); got != tt.want {
+				t.Errorf("NamespaceAllowed(%q) = %v, want %v", tt.namespace, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPermittedNamespaces(t *testing.T) {
+	alice, bob := "usr-alice", "usr-bob"
+	repo := &fakeNamespaceRepo{bindings: []*models.NamespaceBinding{
+		{ID: "nsb-1", Namespace: "payments", UserID: &alice, Role: models.RoleUser},
+		{ID: "nsb-2", Namespace: "orders", UserID: &alice, Role: models.RoleGuest},
+		{ID: "nsb-3", Namespace: "billing", UserID: &alice, Role: models.RoleAdmin},
+		{ID: "nsb-4", Namespace: "payments", UserID: &alice, Role: models.RoleAdmin},
+		{ID: "nsb-5", Namespace: "orders", UserID: &bob, Role: models.RoleAdmin},
+	}}
+	s := NewNamespaceService(repo, nil, nil, nil, nil, security.NewPolicy(), nil, newTestLogger())
+
+	tests := []struct {
+		user       string
+		permission security.Permission
+		want       []string
+	}{
+		{alice, security.PermServicesRead, []string{"billing", "orders", "payments"}},
+		{alice, security.PermServicesWrite, []string{"billing", "payments"}},
+		{alice, security.PermUsersAdmin, []string{"billing", "payments"}},
+		{bob, security.PermServicesWrite, []string{"orders"}},
+		{"usr-nobody", security.PermServicesRead, nil},
+	}
+	for _, tt := range tests {
+		got, err := s.PermittedNamespaces(context.Background(), tt.user, tt.permission)
+		if err != nil {
+			t.Fatalf("PermittedNamespaces: %v", err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("PermittedNamespaces(%s, %s) = %v, want %v", tt.user, tt.permission, got, tt.want)
+		}
+	}
+}
+
+func TestNamespaceScopeHidesOtherNamespaces(t *testing.T) {
+	repo := &fakeNamespaceRepo{namespaces: []*models.Namespace{
+		{Name: models.DefaultNamespace}, {Name: "orders"}, {Name: "payments"},
+	}}
+	namespaces := NewNamespaceService(repo, nil, nil, nil, nil, security.NewPolicy(), nil, newTestLogger())
+	services := NewServiceService(newFakeServiceRepo(
+		&models.Service{ID: "svc-1", Namespace: "orders", Name: "api"},
+		&models.Service{ID: "svc-2", Namespace: "payments", Name: "api"},
+	), nil, nil, namespaces, nil, nil, newTestLogger())
+	ctx := WithNamespaceScope(context.Background(), []string{"orders"})
+
+	visible, err := namespaces.ListNamespaces(ctx)
+	if err != nil {
+		t.Fatalf("ListNamespaces: %v", err)
+	}
+	if len(visible) != 1 || visible[0].Name != "orders" {
+		t.Errorf("ListNamespaces = %+v, want only orders", visible)
+	}
+	if _, err := namespaces.GetNamespace(ctx, "payments"); !errors.Is(err, ErrNamespaceNotFound) {
+		t.Errorf("GetNamespace(payments) = %v, want not found", err)
+	}
+	if _, err := namespaces.resolve(ctx, ""); !errors.Is(err, ErrNamespaceForbidden) {
+		t.Errorf("resolve(default) = %v, want forbidden", err)
+	}
+	if namespace, err := namespaces.resolve(ctx, "orders"); err != nil || namespace.Name != "orders" {
+		t.Errorf("resolve(orders) = %+v, %v", namespace, err)
+	}
+
+	if service, err := services.GetServiceByName(ctx, "orders", "api"); err != nil || service == nil || service.ID != "svc-1" {
+		t.Errorf("GetServiceByName(orders/api) = %+v, %v", service, err)
+	}
+	if service, err := services.GetServiceByName(ctx, "payments", "api"); err != nil || service != nil {
+		t.Errorf("GetServiceByName(payments/api) = %+v, %v, want not found", service, err)
+	}
+}
@@ -0,0 +1,323 @@
+// internal/service/oidc.go
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/repository"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/security"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/errors"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidSSOLogin is returned when a single sign-on callback cannot be
+// matched to a login started by this browser or the provider rejects it
+var ErrInvalidSSOLogin = errors.New("invalid single sign-on login")
+
+// ErrSSOAccountConflict is returned when the provider's email belongs to a
+// local account. Linking them automatically would let the provider take it over.
+var ErrSSOAccountConflict = errors.New("email is already registered to a local account")
+
+// oidcLoginTTL is how long a started login may take to complete
+const oidcLoginTTL = 10 * time.Minute
+
+// usernameInvalidChars matches characters not allowed in provisioned usernames
+var usernameInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// OIDCRoleMapping grants a role to members of a provider group
+type OIDCRoleMapping struct {
+	Group string
+	Role  models.Role
+}
+
+// OIDCLogin is a started login: the URL to send the browser to and the
+// sealed session to hand back on the callback
+type OIDCLogin struct {
+	AuthURL string
+	Session string
+}
+
+// oidcSession is the state kept by the browser between login and callback
+type oidcSession struct {
+	State     string    `json:"state"`
+	Nonce     string    `json:"nonce"`
+	Verifier  string    `json:"verifier"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// OIDCService signs users in through an OpenID Connect provider, creating
+// their accounts on first login and deriving their role from provider groups
+type OIDCService struct {
+	provider    *security.OIDCProvider
+	userRepo    repository.UserRepository
+	tokens      *TokenService
+	mfa         *MFAService
+	policy      *security.Policy
+	box         *security.SecretBox
+	mappings    []OIDCRoleMapping
+	defaultRole models.Role
+	audit       *AuditService
+	log         *logger.Logger
+}
+
+// NewOIDCService creates a new OIDCService
+func NewOIDCService(provider *security.OIDCProvider, userRepo repository.UserRepository, tokens *TokenService, mfa *MFAService, policy *security.Policy, box *security.SecretBox, mappings []OIDCRoleMapping, defaultRole models.Role, audit *AuditService, log *logger.Logger) *OIDCService {
+	if defaultRole == "" {
+		defaultRole = models.RoleGuest
+	}
+	return &OIDCService{
+		provider:    provider,
+		userRepo:    userRepo,
+		tokens:      tokens,
+		mfa:         mfa,
+		policy:      policy,
+		box:         box,
+		mappings:    mappings,
+		defaultRole: defaultRole,
+		audit:       audit,
+		log:         log,
+	}
+}
+
+// StartLogin begins an authorization code flow with PKCE
+func (s *OIDCService) StartLogin(ctx context.Context) (*OIDCLogin, error) {
+	state, err := security.RandomToken(24)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate state")
+	}
+	nonce, err := security.RandomToken(24)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate nonce")
+	}
+	verifier, challenge, err := security.NewPKCE()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate code verifier")
+	}
+
+	authURL, err := s.provider.AuthCodeURL(ctx, state, nonce, challenge)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build authorization URL")
+	}
+
+	payload, err := json.Marshal(oidcSession{
+		State:     state,
+		Nonce:     nonce,
+		Verifier:  verifier,
+		ExpiresAt: time.Now().Add(oidcLoginTTL),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode login session")
+	}
+	sealed, err := s.box.Seal(payload)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to seal login session")
+	}
+
+	return &OIDCLogin{
+		AuthURL: authURL,
+		Session: base64.RawURLEncoding.EncodeToString(sealed),
+	}, nil
+}
+
+// CompleteLogin finishes a login from the provider's callback, provisioning
+// the user if needed, and issues Hermes tokens. Users with MFA get a
+// challenge instead, completed like password logins.
+func (s *OIDCService) CompleteLogin(ctx context.Context, session, state, code string, meta models.SessionMetadata) (*models.TokenResponse, *models.MFAChallengeResponse, error) {
+	login, err := s.openSession(session)
+	if err != nil || state == "" || login.State != state || time.Now().After(login.ExpiresAt) {
+		return nil, nil, ErrInvalidSSOLogin
+	}
+
+	identity, err := s.provider.Exchange(ctx, code, login.Verifier, login.Nonce)
+	if err != nil {
+		s.log.Warn("Single sign-on exchange failed", "error", err)
+		return nil, nil, ErrInvalidSSOLogin
+	}
+
+	user, err := s.provision(ctx, identity)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !user.Active {
+		s.log.Warn("Single sign-on by deactivated user", "id", user.ID, "username", user.Username)
+		return nil, nil, ErrInvalidCredentials
+	}
+	if accountLocked(user) {
+		s.log.Warn("Single sign-on to locked account", "id", user.ID, "username", user.Username)
+		return nil, nil, ErrAccountLocked
+	}
+
+	if user.MFAEnabled {
+		challenge, err := s.mfa.Challenge(ctx, user, models.SessionMethodSSO)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, challenge, nil
+	}
+
+	now := time.Now()
+	if err := s.userRepo.RecordLogin(ctx, user.ID, now); err != nil {
+		s.log.Error("Failed to record last login", "id", user.ID, "error", err)
+	}
+	user.LastLogin = &now
+
+	meta.Method = models.SessionMethodSSO
+	resp, err := s.tokens.IssueTokens(ctx, user, meta)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.log.Info("User logged in with single sign-on", "id", user.ID, "username", user.Username, "issuer", identity.Issuer)
+	return resp, nil, nil
+}
+
+// provision finds or creates the user for a provider identity and applies
+// the role mapped from its groups
+func (s *OIDCService) provision(ctx context.Context, identity *security.OIDCIdentity) (*models.User, error) {
+	externalID := identity.Issuer + "#" + identity.Subject
+
+	user, err := s.userRepo.GetByExternalID(ctx, externalID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve user")
+	}
+
+	role, mapped := s.mapRole(identity.Groups)
+
+	if user != nil {
+		// Groups are re-read on every login so changes at the provider apply.
+		// Users removed from every mapped group fall back to the default role,
+		// so leaving the group that granted ADMIN takes ADMIN away.
+		if user.Role != role {
+			if mapped {
+				s.log.Info("Single sign-on role changed", "id", user.ID, "username", user.Username, "from", user.Role, "to", role)
+			} else {
+				s.log.Warn("Single sign-on user matches no group mapping, applying the default role", "id", user.ID, "username", user.Username, "from", user.Role, "to", role)
+			}
+			before := auditSnapshot(user)
+			user.Role = role
+			if err := s.userRepo.Update(ctx, user); err != nil {
+				return nil, errors.Wrap(err, "failed to update user role")
+			}
+			s.audit.Record(ctx, models.AuditActionUpdate, AuditResourceUser, user.ID, before, user)
+		}
+		return user, nil
+	}
+
+	email := strings.TrimSpace(identity.Email)
+	if email == "" {
+		return nil, errors.New("identity provider did not return an email address")
+	}
+	existing, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to check email")
+	}
+	if existing != nil {
+		s.log.Warn("Single sign-on email matches a local account", "id", existing.ID, "issuer", identity.Issuer)
+		return nil, ErrSSOAccountConflict
+	}
+
+	username, err := s.uniqueUsername(ctx, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	firstName, lastName, _ := strings.Cut(strings.TrimSpace(identity.Name), " ")
+	user = &models.User{
+		ID:         "usr-" + uuid.New().String()[:8],
+		Username:   username,
+		Email:      email,
+		ExternalID: &externalID,
+		FirstName:  firstName,
+		LastName:   lastName,
+		Role:       role,
+		Active:     true,
+	}
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return nil, errors.Wrap(err, "failed to create user")
+	}
+
+	s.log.Info("User provisioned from single sign-on", "id", user.ID, "username", user.Username, "role", user.Role, "issuer", identity.Issuer)
+	s.audit.Record(ctx, models.AuditActionCreate, AuditResourceUser, user.ID, nil, user)
+	return user, nil
+}
+
+// mapRole returns the role of the first mapping whose group the user is in,
+// or the default role when none matches
+func (s *OIDCService) mapRole(groups []string) (models.Role, bool) {
+	member := make(map[string]bool, len(groups))
+	for _, g := range groups {
+		member[g] = true
+	}
+
+	for _, m := range s.mappings {
+		if !member[m.Group] {
+			continue
+		}
+		if !s.policy.HasRole(m.Role) {
+			s.log.Warn("Single sign-on group mapped to unknown role", "group", m.Group, "role", m.Role)
+			continue
+		}
+		return m.Role, true
+	}
+	return s.defaultRole, false
+}
+
+// uniqueUsername derives a free username from the identity
+func (s *OIDCService) uniqueUsername(ctx context.Context, identity *security.OIDCIdentity) (string, error) {
+	base := identity.PreferredUsername
+	if base == "" {
+		base, _, _ = strings.Cut(identity.Email, "@")
+	}
+	base = usernameInvalidChars.ReplaceAllString(base, "")
+	if len(base) > 40 {
+		base = base[:40]
+	}
+	if len(base) < 3 {
+		base = "user"
+	}
+
+	username := base
+	for i := 0; i < 5; i++ {
+		existing, err := s.userRepo.GetByUsername(ctx, username)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to check username")
+		}
+		if existing == nil {
+			return username, nil
+		}
+
+		suffix := make([]byte, 3)
+		if _, err := rand.Read(suffix); err != nil {
+			return "", errors.Wrap(err, "failed to generate username")
+		}
+		username = base + "-" + hex.EncodeToString(suffix)
+	}
+	return "", errors.New("failed to find a free username")
+}
+
+// openSession decrypts the session created by StartLogin
+func (s *OIDCService) openSession(session string) (*oidcSession, error) {
+	sealed, err := base64.RawURLEncoding.DecodeString(session)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := s.box.Open(sealed)
+	if err != nil {
+		return nil, err
+	}
+
+	var login oidcSession
+	if err := json.Unmarshal(payload, &login); err != nil {
+		return nil, err
+	}
+	return &login, nil
+}
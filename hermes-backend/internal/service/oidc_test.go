@@ -0,0 +1,310 @@
+package service
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/security"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/errors"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	stubClientID    = "hermes"
+	stubRedirectURL = "https://hermes.example.com/api/v1/auth/oidc/callback"
+)
+
+// stubIdP is an OpenID Connect provider issuing ID tokens for one user,
+// whose groups tests change between logins
+type stubIdP struct {
+	server *httptest.Server
+	key    ed25519.PrivateKey
+	mu     sync.Mutex
+	groups []string
+	grants map[string]stubGrant // By authorization code
+}
+
+// stubGrant is an authorization the browser brought back to Hermes
+type stubGrant struct {
+	challenge string
+	nonce     string
+}
+
+func newStubIdP(t *testing.T) *stubIdP {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	idp := &stubIdP{key: key, grants: make(map[string]stubGrant)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		public := idp.key.Public().(ed25519.PublicKey)
+		json.NewEncoder(w).Encode(security.JWKS{Keys: []security.JWK{{
+			KeyType:   "OKP",
+			KeyID:     "idp-key",
+			Use:       "sig",
+			Algorithm: "EdDSA",
+			Curve:     "Ed25519",
+			X:         base64.RawURLEncoding.EncodeToString(public),
+		}}})
+	})
+	mux.HandleFunc("/token", idp.token)
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+// authorize approves the login started at authURL and returns the code and
+// state the provider redirects the browser back with
+func (idp *stubIdP) authorize(t *testing.T, authURL string) (code, state string) {
+	t.Helper()
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("invalid authorization URL: %v", err)
+	}
+	q := u.Query()
+	if q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		t.Fatalf("login does not use PKCE: %s", authURL)
+	}
+	if q.Get("client_id") != stubClientID || q.Get("redirect_uri") != stubRedirectURL {
+		t.Fatalf("unexpected client in authorization URL: %s", authURL)
+	}
+
+	code, _ = security.RandomToken(16)
+	idp.mu.Lock()
+	idp.grants[code] = stubGrant{challenge: q.Get("code_challenge"), nonce: q.Get("nonce")}
+	idp.mu.Unlock()
+	return code, q.Get("state")
+}
+
+func (idp *stubIdP) setGroups(groups ...string) {
+	idp.mu.Lock()
+	idp.groups = groups
+	idp.mu.Unlock()
+}
+
+// token redeems an authorization code once, checking its PKCE code verifier
+func (idp *stubIdP) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		http.Error(w, `{"error":"invalid_request"}`, http.StatusBadRequest)
+		return
+	}
+
+	idp.mu.Lock()
+	grant, ok := idp.grants[r.PostForm.Get("code")]
+	delete(idp.grants, r.PostForm.Get("code"))
+	groups := idp.groups
+	idp.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(sum[:]) != grant.challenge {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{
+		"iss":                idp.server.URL,
+		"aud":                stubClientID,
+		"sub":                "idp-user-1",
+		"email":              "alice@example.com",
+		"name":               "Alice Doe",
+		"preferred_username": "alice",
+		"groups":             groups,
+		"nonce":              grant.nonce,
+		"iat":                now.Unix(),
+		"exp":                now.Add(time.Minute).Unix(),
+	})
+	token.Header["kid"] = "idp-key"
+	idToken, err := token.SignedString(idp.key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]string{"id_token": idToken, "token_type": "Bearer"})
+}
+
+func newTestOIDCService(t *testing.T, idp *stubIdP) (*OIDCService, *fakeUserRepo, *fakeAuditRepo) {
+	t.Helper()
+	provider, err := security.NewOIDCProvider(security.OIDCConfig{
+		IssuerURL:   idp.server.URL,
+		ClientID:    stubClientID,
+		RedirectURL: stubRedirectURL,
+		HTTPClient:  idp.server.Client(),
+	})
+	if err != nil {
+		t.Fatalf("NewOIDCProvider: %v", err)
+	}
+
+	users := newFakeUserRepo()
+	tokens, _ := newTestTokenService(t, users)
+	audits := &fakeAuditRepo{}
+	mappings := []OIDCRoleMapping{
+		{Group: "hermes-admins", Role: models.RoleAdmin},
+		{Group: "hermes-developers", Role: models.RoleUser},
+	}
+	log := newTestLogger()
+	s := NewOIDCService(provider, users, tokens, nil, security.NewPolicy(), newTestSecretBox(t), mappings, models.RoleGuest, NewAuditService(audits, log), log)
+	return s, users, audits
+}
+
+func TestOIDCLoginMapsGroupsToRoles(t *testing.T) {
+	idp := newStubIdP(t)
+	s, users, audits := newTestOIDCService(t, idp)
+	ctx := context.Background()
+
+	// The same provider user logs in repeatedly while their groups change
+	steps := []struct {
+		name   string
+		groups []string
+		want   models.Role
+	}{
+		{"provisioned as developer", []string{"hermes-developers"}, models.RoleUser},
+		{"promoted by admin group", []string{"hermes-developers", "hermes-admins"}, models.RoleAdmin},
+		{"demoted after leaving every mapped group", []string{"other"}, models.RoleGuest},
+		{"mapped again", []string{"hermes-developers"}, models.RoleUser},
+	}
+
+	var userID string
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			idp.setGroups(step.groups...)
+			login, err := s.StartLogin(ctx)
+			if err != nil {
+				t.Fatalf("StartLogin: %v", err)
+			}
+			code, state := idp.authorize(t, login.AuthURL)
+
+			resp, challenge, err := s.CompleteLogin(ctx, login.Session, state, code, models.SessionMetadata{})
+			if err != nil {
+				t.Fatalf("CompleteLogin: %v", err)
+			}
+			if challenge != nil || resp == nil || resp.AccessToken == "" {
+				t.Fatalf("CompleteLogin did not issue tokens")
+			}
+			if userID == "" {
+				userID = resp.User.ID
+			} else if resp.User.ID != userID {
+				t.Fatalf("user %s provisioned again as %s", userID, resp.User.ID)
+			}
+
+			stored, _ := users.GetByID(ctx, userID)
+			if resp.User.Role != step.want || stored.Role != step.want {
+				t.Errorf("role = %s (stored %s), want %s", resp.User.Role, stored.Role, step.want)
+			}
+			if stored.Username != "alice" || stored.Email != "alice@example.com" {
+				t.Errorf("provisioned user = %s %s", stored.Username, stored.Email)
+			}
+		})
+	}
+
+	// Provisioning and every role change are audited
+	var changes []string
+	for _, entry := range audits.entries {
+		if entry.ResourceType != AuditResourceUser || entry.ResourceID != userID {
+			continue
+		}
+		var after models.User
+		json.Unmarshal(entry.After, &after)
+		changes = append(changes, string(entry.Action)+":"+string(after.Role))
+	}
+	want := []string{
+		string(models.AuditActionCreate) + ":USER",
+		string(models.AuditActionUpdate) + ":ADMIN",
+		string(models.AuditActionUpdate) + ":GUEST",
+		string(models.AuditActionUpdate) + ":USER",
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("audited changes = %v, want %v", changes, want)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("audited change %d = %s, want %s", i, changes[i], want[i])
+		}
+	}
+}
+
+func TestOIDCLoginRejectsInvalidCallbacks(t *testing.T) {
+	idp := newStubIdP(t)
+	s, users, _ := newTestOIDCService(t, idp)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		callback func(t *testing.T) (session, state, code string)
+	}{
+		{
+			name: "code verifier of another login",
+			callback: func(t *testing.T) (string, string, string) {
+				first, _ := s.StartLogin(ctx)
+				second, _ := s.StartLogin(ctx)
+				code, _ := idp.authorize(t, first.AuthURL)
+				_, state := idp.authorize(t, second.AuthURL)
+				return second.Session, state, code
+			},
+		},
+		{
+			name: "state mismatch",
+			callback: func(t *testing.T) (string, string, string) {
+				login, _ := s.StartLogin(ctx)
+				code, _ := idp.authorize(t, login.AuthURL)
+				return login.Session, "forged-state", code
+			},
+		},
+		{
+			name: "code redeemed twice",
+			callback: func(t *testing.T) (string, string, string) {
+				login, _ := s.StartLogin(ctx)
+				code, state := idp.authorize(t, login.AuthURL)
+				if _, _, err := s.CompleteLogin(ctx, login.Session, state, code, models.SessionMetadata{}); err != nil {
+					t.Fatalf("first CompleteLogin: %v", err)
+				}
+				return login.Session, state, code
+			},
+		},
+		{
+			name: "tampered session",
+			callback: func(t *testing.T) (string, string, string) {
+				login, _ := s.StartLogin(ctx)
+				code, state := idp.authorize(t, login.AuthURL)
+				return login.Session[:len(login.Session)-4] + "AAAA", state, code
+			},
+		},
+	}
+
+	idp.setGroups("hermes-developers")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session, state, code := tt.callback(t)
+			_, _, err := s.CompleteLogin(ctx, session, state, code, models.SessionMetadata{})
+			if !errors.Is(err, ErrInvalidSSOLogin) {
+				t.Errorf("error = %v, want ErrInvalidSSOLogin", err)
+			}
+		})
+	}
+
+	if len(users.users) > 1 {
+		t.Errorf("%d users provisioned, want at most 1", len(users.users))
+	}
+}
@@ -0,0 +1,273 @@
+// internal/service/role.go
+package service
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/repository"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/security"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/errors"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/logger"
+)
+
+// ErrRoleNotFound is returned when a role does not exist
+var ErrRoleNotFound = errors.New("role not found")
+
+// roleNamePattern restricts role names to the style of the built-in roles
+var roleNamePattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+// RoleService manages custom roles and the role of each user
+type RoleService struct {
+	repo     repository.RoleRepository
+	userRepo repository.UserRepository
+	tokens   *TokenService
+	policy   *security.Policy
+	audit    *AuditService
+	log      *logger.Logger
+}
+
+// NewRoleService creates a new RoleService
+func NewRoleService(repo repository.RoleRepository, userRepo repository.UserRepository, tokens *TokenService, policy *security.Policy, audit *AuditService, log *logger.Logger) *RoleService {
+	return &RoleService{
+		repo:     repo,
+		userRepo: userRepo,
+		tokens:   tokens,
+		policy:   policy,
+		audit:    audit,
+		log:      log,
+	}
+}
+
+// ListRoles lists the built-in and custom roles
+func (s *RoleService) ListRoles(ctx context.Context) ([]*models.RoleDefinition, error) {
+	custom, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list roles")
+	}
+
+	roles := custom
+	for name, perms := range s.policy.RolePermissions() {
+		if security.IsBuiltinRole(name) {
+			roles = append(roles, builtinRole(name, perms))
+		}
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+	return roles, nil
+}
+
+// GetRole retrieves a built-in or custom role by name
+func (s *RoleService) GetRole(ctx context.Context, name models.Role) (*models.RoleDefinition, error) {
+	if security.IsBuiltinRole(name) {
+		return builtinRole(name, s.policy.RolePermissions()[name]), nil
+	}
+
+	role, err := s.repo.GetByName(ctx, name)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve role")
+	}
+	if role == nil {
+		return nil, ErrRoleNotFound
+	}
+	return role, nil
+}
+
+// CreateRole creates a custom role
+func (s *RoleService) CreateRole(ctx context.Context, req models.RoleRequest) (*models.RoleDefinition, error) {
+	name := models.Role(strings.ToUpper(strings.TrimSpace(string(req.Name))))
+	if !roleNamePattern.MatchString(string(name)) {
+		return nil, errors.New("role name may only contain letters, digits and underscores")
+	}
+	if security.IsBuiltinRole(name) {
+		return nil, errors.New("cannot redefine a built-in role")
+	}
+
+	existing, err := s.repo.GetByName(ctx, name)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to check role name")
+	}
+	if existing != nil {
+		return nil, errors.New("role with this name already exists")
+	}
+
+	perms, err := validatePermissions(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	role := &models.RoleDefinition{
+		Name:        name,
+		Description: req.Description,
+		Permissions: perms,
+	}
+	if err := s.repo.Create(ctx, role); err != nil {
+		return nil, errors.Wrap(err, "failed to create role")
+	}
+
+	s.log.Info("Role created", "name", role.Name, "permissions", role.Permissions)
+	s.reloadAfterChange(ctx)
+	return role, nil
+}
+
+// UpdateRole changes the description or permissions of a custom role
+func (s *RoleService) UpdateRole(ctx context.Context, name models.Role, req models.RoleUpdateRequest) (*models.RoleDefinition, error) {
+	if security.IsBuiltinRole(name) {
+		return nil, errors.New("built-in roles cannot be changed")
+	}
+	role, err := s.GetRole(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Description != nil {
+		role.Description = *req.Description
+	}
+	if req.Permissions != nil {
+		perms, err := validatePermissions(req.Permissions)
+		if err != nil {
+			return nil, err
+		}
+		role.Permissions = perms
+	}
+
+	if err := s.repo.Update(ctx, role); err != nil {
+		return nil, errors.Wrap(err, "failed to update role")
+	}
+
+	s.log.Info("Role updated", "name", role.Name, "permissions", role.Permissions)
+	s.reloadAfterChange(ctx)
+	return role, nil
+}
+
+// DeleteRole deletes a custom role that no user has
+func (s *RoleService) DeleteRole(ctx context.Context, name models.Role) error {
+	if security.IsBuiltinRole(name) {
+		return errors.New("built-in roles cannot be deleted")
+	}
+	if _, err := s.GetRole(ctx, name); err != nil {
+		return err
+	}
+
+	count, err := s.userRepo.CountByRole(ctx, name)
+	if err != nil {
+		return errors.Wrap(err, "failed to check role usage")
+	}
+	if count > 0 {
+		return errors.New("role is assigned to users")
+	}
+
+	if err := s.repo.Delete(ctx, name); err != nil {
+		return errors.Wrap(err, "failed to delete role")
+	}
+
+	s.log.Info("Role deleted", "name", name)
+	s.reloadAfterChange(ctx)
+	return nil
+}
+
+// SetUserRole changes the role of a user. Access tokens carry the role, so
+// the user is logged out everywhere and the new role applies from their next login.
+func (s *RoleService) SetUserRole(ctx context.Context, userID string, role models.Role) (*models.User, error) {
+	if !s.policy.HasRole(role) {
+		return nil, ErrRoleNotFound
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve user")
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	// Keep at least one administrator
+	if user.Role == models.RoleAdmin && role != models.RoleAdmin {
+		admins, err := s.userRepo.CountByRole(ctx, models.RoleAdmin)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to count administrators")
+		}
+		if admins <= 1 {
+			return nil, errors.New("cannot remove the last administrator")
+		}
+	}
+
+	if user.Role == role {
+		return user, nil
+	}
+
+	before := auditSnapshot(user)
+	user.Role = role
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, errors.Wrap(err, "failed to update user role")
+	}
+	s.log.Info("User role changed", "id", user.ID, "username", user.Username, "role", role)
+	s.audit.Record(ctx, models.AuditActionUpdate, AuditResourceUser, user.ID, before, user)
+
+	sessions, err := s.tokens.ListSessions(ctx, user.ID, "")
+	if err != nil {
+		return nil, err
+	}
+	if err := s.tokens.RevokeUserTokens(ctx, user.ID, "", "role changed"); err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, models.AuditActionDelete, AuditResourceSession, user.ID, sessions, nil)
+	return user, nil
+}
+
+// Reload loads the custom roles from the database into the policy
+func (s *RoleService) Reload(ctx context.Context) error {
+	roles, err := s.repo.List(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to list roles")
+	}
+
+	custom := make(map[models.Role][]security.Permission, len(roles))
+	for _, role := range roles {
+		perms := make([]security.Permission, len(role.Permissions))
+		for i, p := range role.Permissions {
+			perms[i] = security.Permission(p)
+		}
+		custom[role.Name] = perms
+	}
+	s.policy.Replace(custom)
+	return nil
+}
+
+// reloadAfterChange applies role changes right away instead of waiting for the next sync
+func (s *RoleService) reloadAfterChange(ctx context.Context) {
+	if err := s.Reload(ctx); err != nil {
+		s.log.Error("Failed to reload roles", "error", err)
+	}
+}
+
+// validatePermissions checks that every permission is known, removing duplicates
+func validatePermissions(perms []string) ([]string, error) {
+	seen := make(map[string]bool, len(perms))
+	valid := make([]string, 0, len(perms))
+	for _, p := range perms {
+		p = strings.TrimSpace(p)
+		if !security.ValidPermission(security.Permission(p)) {
+			return nil, errors.New("unknown permission: " + p)
+		}
+		if !seen[p] {
+			seen[p] = true
+			valid = append(valid, p)
+		}
+	}
+	if len(valid) == 0 {
+		return nil, errors.New("at least one permission is required")
+	}
+	return valid, nil
+}
+
+// builtinRole describes a built-in role
+func builtinRole(name models.Role, perms []security.Permission) *models.RoleDefinition {
+	role := &models.RoleDefinition{Name: name, Builtin: true}
+	for _, p := range perms {
+		role.Permissions = append(role.Permissions, string(p))
+	}
+	return role
+}
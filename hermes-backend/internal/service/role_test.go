@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/security"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/errors"
+)
+
+func TestSetUserRoleRevokesTokensOfTheOldRole(t *testing.T) {
+	ctx := context.Background()
+	users, tokens, user := newTestUserService(t)
+	audits := &fakeAuditRepo{}
+	s := NewRoleService(nil, users.repo, tokens, security.NewPolicy(), NewAuditService(audits, newTestLogger()), newTestLogger())
+
+	login, _, err := users.Login(ctx, models.UserLogin{Username: user.Username, Password: testPassword}, models.SessionMetadata{})
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+
+	if _, err := s.SetUserRole(ctx, user.ID, models.RoleGuest); err != nil {
+		t.Fatalf("SetUserRole: %v", err)
+	}
+	if _, err := tokens.issuer.Verify(login.AccessToken); err == nil {
+		t.Error("an access token issued before the demotion is still accepted")
+	}
+	if _, err := tokens.Refresh(ctx, login.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Refresh after the demotion = %v, want ErrInvalidRefreshToken", err)
+	}
+	if len(audits.entries) != 2 || audits.entries[1].ResourceType != AuditResourceSession {
+		t.Errorf("audit entries = %+v, want the role change and the revoked sessions", audits.entries)
+	}
+
+	// The new role applies from the next login
+	again, _, err := users.Login(ctx, models.UserLogin{Username: user.Username, Password: testPassword}, models.SessionMetadata{})
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	claims, err := tokens.issuer.Verify(again.AccessToken)
+	if err != nil || claims.Role != string(models.RoleGuest) {
+		t.Errorf("token after the demotion = %+v, %v, want role GUEST", claims, err)
+	}
+}
@@ -0,0 +1,610 @@
+// internal/service/route.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/repository"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/gateway"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/security"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/errors"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// ErrRouteNotFound is returned when a route does not exist
+var ErrRouteNotFound = errors.New("route not found")
+
+// ErrInvalidRoute is returned when a route or fault injection request is invalid
+var ErrInvalidRoute = errors.New("invalid route")
+
+// RouteService handles business logic for gateway routes
+type RouteService struct {
+	repo        repository.RouteRepository
+	serviceRepo repository.ServiceRepository
+	specRepo    repository.APISpecRepository
+	namespaces  *NamespaceService
+	proxy       *gateway.Proxy
+	jwt         *gateway.JWTValidator
+	validator   *gateway.RequestValidator
+	ipAccess    *gateway.IPAccessControl
+	cors        *gateway.CORSHandler
+	audit       *AuditService
+	log         *logger.Logger
+}
+
+// NewRouteService creates a new RouteService
+func NewRouteService(repo repository.RouteRepository, serviceRepo repository.ServiceRepository, specRepo repository.APISpecRepository, namespaces *NamespaceService, proxy *gateway.Proxy, jwt *gateway.JWTValidator, validator *gateway.RequestValidator, ipAccess *gateway.IPAccessControl, cors *gateway.CORSHandler, audit *AuditService, log *logger.Logger) *RouteService {
+	return &RouteService{
+		repo:        repo,
+		serviceRepo: serviceRepo,
+		specRepo:    specRepo,
+		namespaces:  namespaces,
+		proxy:       proxy,
+		jwt:         jwt,
+		validator:   validator,
+		ipAccess:    ipAccess,
+		cors:        cors,
+		audit:       audit,
+		log:         log,
+	}
+}
+
+// CreateRoute creates a new gateway route
+func (s *RouteService) CreateRoute(ctx context.Context, req models.RouteCreationRequest) (*models.Route, error) {
+	if err := validateTargets(req.Targets); err != nil {
+		return nil, err
+	}
+
+	if req.Criticality == "" {
+		req.Criticality = models.CriticalityDefault
+	}
+	if err := validateCriticality(req.Criticality, req.CriticalityRules); err != nil {
+		return nil, err
+	}
+	if err := s.validateJWT(req.JWT); err != nil {
+		return nil, err
+	}
+	if err := validateHMAC(req.HMAC); err != nil {
+		return nil, err
+	}
+	if err := validateIPAccess(req.IPAccess); err != nil {
+		return nil, err
+	}
+	if err := s.validateValidation(ctx, req.Validation); err != nil {
+		return nil, err
+	}
+	if err := validateCORS(req.CORS); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.GetByPath(ctx, req.Path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to check for existing route")
+	}
+	if existing != nil {
+		return nil, invalidRoute("route with this path already exists")
+	}
+
+	service, err := s.serviceRepo.GetByID(ctx, req.ServiceID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve service")
+	}
+	if service == nil {
+		return nil, invalidRoute("service not found")
+	}
+	namespace, err := s.namespaces.resolve(ctx, service.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.namespaces.checkRouteQuota(ctx, namespace); err != nil {
+		return nil, err
+	}
+
+	// Routes live in the namespace of the service they send traffic to
+	route := &models.Route{
+		ID:             "rt-" + uuid.New().String()[:8],
+		Path:           req.Path,
+		Description:    req.Description,
+		ServiceID:      req.ServiceID,
+		Namespace:      namespace.Name,
+		LoadBalancerID: req.LoadBalancerID,
+		Targets:        req.Targets,
+		Active:         true,
+		Headers:        req.Headers,
+		RateLimit:      req.RateLimit,
+		RequireAPIKey:  req.RequireAPIKey,
+		JWT:            req.JWT,
+		HMAC:           req.HMAC,
+		IPAccess:       req.IPAccess,
+		Validation:     req.Validation,
+		CORS:           req.CORS,
+
+		Criticality:      req.Criticality,
+		CriticalityRules: req.CriticalityRules,
+	}
+
+	if err := s.repo.Create(ctx, route); err != nil {
+		return nil, errors.Wrap(err, "failed to create route")
+	}
+
+	s.log.Info("Route created", "id", route.ID, "path", route.Path, "serviceID", route.ServiceID)
+	s.audit.Record(ctx, models.AuditActionCreate, AuditResourceRoute, route.ID, nil, route)
+	s.syncAfterChange(ctx)
+	return route, nil
+}
+
+// GetRoute retrieves a route by its ID
+func (s *RouteService) GetRoute(ctx context.Context, id string) (*models.Route, error) {
+	route, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve route")
+	}
+	if route == nil || !NamespaceAllowed(ctx, route.Namespace) {
+		return nil, ErrRouteNotFound
+	}
+	return route, nil
+}
+
+// ListRoutes lists the gateway routes of the namespaces the caller may see
+func (s *RouteService) ListRoutes(ctx context.Context) ([]*models.Route, error) {
+	scope := namespaceScope(ctx)
+	if scope != nil && len(scope) == 0 {
+		return []*models.Route{}, nil
+	}
+	routes, err := s.repo.List(ctx, scope...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list routes")
+	}
+	return routes, nil
+}
+
+// UpdateRoute updates an existing route
+func (s *RouteService) UpdateRoute(ctx context.Context, id string, update models.RouteUpdateRequest) (*models.Route, error) {
+	route, err := s.GetRoute(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	before := auditSnapshot(route)
+
+	if update.Path != nil && *update.Path != route.Path {
+		existing, err := s.repo.GetByPath(ctx, *update.Path)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to check for existing route")
+		}
+		if existing != nil {
+			return nil, invalidRoute("route with this path already exists")
+		}
+		route.Path = *update.Path
+	}
+	if update.Description != nil {
+		route.Description = *update.Description
+	}
+	if update.ServiceID != nil {
+		service, err := s.serviceRepo.GetByID(ctx, *update.ServiceID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to retrieve service")
+		}
+		if service == nil {
+			return nil, invalidRoute("service not found")
+		}
+		if service.Namespace != route.Namespace {
+			namespace, err := s.namespaces.resolve(ctx, service.Namespace)
+			if err != nil {
+				return nil, err
+			}
+			if err := s.namespaces.checkRouteQuota(ctx, namespace); err != nil {
+				return nil, err
+			}
+			route.Namespace = namespace.Name
+		}
+		route.ServiceID = *update.ServiceID
+	}
+	if update.LoadBalancerID != nil {
+		route.LoadBalancerID = *update.LoadBalancerID
+	}
+	if update.Targets != nil {
+		if err := validateTargets(update.Targets); err != nil {
+			return nil, err
+		}
+		route.Targets = update.Targets
+	}
+	if update.Active != nil {
+		route.Active = *update.Active
+	}
+	if update.Headers != nil {
+		route.Headers = update.Headers
+	}
+	if update.RateLimit != nil {
+		route.RateLimit = update.RateLimit
+	}
+	if update.RequireAPIKey != nil {
+		route.RequireAPIKey = *update.RequireAPIKey
+	}
+	if update.JWT != nil {
+		if err := s.validateJWT(update.JWT); err != nil {
+			return nil, err
+		}
+		route.JWT = update.JWT
+	}
+	if update.RemoveJWT {
+		route.JWT = nil
+	}
+	if update.HMAC != nil {
+		if err := validateHMAC(update.HMAC); err != nil {
+			return nil, err
+		}
+		route.HMAC = update.HMAC
+	}
+	if update.RemoveHMAC {
+		route.HMAC = nil
+	}
+	if update.IPAccess != nil {
+		if err := validateIPAccess(update.IPAccess); err != nil {
+			return nil, err
+		}
+		route.IPAccess = update.IPAccess
+	}
+	if update.RemoveIPAccess {
+		route.IPAccess = nil
+	}
+	if update.Validation != nil {
+		if err := s.validateValidation(ctx, update.Validation); err != nil {
+			return nil, err
+		}
+		route.Validation = update.Validation
+	}
+	if update.RemoveValidation {
+		route.Validation = nil
+	}
+	if update.CORS != nil {
+		if err := validateCORS(update.CORS); err != nil {
+			return nil, err
+		}
+		route.CORS = update.CORS
+	}
+	if update.RemoveCORS {
+		route.CORS = nil
+	}
+	if update.Criticality != nil {
+		route.Criticality = *update.Criticality
+	}
+	if update.CriticalityRules != nil {
+		route.CriticalityRules = update.CriticalityRules
+	}
+	if err := validateCriticality(route.Criticality, route.CriticalityRules); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, route); err != nil {
+		return nil, errors.Wrap(err, "failed to update route")
+	}
+
+	s.log.Info("Route updated", "id", route.ID, "path", route.Path)
+	s.audit.Record(ctx, models.AuditActionUpdate, AuditResourceRoute, route.ID, before, route)
+	s.syncAfterChange(ctx)
+	return route, nil
+}
+
+// DeleteRoute deletes a route by its ID
+func (s *RouteService) DeleteRoute(ctx context.Context, id string) error {
+	route, err := s.GetRoute(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, route.ID); err != nil {
+		return errors.Wrap(err, "failed to delete route")
+	}
+
+	s.log.Info("Route deleted", "id", route.ID, "path", route.Path)
+	s.audit.Record(ctx, models.AuditActionDelete, AuditResourceRoute, route.ID, route, nil)
+	s.syncAfterChange(ctx)
+	return nil
+}
+
+// SetRouteFault enables fault injection on a route until the requested duration elapses
+func (s *RouteService) SetRouteFault(ctx context.Context, id string, req models.FaultInjectionRequest) (*models.Route, error) {
+	if err := validateFault(req); err != nil {
+		return nil, err
+	}
+
+	route, err := s.GetRoute(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	before := auditSnapshot(route)
+
+	route.Fault = &models.FaultInjection{
+		Delay:       req.Delay,
+		Abort:       req.Abort,
+		Reset:       req.Reset,
+		MatchHeader: req.MatchHeader,
+		MatchValue:  req.MatchValue,
+		ExpiresAt:   time.Now().Add(time.Duration(req.DurationSeconds) * time.Second),
+	}
+
+	if err := s.repo.Update(ctx, route); err != nil {
+		return nil, errors.Wrap(err, "failed to update route fault")
+	}
+
+	s.log.Warn("Fault injection enabled", "id", route.ID, "path", route.Path, "expiresAt", route.Fault.ExpiresAt)
+	s.audit.Record(ctx, models.AuditActionUpdate, AuditResourceRoute, route.ID, before, route)
+	s.syncAfterChange(ctx)
+	return route, nil
+}
+
+// ClearRouteFault disables fault injection on a route
+func (s *RouteService) ClearRouteFault(ctx context.Context, id string) error {
+	route, err := s.GetRoute(ctx, id)
+	if err != nil {
+		return err
+	}
+	before := auditSnapshot(route)
+
+	route.Fault = nil
+	if err := s.repo.Update(ctx, route); err != nil {
+		return errors.Wrap(err, "failed to clear route fault")
+	}
+
+	s.log.Info("Fault injection disabled", "id", route.ID, "path", route.Path)
+	s.audit.Record(ctx, models.AuditActionUpdate, AuditResourceRoute, route.ID, before, route)
+	s.syncAfterChange(ctx)
+	return nil
+}
+
+// SyncRoutes loads the active routes and their upstream services into the gateway proxy
+func (s *RouteService) SyncRoutes(ctx context.Context) error {
+	routes, err := s.repo.ListActive(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to load active routes")
+	}
+
+	seen := make(map[string]bool)
+	serviceIDs := make([]string, 0, len(routes))
+	for _, route := range routes {
+		if !seen[route.ServiceID] {
+			seen[route.ServiceID] = true
+			serviceIDs = append(serviceIDs, route.ServiceID)
+		}
+	}
+
+	services, err := s.serviceRepo.GetByIDs(ctx, serviceIDs)
+	if err != nil {
+		return errors.Wrap(err, "failed to load route services")
+	}
+
+	if err := s.syncValidation(ctx, routes); err != nil {
+		return err
+	}
+
+	// Compile the access rules and CORS policies before the proxy can match the routes
+	s.ipAccess.Update(routes)
+	s.cors.Update(routes)
+	s.proxy.UpdateServices(services)
+	s.proxy.UpdateRoutes(routes)
+	return nil
+}
+
+// syncValidation loads the API specifications of routes with request
+// validation into the validator. Routes without a specification of their own
+// use the one of their service's active version.
+func (s *RouteService) syncValidation(ctx context.Context, routes []*models.Route) error {
+	bindings := make(map[string]string)
+	activeSpecs := make(map[string]string) // by service ID
+	var specIDs []string
+	seen := make(map[string]bool)
+	for _, route := range routes {
+		if route.Validation == nil {
+			continue
+		}
+		specID := route.Validation.SpecID
+		if specID == "" {
+			active, ok := activeSpecs[route.ServiceID]
+			if !ok {
+				versions, err := s.serviceRepo.GetVersions(ctx, route.ServiceID)
+				if err != nil {
+					return errors.Wrap(err, "failed to load service versions")
+				}
+				for _, version := range versions {
+					if version.IsActive && version.SpecID != nil {
+						active = *version.SpecID
+					}
+				}
+				activeSpecs[route.ServiceID] = active
+			}
+			specID = active
+		}
+		if specID == "" {
+			continue
+		}
+		bindings[route.ID] = specID
+		if !seen[specID] {
+			seen[specID] = true
+			specIDs = append(specIDs, specID)
+		}
+	}
+
+	specs, err := s.specRepo.GetByIDs(ctx, specIDs)
+	if err != nil {
+		return errors.Wrap(err, "failed to load API specifications")
+	}
+	s.validator.Update(specs, bindings)
+	return nil
+}
+
+// GatewayStats returns the runtime statistics of the gateway proxy
+func (s *RouteService) GatewayStats() map[string]interface{} {
+	return s.proxy.Stats()
+}
+
+// syncAfterChange pushes route changes to the proxy right away instead of
+// waiting for the next periodic sync
+func (s *RouteService) syncAfterChange(ctx context.Context) {
+	if err := s.SyncRoutes(ctx); err != nil {
+		s.log.Error("Failed to sync gateway routes", "error", err)
+	}
+}
+
+// validateTargets checks that every target is an absolute URL
+func validateTargets(targets []string) error {
+	if len(targets) == 0 {
+		return invalidRoute("at least one target is required")
+	}
+	for _, target := range targets {
+		u, err := url.Parse(target)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return invalidRoute("invalid target URL: " + target)
+		}
+	}
+	return nil
+}
+
+// validateFault checks that a fault injection request describes at least one sane fault
+func validateFault(req models.FaultInjectionRequest) error {
+	if req.Delay == nil && req.Abort == nil && req.Reset == nil {
+		return invalidRoute("at least one of delay, abort or reset is required")
+	}
+	if req.MatchValue != "" && req.MatchHeader == "" {
+		return invalidRoute("match_value requires match_header")
+	}
+	if req.Delay != nil {
+		if req.Delay.FixedMs < 0 || req.Delay.MaxMs < 0 {
+			return invalidRoute("delay must not be negative")
+		}
+		if !validPercentage(req.Delay.Percentage) {
+			return invalidRoute("delay percentage must be between 0 and 100")
+		}
+	}
+	if req.Abort != nil {
+		if req.Abort.StatusCode < 200 || req.Abort.StatusCode > 599 {
+			return invalidRoute("abort status code must be between 200 and 599")
+		}
+		if !validPercentage(req.Abort.Percentage) {
+			return invalidRoute("abort percentage must be between 0 and 100")
+		}
+	}
+	if req.Reset != nil && !validPercentage(req.Reset.Percentage) {
+		return invalidRoute("reset percentage must be between 0 and 100")
+	}
+	return nil
+}
+
+// validateCriticality checks a route's criticality class and header rules
+func validateCriticality(criticality models.Criticality, rules []models.CriticalityRule) error {
+	if !criticality.Valid() {
+		return invalidRoute("criticality must be one of: CRITICAL, DEFAULT, SHEDDABLE")
+	}
+	for _, rule := range rules {
+		if rule.Header == "" {
+			return invalidRoute("criticality rules require a header")
+		}
+		if !rule.Criticality.Valid() {
+			return invalidRoute("criticality must be one of: CRITICAL, DEFAULT, SHEDDABLE")
+		}
+	}
+	return nil
+}
+
+// validateJWT checks that a route's token requirement only names configured issuers
+func (s *RouteService) validateJWT(req *models.JWTRequirement) error {
+	if req == nil {
+		return nil
+	}
+	if !s.jwt.HasIssuers() {
+		return invalidRoute("no JWT issuers are configured")
+	}
+	for _, name := range req.Issuers {
+		if !s.jwt.HasIssuer(name) {
+			return invalidRoute("unknown JWT issuer: " + name)
+		}
+	}
+	for header, claim := range req.ForwardClaims {
+		if strings.TrimSpace(header) == "" || claim == "" {
+			return invalidRoute("forwarded claims require a header and a claim")
+		}
+	}
+	return nil
+}
+
+// validateHMAC checks a route's signature requirement, defaulting the format to hermes
+func validateHMAC(req *models.HMACRequirement) error {
+	if req == nil {
+		return nil
+	}
+	if req.Format == "" {
+		req.Format = models.SignatureFormatHermes
+	}
+	if !req.Format.Valid() {
+		return invalidRoute("signature format must be one of: hermes, authorization, compact")
+	}
+	if req.MaxSkew < 0 {
+		return invalidRoute("signature max skew must not be negative")
+	}
+	return nil
+}
+
+// validateIPAccess checks that every rule of a route is a CIDR or an address
+func validateIPAccess(rules *models.IPAccessRules) error {
+	if rules == nil {
+		return nil
+	}
+	if _, err := security.ParseIPRules(rules.Allow, rules.Deny); err != nil {
+		return invalidRoute(err.Error())
+	}
+	return nil
+}
+
+// validateCORS checks that a route's CORS policy compiles
+func validateCORS(policy *models.CORSPolicy) error {
+	if policy == nil {
+		return nil
+	}
+	if len(policy.AllowedOrigins) == 0 {
+		return invalidRoute("CORS policies require at least one allowed origin")
+	}
+	if _, err := security.NewCORS(*policy); err != nil {
+		return invalidRoute(err.Error())
+	}
+	return nil
+}
+
+// validateValidation checks a route's validation mode and that its
+// specification exists, defaulting the mode to enforce
+func (s *RouteService) validateValidation(ctx context.Context, validation *models.RequestValidation) error {
+	if validation == nil {
+		return nil
+	}
+	switch validation.Mode {
+	case "":
+		validation.Mode = models.ValidationModeEnforce
+	case models.ValidationModeEnforce, models.ValidationModeReport:
+	default:
+		return invalidRoute("validation mode must be one of: enforce, report")
+	}
+	if validation.SpecID == "" {
+		return nil
+	}
+	spec, err := s.specRepo.GetByID(ctx, validation.SpecID)
+	if err != nil {
+		return errors.Wrap(err, "failed to retrieve API specification")
+	}
+	if spec == nil {
+		return invalidRoute("API specification not found")
+	}
+	return nil
+}
+
+// invalidRoute returns a validation error of a route request
+func invalidRoute(msg string) error {
+	return fmt.Errorf("%w: %s", ErrInvalidRoute, msg)
+}
+
+func validPercentage(p float64) bool {
+	return p >= 0 && p <= 100
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/repository"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/errors"
+)
+
+// failingRouteRepo fails every lookup, like an unreachable database
+type failingRouteRepo struct {
+	repository.RouteRepository
+}
+
+func (failingRouteRepo) GetByPath(ctx context.Context, path string) (*models.Route, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestRouteValidationErrorsAreInvalidRoute(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"no targets", validateTargets(nil)},
+		{"relative target", validateTargets([]string{"/api"})},
+		{"no fault", validateFault(models.FaultInjectionRequest{})},
+		{"bad criticality", validateCriticality("URGENT", nil)},
+		{"bad signature format", validateHMAC(&models.HMACRequirement{Format: "plain"})},
+		{"bad IP rule", validateIPAccess(&models.IPAccessRules{Allow: []string{"not an address"}})},
+		{"CORS without origins", validateCORS(&models.CORSPolicy{})},
+	}
+	for _, tt := range tests {
+		if !errors.Is(tt.err, ErrInvalidRoute) {
+			t.Errorf("%s: error = %v, want ErrInvalidRoute", tt.name, tt.err)
+		}
+	}
+}
+
+func TestCreateRouteDoesNotReportFailuresAsInvalid(t *testing.T) {
+	s := NewRouteService(failingRouteRepo{}, nil, nil, nil, nil, nil, nil, nil, nil, nil, newTestLogger())
+	req := models.RouteCreationRequest{Path: "/api", ServiceID: "svc-1", Targets: []string{"http://10.0.0.1:8080"}}
+
+	_, err := s.CreateRoute(context.Background(), req)
+	if err == nil || errors.Is(err, ErrInvalidRoute) {
+		t.Errorf("CreateRoute with a failing repository = %v, want an internal error", err)
+	}
+}
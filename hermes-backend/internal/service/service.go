@@ -16,22 +16,37 @@ import (
 
 // ServiceService handles business logic for services
 type ServiceService struct {
-	repo repository.ServiceRepository
-	log  *logger.Logger
+	repo       repository.ServiceRepository
+	teamRepo   repository.TeamRepository
+	specRepo   repository.APISpecRepository
+	namespaces *NamespaceService
+	encryption *EncryptionService
+	audit      *AuditService
+	log        *logger.Logger
 }
 
 // NewServiceService creates a new ServiceService
-func NewServiceService(repo repository.ServiceRepository, log *logger.Logger) *ServiceService {
+func NewServiceService(repo repository.ServiceRepository, teamRepo repository.TeamRepository, specRepo repository.APISpecRepository, namespaces *NamespaceService, encryption *EncryptionService, audit *AuditService, log *logger.Logger) *ServiceService {
 	return &ServiceService{
-		repo: repo,
-		log:  log,
+		repo:       repo,
+		teamRepo:   teamRepo,
+		specRepo:   specRepo,
+		namespaces: namespaces,
+		encryption: encryption,
+		audit:      audit,
+		log:        log,
 	}
 }
 
 // RegisterService handles the registration of a new service
 func (s *ServiceService) RegisterService(ctx context.Context, reg models.ServiceRegistration) (*models.Service, error) {
-	// Check if service with the same name already exists
-	existing, err := s.repo.GetByName(ctx, reg.Name)
+	namespace, err := s.namespaces.resolve(ctx, reg.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check if service with the same name already exists in the namespace
+	existing, err := s.repo.GetByName(ctx, namespace.Name, reg.Name)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, errors.Wrap(err, "failed to check for existing service")
 	}
@@ -39,6 +54,38 @@ func (s *ServiceService) RegisterService(ctx context.Context, reg models.Service
 	if existing != nil {
 		return nil, errors.New("service with this name already exists")
 	}
+	if err := s.namespaces.checkServiceQuota(ctx, namespace); err != nil {
+		return nil, err
+	}
+
+	if err := validateBulkhead(reg.Bulkhead); err != nil {
+		return nil, err
+	}
+	if err := validateAdaptiveConcurrency(reg.AdaptiveConcurrency); err != nil {
+		return nil, err
+	}
+	if reg.HeartbeatTTL < 0 {
+		return nil, ErrInvalidHeartbeatTTL
+	}
+
+	var ownerTeamID *string
+	if reg.OwnerTeamID != "" {
+		if err := s.checkTeam(ctx, reg.OwnerTeamID); err != nil {
+			return nil, err
+		}
+		ownerTeamID = &reg.OwnerTeamID
+	}
+	visibility := reg.Visibility
+	if visibility == "" {
+		visibility = models.VisibilityPublic
+	}
+	if err := validateVisibility(visibility, ownerTeamID); err != nil {
+		return nil, err
+	}
+
+	if err := s.encryption.SealMetadata(reg.Metadata); err != nil {
+		return nil, err
+	}
 
 	var registeredBy string
 	if reg.RegisteredBy == "" {
@@ -51,6 +98,7 @@ func (s *ServiceService) RegisterService(ctx context.Context, reg models.Service
 	service := &models.Service{
 		ID:           "svc-" + uuid.New().String()[:8],
 		Name:         reg.Name,
+		Namespace:    namespace.Name,
 		Description:  reg.Description,
 		Status:       models.ServiceStatusUnknown,
 		Type:         reg.Type,
@@ -61,6 +109,12 @@ func (s *ServiceService) RegisterService(ctx context.Context, reg models.Service
 		UpdatedAt:    time.Now(),
 		LastSeen:     time.Now(),
 		RegisteredBy: registeredBy,
+		OwnerTeamID:  ownerTeamID,
+		Visibility:   visibility,
+		Bulkhead:     reg.Bulkhead,
+		HeartbeatTTL: reg.HeartbeatTTL,
+
+		AdaptiveConcurrency: reg.AdaptiveConcurrency,
 	}
 
 	if err := s.repo.Create(ctx, service); err != nil {
@@ -69,6 +123,7 @@ func (s *ServiceService) RegisterService(ctx context.Context, reg models.Service
 
 	// TODO: Trigger initial health check (async)
 	s.log.Info("Service registered", "id", service.ID, "name", service.Name)
+	s.audit.Record(ctx, models.AuditActionCreate, AuditResourceService, service.ID, nil, service)
 	return service, nil
 }
 
@@ -84,20 +139,32 @@ func (s *ServiceService) GetServiceByID(ctx context.Context, id string) (*models
 	return service, nil
 }
 
-// GetServiceByName retrieves a service by its name
-func (s *ServiceService) GetServiceByName(ctx context.Context, name string) (*models.Service, error) {
-	service, err := s.repo.GetByName(ctx, name)
+// GetServiceByName retrieves a service by its name within a namespace, the
+// default one when none is given. Services deregistered after missing their
+// heartbeats are not found, so that clients stop calling them.
+func (s *ServiceService) GetServiceByName(ctx context.Context, namespace, name string) (*models.Service, error) {
+	if namespace == "" {
+		namespace = models.DefaultNamespace
+	}
+	if !NamespaceAllowed(ctx, namespace) {
+		return nil, nil
+	}
+	service, err := s.repo.GetByName(ctx, namespace, name)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil // Service not found
 		}
 		return nil, errors.Wrap(err, "failed to retrieve service")
 	}
+	if service != nil && service.DeregisteredAt != nil {
+		return nil, nil
+	}
 	return service, nil
 }
 
 // ListServices lists all services with optional filters and pagination
 func (s *ServiceService) ListServices(ctx context.Context, params models.ServiceQueryParams) ([]*models.Service, int64, error) {
+	params.Namespaces = namespaceScope(ctx)
 	services, total, err := s.repo.List(ctx, params)
 	if err != nil {
 		return nil, 0, errors.Wrap(err, "failed to list services")
@@ -115,6 +182,7 @@ func (s *ServiceService) UpdateService(ctx context.Context, id string, update mo
 		}
 		return nil, errors.Wrap(err, "failed to retrieve service")
 	}
+	before := auditSnapshot(service)
 
 	// Update service fields
 	if update.Description != nil {
@@ -124,17 +192,66 @@ func (s *ServiceService) UpdateService(ctx context.Context, id string, update mo
 		service.Endpoint = *update.Endpoint
 	}
 	if update.Metadata != nil {
+		if err := s.encryption.SealMetadata(update.Metadata); err != nil {
+			return nil, err
+		}
 		service.Metadata = update.Metadata
 	}
 	if update.Tags != nil {
 		service.Tags = update.Tags
 	}
+	if update.OwnerTeamID != nil {
+		// An empty ID removes the owner team
+		if *update.OwnerTeamID == "" {
+			service.OwnerTeamID = nil
+		} else {
+			if err := s.checkTeam(ctx, *update.OwnerTeamID); err != nil {
+				return nil, err
+			}
+			service.OwnerTeamID = update.OwnerTeamID
+		}
+	}
+	if update.Visibility != nil {
+		service.Visibility = *update.Visibility
+	}
+	if err := validateVisibility(service.Visibility, service.OwnerTeamID); err != nil {
+		return nil, err
+	}
+	if update.Bulkhead != nil {
+		if err := validateBulkhead(update.Bulkhead); err != nil {
+			return nil, err
+		}
+		// A zero limit removes the bulkhead
+		if update.Bulkhead.MaxConcurrent == 0 {
+			service.Bulkhead = nil
+		} else {
+			service.Bulkhead = update.Bulkhead
+		}
+	}
+	if update.AdaptiveConcurrency != nil {
+		if err := validateAdaptiveConcurrency(update.AdaptiveConcurrency); err != nil {
+			return nil, err
+		}
+		// An empty configuration disables adaptive limiting
+		if *update.AdaptiveConcurrency == (models.AdaptiveConcurrencyConfig{}) {
+			service.AdaptiveConcurrency = nil
+		} else {
+			service.AdaptiveConcurrency = update.AdaptiveConcurrency
+		}
+	}
+	if update.HeartbeatTTL != nil {
+		if *update.HeartbeatTTL < 0 {
+			return nil, ErrInvalidHeartbeatTTL
+		}
+		service.HeartbeatTTL = *update.HeartbeatTTL
+	}
 
 	if err := s.repo.Update(ctx, service); err != nil {
 		return nil, errors.Wrap(err, "failed to update service")
 	}
 
 	s.log.Info("Service updated", "id", service.ID, "name", service.Name)
+	s.audit.Record(ctx, models.AuditActionUpdate, AuditResourceService, service.ID, before, service)
 	return service, nil
 }
 
@@ -154,6 +271,7 @@ func (s *ServiceService) DeleteService(ctx context.Context, id string) error {
 	}
 
 	s.log.Info("Service deleted", "id", service.ID, "name", service.Name)
+	s.audit.Record(ctx, models.AuditActionDelete, AuditResourceService, service.ID, service, nil)
 	return nil
 }
 
@@ -176,27 +294,9 @@ func (s *ServiceService) UpdateServiceStatus(ctx context.Context, id string, sta
 	return nil
 }
 
-// UpdateServiceLastSeen updates the LastSeen timestamp of a service
-func (s *ServiceService) UpdateServiceLastSeen(ctx context.Context, id string) error {
-	service, err := s.repo.GetByID(ctx, id)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("service not found")
-		}
-		return errors.Wrap(err, "failed to retrieve service")
-	}
-
-	service.LastSeen = time.Now()
-	if err := s.repo.Update(ctx, service); err != nil {
-		return errors.Wrap(err, "failed to update service last seen")
-	}
-
-	s.log.Info("Service LastSeen updated", "id", service.ID, "name", service.Name)
-	return nil
-}
-
 // AdvancedDiscovery provides advanced service discovery capabilities
 func (s *ServiceService) AdvancedDiscovery(ctx context.Context, params models.AdvancedDiscoveryParams) ([]*models.Service, int64, error) {
+	params.Namespaces = namespaceScope(ctx)
 	services, total, err := s.repo.AdvancedSearch(ctx, params)
 	if err != nil {
 		return nil, 0, errors.Wrap(err, "failed to perform advanced service discovery")
@@ -259,6 +359,18 @@ func (s *ServiceService) AddServiceVersion(ctx context.Context, serviceID string
 		UpdatedAt:   time.Now(),
 	}
 
+	// Routes of the service validate against the specification of its active version
+	if versionReq.SpecID != "" {
+		spec, err := s.specRepo.GetByID(ctx, versionReq.SpecID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to retrieve API specification")
+		}
+		if spec == nil {
+			return nil, errors.New("API specification not found")
+		}
+		version.SpecID = &spec.ID
+	}
+
 	// If this is the first version or marked as active, ensure it's the only active one
 	if versionReq.IsActive {
 		// Deactivate all other versions first
@@ -283,6 +395,7 @@ func (s *ServiceService) AddServiceVersion(ctx context.Context, serviceID string
 	}
 
 	s.log.Info("Service version added", "serviceID", serviceID, "version", version.Version)
+	s.audit.Record(ctx, models.AuditActionCreate, AuditResourceVersion, serviceID+"@"+version.Version, nil, version)
 	return version, nil
 }
 
@@ -317,7 +430,7 @@ func (s *ServiceService) ActivateServiceVersion(ctx context.Context, serviceID s
 	}
 
 	// Check if version exists
-	_, ver_err := s.repo.GetVersion(ctx, serviceID, version)
+	existing, ver_err := s.repo.GetVersion(ctx, serviceID, version)
 	if ver_err != nil {
 		if errors.Is(ver_err, gorm.ErrRecordNotFound) {
 			return errors.New("version not found")
@@ -331,13 +444,16 @@ func (s *ServiceService) ActivateServiceVersion(ctx context.Context, serviceID s
 	}
 
 	s.log.Info("Service version activated", "serviceID", serviceID, "version", version)
+	activated := *existing
+	activated.IsActive = true
+	s.audit.Record(ctx, models.AuditActionUpdate, AuditResourceVersion, serviceID+"@"+version, existing, &activated)
 	return nil
 }
 
 // AddServiceDependency creates a dependency relationship between services
 func (s *ServiceService) AddServiceDependency(ctx context.Context, serviceID string, depReq models.ServiceDependencyRequest) (*models.ServiceDependency, error) {
 	// Check if source service exists
-	_, source_err := s.repo.GetByID(ctx, serviceID)
+	source, source_err := s.repo.GetByID(ctx, serviceID)
 	if source_err != nil {
 		if errors.Is(source_err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("source service not found")
@@ -346,13 +462,22 @@ func (s *ServiceService) AddServiceDependency(ctx context.Context, serviceID str
 	}
 
 	// Check if dependency service exists
-	_, dep_err := s.repo.GetByID(ctx, depReq.DependencyID)
+	target, dep_err := s.repo.GetByID(ctx, depReq.DependencyID)
 	if dep_err != nil {
 		if errors.Is(dep_err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("dependency service not found")
 		}
 		return nil, errors.Wrap(dep_err, "failed to retrieve dependency service")
 	}
+	if source == nil {
+		return nil, errors.New("source service not found")
+	}
+	if target == nil {
+		return nil, errors.New("dependency service not found")
+	}
+	if err := s.namespaces.checkDependency(ctx, source.Namespace, target.Namespace); err != nil {
+		return nil, err
+	}
 
 	// Validate dependency type
 	validTypes := []string{"REQUIRED", "OPTIONAL"}
@@ -398,6 +523,7 @@ func (s *ServiceService) AddServiceDependency(ctx context.Context, serviceID str
 		"serviceID", serviceID,
 		"dependencyID", depReq.DependencyID,
 		"type", depReq.DependencyType)
+	s.audit.Record(ctx, models.AuditActionCreate, AuditResourceDependency, serviceID+"->"+depReq.DependencyID, nil, dependency)
 
 	return dependency, nil
 }
@@ -448,25 +574,82 @@ func (s *ServiceService) RemoveServiceDependency(ctx context.Context, serviceID
 		return errors.Wrap(err, "failed to retrieve dependencies")
 	}
 
-	var depID uint
-	found := false
-
+	var removed *models.ServiceDependency
 	for _, dep := range dependencies {
 		if dep.DependencyID == dependencyID {
-			depID = dep.ID
-			found = true
+			removed = dep
 			break
 		}
 	}
 
-	if !found {
+	if removed == nil {
 		return errors.New("dependency relationship not found")
 	}
 
-	if err := s.repo.RemoveDependency(ctx, depID); err != nil {
+	if err := s.repo.RemoveDependency(ctx, removed.ID); err != nil {
 		return errors.Wrap(err, "failed to remove dependency")
 	}
 
 	s.log.Info("Service dependency removed", "serviceID", serviceID, "dependencyID", dependencyID)
+	s.audit.Record(ctx, models.AuditActionDelete, AuditResourceDependency, serviceID+"->"+dependencyID, removed, nil)
+	return nil
+}
+
+// checkTeam checks that an owner team exists
+func (s *ServiceService) checkTeam(ctx context.Context, teamID string) error {
+	team, err := s.teamRepo.GetByID(ctx, teamID)
+	if err != nil {
+		return errors.Wrap(err, "failed to retrieve owner team")
+	}
+	if team == nil {
+		return ErrTeamNotFound
+	}
+	return nil
+}
+
+// validateVisibility checks a visibility setting. Private services need an
+// owner team, otherwise nobody but administrators could see them.
+func validateVisibility(visibility models.Visibility, ownerTeamID *string) error {
+	switch visibility {
+	case models.VisibilityPublic:
+		return nil
+	case models.VisibilityPrivate:
+		if ownerTeamID == nil {
+			return errors.New("private services require an owner team")
+		}
+		return nil
+	default:
+		return errors.New("invalid visibility, must be one of: PUBLIC, PRIVATE")
+	}
+}
+
+// validateBulkhead checks the concurrency limits of a bulkhead configuration
+func validateBulkhead(bulkhead *models.BulkheadConfig) error {
+	if bulkhead == nil {
+		return nil
+	}
+	if bulkhead.MaxConcurrent < 0 || bulkhead.MaxQueue < 0 || bulkhead.QueueTimeoutMs < 0 {
+		return errors.New("bulkhead limits must not be negative")
+	}
+	if bulkhead.MaxQueue > 0 && bulkhead.MaxConcurrent == 0 {
+		return errors.New("bulkhead max_queue requires max_concurrent")
+	}
+	return nil
+}
+
+// validateAdaptiveConcurrency checks the bounds of an adaptive concurrency configuration
+func validateAdaptiveConcurrency(adaptive *models.AdaptiveConcurrencyConfig) error {
+	if adaptive == nil {
+		return nil
+	}
+	if adaptive.InitialLimit < 0 || adaptive.MinLimit < 0 || adaptive.MaxLimit < 0 {
+		return errors.New("adaptive concurrency limits must not be negative")
+	}
+	if adaptive.MaxLimit > 0 && adaptive.MinLimit > adaptive.MaxLimit {
+		return errors.New("adaptive concurrency min_limit must not exceed max_limit")
+	}
+	if adaptive.Tolerance != 0 && adaptive.Tolerance < 1 {
+		return errors.New("adaptive concurrency tolerance must be at least 1")
+	}
 	return nil
 }
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/security"
+)
+
+// newTestServiceAccountService creates a ServiceAccountService for the service svc-1
+func newTestServiceAccountService(t *testing.T) (*ServiceAccountService, *fakeAuditRepo) {
+	t.Helper()
+	services := newFakeServiceRepo(&models.Service{ID: "svc-1", Name: "api"})
+	_, _, issuer := newTestTokenIssuer(t)
+	audits := &fakeAuditRepo{}
+	log := newTestLogger()
+	return NewServiceAccountService(newFakeServiceAccountRepo(), services, issuer, NewAuditService(audits, log), log), audits
+}
+
+func TestCreateAccountRequiresScopes(t *testing.T) {
+	s, audits := newTestServiceAccountService(t)
+	for _, scopes := range [][]string{nil, {}} {
+		if _, err := s.CreateAccount(context.Background(), "svc-1", models.ServiceAccountRequest{Name: "reporter", Scopes: scopes}, "usr-1"); err == nil {
+			t.Errorf("CreateAccount with scopes %v succeeded", scopes)
+		}
+	}
+	if _, err := s.CreateAccount(context.Background(), "svc-1", models.ServiceAccountRequest{Name: "reporter", Scopes: []string{"health:report:everything"}}, "usr-1"); err == nil {
+		t.Error("CreateAccount with an unknown scope succeeded")
+	}
+	if len(audits.entries) != 0 {
+		t.Errorf("%d audit entries for rejected accounts", len(audits.entries))
+	}
+
+	creds, err := s.CreateAccount(context.Background(), "svc-1", models.ServiceAccountRequest{Name: "reporter", Scopes: []string{"health:report:self", "health:report:self"}}, "usr-1")
+	if err != nil {
+		t.Fatalf("CreateAccount: %v", err)
+	}
+	if len(creds.Account.Scopes) != 1 || creds.Account.Scopes[0] != "health:report:self" {
+		t.Errorf("scopes = %v, want [health:report:self]", creds.Account.Scopes)
+	}
+}
+
+func TestServiceAccountChangesAreAudited(t *testing.T) {
+	ctx := WithAuditActor(context.Background(), models.AuditActor{ID: "usr-1", Name: "alice", SourceIP: "10.0.0.1"})
+	s, audits := newTestServiceAccountService(t)
+
+	creds, err := s.CreateAccount(ctx, "svc-1", models.ServiceAccountRequest{Name: "reporter", Scopes: []string{"health:report:self"}}, "usr-1")
+	if err != nil {
+		t.Fatalf("CreateAccount: %v", err)
+	}
+	rotated, err := s.RotateSecret(ctx, "svc-1", creds.ClientID)
+	if err != nil {
+		t.Fatalf("RotateSecret: %v", err)
+	}
+
+	// Token requests are not signed in, only their source is known
+	mintCtx := WithAuditActor(context.Background(), models.AuditActor{SourceIP: "10.0.0.2"})
+	token, err := s.MintToken(mintCtx, models.ServiceTokenRequest{ClientID: rotated.ClientID, ClientSecret: rotated.ClientSecret})
+	if err != nil {
+		t.Fatalf("MintToken: %v", err)
+	}
+	if err := s.DeleteAccount(ctx, "svc-1", creds.ClientID); err != nil {
+		t.Fatalf("DeleteAccount: %v", err)
+	}
+
+	want := []struct {
+		action   models.AuditAction
+		resource string
+		actorID  string
+		sourceIP string
+	}{
+		{models.AuditActionCreate, AuditResourceServiceAccount, "usr-1", "10.0.0.1"},
+		{models.AuditActionUpdate, AuditResourceServiceAccount, "usr-1", "10.0.0.1"},
+		{models.AuditActionCreate, AuditResourceServiceAccountToken, creds.ClientID, "10.0.0.2"},
+		{models.AuditActionDelete, AuditResourceServiceAccount, "usr-1", "10.0.0.1"},
+	}
+	if len(audits.entries) != len(want) {
+		t.Fatalf("%d audit entries, want %d", len(audits.entries), len(want))
+	}
+	for i, w := range want {
+		entry := audits.entries[i]
+		if entry.Action != w.action || entry.ResourceType != w.resource || entry.ActorID != w.actorID || entry.SourceIP != w.sourceIP {
+			t.Errorf("entry %d = %s %s by %s from %s, want %s %s by %s from %s", i,
+				entry.Action, entry.ResourceType, entry.ActorID, entry.SourceIP, w.action, w.resource, w.actorID, w.sourceIP)
+		}
+	}
+	for _, entry := range audits.entries {
+		for _, snapshot := range [][]byte{entry.Before, entry.After} {
+			if containsSecret(snapshot, creds.ClientSecret, rotated.ClientSecret, token.AccessToken) {
+				t.Errorf("%s %s entry records a secret", entry.Action, entry.ResourceType)
+			}
+		}
+	}
+}
+
+func containsSecret(snapshot []byte, secrets ...string) bool {
+	for _, secret := range secrets {
+		if strings.Contains(string(snapshot), secret) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRotateSecretRevokesTokensOfTheSameSecond(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestServiceAccountService(t)
+	creds, err := s.CreateAccount(ctx, "svc-1", models.ServiceAccountRequest{Name: "reporter", Scopes: []string{"health:report:self"}}, "usr-1")
+	if err != nil {
+		t.Fatalf("CreateAccount: %v", err)
+	}
+	mint := func(creds *models.ServiceAccountCredentials) *security.Claims {
+		t.Helper()
+		token, err := s.MintToken(ctx, models.ServiceTokenRequest{ClientID: creds.ClientID, ClientSecret: creds.ClientSecret})
+		if err != nil {
+			t.Fatalf("MintToken: %v", err)
+		}
+		claims, err := s.issuer.Verify(token.AccessToken)
+		if err != nil {
+			t.Fatalf("Verify: %v", err)
+		}
+		return claims
+	}
+
+	old := mint(creds)
+	if err := s.Authorize(ctx, old); err != nil {
+		t.Fatalf("Authorize before rotation: %v", err)
+	}
+
+	// Both tokens are minted within the second of the rotation
+	rotated, err := s.RotateSecret(ctx, "svc-1", creds.ClientID)
+	if err != nil {
+		t.Fatalf("RotateSecret: %v", err)
+	}
+	if err := s.Authorize(ctx, old); !errors.Is(err, ErrServiceAccountRevoked) {
+		t.Errorf("Authorize after rotation = %v, want revoked", err)
+	}
+	if err := s.Authorize(ctx, mint(rotated)); err != nil {
+		t.Errorf("Authorize with the rotated secret: %v", err)
+	}
+}
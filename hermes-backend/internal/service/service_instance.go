@@ -0,0 +1,385 @@
+// internal/service/service_instance.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/repository"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/errors"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// ErrInstanceNotFound is returned for an unknown service instance, or one of another service
+var ErrInstanceNotFound = errors.New("service instance not found")
+
+// ErrInvalidInstanceWeight is returned for a negative instance weight
+var ErrInvalidInstanceWeight = errors.New("instance weight must not be negative")
+
+// InstanceService handles the instances services run as, and aggregates the
+// status of a service from the status of its instances
+type InstanceService struct {
+	repo        repository.ServiceInstanceRepository
+	serviceRepo repository.ServiceRepository
+	healthRepo  repository.HealthRepository
+	encryption  *EncryptionService
+	audit       *AuditService
+	log         *logger.Logger
+
+	// Serializes aggregations, so that one computed from an older view of
+	// the instances does not overwrite a newer one
+	aggregateMu sync.Mutex
+}
+
+// NewInstanceService creates a new InstanceService
+func NewInstanceService(repo repository.ServiceInstanceRepository, serviceRepo repository.ServiceRepository, healthRepo repository.HealthRepository, encryption *EncryptionService, audit *AuditService, log *logger.Logger) *InstanceService {
+	return &InstanceService{
+		repo:        repo,
+		serviceRepo: serviceRepo,
+		healthRepo:  healthRepo,
+		encryption:  encryption,
+		audit:       audit,
+		log:         log,
+	}
+}
+
+// RegisterInstance registers an instance of a service. Registering an
+// endpoint again, such as after a restart, updates its instance.
+func (s *InstanceService) RegisterInstance(ctx context.Context, serviceID string, reg models.ServiceInstanceRegistration) (*models.ServiceInstance, error) {
+	if reg.Weight < 0 {
+		return nil, ErrInvalidInstanceWeight
+	}
+	if reg.HeartbeatTTL < 0 {
+		return nil, ErrInvalidHeartbeatTTL
+	}
+	if reg.Weight == 0 {
+		reg.Weight = 1
+	}
+
+	service, err := s.serviceRepo.GetByID(ctx, serviceID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve service")
+	}
+	if service == nil {
+		return nil, ErrServiceNotFound
+	}
+	if err := s.encryption.SealMetadata(reg.Metadata); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.GetByEndpoint(ctx, serviceID, reg.Endpoint)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to check for existing instance")
+	}
+	status, err := s.initialStatus(ctx, serviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing != nil {
+		// The endpoint restarted, so the status and failures of its previous
+		// run say nothing about it
+		before, previous := auditSnapshot(existing), existing.Status
+		existing.Zone = reg.Zone
+		existing.Weight = reg.Weight
+		existing.Metadata = reg.Metadata
+		existing.HeartbeatTTL = reg.HeartbeatTTL
+		existing.Status = status
+		existing.Failures = 0
+		existing.LastSeen = time.Now()
+		if err := s.repo.Update(ctx, existing); err != nil {
+			return nil, errors.Wrap(err, "failed to update instance")
+		}
+		s.log.Info("Service instance registered again", "id", existing.ID, "service_id", serviceID, "endpoint", existing.Endpoint)
+		if previous != status {
+			s.recordHistory(ctx, existing, status, "Instance registered again")
+		}
+		s.audit.Record(ctx, models.AuditActionUpdate, AuditResourceInstance, existing.ID, before, existing)
+		s.aggregate(ctx, serviceID)
+		return existing, nil
+	}
+
+	instance := &models.ServiceInstance{
+		ID:           "ins-" + uuid.New().String()[:8],
+		ServiceID:    serviceID,
+		Endpoint:     reg.Endpoint,
+		Zone:         reg.Zone,
+		Weight:       reg.Weight,
+		Metadata:     reg.Metadata,
+		Status:       status,
+		HeartbeatTTL: reg.HeartbeatTTL,
+		LastSeen:     time.Now(),
+	}
+	if err := s.repo.Create(ctx, instance); err != nil {
+		return nil, errors.Wrap(err, "failed to create instance")
+	}
+
+	s.log.Info("Service instance registered", "id", instance.ID, "service_id", serviceID, "endpoint", instance.Endpoint)
+	s.audit.Record(ctx, models.AuditActionCreate, AuditResourceInstance, instance.ID, nil, instance)
+	s.aggregate(ctx, serviceID)
+	return instance, nil
+}
+
+// DeregisterInstance removes an instance of a service
+func (s *InstanceService) DeregisterInstance(ctx context.Context, serviceID, instanceID string) error {
+	instance, err := s.GetInstance(ctx, serviceID, instanceID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, instance.ID); err != nil {
+		return errors.Wrap(err, "failed to delete instance")
+	}
+
+	s.log.Info("Service instance deregistered", "id", instance.ID, "service_id", serviceID, "endpoint", instance.Endpoint)
+	s.audit.Record(ctx, models.AuditActionDelete, AuditResourceInstance, instance.ID, instance, nil)
+	s.aggregate(ctx, serviceID)
+	return nil
+}
+
+// initialStatus returns the status an instance of a service starts with.
+// Instances of services probed by active health checks wait for their first
+// check, the others are trusted until they report otherwise.
+func (s *InstanceService) initialStatus(ctx context.Context, serviceID string) (models.ServiceStatus, error) {
+	checks, err := s.healthRepo.GetHealthChecks(ctx, serviceID)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to retrieve health checks")
+	}
+	for _, check := range checks {
+		if check.Type == models.HealthCheckTypeActive && check.Enabled {
+			return models.ServiceStatusUnknown, nil
+		}
+	}
+	return models.ServiceStatusHealthy, nil
+}
+
+// GetInstance retrieves an instance of a service
+func (s *InstanceService) GetInstance(ctx context.Context, serviceID, instanceID string) (*models.ServiceInstance, error) {
+	instance, err := s.repo.GetByID(ctx, instanceID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve instance")
+	}
+	if instance == nil || instance.ServiceID != serviceID {
+		return nil, ErrInstanceNotFound
+	}
+	return instance, nil
+}
+
+// ListInstances retrieves the instances of a service
+func (s *InstanceService) ListInstances(ctx context.Context, serviceID string, params models.InstanceQueryParams) ([]*models.ServiceInstance, error) {
+	instances, err := s.repo.ListByService(ctx, serviceID, params)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list instances")
+	}
+	return instances, nil
+}
+
+// AttachHealthy adds the instances that should receive traffic to each service
+func (s *InstanceService) AttachHealthy(ctx context.Context, services []*models.Service) error {
+	ids := make([]string, len(services))
+	for i, service := range services {
+		ids[i] = service.ID
+	}
+	instances, err := s.repo.ListByServices(ctx, ids)
+	if err != nil {
+		return errors.Wrap(err, "failed to list instances")
+	}
+
+	healthy := make(map[string][]*models.ServiceInstance)
+	for _, instance := range instances {
+		if instance.Routable() {
+			healthy[instance.ServiceID] = append(healthy[instance.ServiceID], instance)
+		}
+	}
+	for _, service := range services {
+		service.Instances = healthy[service.ID]
+	}
+	return nil
+}
+
+// SetStatus changes the status of an instance, records the change in the
+// health history of its service and aggregates the service status again
+func (s *InstanceService) SetStatus(ctx context.Context, instance *models.ServiceInstance, status models.ServiceStatus, message string) error {
+	if instance.Status == status {
+		return nil
+	}
+	if err := s.repo.UpdateStatus(ctx, instance.ID, status); err != nil {
+		return errors.Wrap(err, "failed to update instance status")
+	}
+	s.log.Info("Service instance status changed", "id", instance.ID, "service_id", instance.ServiceID, "from", instance.Status, "to", status)
+	instance.Status = status
+	s.recordHistory(ctx, instance, status, message)
+	s.aggregate(ctx, instance.ServiceID)
+	return nil
+}
+
+// checkPassed clears the failures of an instance that passed a health check
+func (s *InstanceService) checkPassed(ctx context.Context, instance *models.ServiceInstance) error {
+	if instance.Failures > 0 {
+		if err := s.repo.ResetFailures(ctx, instance.ID); err != nil {
+			return errors.Wrap(err, "failed to reset instance health check failures")
+		}
+	}
+	return s.SetStatus(ctx, instance, models.ServiceStatusHealthy, "Instance recovered")
+}
+
+// checkFailed counts a failed health check of an instance, marking it
+// UNHEALTHY once its consecutive failures reach the check's threshold
+func (s *InstanceService) checkFailed(ctx context.Context, instance *models.ServiceInstance, check *models.HealthCheck) error {
+	failures, err := s.repo.IncrementFailures(ctx, instance.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to increment instance health check failures")
+	}
+	if failures < check.ThresholdCount {
+		return nil
+	}
+	return s.SetStatus(ctx, instance, models.ServiceStatusUnhealthy,
+		fmt.Sprintf("Health check '%s' failed %d times", check.Name, failures))
+}
+
+// recordHeartbeat marks an instance as seen now with the reported status
+func (s *InstanceService) recordHeartbeat(ctx context.Context, instance *models.ServiceInstance, status models.ServiceStatus, ttl int, message string) error {
+	if err := s.repo.RecordHeartbeat(ctx, instance.ID, status, ttl); err != nil {
+		return errors.Wrap(err, "failed to record heartbeat")
+	}
+	if instance.Status == status {
+		return nil
+	}
+	if message == "" {
+		message = "Heartbeat received"
+	}
+	instance.Status = status
+	s.recordHistory(ctx, instance, status, message)
+	s.aggregate(ctx, instance.ServiceID)
+	return nil
+}
+
+// listMissedHeartbeats retrieves the instances whose last heartbeat is older than their TTL
+func (s *InstanceService) listMissedHeartbeats(ctx context.Context, now time.Time) ([]*models.ServiceInstance, error) {
+	instances, err := s.repo.ListMissedHeartbeats(ctx, now)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list instances with missed heartbeats")
+	}
+	return instances, nil
+}
+
+// expireHeartbeat moves an instance that missed its heartbeats to status, or
+// removes it when deregister is set, unless a heartbeat arrived meanwhile
+func (s *InstanceService) expireHeartbeat(ctx context.Context, instance *models.ServiceInstance, status models.ServiceStatus, deregister bool, missed int) error {
+	if deregister {
+		deleted, err := s.repo.DeleteExpired(ctx, instance.ID, instance.LastSeen)
+		if err != nil {
+			return errors.Wrap(err, "failed to delete instance")
+		}
+		if !deleted {
+			return nil
+		}
+		s.log.Warn("Service instance deregistered after missing its heartbeats", "id", instance.ID, "service_id", instance.ServiceID, "missed", missed)
+		s.audit.Record(ctx, models.AuditActionDelete, AuditResourceInstance, instance.ID, instance, nil)
+		s.aggregate(ctx, instance.ServiceID)
+		return nil
+	}
+
+	changed, err := s.repo.ExpireHeartbeat(ctx, instance.ID, instance.LastSeen, status)
+	if err != nil {
+		return errors.Wrap(err, "failed to update instance status")
+	}
+	if !changed {
+		return nil
+	}
+	s.log.Warn("Service instance missed its heartbeats", "id", instance.ID, "service_id", instance.ServiceID, "missed", missed, "status", status)
+	instance.Status = status
+	s.recordHistory(ctx, instance, status, fmt.Sprintf("Missed %d heartbeats", missed))
+	s.aggregate(ctx, instance.ServiceID)
+	return nil
+}
+
+// aggregate sets the status of a service from the status of its instances.
+// A service whose last instance went away becomes UNKNOWN, so that discovery
+// does not keep reporting the status of instances that no longer exist.
+// Failures are logged, the instance change that triggered the aggregation has
+// been made.
+func (s *InstanceService) aggregate(ctx context.Context, serviceID string) {
+	s.aggregateMu.Lock()
+	defer s.aggregateMu.Unlock()
+
+	instances, err := s.repo.ListByService(ctx, serviceID, models.InstanceQueryParams{})
+	if err != nil {
+		s.log.Error("Failed to list instances for status aggregation", "service_id", serviceID, "error", err)
+		return
+	}
+	service, err := s.serviceRepo.GetByID(ctx, serviceID)
+	if err != nil || service == nil {
+		s.log.Error("Failed to retrieve service for status aggregation", "service_id", serviceID, "error", err)
+		return
+	}
+
+	status, message := aggregateStatus(instances)
+	if service.Status == status {
+		return
+	}
+	if err := s.healthRepo.UpdateHealthStatus(ctx, serviceID, status, message); err != nil {
+		s.log.Error("Failed to update aggregated service status", "service_id", serviceID, "error", err)
+		return
+	}
+	history := &models.HealthHistory{
+		ServiceID: serviceID,
+		Status:    status,
+		Message:   message,
+		Timestamp: time.Now(),
+	}
+	if err := s.healthRepo.RecordHealthHistory(ctx, history); err != nil {
+		s.log.Error("Failed to record health history", "service_id", serviceID, "error", err)
+	}
+	s.log.Info("Service status aggregated from its instances", "id", serviceID, "status", status)
+}
+
+// recordHistory adds an instance status change to the health history of its service
+func (s *InstanceService) recordHistory(ctx context.Context, instance *models.ServiceInstance, status models.ServiceStatus, message string) {
+	history := &models.HealthHistory{
+		ServiceID:  instance.ServiceID,
+		InstanceID: instance.ID,
+		Status:     status,
+		Message:    message,
+		Timestamp:  time.Now(),
+	}
+	if err := s.healthRepo.RecordHealthHistory(ctx, history); err != nil {
+		s.log.Error("Failed to record health history", "id", instance.ID, "error", err)
+	}
+}
+
+// aggregateStatus derives the status of a service from its instances: HEALTHY
+// when all are healthy, WARNING when only some can receive traffic, UNHEALTHY
+// or UNKNOWN when none can, and UNKNOWN when there are none
+func aggregateStatus(instances []*models.ServiceInstance) (models.ServiceStatus, string) {
+	healthy, routable, unhealthy := 0, 0, 0
+	for _, instance := range instances {
+		switch instance.Status {
+		case models.ServiceStatusHealthy:
+			healthy++
+			routable++
+		case models.ServiceStatusWarning:
+			routable++
+		case models.ServiceStatusUnhealthy:
+			unhealthy++
+		}
+	}
+
+	message := fmt.Sprintf("%d of %d instances healthy", routable, len(instances))
+	switch {
+	case len(instances) == 0:
+		return models.ServiceStatusUnknown, "No instances registered"
+	case healthy == len(instances):
+		return models.ServiceStatusHealthy, message
+	case routable > 0:
+		return models.ServiceStatusWarning, message
+	case unhealthy > 0:
+		return models.ServiceStatusUnhealthy, message
+	default:
+		return models.ServiceStatusUnknown, message
+	}
+}
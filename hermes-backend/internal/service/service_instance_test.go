@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+)
+
+func TestAggregateStatus(t *testing.T) {
+	const (
+		healthy   = models.ServiceStatusHealthy
+		warning   = models.ServiceStatusWarning
+		unhealthy = models.ServiceStatusUnhealthy
+		unknown   = models.ServiceStatusUnknown
+	)
+	tests := []struct {
+		name     string
+		statuses []models.ServiceStatus
+		want     models.ServiceStatus
+		message  string
+	}{
+		{"no instances", nil, unknown, "No instances registered"},
+		{"all healthy", []models.ServiceStatus{healthy, healthy}, healthy, "2 of 2 instances healthy"},
+		{"one warning", []models.ServiceStatus{healthy, warning}, warning, "2 of 2 instances healthy"},
+		{"only warnings", []models.ServiceStatus{warning}, warning, "1 of 1 instances healthy"},
+		{"some unhealthy", []models.ServiceStatus{healthy, unhealthy, unhealthy}, warning, "1 of 3 instances healthy"},
+		{"some unknown", []models.ServiceStatus{unknown, healthy}, warning, "1 of 2 instances healthy"},
+		{"all unhealthy", []models.ServiceStatus{unhealthy, unhealthy}, unhealthy, "0 of 2 instances healthy"},
+		{"unhealthy and unknown", []models.ServiceStatus{unknown, unhealthy}, unhealthy, "0 of 2 instances healthy"},
+		{"all unknown", []models.ServiceStatus{unknown, unknown}, unknown, "0 of 2 instances healthy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instances := make([]*models.ServiceInstance, len(tt.statuses))
+			for i, status := range tt.statuses {
+				instances[i] = &models.ServiceInstance{Status: status}
+			}
+			status, message := aggregateStatus(instances)
+			if status != tt.want || message != tt.message {
+				t.Errorf("aggregateStatus = %s %q, want %s %q", status, message, tt.want, tt.message)
+			}
+		})
+	}
+}
+
+// newTestInstanceService creates an InstanceService for the service svc-1,
+// which is probed by active health checks when activeChecks is set
+func newTestInstanceService(t *testing.T, activeChecks bool) (*InstanceService, *fakeInstanceRepo, *fakeServiceRepo, *fakeHealthRepo) {
+	t.Helper()
+	services := newFakeServiceRepo(&models.Service{ID: "svc-1", Name: "api", Status: models.ServiceStatusUnknown})
+	health := &fakeHealthRepo{services: services}
+	if activeChecks {
+		health.checks = []*models.HealthCheck{{ServiceID: "svc-1", Type: models.HealthCheckTypeActive, Enabled: true}}
+	}
+	instances := &fakeInstanceRepo{}
+	log := newTestLogger()
+	encryption := NewEncryptionService(nil, newTestSecretBox(t), nil, log)
+	s := NewInstanceService(instances, services, health, encryption, NewAuditService(&fakeAuditRepo{}, log), log)
+	return s, instances, services, health
+}
+
+func serviceStatus(t *testing.T, services *fakeServiceRepo) models.ServiceStatus {
+	t.Helper()
+	service, _ := services.GetByID(context.Background(), "svc-1")
+	return service.Status
+}
+
+func TestRegisterInstanceAggregatesServiceStatus(t *testing.T) {
+	ctx := context.Background()
+	s, _, services, _ := newTestInstanceService(t, false)
+
+	first, err := s.RegisterInstance(ctx, "svc-1", models.ServiceInstanceRegistration{Endpoint: "http://10.0.0.1:8080"})
+	if err != nil {
+		t.Fatalf("RegisterInstance: %v", err)
+	}
+	if first.Status != models.ServiceStatusHealthy || first.Weight != 1 {
+		t.Errorf("instance status %s, weight %d", first.Status, first.Weight)
+	}
+	if got := serviceStatus(t, services); got != models.ServiceStatusHealthy {
+		t.Errorf("service status = %s, want HEALTHY", got)
+	}
+
+	second, err := s.RegisterInstance(ctx, "svc-1", models.ServiceInstanceRegistration{Endpoint: "http://10.0.0.2:8080"})
+	if err != nil {
+		t.Fatalf("RegisterInstance: %v", err)
+	}
+	if err := s.SetStatus(ctx, second, models.ServiceStatusUnhealthy, "down"); err != nil {
+		t.Fatalf("SetStatus: %v", err)
+	}
+	if got := serviceStatus(t, services); got != models.ServiceStatusWarning {
+		t.Errorf("service status = %s, want WARNING", got)
+	}
+
+	if err := s.DeregisterInstance(ctx, "svc-1", first.ID); err != nil {
+		t.Fatalf("DeregisterInstance: %v", err)
+	}
+	if got := serviceStatus(t, services); got != models.ServiceStatusUnhealthy {
+		t.Errorf("service status = %s, want UNHEALTHY", got)
+	}
+
+	// The service must not keep the status of its last instance
+	if err := s.DeregisterInstance(ctx, "svc-1", second.ID); err != nil {
+		t.Fatalf("DeregisterInstance: %v", err)
+	}
+	if got := serviceStatus(t, services); got != models.ServiceStatusUnknown {
+		t.Errorf("service status = %s, want UNKNOWN once no instances are left", got)
+	}
+}
+
+func TestRegisterInstanceAgainResetsStatus(t *testing.T) {
+	tests := []struct {
+		name         string
+		activeChecks bool
+		want         models.ServiceStatus
+	}{
+		{"trusted until reported otherwise", false, models.ServiceStatusHealthy},
+		{"waits for the first active check", true, models.ServiceStatusUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s, instances, services, health := newTestInstanceService(t, tt.activeChecks)
+			reg := models.ServiceInstanceRegistration{Endpoint: "http://10.0.0.1:8080", Zone: "a"}
+
+			instance, err := s.RegisterInstance(ctx, "svc-1", reg)
+			if err != nil {
+				t.Fatalf("RegisterInstance: %v", err)
+			}
+			if err := s.SetStatus(ctx, instance, models.ServiceStatusUnhealthy, "down"); err != nil {
+				t.Fatalf("SetStatus: %v", err)
+			}
+			stored, _ := instances.GetByID(ctx, instance.ID)
+			stored.Failures = 5
+			_ = instances.Update(ctx, stored)
+			history := len(health.history)
+
+			reg.Zone = "b"
+			again, err := s.RegisterInstance(ctx, "svc-1", reg)
+			if err != nil {
+				t.Fatalf("RegisterInstance again: %v", err)
+			}
+			if again.ID != instance.ID || len(instances.instances) != 1 {
+				t.Fatalf("registering the endpoint again created another instance")
+			}
+			stored, _ = instances.GetByID(ctx, instance.ID)
+			if stored.Status != tt.want || stored.Failures != 0 || stored.Zone != "b" {
+				t.Errorf("instance status %s, failures %d, zone %s, want %s, 0, b", stored.Status, stored.Failures, stored.Zone, tt.want)
+			}
+			if got := serviceStatus(t, services); got != tt.want {
+				t.Errorf("service status = %s, want %s", got, tt.want)
+			}
+			if len(health.history) <= history {
+				t.Errorf("the status reset was not recorded in the health history")
+			}
+		})
+	}
+}
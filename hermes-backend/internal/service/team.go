@@ -0,0 +1,199 @@
+// internal/service/team.go
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/repository"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/errors"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// ErrTeamNotFound is returned when a team does not exist
+var ErrTeamNotFound = errors.New("team not found")
+
+// TeamService handles business logic for teams and their members
+type TeamService struct {
+	repo        repository.TeamRepository
+	userRepo    repository.UserRepository
+	serviceRepo repository.ServiceRepository
+	log         *logger.Logger
+}
+
+// NewTeamService creates a new TeamService
+func NewTeamService(repo repository.TeamRepository, userRepo repository.UserRepository, serviceRepo repository.ServiceRepository, log *logger.Logger) *TeamService {
+	return &TeamService{
+		repo:        repo,
+		userRepo:    userRepo,
+		serviceRepo: serviceRepo,
+		log:         log,
+	}
+}
+
+// CreateTeam creates a new team
+func (s *TeamService) CreateTeam(ctx context.Context, req models.TeamRequest) (*models.Team, error) {
+	name := strings.TrimSpace(req.Name)
+	if err := s.checkName(ctx, name); err != nil {
+		return nil, err
+	}
+
+	team := &models.Team{
+		ID:          "team-" + uuid.New().String()[:8],
+		Name:        name,
+		Description: req.Description,
+	}
+	if err := s.repo.Create(ctx, team); err != nil {
+		return nil, errors.Wrap(err, "failed to create team")
+	}
+
+	s.log.Info("Team created", "id", team.ID, "name", team.Name)
+	return team, nil
+}
+
+// GetTeam retrieves a team by ID
+func (s *TeamService) GetTeam(ctx context.Context, id string) (*models.Team, error) {
+	team, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve team")
+	}
+	if team == nil {
+		return nil, ErrTeamNotFound
+	}
+	return team, nil
+}
+
+// ListTeams lists all teams
+func (s *TeamService) ListTeams(ctx context.Context) ([]*models.Team, error) {
+	teams, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list teams")
+	}
+	return teams, nil
+}
+
+// UpdateTeam renames a team or changes its description
+func (s *TeamService) UpdateTeam(ctx context.Context, id string, req models.TeamUpdateRequest) (*models.Team, error) {
+	team, err := s.GetTeam(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name != team.Name {
+			if err := s.checkName(ctx, name); err != nil {
+				return nil, err
+			}
+			team.Name = name
+		}
+	}
+	if req.Description != nil {
+		team.Description = *req.Description
+	}
+
+	if err := s.repo.Update(ctx, team); err != nil {
+		return nil, errors.Wrap(err, "failed to update team")
+	}
+
+	s.log.Info("Team updated", "id", team.ID, "name", team.Name)
+	return team, nil
+}
+
+// DeleteTeam deletes a team that owns no services
+func (s *TeamService) DeleteTeam(ctx context.Context, id string) error {
+	if _, err := s.GetTeam(ctx, id); err != nil {
+		return err
+	}
+
+	_, owned, err := s.serviceRepo.List(ctx, models.ServiceQueryParams{Owner: id, AllVisible: true, Limit: 1})
+	if err != nil {
+		return errors.Wrap(err, "failed to check owned services")
+	}
+	if owned > 0 {
+		return errors.New("team still owns services, transfer or delete them first")
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return errors.Wrap(err, "failed to delete team")
+	}
+
+	s.log.Info("Team deleted", "id", id)
+	return nil
+}
+
+// AddMember adds a user to a team
+func (s *TeamService) AddMember(ctx context.Context, teamID, userID string) (*models.TeamMember, error) {
+	if _, err := s.GetTeam(ctx, teamID); err != nil {
+		return nil, err
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve user")
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	member := &models.TeamMember{TeamID: teamID, UserID: userID}
+	if err := s.repo.AddMember(ctx, member); err != nil {
+		return nil, errors.Wrap(err, "failed to add team member")
+	}
+
+	s.log.Info("Team member added", "team_id", teamID, "user_id", userID)
+	return member, nil
+}
+
+// RemoveMember removes a user from a team
+func (s *TeamService) RemoveMember(ctx context.Context, teamID, userID string) error {
+	if _, err := s.GetTeam(ctx, teamID); err != nil {
+		return err
+	}
+
+	if err := s.repo.RemoveMember(ctx, teamID, userID); err != nil {
+		return errors.Wrap(err, "failed to remove team member")
+	}
+
+	s.log.Info("Team member removed", "team_id", teamID, "user_id", userID)
+	return nil
+}
+
+// ListMembers lists the members of a team
+func (s *TeamService) ListMembers(ctx context.Context, teamID string) ([]*models.TeamMember, error) {
+	if _, err := s.GetTeam(ctx, teamID); err != nil {
+		return nil, err
+	}
+
+	members, err := s.repo.ListMembers(ctx, teamID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list team members")
+	}
+	return members, nil
+}
+
+// TeamIDsForUser returns the IDs of the teams a user belongs to
+func (s *TeamService) TeamIDsForUser(ctx context.Context, userID string) ([]string, error) {
+	ids, err := s.repo.ListTeamIDsForUser(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list user teams")
+	}
+	return ids, nil
+}
+
+// checkName validates a team name and checks that it is not taken
+func (s *TeamService) checkName(ctx context.Context, name string) error {
+	if name == "" {
+		return errors.New("team name is required")
+	}
+
+	existing, err := s.repo.GetByName(ctx, name)
+	if err != nil {
+		return errors.Wrap(err, "failed to check team name")
+	}
+	if existing != nil {
+		return errors.New("team with this name already exists")
+	}
+	return nil
+}
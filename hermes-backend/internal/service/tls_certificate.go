@@ -0,0 +1,279 @@
+// internal/service/tls_certificate.go
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"strings"
+	"time"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/repository"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/security"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/errors"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// ErrTLSCertificateNotFound is returned when a TLS certificate does not exist
+var ErrTLSCertificateNotFound = errors.New("TLS certificate not found")
+
+// ErrTLSNotConfigured is returned when no encryption key is configured for private keys
+var ErrTLSNotConfigured = errors.New("TLS encryption key is not configured")
+
+// TLSCertificateService manages the certificates used to terminate TLS
+type TLSCertificateService struct {
+	repo     repository.TLSCertificateRepository
+	box      *security.SecretBox
+	store    *security.CertificateStore
+	fallback string
+	log      *logger.Logger
+}
+
+// NewTLSCertificateService creates a new TLSCertificateService. fallback names
+// the certificate served to clients that send no SNI host name. box may be nil
+// when no encryption key is configured, in which case certificates cannot be managed.
+func NewTLSCertificateService(repo repository.TLSCertificateRepository, box *security.SecretBox, store *security.CertificateStore, fallback string, log *logger.Logger) *TLSCertificateService {
+	return &TLSCertificateService{
+		repo:     repo,
+		box:      box,
+		store:    store,
+		fallback: fallback,
+		log:      log,
+	}
+}
+
+// CreateCertificate stores a new certificate and starts serving it
+func (s *TLSCertificateService) CreateCertificate(ctx context.Context, req models.TLSCertificateRequest) (*models.TLSCertificate, error) {
+	existing, err := s.repo.GetByName(ctx, req.Name)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to check certificate name")
+	}
+	if existing != nil {
+		return nil, errors.New("certificate with this name already exists")
+	}
+
+	cert := &models.TLSCertificate{
+		ID:   "cert-" + uuid.New().String()[:8],
+		Name: req.Name,
+	}
+	if err := s.apply(cert, req.Certificate, req.PrivateKey, req.Hostnames); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, cert); err != nil {
+		return nil, errors.Wrap(err, "failed to create certificate")
+	}
+
+	s.log.Info("TLS certificate created", "id", cert.ID, "name", cert.Name, "hostnames", cert.Hostnames, "not_after", cert.NotAfter)
+	s.reloadAfterChange(ctx)
+	return cert, nil
+}
+
+// GetCertificate retrieves a certificate by ID
+func (s *TLSCertificateService) GetCertificate(ctx context.Context, id string) (*models.TLSCertificate, error) {
+	cert, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve certificate")
+	}
+	if cert == nil {
+		return nil, ErrTLSCertificateNotFound
+	}
+	return cert, nil
+}
+
+// ListCertificates lists all certificates
+func (s *TLSCertificateService) ListCertificates(ctx context.Context) ([]*models.TLSCertificate, error) {
+	certs, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list certificates")
+	}
+	return certs, nil
+}
+
+// UpdateCertificate replaces a certificate, its key or its host names
+func (s *TLSCertificateService) UpdateCertificate(ctx context.Context, id string, req models.TLSCertificateUpdateRequest) (*models.TLSCertificate, error) {
+	cert, err := s.GetCertificate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	certPEM := cert.Certificate
+	if req.Certificate != nil {
+		certPEM = *req.Certificate
+	}
+	var keyPEM string
+	if req.PrivateKey != nil {
+		keyPEM = *req.PrivateKey
+	} else {
+		if s.box == nil {
+			return nil, ErrTLSNotConfigured
+		}
+		key, err := s.box.Open(cert.EncryptedKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decrypt private key")
+		}
+		keyPEM = string(key)
+	}
+	hostnames := req.Hostnames
+	if hostnames == nil && req.Certificate == nil {
+		hostnames = cert.Hostnames
+	}
+
+	if err := s.apply(cert, certPEM, keyPEM, hostnames); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, cert); err != nil {
+		return nil, errors.Wrap(err, "failed to update certificate")
+	}
+
+	s.log.Info("TLS certificate updated", "id", cert.ID, "name", cert.Name, "hostnames", cert.Hostnames, "not_after", cert.NotAfter)
+	s.reloadAfterChange(ctx)
+	return cert, nil
+}
+
+// DeleteCertificate deletes a certificate and stops serving it
+func (s *TLSCertificateService) DeleteCertificate(ctx context.Context, id string) error {
+	if _, err := s.GetCertificate(ctx, id); err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return errors.Wrap(err, "failed to delete certificate")
+	}
+
+	s.log.Info("TLS certificate deleted", "id", id)
+	s.reloadAfterChange(ctx)
+	return nil
+}
+
+// Reload loads all certificates from the database into the certificate store.
+// Certificates that cannot be decrypted or parsed are skipped.
+func (s *TLSCertificateService) Reload(ctx context.Context) error {
+	certs, err := s.repo.List(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to list certificates")
+	}
+
+	hostnames := make(map[string]*tls.Certificate)
+	var fallback *tls.Certificate
+	for _, cert := range certs {
+		pair, err := s.keyPair(cert)
+		if err != nil {
+			s.log.Error("Skipping TLS certificate", "id", cert.ID, "name", cert.Name, "error", err)
+			continue
+		}
+
+		for _, name := range cert.Hostnames {
+			// Prefer the certificate that stays valid the longest when names overlap
+			if current, ok := hostnames[name]; ok && current.Leaf.NotAfter.After(pair.Leaf.NotAfter) {
+				continue
+			}
+			hostnames[name] = pair
+		}
+		if cert.Name == s.fallback {
+			fallback = pair
+		}
+	}
+
+	s.store.Update(hostnames, fallback)
+	s.log.Debug("Reloaded TLS certificates", "certificates", len(certs), "hostnames", len(hostnames))
+	return nil
+}
+
+// ExpiringCertificates returns the certificates that expire within the given duration
+func (s *TLSCertificateService) ExpiringCertificates(ctx context.Context, within time.Duration) ([]*models.TLSCertificate, error) {
+	certs, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list certificates")
+	}
+
+	deadline := time.Now().Add(within)
+	var expiring []*models.TLSCertificate
+	for _, cert := range certs {
+		if cert.NotAfter.Before(deadline) {
+			expiring = append(expiring, cert)
+		}
+	}
+	return expiring, nil
+}
+
+// apply validates a certificate and key pair and copies it into cert.
+// Host names default to the names the certificate is valid for.
+func (s *TLSCertificateService) apply(cert *models.TLSCertificate, certPEM, keyPEM string, hostnames []string) error {
+	if s.box == nil {
+		return ErrTLSNotConfigured
+	}
+
+	pair, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+	if err != nil {
+		return errors.New("invalid certificate or private key: " + err.Error())
+	}
+	leaf, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return errors.New("invalid certificate: " + err.Error())
+	}
+	if time.Now().After(leaf.NotAfter) {
+		return errors.New("certificate has expired")
+	}
+
+	if len(hostnames) == 0 {
+		hostnames = leaf.DNSNames
+	}
+	if len(hostnames) == 0 && leaf.Subject.CommonName != "" {
+		hostnames = []string{leaf.Subject.CommonName}
+	}
+	if len(hostnames) == 0 {
+		return errors.New("certificate has no host names")
+	}
+	for i, name := range hostnames {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if err := leaf.VerifyHostname(strings.Replace(name, "*", "wildcard", 1)); err != nil {
+			return errors.New("certificate is not valid for host " + name)
+		}
+		hostnames[i] = name
+	}
+
+	sealed, err := s.box.Seal([]byte(keyPEM))
+	if err != nil {
+		return errors.Wrap(err, "failed to encrypt private key")
+	}
+
+	cert.Certificate = certPEM
+	cert.EncryptedKey = sealed
+	cert.Hostnames = hostnames
+	cert.Issuer = leaf.Issuer.String()
+	cert.NotBefore = leaf.NotBefore
+	cert.NotAfter = leaf.NotAfter
+	return nil
+}
+
+// keyPair decrypts a stored certificate into a servable key pair
+func (s *TLSCertificateService) keyPair(cert *models.TLSCertificate) (*tls.Certificate, error) {
+	if s.box == nil {
+		return nil, ErrTLSNotConfigured
+	}
+	key, err := s.box.Open(cert.EncryptedKey)
+	if err != nil {
+		return nil, err
+	}
+	pair, err := tls.X509KeyPair([]byte(cert.Certificate), key)
+	if err != nil {
+		return nil, err
+	}
+	if pair.Leaf == nil {
+		if pair.Leaf, err = x509.ParseCertificate(pair.Certificate[0]); err != nil {
+			return nil, err
+		}
+	}
+	return &pair, nil
+}
+
+// reloadAfterChange serves the certificates right away instead of waiting for the next reload
+func (s *TLSCertificateService) reloadAfterChange(ctx context.Context) {
+	if err := s.Reload(ctx); err != nil {
+		s.log.Error("Failed to reload TLS certificates", "error", err)
+	}
+}
@@ -0,0 +1,460 @@
+// internal/service/token.go
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/repository"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/security"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/errors"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidRefreshToken is returned when a refresh token is unknown, expired or revoked
+var ErrInvalidRefreshToken = errors.New("invalid refresh token")
+
+// ErrSessionNotFound is returned when a session does not exist or belongs to another user
+var ErrSessionNotFound = errors.New("session not found")
+
+// TokenConfig configures token lifetimes and signing key rotation
+type TokenConfig struct {
+	Algorithm       string        // EdDSA or RS256
+	AccessTokenTTL  time.Duration // Lifetime of access tokens
+	RefreshTokenTTL time.Duration // Lifetime of refresh tokens
+	KeyRotation     time.Duration // How long a signing key signs tokens before a new one takes over
+}
+
+// TokenService issues, refreshes and revokes tokens and manages signing keys
+type TokenService struct {
+	repo     repository.TokenRepository
+	userRepo repository.UserRepository
+	box      *security.SecretBox
+	keys     *security.KeySet
+	revoked  *security.RevocationList
+	issuer   *security.TokenIssuer
+	config   TokenConfig
+	log      *logger.Logger
+}
+
+// NewTokenService creates a new TokenService
+func NewTokenService(repo repository.TokenRepository, userRepo repository.UserRepository, box *security.SecretBox, keys *security.KeySet, revoked *security.RevocationList, issuer *security.TokenIssuer, config TokenConfig, log *logger.Logger) *TokenService {
+	return &TokenService{
+		repo:     repo,
+		userRepo: userRepo,
+		box:      box,
+		keys:     keys,
+		revoked:  revoked,
+		issuer:   issuer,
+		config:   config,
+		log:      log,
+	}
+}
+
+// IssueTokens starts a session for a user and issues its access token and
+// first refresh token. The session ID doubles as the refresh token family.
+func (s *TokenService) IssueTokens(ctx context.Context, user *models.User, meta models.SessionMetadata) (*models.TokenResponse, error) {
+	now := time.Now()
+	session := &models.Session{
+		ID:         "ses-" + uuid.New().String()[:8],
+		UserID:     user.ID,
+		Method:     meta.Method,
+		MFA:        meta.MFA,
+		IPAddress:  meta.IPAddress,
+		UserAgent:  meta.UserAgent,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(s.config.RefreshTokenTTL),
+	}
+	if err := s.repo.CreateSession(ctx, session); err != nil {
+		return nil, errors.Wrap(err, "failed to create session")
+	}
+	resp, _, err := s.issue(ctx, user, session.ID, session.MFA)
+	return resp, err
+}
+
+// Refresh exchanges a refresh token for a new access and refresh token.
+// Presenting a token that was already rotated revokes its whole family, since
+// it means the token was copied.
+func (s *TokenService) Refresh(ctx context.Context, refreshToken string) (*models.TokenResponse, error) {
+	token, err := s.repo.GetRefreshTokenByHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve refresh token")
+	}
+	if token == nil || time.Now().After(token.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if token.RevokedAt != nil {
+		s.revokeFamily(ctx, token, "refresh token reused")
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// Families of tokens issued before sessions existed have no session
+	session, err := s.repo.GetSession(ctx, token.FamilyID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve session")
+	}
+	if session != nil && session.RevokedAt != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := s.userRepo.GetByID(ctx, token.UserID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve user")
+	}
+	if user == nil || !user.Active {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	resp, replacement, err := s.issue(ctx, user, token.FamilyID, session != nil && session.MFA)
+	if err != nil {
+		return nil, err
+	}
+
+	rotated, err := s.repo.RotateRefreshToken(ctx, token.ID, replacement.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to rotate refresh token")
+	}
+	if !rotated {
+		// Another request used the same token at the same time
+		s.revokeFamily(ctx, token, "refresh token reused")
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if session != nil {
+		if err := s.repo.TouchSession(ctx, session.ID, time.Now(), resp.RefreshExpiresAt); err != nil {
+			s.log.Error("Failed to record session use", "session_id", session.ID, "error", err)
+		}
+	}
+	return resp, nil
+}
+
+// Logout revokes the access token described by claims and its session, or
+// for tokens without a session the refresh token family of refreshToken
+func (s *TokenService) Logout(ctx context.Context, claims *security.Claims, refreshToken string) error {
+	if err := s.RevokeAccessToken(ctx, claims.ID, claims.Subject, claims.ExpiresAt.Time, "logout"); err != nil {
+		return err
+	}
+	if claims.SessionID != "" {
+		return s.revokeSession(ctx, claims.SessionID, claims.Subject, "logout")
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+	token, err := s.repo.GetRefreshTokenByHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		return errors.Wrap(err, "failed to retrieve refresh token")
+	}
+	if token != nil && token.UserID == claims.Subject {
+		if err := s.repo.RevokeRefreshTokenFamily(ctx, token.FamilyID); err != nil {
+			return errors.Wrap(err, "failed to revoke refresh token")
+		}
+	}
+	return nil
+}
+
+// RevokeAccessToken puts an access token on the revocation list until it expires
+func (s *TokenService) RevokeAccessToken(ctx context.Context, tokenID, userID string, expiresAt time.Time, reason string) error {
+	if tokenID == "" {
+		return errors.New("token ID is required")
+	}
+	if expiresAt.IsZero() {
+		// Unknown expiry, keep the entry for as long as any access token can live
+		expiresAt = time.Now().Add(s.config.AccessTokenTTL)
+	}
+
+	if err := s.addRevocation(ctx, tokenID, userID, expiresAt, reason); err != nil {
+		return errors.Wrap(err, "failed to revoke token")
+	}
+
+	s.log.Info("Access token revoked", "jti", tokenID, "user_id", userID, "reason", reason)
+	return nil
+}
+
+// RevokeUserTokens revokes every session and refresh token of a user but the
+// session keepID, if any, logging them out everywhere else at once
+func (s *TokenService) RevokeUserTokens(ctx context.Context, userID, keepID, reason string) error {
+	sessions, err := s.repo.RevokeUserSessions(ctx, userID, keepID)
+	if err != nil {
+		return errors.Wrap(err, "failed to revoke sessions")
+	}
+	expiresAt := time.Now().Add(s.config.AccessTokenTTL)
+	for _, id := range sessions {
+		if err := s.addRevocation(ctx, id, userID, expiresAt, reason); err != nil {
+			return errors.Wrap(err, "failed to revoke session")
+		}
+	}
+
+	s.log.Info("Sessions revoked", "user_id", userID, "sessions", len(sessions), "reason", reason)
+	return nil
+}
+
+// ListSessions returns the active sessions of a user, marking the one with
+// the ID currentID
+func (s *TokenService) ListSessions(ctx context.Context, userID, currentID string) ([]*models.Session, error) {
+	sessions, err := s.repo.ListUserSessions(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list sessions")
+	}
+	for _, session := range sessions {
+		session.Current = session.ID == currentID
+	}
+	return sessions, nil
+}
+
+// RevokeSession ends one of a user's sessions
+func (s *TokenService) RevokeSession(ctx context.Context, userID, sessionID, reason string) error {
+	session, err := s.repo.GetSession(ctx, sessionID)
+	if err != nil {
+		return errors.Wrap(err, "failed to retrieve session")
+	}
+	if session == nil || session.UserID != userID {
+		return ErrSessionNotFound
+	}
+	if session.RevokedAt != nil {
+		return nil
+	}
+	return s.revokeSession(ctx, session.ID, userID, reason)
+}
+
+// Revoke revokes an access token by its ID, all refresh tokens of a user, or both
+func (s *TokenService) Revoke(ctx context.Context, req models.TokenRevocationRequest) error {
+	if req.TokenID == "" && req.UserID == "" {
+		return errors.New("token_id or user_id is required")
+	}
+	reason := req.Reason
+	if reason == "" {
+		reason = "revoked by administrator"
+	}
+
+	if req.TokenID != "" {
+		if err := s.RevokeAccessToken(ctx, req.TokenID, req.UserID, req.ExpiresAt, reason); err != nil {
+			return err
+		}
+	}
+	if req.UserID != "" {
+		return s.RevokeUserTokens(ctx, req.UserID, "", reason)
+	}
+	return nil
+}
+
+// JWKS returns the public signing keys for downstream token verification
+func (s *TokenService) JWKS() security.JWKS {
+	return s.keys.JWKS()
+}
+
+// RotateKeys retires the current signing key and starts signing with a new one.
+// The old key verifies tokens for one more access token lifetime, so tokens
+// it signed are rejected once they should have expired.
+func (s *TokenService) RotateKeys(ctx context.Context) error {
+	now := time.Now()
+	if err := s.repo.RetireSigningKeys(ctx, now, now.Add(s.config.AccessTokenTTL)); err != nil {
+		return errors.Wrap(err, "failed to retire signing keys")
+	}
+	return s.Sync(ctx)
+}
+
+// Sync loads signing keys and revocations from the database, generating a
+// new signing key when the current one is due for rotation
+func (s *TokenService) Sync(ctx context.Context) error {
+	if err := s.syncKeys(ctx); err != nil {
+		return err
+	}
+	return s.syncRevocations(ctx)
+}
+
+// Cleanup deletes expired keys, refresh tokens, revocations, sessions and MFA challenges
+func (s *TokenService) Cleanup(ctx context.Context) error {
+	if err := s.repo.DeleteExpiredSigningKeys(ctx); err != nil {
+		return errors.Wrap(err, "failed to delete expired signing keys")
+	}
+	if err := s.repo.DeleteExpiredRefreshTokens(ctx); err != nil {
+		return errors.Wrap(err, "failed to delete expired refresh tokens")
+	}
+	if err := s.repo.DeleteExpiredRevokedTokens(ctx); err != nil {
+		return errors.Wrap(err, "failed to delete expired revocations")
+	}
+	if err := s.repo.DeleteExpiredSessions(ctx); err != nil {
+		return errors.Wrap(err, "failed to delete expired sessions")
+	}
+	if err := s.repo.DeleteExpiredMFAChallenges(ctx); err != nil {
+		return errors.Wrap(err, "failed to delete expired MFA challenges")
+	}
+	return nil
+}
+
+func (s *TokenService) syncKeys(ctx context.Context) error {
+	stored, err := s.repo.ListSigningKeys(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to list signing keys")
+	}
+
+	now := time.Now()
+	keys := make([]*security.SigningKey, 0, len(stored)+1)
+	signing := false
+	for _, k := range stored {
+		private, err := s.box.Open(k.EncryptedKey)
+		if err != nil {
+			s.log.Error("Skipping signing key", "kid", k.ID, "error", err)
+			continue
+		}
+		key, err := security.ParseSigningKey(k.ID, k.Algorithm, private, k.RetiresAt, k.ExpiresAt)
+		if err != nil {
+			s.log.Error("Skipping signing key", "kid", k.ID, "error", err)
+			continue
+		}
+		keys = append(keys, key)
+		if k.Algorithm == s.config.Algorithm && now.Before(k.RetiresAt) {
+			signing = true
+		}
+	}
+
+	if !signing {
+		key, err := s.createKey(ctx, now)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, key)
+	}
+
+	s.keys.Replace(keys)
+	return nil
+}
+
+// createKey generates and stores a signing key
+func (s *TokenService) createKey(ctx context.Context, now time.Time) (*security.SigningKey, error) {
+	retiresAt := now.Add(s.config.KeyRotation)
+	key, err := security.GenerateSigningKey(s.config.Algorithm, retiresAt, retiresAt.Add(s.config.AccessTokenTTL))
+	if err != nil {
+		return nil, err
+	}
+
+	private, err := key.MarshalPrivateKey()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode signing key")
+	}
+	sealed, err := s.box.Seal(private)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encrypt signing key")
+	}
+
+	err = s.repo.CreateSigningKey(ctx, &models.SigningKey{
+		ID:           key.ID,
+		Algorithm:    key.Algorithm,
+		EncryptedKey: sealed,
+		RetiresAt:    key.RetiresAt,
+		ExpiresAt:    key.ExpiresAt,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to store signing key")
+	}
+
+	s.log.Info("Generated signing key", "kid", key.ID, "algorithm", key.Algorithm, "retires_at", key.RetiresAt)
+	return key, nil
+}
+
+func (s *TokenService) syncRevocations(ctx context.Context) error {
+	revoked, err := s.repo.ListRevokedTokens(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to list revoked tokens")
+	}
+
+	entries := make(map[string]time.Time, len(revoked))
+	for _, token := range revoked {
+		entries[token.ID] = token.ExpiresAt
+	}
+	s.revoked.Replace(entries)
+	return nil
+}
+
+// issue creates an access token for a session and a refresh token in the
+// session's family, returning the stored refresh token along with them
+func (s *TokenService) issue(ctx context.Context, user *models.User, familyID string, mfa bool) (*models.TokenResponse, *models.RefreshToken, error) {
+	accessToken, claims, err := s.issuer.Issue(user.ID, user.Username, user.Email, string(user.Role), familyID, mfa)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to issue access token")
+	}
+
+	refreshToken, err := newOpaqueToken()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to generate refresh token")
+	}
+	refresh := &models.RefreshToken{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: time.Now().Add(s.config.RefreshTokenTTL),
+	}
+	if err := s.repo.CreateRefreshToken(ctx, refresh); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to store refresh token")
+	}
+
+	return &models.TokenResponse{
+		AccessToken:      accessToken,
+		TokenType:        "Bearer",
+		ExpiresAt:        claims.ExpiresAt.Time,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: refresh.ExpiresAt,
+		User:             *user,
+	}, refresh, nil
+}
+
+// revokeSession revokes a session's refresh tokens and, until they expire,
+// its access tokens
+func (s *TokenService) revokeSession(ctx context.Context, sessionID, userID, reason string) error {
+	if err := s.repo.RevokeSession(ctx, sessionID); err != nil {
+		return errors.Wrap(err, "failed to revoke session")
+	}
+	if err := s.addRevocation(ctx, sessionID, userID, time.Now().Add(s.config.AccessTokenTTL), reason); err != nil {
+		return errors.Wrap(err, "failed to revoke session")
+	}
+
+	s.log.Info("Session revoked", "session_id", sessionID, "user_id", userID, "reason", reason)
+	return nil
+}
+
+// addRevocation puts a token or session ID on the revocation list until expiresAt
+func (s *TokenService) addRevocation(ctx context.Context, id, userID string, expiresAt time.Time, reason string) error {
+	revoked := &models.RevokedToken{
+		ID:        id,
+		UserID:    userID,
+		Reason:    reason,
+		ExpiresAt: expiresAt,
+	}
+	if err := s.repo.CreateRevokedToken(ctx, revoked); err != nil {
+		return err
+	}
+	s.revoked.Add(id, expiresAt)
+	return nil
+}
+
+// revokeFamily revokes a refresh token family after suspected token theft
+func (s *TokenService) revokeFamily(ctx context.Context, token *models.RefreshToken, reason string) {
+	if err := s.repo.RevokeRefreshTokenFamily(ctx, token.FamilyID); err != nil {
+		s.log.Error("Failed to revoke refresh token family", "family_id", token.FamilyID, "error", err)
+		return
+	}
+	s.log.Warn("Refresh token family revoked", "family_id", token.FamilyID, "user_id", token.UserID, "reason", reason)
+}
+
+// newOpaqueToken returns a random opaque token, such as a refresh token
+func newOpaqueToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken returns the value stored for an opaque token
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+)
+
+func TestRotateKeysStopsVerifyingAfterAnAccessTokenLifetime(t *testing.T) {
+	ctx := context.Background()
+	s, repo := newTestTokenService(t, newFakeUserRepo())
+	s.config.AccessTokenTTL = 100 * time.Millisecond
+	if err := s.Sync(ctx); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	// The issuer's own lifetime outlasts the window, as for a token signed
+	// with a leaked key
+	token, _, err := s.issuer.Issue("usr-1", "alice", "", "USER", "", false)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	if err := s.RotateKeys(ctx); err != nil {
+		t.Fatalf("RotateKeys: %v", err)
+	}
+	if len(repo.keys) != 2 {
+		t.Fatalf("%d signing keys after rotation, want 2", len(repo.keys))
+	}
+	if _, err := s.issuer.Verify(token); err != nil {
+		t.Fatalf("Verify right after rotation: %v", err)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	if _, err := s.issuer.Verify(token); err == nil {
+		t.Error("a token signed with the retired key verified after an access token lifetime")
+	}
+	if err := s.Sync(ctx); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if keys := s.JWKS().Keys; len(keys) != 1 {
+		t.Errorf("%d published keys, want only the new one", len(keys))
+	}
+}
+
+func TestRefreshRecordsTheReplacementTokenID(t *testing.T) {
+	ctx := context.Background()
+	user := &models.User{ID: "usr-1", Username: "alice", Role: models.RoleUser, Active: true}
+	s, repo := newTestTokenService(t, newFakeUserRepo(user))
+
+	login, err := s.IssueTokens(ctx, user, models.SessionMetadata{})
+	if err != nil {
+		t.Fatalf("IssueTokens: %v", err)
+	}
+	if _, err := s.Refresh(ctx, login.RefreshToken); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if len(repo.refresh) != 2 {
+		t.Fatalf("%d refresh tokens, want 2", len(repo.refresh))
+	}
+	if got, want := repo.refresh[0].ReplacedBy, repo.refresh[1].ID; got != want {
+		t.Errorf("replaced_by = %q, want the ID of the new token %q", got, want)
+	}
+}
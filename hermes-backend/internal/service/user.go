@@ -0,0 +1,347 @@
+// internal/service/user.go
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/repository"
+	"github.com/amaydixit11/hermes/hermes-backend/internal/security"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/errors"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// ErrUserNotFound is returned when a user does not exist
+var ErrUserNotFound = errors.New("user not found")
+
+// ErrInvalidCredentials is returned when a login or password check fails.
+// It does not reveal whether the username exists.
+var ErrInvalidCredentials = errors.New("invalid username or password")
+
+// ErrAccountLocked is returned when logging in to an account locked after
+// repeated failed logins
+var ErrAccountLocked = errors.New("account is temporarily locked")
+
+// LockoutPolicy locks accounts after repeated failed logins, counting wrong
+// passwords and wrong second factors alike
+type LockoutPolicy struct {
+	MaxAttempts int           // Failed logins before the account is locked, zero disables lockout
+	Duration    time.Duration // How long the account stays locked
+}
+
+// UserService handles business logic for user accounts
+type UserService struct {
+	repo    repository.UserRepository
+	tokens  *TokenService
+	mfa     *MFAService
+	lockout LockoutPolicy
+	audit   *AuditService
+	log     *logger.Logger
+}
+
+// NewUserService creates a new UserService
+func NewUserService(repo repository.UserRepository, tokens *TokenService, mfa *MFAService, lockout LockoutPolicy, audit *AuditService, log *logger.Logger) *UserService {
+	return &UserService{
+		repo:    repo,
+		tokens:  tokens,
+		mfa:     mfa,
+		lockout: lockout,
+		audit:   audit,
+		log:     log,
+	}
+}
+
+// Register creates a new user account with the read-only GUEST role.
+// Administrators grant further permissions by changing the role.
+func (s *UserService) Register(ctx context.Context, req models.UserRegistration) (*models.User, error) {
+	return s.create(ctx, req, models.RoleGuest)
+}
+
+// BootstrapAdmin creates the first administrator when no users exist yet.
+// It does nothing once any account has been created.
+func (s *UserService) BootstrapAdmin(ctx context.Context, req models.UserRegistration) error {
+	count, err := s.repo.Count(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to count users")
+	}
+	if count > 0 {
+		return nil
+	}
+	if req.Username == "" || req.Email == "" || len(req.Password) < 8 {
+		return errors.New("bootstrap admin requires a username, an email and a password of at least 8 characters")
+	}
+
+	user, err := s.create(ctx, req, models.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	s.log.Info("Bootstrapped first admin user", "id", user.ID, "username", user.Username)
+	return nil
+}
+
+// Login checks a user's credentials and issues an access and refresh token.
+// Users with MFA get a challenge instead, completed by CompleteMFALogin.
+func (s *UserService) Login(ctx context.Context, req models.UserLogin, meta models.SessionMetadata) (*models.TokenResponse, *models.MFAChallengeResponse, error) {
+	user, err := s.repo.GetByUsername(ctx, req.Username)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to retrieve user")
+	}
+
+	var hash string
+	if user != nil {
+		hash = user.PasswordHash
+	}
+	valid := security.CheckPassword(hash, req.Password)
+
+	// Locked accounts fail like a wrong password, so that neither whether an
+	// account is locked nor whether a guessed password is right is revealed
+	if user != nil && accountLocked(user) {
+		s.log.Warn("Login attempt on locked account", "username", req.Username)
+		return nil, nil, ErrInvalidCredentials
+	}
+	if !valid || !user.Active {
+		s.log.Warn("Failed login attempt", "username", req.Username)
+		if user != nil {
+			s.recordFailedLogin(ctx, user)
+		}
+		return nil, nil, ErrInvalidCredentials
+	}
+
+	if user.MFAEnabled {
+		challenge, err := s.mfa.Challenge(ctx, user, models.SessionMethodPassword)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, challenge, nil
+	}
+
+	meta.Method = models.SessionMethodPassword
+	resp, err := s.completeLogin(ctx, user, meta)
+	return resp, nil, err
+}
+
+// CompleteMFALogin finishes a login held for its second factor and issues
+// an access and refresh token
+func (s *UserService) CompleteMFALogin(ctx context.Context, req models.MFALoginRequest, meta models.SessionMetadata) (*models.TokenResponse, error) {
+	challenge, err := s.mfa.GetChallenge(ctx, req.MFAToken)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.repo.GetByID(ctx, challenge.UserID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve user")
+	}
+	if user == nil || !user.Active || !user.MFAEnabled {
+		return nil, ErrInvalidMFAToken
+	}
+	if accountLocked(user) {
+		return nil, ErrAccountLocked
+	}
+
+	ok, err := s.mfa.Verify(ctx, user, models.MFACodeRequest{Code: req.Code, RecoveryCode: req.RecoveryCode})
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		s.log.Warn("Failed MFA attempt", "id", user.ID, "username", user.Username)
+		s.mfa.FailChallenge(ctx, challenge)
+		s.recordFailedLogin(ctx, user)
+		return nil, ErrInvalidMFACode
+	}
+
+	consumed, err := s.mfa.ConsumeChallenge(ctx, challenge)
+	if err != nil {
+		return nil, err
+	}
+	if !consumed {
+		return nil, ErrInvalidMFAToken
+	}
+
+	meta.Method = challenge.Method
+	meta.MFA = true
+	return s.completeLogin(ctx, user, meta)
+}
+
+// GetUser retrieves a user by ID
+func (s *UserService) GetUser(ctx context.Context, id string) (*models.User, error) {
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve user")
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+// ChangePassword replaces a user's password after verifying the current one,
+// logging the user out of every session but sessionID
+func (s *UserService) ChangePassword(ctx context.Context, id, sessionID string, req models.UserPasswordChange) error {
+	user, err := s.GetUser(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if !security.CheckPassword(user.PasswordHash, req.CurrentPassword) {
+		return ErrInvalidCredentials
+	}
+	if req.NewPassword == req.CurrentPassword {
+		return errors.New("new password must differ from the current password")
+	}
+
+	hash, err := security.HashPassword(req.NewPassword)
+	if err != nil {
+		return errors.Wrap(err, "failed to hash password")
+	}
+	before := auditSnapshot(user)
+	user.PasswordHash = hash
+
+	if err := s.repo.Update(ctx, user); err != nil {
+		return errors.Wrap(err, "failed to update password")
+	}
+	if err := s.tokens.RevokeUserTokens(ctx, user.ID, sessionID, "password changed"); err != nil {
+		return err
+	}
+
+	s.log.Info("User changed password", "id", user.ID, "username", user.Username)
+	s.audit.Record(ctx, models.AuditActionUpdate, AuditResourceUser, user.ID, before, user)
+	return nil
+}
+
+// UnlockUser lifts the lockout of a user after failed logins
+func (s *UserService) UnlockUser(ctx context.Context, id string) error {
+	user, err := s.GetUser(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Unlock(ctx, id); err != nil {
+		return errors.Wrap(err, "failed to unlock user")
+	}
+	before := auditSnapshot(user)
+	user.LockedUntil = nil
+
+	s.log.Info("User unlocked", "id", user.ID, "username", user.Username)
+	s.audit.Record(ctx, models.AuditActionUpdate, AuditResourceUser, user.ID, before, user)
+	return nil
+}
+
+// ForceLogout ends every session of a user at once
+func (s *UserService) ForceLogout(ctx context.Context, id string) error {
+	user, err := s.GetUser(ctx, id)
+	if err != nil {
+		return err
+	}
+	sessions, err := s.tokens.ListSessions(ctx, id, "")
+	if err != nil {
+		return err
+	}
+	if err := s.tokens.RevokeUserTokens(ctx, id, "", "logged out by administrator"); err != nil {
+		return err
+	}
+
+	s.log.Warn("User logged out by administrator", "id", user.ID, "username", user.Username, "sessions", len(sessions))
+	s.audit.Record(ctx, models.AuditActionDelete, AuditResourceSession, user.ID, sessions, nil)
+	return nil
+}
+
+// completeLogin records a successful login and starts the user's session
+func (s *UserService) completeLogin(ctx context.Context, user *models.User, meta models.SessionMetadata) (*models.TokenResponse, error) {
+	now := time.Now()
+	if err := s.repo.RecordLogin(ctx, user.ID, now); err != nil {
+		s.log.Error("Failed to record last login", "id", user.ID, "error", err)
+	}
+	user.LastLogin = &now
+	user.LockedUntil = nil
+
+	resp, err := s.tokens.IssueTokens(ctx, user, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("User logged in", "id", user.ID, "username", user.Username, "method", meta.Method, "mfa", meta.MFA)
+	return resp, nil
+}
+
+// recordFailedLogin counts a failed login and locks the account once the
+// lockout policy's limit is reached
+func (s *UserService) recordFailedLogin(ctx context.Context, user *models.User) {
+	s.lockout.recordFailure(ctx, s.repo, user, s.log)
+}
+
+// recordFailure counts a failed login or second factor check of a user,
+// locking the account once it had too many
+func (p LockoutPolicy) recordFailure(ctx context.Context, repo repository.UserRepository, user *models.User, log *logger.Logger) {
+	if p.MaxAttempts <= 0 {
+		return
+	}
+	count, err := repo.RecordFailedLogin(ctx, user.ID)
+	if err != nil {
+		log.Error("Failed to record failed login", "id", user.ID, "error", err)
+		return
+	}
+	if count < p.MaxAttempts {
+		return
+	}
+
+	until := time.Now().Add(p.Duration)
+	if err := repo.Lock(ctx, user.ID, until); err != nil {
+		log.Error("Failed to lock account", "id", user.ID, "error", err)
+		return
+	}
+	log.Warn("Account locked after failed logins", "id", user.ID, "username", user.Username, "attempts", count, "until", until)
+}
+
+// accountLocked reports whether a user is locked out after failed logins
+func accountLocked(user *models.User) bool {
+	return user.LockedUntil != nil && time.Now().Before(*user.LockedUntil)
+}
+
+// create validates and stores a new user with the given role
+func (s *UserService) create(ctx context.Context, req models.UserRegistration, role models.Role) (*models.User, error) {
+	req.Username = strings.TrimSpace(req.Username)
+	req.Email = strings.TrimSpace(req.Email)
+
+	existing, err := s.repo.GetByUsername(ctx, req.Username)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to check username")
+	}
+	if existing != nil {
+		return nil, errors.New("username is already taken")
+	}
+
+	existing, err = s.repo.GetByEmail(ctx, req.Email)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to check email")
+	}
+	if existing != nil {
+		return nil, errors.New("email is already registered")
+	}
+
+	hash, err := security.HashPassword(req.Password)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to hash password")
+	}
+
+	user := &models.User{
+		ID:           "usr-" + uuid.New().String()[:8],
+		Username:     req.Username,
+		Email:        req.Email,
+		PasswordHash: hash,
+		FirstName:    req.FirstName,
+		LastName:     req.LastName,
+		Role:         role,
+		Active:       true,
+	}
+
+	if err := s.repo.Create(ctx, user); err != nil {
+		return nil, errors.Wrap(err, "failed to create user")
+	}
+
+	s.log.Info("User registered", "id", user.ID, "username", user.Username, "role", user.Role)
+	s.audit.Record(ctx, models.AuditActionCreate, AuditResourceUser, user.ID, nil, user)
+	return user, nil
+}
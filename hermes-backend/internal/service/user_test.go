@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/domain/models"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/errors"
+)
+
+// newTestUserService creates a UserService for the user alice, who has not
+// enabled MFA
+func newTestUserService(t *testing.T) (*UserService, *TokenService, *models.User) {
+	t.Helper()
+	box := newTestSecretBox(t)
+	user, _ := newMFATestUser(t, box, false)
+	users := newFakeUserRepo(user)
+	tokens, _ := newTestTokenService(t, users)
+	log := newTestLogger()
+	audit := NewAuditService(&fakeAuditRepo{}, log)
+	mfa := NewMFAService(users, &fakeTokenRepo{}, box, "Hermes", LockoutPolicy{}, audit, log)
+	return NewUserService(users, tokens, mfa, LockoutPolicy{}, audit, log), tokens, user
+}
+
+func TestChangePasswordLogsOutOtherSessions(t *testing.T) {
+	ctx := context.Background()
+	s, tokens, user := newTestUserService(t)
+	login := func() *models.TokenResponse {
+		t.Helper()
+		resp, _, err := s.Login(ctx, models.UserLogin{Username: user.Username, Password: testPassword}, models.SessionMetadata{})
+		if err != nil {
+			t.Fatalf("Login: %v", err)
+		}
+		return resp
+	}
+	current, other := login(), login()
+	claims, err := tokens.issuer.Verify(current.AccessToken)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+
+	change := models.UserPasswordChange{CurrentPassword: testPassword, NewPassword: "a new password"}
+	if err := s.ChangePassword(ctx, user.ID, claims.SessionID, change); err != nil {
+		t.Fatalf("ChangePassword: %v", err)
+	}
+
+	if _, err := tokens.Refresh(ctx, other.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Refresh of another session = %v, want ErrInvalidRefreshToken", err)
+	}
+	if _, err := tokens.issuer.Verify(other.AccessToken); err == nil {
+		t.Error("the access token of another session is still accepted")
+	}
+	if _, err := tokens.issuer.Verify(current.AccessToken); err != nil {
+		t.Errorf("the current session's access token was revoked: %v", err)
+	}
+	if _, err := tokens.Refresh(ctx, current.RefreshToken); err != nil {
+		t.Errorf("Refresh of the current session: %v", err)
+	}
+}
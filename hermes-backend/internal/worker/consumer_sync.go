@@ -0,0 +1,62 @@
+// worker/consumer_sync.go
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/service"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/logger"
+)
+
+// ConsumerSyncer periodically reloads gateway consumers and their API keys so
+// that changes made through other Hermes instances reach this instance's proxy
+type ConsumerSyncer struct {
+	consumerService *service.ConsumerService
+	interval        time.Duration
+	log             *logger.Logger
+	stopCh          chan struct{}
+}
+
+func NewConsumerSyncer(consumerService *service.ConsumerService, interval time.Duration, log *logger.Logger) *ConsumerSyncer {
+	return &ConsumerSyncer{
+		consumerService: consumerService,
+		interval:        interval,
+		log:             log,
+		stopCh:          make(chan struct{}),
+	}
+}
+
+// Start begins syncing consumers on the configured interval
+func (s *ConsumerSyncer) Start() {
+	s.log.Info("Starting gateway consumer syncer", "interval", s.interval.String())
+
+	s.sync()
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.sync()
+		case <-s.stopCh:
+			s.log.Info("Stopping gateway consumer syncer")
+			return
+		}
+	}
+}
+
+// Stop gracefully stops the consumer syncer
+func (s *ConsumerSyncer) Stop() {
+	close(s.stopCh)
+}
+
+func (s *ConsumerSyncer) sync() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := s.consumerService.SyncConsumers(ctx); err != nil {
+		s.log.Error("Failed to sync gateway consumers", "error", err)
+	}
+}
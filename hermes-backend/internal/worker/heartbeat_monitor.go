@@ -0,0 +1,60 @@
+// worker/heartbeat_monitor.go
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/service"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/logger"
+)
+
+// HeartbeatMonitor periodically expires services that stopped sending
+// heartbeats, marking them UNKNOWN, then UNHEALTHY, and finally deregistering them
+type HeartbeatMonitor struct {
+	heartbeatService *service.HeartbeatService
+	interval         time.Duration
+	log              *logger.Logger
+	stopCh           chan struct{}
+}
+
+func NewHeartbeatMonitor(heartbeatService *service.HeartbeatService, interval time.Duration, log *logger.Logger) *HeartbeatMonitor {
+	return &HeartbeatMonitor{
+		heartbeatService: heartbeatService,
+		interval:         interval,
+		log:              log,
+		stopCh:           make(chan struct{}),
+	}
+}
+
+// Start begins checking heartbeats on the configured interval
+func (m *HeartbeatMonitor) Start() {
+	m.log.Info("Starting heartbeat monitor", "interval", m.interval.String())
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.check()
+		case <-m.stopCh:
+			m.log.Info("Stopping heartbeat monitor")
+			return
+		}
+	}
+}
+
+// Stop gracefully stops the heartbeat monitor
+func (m *HeartbeatMonitor) Stop() {
+	close(m.stopCh)
+}
+
+func (m *HeartbeatMonitor) check() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := m.heartbeatService.ExpireHeartbeats(ctx); err != nil {
+		m.log.Error("Failed to expire missed heartbeats", "error", err)
+	}
+}
@@ -0,0 +1,60 @@
+// worker/role_sync.go
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/service"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/logger"
+)
+
+// RoleSyncer periodically reloads custom roles so that changes made through
+// other Hermes instances are enforced by this instance
+type RoleSyncer struct {
+	roleService *service.RoleService
+	interval    time.Duration
+	log         *logger.Logger
+	stopCh      chan struct{}
+}
+
+func NewRoleSyncer(roleService *service.RoleService, interval time.Duration, log *logger.Logger) *RoleSyncer {
+	return &RoleSyncer{
+		roleService: roleService,
+		interval:    interval,
+		log:         log,
+		stopCh:      make(chan struct{}),
+	}
+}
+
+// Start begins syncing roles on the configured interval
+func (s *RoleSyncer) Start() {
+	s.log.Info("Starting role syncer", "interval", s.interval.String())
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.sync()
+		case <-s.stopCh:
+			s.log.Info("Stopping role syncer")
+			return
+		}
+	}
+}
+
+// Stop gracefully stops the role syncer
+func (s *RoleSyncer) Stop() {
+	close(s.stopCh)
+}
+
+func (s *RoleSyncer) sync() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := s.roleService.Reload(ctx); err != nil {
+		s.log.Error("Failed to sync roles", "error", err)
+	}
+}
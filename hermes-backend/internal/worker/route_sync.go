@@ -0,0 +1,62 @@
+// worker/route_sync.go
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/service"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/logger"
+)
+
+// RouteSyncer periodically reloads gateway routes so that changes made through
+// other Hermes instances, and expired faults, reach this instance's proxy
+type RouteSyncer struct {
+	routeService *service.RouteService
+	interval     time.Duration
+	log          *logger.Logger
+	stopCh       chan struct{}
+}
+
+func NewRouteSyncer(routeService *service.RouteService, interval time.Duration, log *logger.Logger) *RouteSyncer {
+	return &RouteSyncer{
+		routeService: routeService,
+		interval:     interval,
+		log:          log,
+		stopCh:       make(chan struct{}),
+	}
+}
+
+// Start begins syncing routes on the configured interval
+func (s *RouteSyncer) Start() {
+	s.log.Info("Starting gateway route syncer", "interval", s.interval.String())
+
+	s.sync()
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.sync()
+		case <-s.stopCh:
+			s.log.Info("Stopping gateway route syncer")
+			return
+		}
+	}
+}
+
+// Stop gracefully stops the route syncer
+func (s *RouteSyncer) Stop() {
+	close(s.stopCh)
+}
+
+func (s *RouteSyncer) sync() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := s.routeService.SyncRoutes(ctx); err != nil {
+		s.log.Error("Failed to sync gateway routes", "error", err)
+	}
+}
@@ -0,0 +1,91 @@
+// worker/tls_certificate_monitor.go
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/service"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/logger"
+)
+
+// expiryWarningRepeat is how often a warning is repeated for the same certificate
+const expiryWarningRepeat = 24 * time.Hour
+
+// TLSCertificateMonitor periodically reloads TLS certificates so that changes
+// made through other Hermes instances are served, and warns about certificates
+// that are about to expire
+type TLSCertificateMonitor struct {
+	certificateService *service.TLSCertificateService
+	interval           time.Duration
+	warnBefore         time.Duration
+	warned             map[string]time.Time
+	log                *logger.Logger
+	stopCh             chan struct{}
+}
+
+func NewTLSCertificateMonitor(certificateService *service.TLSCertificateService, interval, warnBefore time.Duration, log *logger.Logger) *TLSCertificateMonitor {
+	return &TLSCertificateMonitor{
+		certificateService: certificateService,
+		interval:           interval,
+		warnBefore:         warnBefore,
+		warned:             make(map[string]time.Time),
+		log:                log,
+		stopCh:             make(chan struct{}),
+	}
+}
+
+// Start begins monitoring certificates on the configured interval
+func (m *TLSCertificateMonitor) Start() {
+	m.log.Info("Starting TLS certificate monitor", "interval", m.interval.String(), "warn_before", m.warnBefore.String())
+
+	m.check()
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.check()
+		case <-m.stopCh:
+			m.log.Info("Stopping TLS certificate monitor")
+			return
+		}
+	}
+}
+
+// Stop gracefully stops the certificate monitor
+func (m *TLSCertificateMonitor) Stop() {
+	close(m.stopCh)
+}
+
+func (m *TLSCertificateMonitor) check() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := m.certificateService.Reload(ctx); err != nil {
+		m.log.Error("Failed to reload TLS certificates", "error", err)
+		return
+	}
+
+	expiring, err := m.certificateService.ExpiringCertificates(ctx, m.warnBefore)
+	if err != nil {
+		m.log.Error("Failed to check TLS certificate expiry", "error", err)
+		return
+	}
+
+	now := time.Now()
+	for _, cert := range expiring {
+		if last, ok := m.warned[cert.ID]; ok && now.Sub(last) < expiryWarningRepeat {
+			continue
+		}
+		m.warned[cert.ID] = now
+
+		if now.After(cert.NotAfter) {
+			m.log.Error("TLS certificate has expired", "id", cert.ID, "name", cert.Name, "hostnames", cert.Hostnames, "not_after", cert.NotAfter)
+		} else {
+			m.log.Warn("TLS certificate expires soon", "id", cert.ID, "name", cert.Name, "hostnames", cert.Hostnames, "not_after", cert.NotAfter, "remaining", time.Until(cert.NotAfter).Round(time.Hour).String())
+		}
+	}
+}
@@ -0,0 +1,73 @@
+// worker/token_keeper.go
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/amaydixit11/hermes/hermes-backend/internal/service"
+	"github.com/amaydixit11/hermes/hermes-backend/pkg/logger"
+)
+
+// tokenCleanupInterval is how often expired keys, refresh tokens and revocations are deleted
+const tokenCleanupInterval = time.Hour
+
+// TokenKeeper periodically reloads signing keys and the revocation list so that
+// rotations and revocations made through other Hermes instances take effect,
+// and deletes expired token data
+type TokenKeeper struct {
+	tokenService *service.TokenService
+	interval     time.Duration
+	lastCleanup  time.Time
+	log          *logger.Logger
+	stopCh       chan struct{}
+}
+
+func NewTokenKeeper(tokenService *service.TokenService, interval time.Duration, log *logger.Logger) *TokenKeeper {
+	return &TokenKeeper{
+		tokenService: tokenService,
+		interval:     interval,
+		log:          log,
+		stopCh:       make(chan struct{}),
+	}
+}
+
+// Start begins syncing token state on the configured interval
+func (k *TokenKeeper) Start() {
+	k.log.Info("Starting token keeper", "interval", k.interval.String())
+
+	ticker := time.NewTicker(k.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			k.sync()
+		case <-k.stopCh:
+			k.log.Info("Stopping token keeper")
+			return
+		}
+	}
+}
+
+// Stop gracefully stops the token keeper
+func (k *TokenKeeper) Stop() {
+	close(k.stopCh)
+}
+
+func (k *TokenKeeper) sync() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := k.tokenService.Sync(ctx); err != nil {
+		k.log.Error("Failed to sync signing keys and revocations", "error", err)
+	}
+
+	if time.Since(k.lastCleanup) < tokenCleanupInterval {
+		return
+	}
+	k.lastCleanup = time.Now()
+	if err := k.tokenService.Cleanup(ctx); err != nil {
+		k.log.Error("Failed to clean up expired tokens", "error", err)
+	}
+}
@@ -0,0 +1,3 @@
+-- Revert: Create routes table
+
+DROP TABLE IF EXISTS routes;
@@ -0,0 +1,19 @@
+-- Migration: Create routes table
+
+CREATE TABLE IF NOT EXISTS routes (
+    id VARCHAR(255) PRIMARY KEY,
+    path VARCHAR(255) NOT NULL UNIQUE,
+    description TEXT,
+    service_id VARCHAR(255) NOT NULL,
+    load_balancer_id VARCHAR(255),
+    targets TEXT[] DEFAULT '{}',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    headers JSONB DEFAULT '{}',
+    rate_limit JSONB,
+    fault JSONB,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_routes_service_id ON routes(service_id);
+CREATE INDEX idx_routes_active ON routes(active);
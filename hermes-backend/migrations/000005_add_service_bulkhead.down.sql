@@ -0,0 +1,3 @@
+-- Revert: Add bulkhead configuration to services
+
+ALTER TABLE services DROP COLUMN IF EXISTS bulkhead;
@@ -0,0 +1,3 @@
+-- Migration: Add bulkhead configuration to services
+
+ALTER TABLE services ADD COLUMN IF NOT EXISTS bulkhead JSONB;
@@ -0,0 +1,3 @@
+-- Revert: Add adaptive concurrency configuration to services
+
+ALTER TABLE services DROP COLUMN IF EXISTS adaptive_concurrency;
@@ -0,0 +1,3 @@
+-- Migration: Add adaptive concurrency configuration to services
+
+ALTER TABLE services ADD COLUMN IF NOT EXISTS adaptive_concurrency JSONB;
@@ -0,0 +1,4 @@
+-- Revert: Add criticality classes to routes
+
+ALTER TABLE routes DROP COLUMN IF EXISTS criticality_rules;
+ALTER TABLE routes DROP COLUMN IF EXISTS criticality;
@@ -0,0 +1,4 @@
+-- Migration: Add criticality classes to routes
+
+ALTER TABLE routes ADD COLUMN IF NOT EXISTS criticality VARCHAR(20) NOT NULL DEFAULT 'DEFAULT';
+ALTER TABLE routes ADD COLUMN IF NOT EXISTS criticality_rules JSONB;
@@ -0,0 +1,3 @@
+-- Revert: Create TLS certificates table
+
+DROP TABLE IF EXISTS tls_certificates;
@@ -0,0 +1,16 @@
+-- Migration: Create TLS certificates table
+
+CREATE TABLE IF NOT EXISTS tls_certificates (
+    id VARCHAR(255) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    hostnames TEXT[] DEFAULT '{}',
+    certificate TEXT NOT NULL,
+    encrypted_key BYTEA NOT NULL,
+    issuer TEXT,
+    not_before TIMESTAMP WITH TIME ZONE,
+    not_after TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_tls_certificates_not_after ON tls_certificates(not_after);
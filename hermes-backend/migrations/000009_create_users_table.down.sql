@@ -0,0 +1,3 @@
+-- Revert: Create users table
+
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,5 @@
+-- Revert: Create token tables
+
+DROP TABLE IF EXISTS revoked_tokens;
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS signing_keys;
@@ -0,0 +1,3 @@
+-- Revert: Create role definitions table
+
+DROP TABLE IF EXISTS role_definitions;
@@ -0,0 +1,9 @@
+-- Migration: Create role definitions table
+
+CREATE TABLE IF NOT EXISTS role_definitions (
+    name VARCHAR(50) PRIMARY KEY,
+    description TEXT,
+    permissions TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
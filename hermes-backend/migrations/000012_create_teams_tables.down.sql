@@ -0,0 +1,8 @@
+-- Revert: Create teams tables and service ownership
+
+ALTER TABLE services
+    DROP COLUMN IF EXISTS visibility,
+    DROP COLUMN IF EXISTS owner_team_id;
+
+DROP TABLE IF EXISTS team_members;
+DROP TABLE IF EXISTS teams;
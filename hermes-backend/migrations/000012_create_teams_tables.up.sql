@@ -0,0 +1,24 @@
+-- Migration: Create teams tables and service ownership
+
+CREATE TABLE IF NOT EXISTS teams (
+    id VARCHAR(255) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS team_members (
+    team_id VARCHAR(255) NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    user_id VARCHAR(255) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (team_id, user_id)
+);
+
+CREATE INDEX idx_team_members_user_id ON team_members(user_id);
+
+ALTER TABLE services
+    ADD COLUMN owner_team_id VARCHAR(255) REFERENCES teams(id),
+    ADD COLUMN visibility VARCHAR(20) NOT NULL DEFAULT 'PUBLIC';
+
+CREATE INDEX idx_services_owner_team_id ON services(owner_team_id);
@@ -0,0 +1,3 @@
+-- Revert: Add single sign-on identity to users
+
+ALTER TABLE users DROP COLUMN IF EXISTS external_id;
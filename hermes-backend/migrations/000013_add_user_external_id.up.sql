@@ -0,0 +1,3 @@
+-- Migration: Add single sign-on identity to users
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS external_id VARCHAR(255) UNIQUE;
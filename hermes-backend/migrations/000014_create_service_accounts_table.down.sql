@@ -0,0 +1,3 @@
+-- Revert: Create service accounts table
+
+DROP TABLE IF EXISTS service_accounts;
@@ -0,0 +1,18 @@
+-- Migration: Create service accounts table
+
+CREATE TABLE IF NOT EXISTS service_accounts (
+    id VARCHAR(255) PRIMARY KEY,
+    service_id VARCHAR(255) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+    secret_hash VARCHAR(64) NOT NULL,
+    secret_rotated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    created_by VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT idx_service_accounts_service_id_name UNIQUE (service_id, name)
+);
+
+CREATE INDEX idx_service_accounts_service_id ON service_accounts(service_id);
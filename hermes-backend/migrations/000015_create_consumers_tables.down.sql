@@ -0,0 +1,6 @@
+-- Revert: Create API consumers tables
+
+ALTER TABLE routes DROP COLUMN IF EXISTS require_api_key;
+
+DROP TABLE IF EXISTS consumer_keys;
+DROP TABLE IF EXISTS consumers;
@@ -0,0 +1,26 @@
+-- Migration: Create API consumers tables
+
+CREATE TABLE IF NOT EXISTS consumers (
+    id VARCHAR(255) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    allowed_routes TEXT[] NOT NULL DEFAULT '{}',
+    rate_limit JSONB,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS consumer_keys (
+    id VARCHAR(255) PRIMARY KEY,
+    consumer_id VARCHAR(255) NOT NULL REFERENCES consumers(id) ON DELETE CASCADE,
+    name VARCHAR(100),
+    prefix VARCHAR(20) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_consumer_keys_consumer_id ON consumer_keys(consumer_id);
+
+ALTER TABLE routes ADD COLUMN IF NOT EXISTS require_api_key BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,3 @@
+-- Revert: Add bearer token requirements to routes
+
+ALTER TABLE routes DROP COLUMN IF EXISTS jwt;
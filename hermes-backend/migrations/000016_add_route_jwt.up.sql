@@ -0,0 +1,3 @@
+-- Migration: Add bearer token requirements to routes
+
+ALTER TABLE routes ADD COLUMN IF NOT EXISTS jwt JSONB;
@@ -0,0 +1,5 @@
+-- Revert: Create append-only audit log table
+
+DROP TRIGGER IF EXISTS audit_entries_append_only ON audit_entries;
+DROP FUNCTION IF EXISTS audit_entries_append_only();
+DROP TABLE IF EXISTS audit_entries;
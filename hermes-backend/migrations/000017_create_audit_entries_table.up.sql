@@ -0,0 +1,30 @@
+-- Migration: Create append-only audit log table
+
+CREATE TABLE IF NOT EXISTS audit_entries (
+    id BIGSERIAL PRIMARY KEY,
+    timestamp TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    actor_id VARCHAR(255),
+    actor_name VARCHAR(255),
+    actor_role VARCHAR(50),
+    source_ip VARCHAR(64),
+    action VARCHAR(20) NOT NULL,
+    resource_type VARCHAR(50) NOT NULL,
+    resource_id VARCHAR(255),
+    before JSONB,
+    after JSONB
+);
+
+CREATE INDEX idx_audit_entries_timestamp ON audit_entries(timestamp);
+CREATE INDEX idx_audit_entries_actor_id ON audit_entries(actor_id);
+CREATE INDEX idx_audit_entries_resource ON audit_entries(resource_type, resource_id);
+
+-- Entries can only be added, never changed or removed
+CREATE OR REPLACE FUNCTION audit_entries_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit entries cannot be modified';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_entries_append_only
+    BEFORE UPDATE OR DELETE OR TRUNCATE ON audit_entries
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_entries_append_only();
@@ -0,0 +1,12 @@
+-- Revert: Create namespaces and scope services and routes to them
+
+-- Fails if the same service name is used in several namespaces
+DROP INDEX IF EXISTS idx_routes_namespace;
+ALTER TABLE routes DROP COLUMN IF EXISTS namespace;
+
+DROP INDEX IF EXISTS idx_services_namespace_name;
+ALTER TABLE services ADD CONSTRAINT services_name_key UNIQUE (name);
+ALTER TABLE services DROP COLUMN IF EXISTS namespace;
+
+DROP TABLE IF EXISTS namespace_bindings;
+DROP TABLE IF EXISTS namespaces;
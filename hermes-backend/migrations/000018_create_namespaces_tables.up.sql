@@ -0,0 +1,36 @@
+-- Migration: Create namespaces and scope services and routes to them
+
+CREATE TABLE IF NOT EXISTS namespaces (
+    name VARCHAR(63) PRIMARY KEY,
+    description TEXT,
+    quota JSONB,
+    allowed_dependencies TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+INSERT INTO namespaces (name, description)
+VALUES ('default', 'Services and routes created without a namespace')
+ON CONFLICT (name) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS namespace_bindings (
+    id VARCHAR(255) PRIMARY KEY,
+    namespace VARCHAR(63) NOT NULL REFERENCES namespaces(name) ON DELETE CASCADE,
+    user_id VARCHAR(255) REFERENCES users(id) ON DELETE CASCADE,
+    team_id VARCHAR(255) REFERENCES teams(id) ON DELETE CASCADE,
+    role VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT chk_namespace_bindings_subject CHECK ((user_id IS NULL) <> (team_id IS NULL))
+);
+
+CREATE INDEX idx_namespace_bindings_namespace ON namespace_bindings(namespace);
+CREATE INDEX idx_namespace_bindings_user_id ON namespace_bindings(user_id);
+CREATE INDEX idx_namespace_bindings_team_id ON namespace_bindings(team_id);
+
+-- Service names are unique per namespace instead of globally
+ALTER TABLE services ADD COLUMN IF NOT EXISTS namespace VARCHAR(63) NOT NULL DEFAULT 'default' REFERENCES namespaces(name);
+ALTER TABLE services DROP CONSTRAINT IF EXISTS services_name_key;
+CREATE UNIQUE INDEX idx_services_namespace_name ON services(namespace, name);
+
+ALTER TABLE routes ADD COLUMN IF NOT EXISTS namespace VARCHAR(63) NOT NULL DEFAULT 'default' REFERENCES namespaces(name);
+CREATE INDEX idx_routes_namespace ON routes(namespace);
@@ -0,0 +1,3 @@
+-- Revert: Add client address restrictions to routes
+
+ALTER TABLE routes DROP COLUMN IF EXISTS ip_access;
@@ -0,0 +1,3 @@
+-- Migration: Add client address restrictions to routes
+
+ALTER TABLE routes ADD COLUMN IF NOT EXISTS ip_access JSONB;
@@ -0,0 +1,8 @@
+-- Revert: Create API specifications table for request validation
+
+DROP INDEX IF EXISTS idx_service_versions_spec_id;
+ALTER TABLE service_versions DROP COLUMN IF EXISTS spec_id;
+
+ALTER TABLE routes DROP COLUMN IF EXISTS validation;
+
+DROP TABLE IF EXISTS api_specs;
@@ -0,0 +1,17 @@
+-- Migration: Create API specifications table for request validation
+
+CREATE TABLE IF NOT EXISTS api_specs (
+    id VARCHAR(255) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT,
+    title VARCHAR(255),
+    version VARCHAR(100),
+    document JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+ALTER TABLE routes ADD COLUMN IF NOT EXISTS validation JSONB;
+
+ALTER TABLE service_versions ADD COLUMN IF NOT EXISTS spec_id VARCHAR(255) REFERENCES api_specs(id);
+CREATE INDEX IF NOT EXISTS idx_service_versions_spec_id ON service_versions(spec_id);
@@ -0,0 +1,3 @@
+-- Revert: Add CORS policies to routes
+
+ALTER TABLE routes DROP COLUMN IF EXISTS cors;
@@ -0,0 +1,3 @@
+-- Migration: Add CORS policies to routes
+
+ALTER TABLE routes ADD COLUMN IF NOT EXISTS cors JSONB;
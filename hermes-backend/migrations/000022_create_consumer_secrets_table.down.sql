@@ -0,0 +1,5 @@
+-- Revert: Create consumer signing secrets table and route signature requirements
+
+ALTER TABLE routes DROP COLUMN IF EXISTS hmac;
+
+DROP TABLE IF EXISTS consumer_secrets;
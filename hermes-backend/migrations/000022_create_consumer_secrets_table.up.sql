@@ -0,0 +1,14 @@
+-- Migration: Create consumer signing secrets table and route signature requirements
+
+CREATE TABLE IF NOT EXISTS consumer_secrets (
+    id VARCHAR(255) PRIMARY KEY,
+    consumer_id VARCHAR(255) NOT NULL REFERENCES consumers(id) ON DELETE CASCADE,
+    name VARCHAR(100),
+    encrypted_secret BYTEA NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_consumer_secrets_consumer_id ON consumer_secrets(consumer_id);
+
+ALTER TABLE routes ADD COLUMN IF NOT EXISTS hmac JSONB;
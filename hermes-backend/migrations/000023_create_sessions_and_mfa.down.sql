@@ -0,0 +1,11 @@
+-- Revert: Create sessions and add multi-factor authentication and lockout to users
+
+DROP TABLE IF EXISTS mfa_challenges;
+DROP TABLE IF EXISTS sessions;
+
+ALTER TABLE users DROP COLUMN IF EXISTS locked_until;
+ALTER TABLE users DROP COLUMN IF EXISTS failed_logins;
+ALTER TABLE users DROP COLUMN IF EXISTS recovery_codes;
+ALTER TABLE users DROP COLUMN IF EXISTS mfa_last_step;
+ALTER TABLE users DROP COLUMN IF EXISTS mfa_secret;
+ALTER TABLE users DROP COLUMN IF EXISTS mfa_enabled;
@@ -0,0 +1,6 @@
+-- Revert: Add heartbeat TTLs and deregistration to services
+
+DROP INDEX IF EXISTS idx_services_deregistered_at;
+
+ALTER TABLE services DROP COLUMN IF EXISTS deregistered_at;
+ALTER TABLE services DROP COLUMN IF EXISTS heartbeat_ttl;
@@ -0,0 +1,6 @@
+-- Migration: Add heartbeat TTLs and deregistration to services
+
+ALTER TABLE services ADD COLUMN IF NOT EXISTS heartbeat_ttl INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE services ADD COLUMN IF NOT EXISTS deregistered_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_services_deregistered_at ON services(deregistered_at);
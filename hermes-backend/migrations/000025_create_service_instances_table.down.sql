@@ -0,0 +1,6 @@
+-- Revert: Create service instances table and record instance health
+
+DROP INDEX IF EXISTS idx_health_history_instance_id;
+ALTER TABLE health_history DROP COLUMN IF EXISTS instance_id;
+
+DROP TABLE IF EXISTS service_instances;
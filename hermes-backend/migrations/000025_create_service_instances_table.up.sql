@@ -0,0 +1,23 @@
+-- Migration: Create service instances table and record instance health
+
+CREATE TABLE IF NOT EXISTS service_instances (
+    id VARCHAR(255) PRIMARY KEY,
+    service_id VARCHAR(255) NOT NULL REFERENCES services(id) ON DELETE CASCADE,
+    endpoint VARCHAR(255) NOT NULL,
+    zone VARCHAR(100),
+    weight INTEGER NOT NULL DEFAULT 1,
+    metadata JSONB,
+    status VARCHAR(20) NOT NULL DEFAULT 'UNKNOWN',
+    failures INTEGER NOT NULL DEFAULT 0,
+    heartbeat_ttl INTEGER NOT NULL DEFAULT 0,
+    last_seen TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT idx_service_instances_service_id_endpoint UNIQUE (service_id, endpoint)
+);
+
+CREATE INDEX IF NOT EXISTS idx_service_instances_service_id ON service_instances(service_id);
+CREATE INDEX IF NOT EXISTS idx_service_instances_zone ON service_instances(zone);
+
+ALTER TABLE health_history ADD COLUMN IF NOT EXISTS instance_id VARCHAR(255);
+CREATE INDEX IF NOT EXISTS idx_health_history_instance_id ON health_history(instance_id);
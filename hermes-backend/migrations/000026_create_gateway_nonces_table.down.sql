@@ -0,0 +1,3 @@
+-- Revert: Create gateway nonces table shared by every Hermes instance for replay protection
+
+DROP TABLE IF EXISTS gateway_nonces;
@@ -0,0 +1,8 @@
+-- Migration: Create gateway nonces table shared by every Hermes instance for replay protection
+
+CREATE TABLE IF NOT EXISTS gateway_nonces (
+    nonce VARCHAR(64) PRIMARY KEY,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_gateway_nonces_expires_at ON gateway_nonces(expires_at);
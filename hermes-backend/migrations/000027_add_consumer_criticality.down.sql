@@ -0,0 +1,3 @@
+-- Revert: Add criticality classes to consumers
+
+ALTER TABLE consumers DROP COLUMN IF EXISTS criticality;
@@ -0,0 +1,3 @@
+-- Migration: Add criticality classes to consumers
+
+ALTER TABLE consumers ADD COLUMN IF NOT EXISTS criticality VARCHAR(20) NOT NULL DEFAULT '';
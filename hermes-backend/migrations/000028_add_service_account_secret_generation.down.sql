@@ -0,0 +1,3 @@
+-- Revert: Add secret generations to service accounts
+
+ALTER TABLE service_accounts DROP COLUMN IF EXISTS secret_generation;
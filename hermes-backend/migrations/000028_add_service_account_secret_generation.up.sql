@@ -0,0 +1,3 @@
+-- Migration: Add secret generations to service accounts
+
+ALTER TABLE service_accounts ADD COLUMN IF NOT EXISTS secret_generation INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,6 @@
+-- Migration: Store the ID of the replacement of a rotated refresh token instead of its hash
+
+UPDATE refresh_tokens AS rotated
+SET replaced_by = replacement.id
+FROM refresh_tokens AS replacement
+WHERE rotated.replaced_by = replacement.token_hash;